@@ -0,0 +1,177 @@
+package integrations
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Config holds the clients constructed by LoadFromEnv. A client is nil when
+// its platform is not configured in the environment.
+type Config struct {
+	Twitter   *TwitterClient
+	Instagram *InstagramClient
+	Facebook  *FaceBookClient
+	LinkedIn  *LinkedInClient
+	TikTok    *TikTokClient
+	YouTube   *YouTubeClient
+	Pinterest *Pinterest
+	Reddit    *RedditClient
+	Dribbble  *DribbbleClient
+	Threads   *ThreadService
+	WhatsApp  *WhatsAppClient
+	Telegram  *TelegramClient
+	Slack     *SlackClient
+}
+
+// defaultRedditUserAgent is used when REDDIT_USER_AGENT is not set
+const defaultRedditUserAgent = "postly/1.0"
+
+// LoadFromEnv constructs a client for every platform whose credentials are
+// present in the environment. A platform is skipped when none of its required
+// variables are set; setting only some of them is an error.
+//
+// Required variables per platform (optional ones in brackets):
+//
+//	Twitter:   TWITTER_BEARER_TOKEN [TWITTER_API_KEY, TWITTER_API_SECRET,
+//	           TWITTER_ACCESS_TOKEN, TWITTER_ACCESS_TOKEN_SECRET]
+//	Instagram: INSTAGRAM_APP_ID, INSTAGRAM_APP_SECRET [INSTAGRAM_REDIRECT_URI,
+//	           INSTAGRAM_ACCESS_TOKEN]
+//	Facebook:  FACEBOOK_ACCESS_TOKEN [FACEBOOK_APP_SECRET]
+//	LinkedIn:  LINKEDIN_CLIENT_ID, LINKEDIN_CLIENT_SECRET [LINKEDIN_REDIRECT_URI,
+//	           LINKEDIN_ACCESS_TOKEN, LINKEDIN_REFRESH_TOKEN]
+//	TikTok:    TIKTOK_ACCESS_TOKEN, TIKTOK_API_KEY
+//	YouTube:   YOUTUBE_ACCESS_TOKEN
+//	Pinterest: PINTEREST_ACCESS_TOKEN
+//	Reddit:    REDDIT_CLIENT_ID, REDDIT_CLIENT_SECRET, REDDIT_USERNAME,
+//	           REDDIT_PASSWORD [REDDIT_USER_AGENT]
+//	Dribbble:  DRIBBBLE_ACCESS_TOKEN
+//	Threads:   THREADS_BASE_URL, THREADS_AUTH_TOKEN
+//	WhatsApp:  WHATSAPP_ACCESS_TOKEN, WHATSAPP_PHONE_NUMBER_ID
+//	Telegram:  TELEGRAM_BOT_TOKEN
+//	Slack:     SLACK_BOT_TOKEN
+func LoadFromEnv() (*Config, error) {
+	cfg := &Config{}
+	var errs []error
+
+	if v, ok, err := requireEnv("twitter", "TWITTER_BEARER_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Twitter = NewTwitterClient(
+			os.Getenv("TWITTER_API_KEY"),
+			os.Getenv("TWITTER_API_SECRET"),
+			os.Getenv("TWITTER_ACCESS_TOKEN"),
+			os.Getenv("TWITTER_ACCESS_TOKEN_SECRET"),
+			v[0],
+		)
+	}
+
+	if v, ok, err := requireEnv("instagram", "INSTAGRAM_APP_ID", "INSTAGRAM_APP_SECRET"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Instagram = NewInstagramClient(v[0], v[1], os.Getenv("INSTAGRAM_REDIRECT_URI"))
+		cfg.Instagram.AccessToken = os.Getenv("INSTAGRAM_ACCESS_TOKEN")
+	}
+
+	if v, ok, err := requireEnv("facebook", "FACEBOOK_ACCESS_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Facebook = NewFaceBookClient(v[0])
+		cfg.Facebook.AppSecret = os.Getenv("FACEBOOK_APP_SECRET")
+	}
+
+	if v, ok, err := requireEnv("linkedin", "LINKEDIN_CLIENT_ID", "LINKEDIN_CLIENT_SECRET"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.LinkedIn = NewLinkedInClient(v[0], v[1], os.Getenv("LINKEDIN_REDIRECT_URI"))
+		cfg.LinkedIn.AccessToken = os.Getenv("LINKEDIN_ACCESS_TOKEN")
+		cfg.LinkedIn.RefreshToken = os.Getenv("LINKEDIN_REFRESH_TOKEN")
+	}
+
+	if v, ok, err := requireEnv("tiktok", "TIKTOK_ACCESS_TOKEN", "TIKTOK_API_KEY"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.TikTok = NewTikTokClient(v[0], v[1])
+	}
+
+	if v, ok, err := requireEnv("youtube", "YOUTUBE_ACCESS_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.YouTube = NewYouTubeClient(v[0])
+	}
+
+	if v, ok, err := requireEnv("pinterest", "PINTEREST_ACCESS_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Pinterest = NewPinterest(v[0])
+	}
+
+	if v, ok, err := requireEnv("reddit", "REDDIT_CLIENT_ID", "REDDIT_CLIENT_SECRET", "REDDIT_USERNAME", "REDDIT_PASSWORD"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		userAgent := os.Getenv("REDDIT_USER_AGENT")
+		if userAgent == "" {
+			userAgent = defaultRedditUserAgent
+		}
+		cfg.Reddit = NewRedditClient(v[0], v[1], v[2], v[3], userAgent)
+	}
+
+	if v, ok, err := requireEnv("dribbble", "DRIBBBLE_ACCESS_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Dribbble = NewDribbbleClient(v[0])
+	}
+
+	if v, ok, err := requireEnv("threads", "THREADS_BASE_URL", "THREADS_AUTH_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Threads = NewThreadService(v[0], v[1])
+	}
+
+	if v, ok, err := requireEnv("whatsapp", "WHATSAPP_ACCESS_TOKEN", "WHATSAPP_PHONE_NUMBER_ID"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.WhatsApp = NewWhatsAppClient(v[0], v[1])
+	}
+
+	if v, ok, err := requireEnv("telegram", "TELEGRAM_BOT_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Telegram = NewTelegramClient(v[0])
+	}
+
+	if v, ok, err := requireEnv("slack", "SLACK_BOT_TOKEN"); err != nil {
+		errs = append(errs, err)
+	} else if ok {
+		cfg.Slack = NewSlackClient(v[0])
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return cfg, nil
+}
+
+// requireEnv reads the required variables of a platform. ok is false when none
+// of them are set; an error is returned when only some of them are.
+func requireEnv(platform string, names ...string) (values []string, ok bool, err error) {
+	values = make([]string, len(names))
+	var missing []string
+	for i, name := range names {
+		values[i] = os.Getenv(name)
+		if values[i] == "" {
+			missing = append(missing, name)
+		}
+	}
+
+	switch len(missing) {
+	case 0:
+		return values, true, nil
+	case len(names):
+		return nil, false, nil
+	}
+
+	return nil, false, fmt.Errorf("%s is partially configured: missing %s", platform, strings.Join(missing, ", "))
+}
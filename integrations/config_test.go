@@ -0,0 +1,114 @@
+package integrations
+
+import (
+	"strings"
+	"testing"
+)
+
+// configEnv lists every variable LoadFromEnv reads
+var configEnv = []string{
+	"TWITTER_BEARER_TOKEN", "TWITTER_API_KEY", "TWITTER_API_SECRET", "TWITTER_ACCESS_TOKEN", "TWITTER_ACCESS_TOKEN_SECRET",
+	"INSTAGRAM_APP_ID", "INSTAGRAM_APP_SECRET", "INSTAGRAM_REDIRECT_URI", "INSTAGRAM_ACCESS_TOKEN",
+	"FACEBOOK_ACCESS_TOKEN", "FACEBOOK_APP_SECRET",
+	"LINKEDIN_CLIENT_ID", "LINKEDIN_CLIENT_SECRET", "LINKEDIN_REDIRECT_URI", "LINKEDIN_ACCESS_TOKEN", "LINKEDIN_REFRESH_TOKEN",
+	"TIKTOK_ACCESS_TOKEN", "TIKTOK_API_KEY",
+	"YOUTUBE_ACCESS_TOKEN",
+	"PINTEREST_ACCESS_TOKEN",
+	"REDDIT_CLIENT_ID", "REDDIT_CLIENT_SECRET", "REDDIT_USERNAME", "REDDIT_PASSWORD", "REDDIT_USER_AGENT",
+	"DRIBBBLE_ACCESS_TOKEN",
+	"THREADS_BASE_URL", "THREADS_AUTH_TOKEN",
+	"WHATSAPP_ACCESS_TOKEN", "WHATSAPP_PHONE_NUMBER_ID",
+	"TELEGRAM_BOT_TOKEN",
+	"SLACK_BOT_TOKEN",
+}
+
+// setConfigEnv clears the configuration variables and then sets env
+func setConfigEnv(t *testing.T, env map[string]string) {
+	t.Helper()
+
+	for _, name := range configEnv {
+		t.Setenv(name, "")
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+func TestLoadFromEnvBuildsConfiguredClients(t *testing.T) {
+	setConfigEnv(t, map[string]string{
+		"TWITTER_BEARER_TOKEN":   "bearer",
+		"TWITTER_API_KEY":        "key",
+		"FACEBOOK_ACCESS_TOKEN":  "fb-token",
+		"FACEBOOK_APP_SECRET":    "fb-secret",
+		"LINKEDIN_CLIENT_ID":     "li-id",
+		"LINKEDIN_CLIENT_SECRET": "li-secret",
+		"LINKEDIN_REFRESH_TOKEN": "li-refresh",
+		"REDDIT_CLIENT_ID":       "r-id",
+		"REDDIT_CLIENT_SECRET":   "r-secret",
+		"REDDIT_USERNAME":        "r-user",
+		"REDDIT_PASSWORD":        "r-pass",
+		"SLACK_BOT_TOKEN":        "xoxb",
+	})
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Twitter == nil || cfg.Twitter.BearerToken != "bearer" || cfg.Twitter.APIKey != "key" {
+		t.Errorf("Twitter = %+v", cfg.Twitter)
+	}
+	if cfg.Facebook == nil || cfg.Facebook.AppSecret != "fb-secret" {
+		t.Errorf("Facebook = %+v", cfg.Facebook)
+	}
+	if cfg.LinkedIn == nil || cfg.LinkedIn.ClientID != "li-id" || cfg.LinkedIn.RefreshToken != "li-refresh" {
+		t.Errorf("LinkedIn = %+v", cfg.LinkedIn)
+	}
+	if cfg.Reddit == nil || cfg.Reddit.Username != "r-user" || cfg.Reddit.UserAgent != defaultRedditUserAgent {
+		t.Errorf("Reddit = %+v", cfg.Reddit)
+	}
+	if cfg.Slack == nil || cfg.Slack.BotToken != "xoxb" {
+		t.Errorf("Slack = %+v", cfg.Slack)
+	}
+
+	if cfg.Instagram != nil || cfg.TikTok != nil || cfg.YouTube != nil || cfg.Pinterest != nil ||
+		cfg.Dribbble != nil || cfg.Threads != nil || cfg.WhatsApp != nil || cfg.Telegram != nil {
+		t.Errorf("unconfigured platforms got clients: %+v", cfg)
+	}
+}
+
+func TestLoadFromEnvEmpty(t *testing.T) {
+	setConfigEnv(t, nil)
+
+	cfg, err := LoadFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *cfg != (Config{}) {
+		t.Errorf("cfg = %+v, want no clients", cfg)
+	}
+}
+
+func TestLoadFromEnvReportsPartialConfiguration(t *testing.T) {
+	setConfigEnv(t, map[string]string{
+		"REDDIT_CLIENT_ID":         "r-id",
+		"REDDIT_USERNAME":          "r-user",
+		"WHATSAPP_PHONE_NUMBER_ID": "123",
+		"TELEGRAM_BOT_TOKEN":       "bot",
+	})
+
+	cfg, err := LoadFromEnv()
+	if err == nil {
+		t.Fatalf("LoadFromEnv() = %+v, want an error", cfg)
+	}
+
+	msg := err.Error()
+	for _, want := range []string{"reddit", "REDDIT_CLIENT_SECRET", "REDDIT_PASSWORD", "whatsapp", "WHATSAPP_ACCESS_TOKEN"} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q does not mention %s", msg, want)
+		}
+	}
+	if strings.Contains(msg, "telegram") {
+		t.Errorf("error %q reports the fully configured Telegram", msg)
+	}
+}
@@ -0,0 +1,58 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+)
+
+// Cursor marks a position in a paginated list, whatever the platform calls
+// it: Facebook paging cursors, Twitter next_token or Reddit after. Methods
+// that return a page also return the Cursor for the next one; pass it back to
+// continue. The zero Cursor starts at the first page.
+type Cursor struct {
+	Platform string `json:"platform"`
+	Next     string `json:"next,omitempty"`     // position of the next page, empty on the last page
+	Previous string `json:"previous,omitempty"` // position of the previous page, if the platform reports it
+}
+
+// HasNext reports whether there is another page after the one the cursor was
+// returned with
+func (c Cursor) HasNext() bool {
+	return c.Next != ""
+}
+
+// PageFunc fetches the page at cursor and returns its items together with the
+// cursor of the following page
+type PageFunc[T any] func(ctx context.Context, cursor Cursor) ([]T, Cursor, error)
+
+// Paginate calls fn with every item of every page fetched by page, starting
+// from the first page, until the last page is reached. fn can return
+// ErrStopIteration to stop early; any other error aborts the walk and is
+// returned.
+func Paginate[T any](ctx context.Context, page PageFunc[T], fn func(T) error) error {
+	var cursor Cursor
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		items, next, err := page(ctx, cursor)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			if err := fn(item); err != nil {
+				if errors.Is(err, ErrStopIteration) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if !next.HasNext() || len(items) == 0 || next.Next == cursor.Next {
+			return nil
+		}
+		cursor = next
+	}
+}
@@ -0,0 +1,177 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestFacebookCommentsPageCursor(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v18.0/post_1/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch after := r.URL.Query().Get("after"); after {
+		case "":
+			fmt.Fprint(w, `{
+				"data": [{"id": "c1", "message": "first"}],
+				"paging": {
+					"cursors": {"before": "QVFIUm1", "after": "QVFIUm2"},
+					"next": "https://graph.facebook.com/v18.0/post_1/comments?after=QVFIUm2"
+				}
+			}`)
+		case "QVFIUm2":
+			// the last page still reports an after cursor, but no next link
+			fmt.Fprint(w, `{
+				"data": [{"id": "c2", "message": "second"}],
+				"paging": {"cursors": {"before": "QVFIUm3", "after": "QVFIUm4"}}
+			}`)
+		default:
+			t.Errorf("unexpected after %q", after)
+		}
+	})
+
+	comments, cursor, err := c.GetCommentsPage("post_1", 1, Cursor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Cursor{Platform: PlatformFacebook, Next: "QVFIUm2", Previous: "QVFIUm1"}
+	if len(comments) != 1 || cursor != want {
+		t.Errorf("first page = %d comments, %+v, want 1 comment, %+v", len(comments), cursor, want)
+	}
+
+	comments, cursor, err = c.GetCommentsPage("post_1", 1, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Cursor{Platform: PlatformFacebook, Previous: "QVFIUm3"}
+	if len(comments) != 1 || comments[0].ID != "c2" || cursor != want || cursor.HasNext() {
+		t.Errorf("last page = %+v, %+v, want c2 and %+v", comments, cursor, want)
+	}
+}
+
+func TestTwitterMentionsPageCursor(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/2244994945/mentions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch token := r.URL.Query().Get("pagination_token"); token {
+		case "":
+			fmt.Fprint(w, `{
+				"data": [{"id": "1", "text": "@you hi"}],
+				"meta": {"result_count": 1, "newest_id": "1", "oldest_id": "1", "next_token": "7140dibdnow9c7btw3w29grvxfcgvpb9n9coehpk7xz5i"}
+			}`)
+		case "7140dibdnow9c7btw3w29grvxfcgvpb9n9coehpk7xz5i":
+			fmt.Fprint(w, `{
+				"data": [{"id": "2", "text": "@you again"}],
+				"meta": {"result_count": 1, "newest_id": "2", "oldest_id": "2"}
+			}`)
+		default:
+			t.Errorf("unexpected pagination_token %q", token)
+		}
+	})
+
+	tweets, cursor, err := c.GetMentionsPage("2244994945", 10, Cursor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Cursor{Platform: PlatformTwitter, Next: "7140dibdnow9c7btw3w29grvxfcgvpb9n9coehpk7xz5i"}
+	if len(tweets) != 1 || cursor != want {
+		t.Errorf("first page = %d tweets, %+v, want 1 tweet, %+v", len(tweets), cursor, want)
+	}
+
+	tweets, cursor, err = c.GetMentionsPage("2244994945", 10, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets) != 1 || tweets[0].ID != "2" || cursor.HasNext() || cursor.Platform != PlatformTwitter {
+		t.Errorf("last page = %+v, %+v, want tweet 2 and no next page", tweets, cursor)
+	}
+}
+
+func TestRedditListingCursor(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/r/golang/new" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("limit") != "2" {
+			t.Errorf("limit = %q, want the caller's 2", q.Get("limit"))
+		}
+		switch after := q.Get("after"); after {
+		case "":
+			fmt.Fprint(w, `{"kind":"Listing","data":{"after":"t3_b","before":null,"children":[
+				{"kind":"t3","data":{"id":"a"}},{"kind":"t3","data":{"id":"b"}}
+			]}}`)
+		case "t3_b":
+			fmt.Fprint(w, `{"kind":"Listing","data":{"after":null,"before":"t3_c","children":[
+				{"kind":"t3","data":{"id":"c"}}
+			]}}`)
+		default:
+			t.Errorf("unexpected after %q", after)
+		}
+	})
+
+	params := url.Values{"limit": {"2"}}
+	listing, cursor, err := c.GetListing("/r/golang/new", params, Cursor{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Cursor{Platform: PlatformReddit, Next: "t3_b"}
+	if len(listing.Children) != 2 || cursor != want {
+		t.Errorf("first page = %d children, %+v, want 2 children, %+v", len(listing.Children), cursor, want)
+	}
+
+	listing, cursor, err = c.GetListing("/r/golang/new", params, cursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Cursor{Platform: PlatformReddit, Previous: "t3_c"}
+	if len(listing.Children) != 1 || cursor != want {
+		t.Errorf("last page = %d children, %+v, want 1 child, %+v", len(listing.Children), cursor, want)
+	}
+	if params.Get("after") != "" {
+		t.Error("GetListing modified the caller's params")
+	}
+}
+
+func TestPaginateWalksPlatformCursors(t *testing.T) {
+	var requests int
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Query().Get("after") {
+		case "":
+			fmt.Fprint(w, `{"data":[{"id":"c1"},{"id":"c2"}],"paging":{"cursors":{"after":"p2"},"next":"https://graph.facebook.com/next"}}`)
+		case "p2":
+			fmt.Fprint(w, `{"data":[{"id":"c3"}],"paging":{"cursors":{"after":"p3"}}}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	})
+
+	page := func(ctx context.Context, cursor Cursor) ([]Comment, Cursor, error) {
+		return c.GetCommentsPageContext(ctx, "post_1", 2, cursor)
+	}
+	var ids []string
+	err := Paginate(context.Background(), page, func(comment Comment) error {
+		ids = append(ids, comment.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[c1 c2 c3]" || requests != 2 {
+		t.Errorf("ids = %v after %d requests, want [c1 c2 c3] after 2", ids, requests)
+	}
+
+	requests, ids = 0, nil
+	err = Paginate(context.Background(), page, func(comment Comment) error {
+		ids = append(ids, comment.ID)
+		return ErrStopIteration
+	})
+	if err != nil || len(ids) != 1 || requests != 1 {
+		t.Errorf("Paginate() = %v with ids %v after %d requests, want a clean stop after c1", err, ids, requests)
+	}
+}
@@ -2,13 +2,16 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 )
 
 // Client represents a Dribbble API client
@@ -20,34 +23,97 @@ type DribbbleClient struct {
 
 // Shot represents a Dribbble shot (post)
 type Shot struct {
-	ID          int64    `json:"id,omitempty"`
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Tags        []string `json:"tags,omitempty"`
-	TeamID      int64    `json:"team_id,omitempty"`
-	Low         string   `json:"low_profile,omitempty"`  // URL to low-res image
-	Normal      string   `json:"normal,omitempty"`       // URL to normal-res image
-	High        string   `json:"high_profile,omitempty"` // URL to high-res image
+	ID          int64      `json:"id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Tags        []string   `json:"tags,omitempty"`
+	TeamID      int64      `json:"team_id,omitempty"`
+	Images      ShotImages `json:"images"`
+	Animated    bool       `json:"animated,omitempty"` // GIF shot
+	Video       *VideoInfo `json:"video,omitempty"`    // set for video shots
 }
 
-// Comment represents a comment on a Dribbble shot
+// VideoInfo describes the video of a Dribbble video shot
+type VideoInfo struct {
+	ID               int64  `json:"id"`
+	Duration         int    `json:"duration"` // seconds
+	FileName         string `json:"video_file_name,omitempty"`
+	FileSize         int64  `json:"video_file_size,omitempty"`
+	Width            int    `json:"width,omitempty"`
+	Height           int    `json:"height,omitempty"`
+	Silent           bool   `json:"silent"`
+	URL              string `json:"url"`
+	SmallPreviewURL  string `json:"small_preview_url,omitempty"`
+	LargePreviewURL  string `json:"large_preview_url,omitempty"`
+	XLargePreviewURL string `json:"xlarge_preview_url,omitempty"`
+	CreatedAt        string `json:"created_at,omitempty"`
+	UpdatedAt        string `json:"updated_at,omitempty"`
+}
+
+// IsVideo reports whether the shot is a video. View counts of video shots
+// include autoplays, so they read higher than those of image shots.
+func (s *Shot) IsVideo() bool {
+	return s.Video != nil
+}
+
+// ShotImages holds the image URLs Dribbble returns for a shot
+type ShotImages struct {
+	HiDPI  string `json:"hidpi,omitempty"`  // URL to high-res image, null for small uploads
+	Normal string `json:"normal,omitempty"` // URL to normal-res image
+	Teaser string `json:"teaser,omitempty"` // URL to thumbnail image
+}
+
+// BestImageURL returns the highest resolution image available for the shot
+func (s *Shot) BestImageURL() string {
+	if s.Images.HiDPI != "" {
+		return s.Images.HiDPI
+	}
+	return s.Images.Normal
+}
+
+// DribbbleComment represents a comment on a Dribbble shot
 type DribbbleComment struct {
-	ID      int64  `json:"id,omitempty"`
-	Body    string `json:"body"`
-	ShotID  int64  `json:"shot_id,omitempty"`
-	UserID  int64  `json:"user_id,omitempty"`
-	Created string `json:"created_at,omitempty"`
+	ID         int64                  `json:"id,omitempty"`
+	Body       string                 `json:"body"`
+	LikesCount int                    `json:"likes_count,omitempty"`
+	HTMLURL    string                 `json:"html_url,omitempty"`
+	Author     *DribbbleCommentAuthor `json:"user,omitempty"`
+	CreatedAt  string                 `json:"created_at,omitempty"`
+	UpdatedAt  string                 `json:"updated_at,omitempty"`
+}
+
+// DribbbleCommentAuthor is the user who wrote a Dribbble comment
+type DribbbleCommentAuthor struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Login   string `json:"login"`
+	HTMLURL string `json:"html_url,omitempty"`
+	Avatar  string `json:"avatar_url,omitempty"`
 }
 
-// Stats represents statistics for a Dribbble shot
+// DribbbleStats represents statistics for a Dribbble shot. The tags match the
+// counters Dribbble returns on the shot object.
 type DribbbleStats struct {
-	Views       int `json:"views"`
-	Likes       int `json:"likes"`
-	Comments    int `json:"comments"`
-	Rebounds    int `json:"rebounds"`
-	Attachments int `json:"attachments"`
+	Views       int `json:"views_count"`
+	Likes       int `json:"likes_count"`
+	Comments    int `json:"comments_count"`
+	Rebounds    int `json:"rebounds_count"`
+	Attachments int `json:"attachments_count"`
+	Buckets     int `json:"buckets_count"` // times the shot was saved to a bucket
+
+	// Estimated is set when analytics were unavailable and the likes and
+	// comments were counted from their list endpoints instead. Counts are
+	// capped at dribbbleEstimateCap and the other fields are left at zero.
+	Estimated bool `json:"estimated,omitempty"`
 }
 
+// dribbbleEstimateCap bounds how many likes/comments are counted per shot
+// when estimating stats
+const dribbbleEstimateCap = 1000
+
+// dribbbleListPageSize is the largest page size Dribbble list endpoints accept
+const dribbbleListPageSize = 100
+
 // NewClient creates a new Dribbble API client
 func NewDribbbleClient(accessToken string) *DribbbleClient {
 	return &DribbbleClient{
@@ -119,7 +185,7 @@ func (c *DribbbleClient) CreateShot(title, description string, tags []string, im
 
 	// Parse the response
 	var shot Shot
-	err = json.NewDecoder(resp.Body).Decode(&shot)
+	err = decodeJSONBody(resp, &shot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
@@ -128,7 +194,7 @@ func (c *DribbbleClient) CreateShot(title, description string, tags []string, im
 }
 
 // ReplyToComment adds a reply to an existing comment on a shot
-func (c *DribbbleClient) ReplyToComment(shotID int64, commentID int64, body string) (*Comment, error) {
+func (c *DribbbleClient) ReplyToComment(shotID int64, commentID int64, body string) (*DribbbleComment, error) {
 	endpoint := fmt.Sprintf("%s/shots/%d/comments/%d/replies", c.BaseURL, shotID, commentID)
 
 	// Create the request body
@@ -165,8 +231,8 @@ func (c *DribbbleClient) ReplyToComment(shotID int64, commentID int64, body stri
 	}
 
 	// Parse the response
-	var comment Comment
-	err = json.NewDecoder(resp.Body).Decode(&comment)
+	var comment DribbbleComment
+	err = decodeJSONBody(resp, &comment)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
@@ -174,8 +240,10 @@ func (c *DribbbleClient) ReplyToComment(shotID int64, commentID int64, body stri
 	return &comment, nil
 }
 
-// GetShotStats retrieves statistics for a specific shot
-func (c *DribbbleClient) GetShotStats(shotID int64) (*Stats, error) {
+// GetShotStats retrieves statistics for a specific shot. When the API refuses
+// access to the statistics, the likes and comments are counted instead and the
+// result is marked as Estimated.
+func (c *DribbbleClient) GetShotStats(shotID int64) (*DribbbleStats, error) {
 	endpoint := fmt.Sprintf("%s/shots/%d", c.BaseURL, shotID)
 
 	// Create the request
@@ -193,6 +261,10 @@ func (c *DribbbleClient) GetShotStats(shotID int64) (*Stats, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusForbidden {
+		return c.estimateShotStats(shotID)
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		responseBody, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf(
@@ -202,18 +274,150 @@ func (c *DribbbleClient) GetShotStats(shotID int64) (*Stats, error) {
 		)
 	}
 
-	// Parse the response
+	// The counters are fields of the shot itself; some responses nest them
+	// in a statistics object instead
 	var shot struct {
-		ID    int64 `json:"id"`
-		Stats Stats `json:"statistics"`
+		DribbbleStats
+		Statistics *DribbbleStats `json:"statistics"`
+	}
+
+	err = decodeJSONBody(resp, &shot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	if shot.Statistics != nil {
+		return shot.Statistics, nil
+	}
+	return &shot.DribbbleStats, nil
+}
+
+// GetStats implements StatsProvider with GetShotStats. Shares are rebounds;
+// bucket saves only count towards Engagement. The Dribbble client does not
+// take a context, so ctx is only checked before the request.
+func (c *DribbbleClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	shotID, err := strconv.ParseInt(postID, 10, 64)
+	if err != nil {
+		return PostStats{}, fmt.Errorf("invalid dribbble shot ID %q", postID)
+	}
+	if err := ctx.Err(); err != nil {
+		return PostStats{}, err
+	}
+
+	stats, err := c.GetShotStats(shotID)
+	if err != nil {
+		return PostStats{}, err
+	}
+
+	interactions := int64(stats.Likes + stats.Comments + stats.Rebounds + stats.Buckets)
+	return PostStats{
+		Views:      int64(stats.Views),
+		Likes:      int64(stats.Likes),
+		Comments:   int64(stats.Comments),
+		Shares:     int64(stats.Rebounds),
+		Engagement: engagementPercent(interactions, int64(stats.Views)),
+	}, nil
+}
+
+// estimateShotStats builds partial stats by counting a shot's likes and comments
+func (c *DribbbleClient) estimateShotStats(shotID int64) (*DribbbleStats, error) {
+	likes, err := c.countShotItems(shotID, "likes")
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := c.countShotItems(shotID, "comments")
+	if err != nil {
+		return nil, err
+	}
+
+	return &DribbbleStats{
+		Likes:     likes,
+		Comments:  comments,
+		Estimated: true,
+	}, nil
+}
+
+// countShotItems counts the entries of a shot list endpoint such as "likes" or
+// "comments", stopping at dribbbleEstimateCap
+func (c *DribbbleClient) countShotItems(shotID int64, edge string) (int, error) {
+	count := 0
+
+	for page := 1; count < dribbbleEstimateCap; page++ {
+		endpoint := fmt.Sprintf("%s/shots/%d/%s?page=%d&per_page=%d",
+			c.BaseURL, shotID, edge, page, dribbbleListPageSize)
+
+		req, err := http.NewRequest("GET", endpoint, nil)
+		if err != nil {
+			return 0, fmt.Errorf("failed to create request: %v", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return 0, fmt.Errorf("failed to send request: %v", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			responseBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return 0, fmt.Errorf("failed to list shot %s. Status: %d, Response: %s", edge, resp.StatusCode, string(responseBody))
+		}
+
+		var items []json.RawMessage
+		err = decodeJSONBody(resp, &items)
+		resp.Body.Close()
+		if err != nil {
+			return 0, fmt.Errorf("failed to decode response: %v", err)
+		}
+
+		count += len(items)
+		if len(items) < dribbbleListPageSize {
+			break
+		}
+	}
+
+	if count > dribbbleEstimateCap {
+		count = dribbbleEstimateCap
+	}
+
+	return count, nil
+}
+
+// GetShot fetches a single shot
+func (c *DribbbleClient) GetShot(shotID int64) (*Shot, error) {
+	endpoint := fmt.Sprintf("%s/shots/%d", c.BaseURL, shotID)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&shot)
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("dribbble shot %d: %w", shotID, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		responseBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get shot. Status: %d, Response: %s", resp.StatusCode, string(responseBody))
+	}
+
+	var shot Shot
+	err = decodeJSONBody(resp, &shot)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
 
-	return &shot.Stats, nil
+	return &shot, nil
 }
 
 // ListShots fetches shots based on filters
@@ -243,7 +447,53 @@ func (c *DribbbleClient) ListShots(page, perPage int, timeframe string) ([]Shot,
 
 	// Parse the response
 	var shots []Shot
-	err = json.NewDecoder(resp.Body).Decode(&shots)
+	err = decodeJSONBody(resp, &shots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode response: %v", err)
+	}
+
+	return shots, nil
+}
+
+// GetUserShots fetches a page of another user's public shots. ErrNotFound is
+// returned for an unknown username and ErrPrivateProfile when the user's
+// shots are not visible to the authenticated account.
+func (c *DribbbleClient) GetUserShots(username string, page int) ([]Shot, error) {
+	if page < 1 {
+		page = 1
+	}
+	endpoint := fmt.Sprintf("%s/users/%s/shots?page=%d&per_page=%d",
+		c.BaseURL, url.PathEscape(username), page, dribbbleListPageSize)
+
+	// Create the request
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+
+	// Send the request
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("dribbble user %q: %w", username, ErrNotFound)
+	case http.StatusForbidden:
+		return nil, fmt.Errorf("dribbble user %q: %w", username, ErrPrivateProfile)
+	default:
+		responseBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get user shots. Status: %d, Response: %s", resp.StatusCode, string(responseBody))
+	}
+
+	// Parse the response
+	var shots []Shot
+	err = decodeJSONBody(resp, &shots)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode response: %v", err)
 	}
@@ -0,0 +1,371 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func newTestDribbbleClient(t *testing.T, handler http.HandlerFunc) *DribbbleClient {
+	t.Helper()
+
+	srv, client := newTestServer(t, handler)
+	c := NewDribbbleClient("token")
+	c.BaseURL = srv.URL
+	c.HTTPClient = client
+	return c
+}
+
+func TestDribbbleGetShotDecodesImages(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shots/471756" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{
+			"id": 471756,
+			"title": "Sasquatch",
+			"description": "<p>Quick, messy, five minute sketch.</p>",
+			"images": {
+				"hidpi": null,
+				"normal": "https://cdn.dribbble.com/users/1/screenshots/471756/sasquatch.png",
+				"teaser": "https://cdn.dribbble.com/users/1/screenshots/471756/sasquatch_teaser.png"
+			},
+			"animated": false,
+			"tags": ["fur", "lol"]
+		}`)
+	})
+
+	shot, err := c.GetShot(471756)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if shot.Images.HiDPI != "" {
+		t.Errorf("HiDPI = %q, want empty for null", shot.Images.HiDPI)
+	}
+	if shot.Images.Teaser == "" {
+		t.Error("Teaser was not decoded")
+	}
+	if got, want := shot.BestImageURL(), shot.Images.Normal; got != want {
+		t.Errorf("BestImageURL() = %q, want the normal image %q", got, want)
+	}
+}
+
+func TestDribbbleDecodesVideoShots(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shots" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `[
+			{
+				"id": 8230201,
+				"title": "Onboarding flow",
+				"animated": false,
+				"images": {"hidpi": null, "normal": "https://cdn.dribbble.com/still.png"},
+				"video": {
+					"id": 5523,
+					"duration": 12,
+					"video_file_name": "onboarding.mp4",
+					"video_file_size": 2408671,
+					"width": 1600,
+					"height": 1200,
+					"silent": true,
+					"url": "https://cdn.dribbble.com/videos/onboarding.mp4",
+					"small_preview_url": "https://cdn.dribbble.com/videos/onboarding-small.mp4",
+					"large_preview_url": "https://cdn.dribbble.com/videos/onboarding-large.mp4",
+					"xlarge_preview_url": "https://cdn.dribbble.com/videos/onboarding-xlarge.mp4",
+					"created_at": "2020-05-12T09:41:03Z"
+				}
+			},
+			{
+				"id": 471757,
+				"title": "Loader",
+				"animated": true,
+				"images": {"normal": "https://cdn.dribbble.com/loader.gif"},
+				"video": null
+			}
+		]`)
+	})
+
+	shots, err := c.ListShots(1, 2, "week")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shots) != 2 {
+		t.Fatalf("got %d shots, want 2", len(shots))
+	}
+
+	video := shots[0]
+	if !video.IsVideo() || video.Animated {
+		t.Errorf("IsVideo() = %v, Animated = %v, want a non-animated video shot", video.IsVideo(), video.Animated)
+	}
+	want := VideoInfo{
+		ID:               5523,
+		Duration:         12,
+		FileName:         "onboarding.mp4",
+		FileSize:         2408671,
+		Width:            1600,
+		Height:           1200,
+		Silent:           true,
+		URL:              "https://cdn.dribbble.com/videos/onboarding.mp4",
+		SmallPreviewURL:  "https://cdn.dribbble.com/videos/onboarding-small.mp4",
+		LargePreviewURL:  "https://cdn.dribbble.com/videos/onboarding-large.mp4",
+		XLargePreviewURL: "https://cdn.dribbble.com/videos/onboarding-xlarge.mp4",
+		CreatedAt:        "2020-05-12T09:41:03Z",
+	}
+	if video.Video == nil || *video.Video != want {
+		t.Errorf("video = %+v, want %+v", video.Video, want)
+	}
+
+	gif := shots[1]
+	if gif.IsVideo() || !gif.Animated {
+		t.Errorf("IsVideo() = %v, Animated = %v, want an animated image shot", gif.IsVideo(), gif.Animated)
+	}
+}
+
+func TestDribbbleBestImageURLPrefersHiDPI(t *testing.T) {
+	shot := Shot{Images: ShotImages{
+		HiDPI:  "https://cdn.dribbble.com/hidpi.png",
+		Normal: "https://cdn.dribbble.com/normal.png",
+	}}
+	if got := shot.BestImageURL(); got != shot.Images.HiDPI {
+		t.Errorf("BestImageURL() = %q, want %q", got, shot.Images.HiDPI)
+	}
+}
+
+func TestDribbbleGetShotStatsFallsBackOn403(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/shots/7":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message":"You do not have access to this resource"}`)
+		case "/shots/7/likes":
+			fmt.Fprint(w, `[{"id":1},{"id":2},{"id":3}]`)
+		case "/shots/7/comments":
+			fmt.Fprint(w, `[{"id":10,"body":"nice"}]`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	stats, err := c.GetShotStats(7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Estimated {
+		t.Error("Estimated = false, want true after a 403")
+	}
+	if stats.Likes != 3 || stats.Comments != 1 || stats.Views != 0 {
+		t.Errorf("stats = %+v, want 3 likes and 1 comment", stats)
+	}
+}
+
+func TestDribbbleGetShotStatsDecodesShotCounters(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shots/471756" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		fmt.Fprint(w, `{
+			"id": 471756,
+			"title": "Sasquatch",
+			"width": 400,
+			"height": 300,
+			"images": {"hidpi": null, "normal": "https://cdn.dribbble.com/normal.png", "teaser": "https://cdn.dribbble.com/teaser.png"},
+			"views_count": 4372,
+			"likes_count": 149,
+			"comments_count": 27,
+			"attachments_count": 1,
+			"rebounds_count": 2,
+			"buckets_count": 8,
+			"created_at": "2012-03-15T01:52:33Z",
+			"user": {"id": 1, "name": "Dan Cederholm", "login": "simplebits"}
+		}`)
+	})
+
+	stats, err := c.GetShotStats(471756)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := DribbbleStats{Views: 4372, Likes: 149, Comments: 27, Rebounds: 2, Attachments: 1, Buckets: 8}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+}
+
+func TestDribbbleGetShotStatsDecodesNestedStatistics(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/shots/2155133" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{
+			"id": 2155133,
+			"title": "Weather app",
+			"description": "<p>Exploring a calmer forecast.</p>",
+			"images": {
+				"hidpi": "https://cdn.dribbble.com/users/4/screenshots/2155133/weather@2x.png",
+				"normal": "https://cdn.dribbble.com/users/4/screenshots/2155133/weather.png",
+				"teaser": "https://cdn.dribbble.com/users/4/screenshots/2155133/weather_teaser.png"
+			},
+			"animated": false,
+			"tags": ["app", "weather"],
+			"html_url": "https://dribbble.com/shots/2155133-Weather-app",
+			"published_at": "2015-07-09T15:12:04Z",
+			"statistics": {
+				"views_count": 18204,
+				"likes_count": 912,
+				"comments_count": 41,
+				"rebounds_count": 3,
+				"attachments_count": 2,
+				"buckets_count": 87
+			},
+			"user": {"id": 4, "name": "Jane Designer", "login": "jane"},
+			"team": null
+		}`)
+	})
+
+	stats, err := c.GetShotStats(2155133)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DribbbleStats{Views: 18204, Likes: 912, Comments: 41, Rebounds: 3, Attachments: 2, Buckets: 87}
+	if *stats != want {
+		t.Errorf("stats = %+v, want %+v", *stats, want)
+	}
+
+	post, err := c.GetStats(context.Background(), "2155133")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if post.Views != 18204 || post.Likes != 912 || post.Comments != 41 || post.Shares != 3 {
+		t.Errorf("post stats = %+v, want the shot counters with rebounds as shares", post)
+	}
+	if wantEngagement := engagementPercent(912+41+3+87, 18204); post.Engagement != wantEngagement {
+		t.Errorf("Engagement = %v, want %v", post.Engagement, wantEngagement)
+	}
+}
+
+func TestDribbbleReplyToCommentDecodesComment(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/shots/471756/comments/1145736/replies" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload["body"] != "Thanks!" {
+			t.Errorf("body = %q", payload["body"])
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{
+			"id": 1145737,
+			"body": "<p>Thanks!</p>",
+			"likes_count": 0,
+			"html_url": "https://dribbble.com/shots/471756-Sasquatch#comment-1145737",
+			"created_at": "2012-03-15T04:24:39Z",
+			"updated_at": "2012-03-15T04:24:39Z",
+			"user": {
+				"id": 1,
+				"name": "Dan Cederholm",
+				"login": "simplebits",
+				"html_url": "https://dribbble.com/simplebits",
+				"avatar_url": "https://cdn.dribbble.com/users/1/avatars/normal/dc.jpg"
+			}
+		}`)
+	})
+
+	comment, err := c.ReplyToComment(471756, 1145736, "Thanks!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.ID != 1145737 || comment.Body != "<p>Thanks!</p>" || comment.CreatedAt != "2012-03-15T04:24:39Z" || comment.HTMLURL == "" {
+		t.Errorf("comment = %+v", comment)
+	}
+	if comment.Author == nil || comment.Author.ID != 1 || comment.Author.Login != "simplebits" || comment.Author.Avatar == "" {
+		t.Errorf("author = %+v", comment.Author)
+	}
+}
+
+func TestDribbbleGetUserShotsDecodesShots(t *testing.T) {
+	c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/users/simplebits/shots" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("page") != "1" || q.Get("per_page") != "100" {
+			t.Errorf("query = %v, want page 1 of 100", q)
+		}
+		fmt.Fprint(w, `[
+			{
+				"id": 471756,
+				"title": "Sasquatch",
+				"description": "<p>Quick, messy, five minute sketch.</p>",
+				"images": {"hidpi": null, "normal": "https://cdn.dribbble.com/normal.png", "teaser": "https://cdn.dribbble.com/teaser.png"},
+				"tags": ["fur", "lol"],
+				"team": null
+			},
+			{
+				"id": 471757,
+				"title": "Loader",
+				"animated": true,
+				"images": {"hidpi": "https://cdn.dribbble.com/loader@2x.gif", "normal": "https://cdn.dribbble.com/loader.gif"}
+			}
+		]`)
+	})
+
+	shots, err := c.GetUserShots("simplebits", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shots) != 2 {
+		t.Fatalf("got %d shots, want 2", len(shots))
+	}
+	if s := shots[0]; s.ID != 471756 || s.Title != "Sasquatch" || len(s.Tags) != 2 || s.Images.Normal == "" || s.Animated {
+		t.Errorf("first shot = %+v", s)
+	}
+	if s := shots[1]; s.ID != 471757 || !s.Animated || s.BestImageURL() != "https://cdn.dribbble.com/loader@2x.gif" {
+		t.Errorf("second shot = %+v", s)
+	}
+}
+
+func TestDribbbleGetUserShotsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		target error
+	}{
+		{"not found", http.StatusNotFound, ErrNotFound},
+		{"private", http.StatusForbidden, ErrPrivateProfile},
+		{"server error", http.StatusBadGateway, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestDribbbleClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if got := r.URL.Query().Get("page"); got != "3" {
+					t.Errorf("page = %q, want 3", got)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, `{"message":"Not found."}`)
+			})
+
+			shots, err := c.GetUserShots("someone", 3)
+			if err == nil || shots != nil {
+				t.Fatalf("GetUserShots() = %v, %v, want an error", shots, err)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("error = %v, want %v", err, tt.target)
+			}
+			if tt.target == nil && (errors.Is(err, ErrNotFound) || errors.Is(err, ErrPrivateProfile)) {
+				t.Errorf("error = %v matches a typed error for status %d", err, tt.status)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package integrations
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSchedulingUnsupported is returned when a post asks for a publish time on
+// a platform that cannot schedule natively. Callers should hold the post and
+// publish it themselves at the requested time.
+var ErrSchedulingUnsupported = errors.New("native scheduling is not supported by this platform")
+
+// ErrNotConfigured is returned when a client is missing credentials it needs
+// to make a request
+var ErrNotConfigured = errors.New("client is not configured")
+
+// ErrNoRefreshToken is returned when an access token has expired and there is
+// no refresh token to renew it; the user has to authorize the app again
+var ErrNoRefreshToken = errors.New("access token expired and no refresh token is available")
+
+// ErrUnsupported is returned when the platform API offers no way to perform
+// the requested operation
+var ErrUnsupported = errors.New("operation is not supported by this platform")
+
+// ErrRateLimited is returned when the platform throttled the request. The
+// error is a *RateLimitError when a suggested backoff is known.
+var ErrRateLimited = errors.New("rate limited by platform")
+
+// RateLimitError reports a throttled request along with how long to wait
+// before retrying. It matches ErrRateLimited with errors.Is.
+type RateLimitError struct {
+	Platform   string
+	Code       int // platform specific error code, if any
+	Message    string
+	RetryAfter time.Duration // suggested backoff
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s rate limit reached (code %d): %s, retry after %s", e.Platform, e.Code, e.Message, e.RetryAfter)
+}
+
+// Unwrap makes the error match ErrRateLimited
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// ErrNotFound is returned when the requested user or content does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrPrivateProfile is returned when a user's content is not visible to the
+// authenticated account because their profile is private
+var ErrPrivateProfile = errors.New("profile is private")
+
+// ErrStopIteration can be returned by the callback of an iterator such as
+// RedditClient.Iterate to stop early without reporting an error
+var ErrStopIteration = errors.New("stop iteration")
+
+// ErrTokenExpired is returned when the platform rejected the access token
+// because it expired. Refreshing the token and retrying should succeed.
+var ErrTokenExpired = errors.New("access token expired")
+
+// ErrTokenInvalid is returned when the platform rejected the access token (or
+// refresh token) because it was revoked or is otherwise invalid. Refreshing
+// will not help; the user has to authorize the app again.
+var ErrTokenInvalid = errors.New("access token is invalid")
+
+// APIError is returned when a platform rejects the credentials of a request.
+// It matches ErrTokenExpired or ErrTokenInvalid with errors.Is, so refresh
+// logic can tell whether to refresh or prompt the user to log in again.
+type APIError struct {
+	Platform   string
+	StatusCode int    // HTTP status of the response
+	Code       string // platform specific error code, e.g. EXPIRED_ACCESS_TOKEN
+	Message    string
+	Err        error // ErrTokenExpired or ErrTokenInvalid
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API error (status %d, code %s): %s", e.Platform, e.StatusCode, e.Code, e.Message)
+}
+
+// Unwrap makes the error match ErrTokenExpired or ErrTokenInvalid
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
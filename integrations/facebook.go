@@ -2,6 +2,7 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,7 +11,9 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -20,7 +23,10 @@ const (
 // Client represents a Facebook API client
 type FaceBookClient struct {
 	AccessToken string
-	HTTPClient  *http.Client
+	// AppSecret, when set, is used to send appsecret_proof with every call,
+	// which apps with "Require App Secret" enabled must do
+	AppSecret  string
+	HTTPClient *http.Client
 }
 
 // NewClient creates a new Facebook API client
@@ -31,6 +37,31 @@ func NewFaceBookClient(accessToken string) *FaceBookClient {
 	}
 }
 
+// authParams returns the access token parameters sent with every Graph call
+func (c *FaceBookClient) authParams() url.Values {
+	params := url.Values{}
+	params.Set("access_token", c.AccessToken)
+	if c.AppSecret != "" {
+		params.Set("appsecret_proof", appSecretProof(c.AccessToken, c.AppSecret))
+	}
+	return params
+}
+
+// setAccessToken adds the access token parameters to a query or form
+func (c *FaceBookClient) setAccessToken(data url.Values) {
+	for key, values := range c.authParams() {
+		data[key] = values
+	}
+}
+
+// writeAccessToken adds the access token parameters to a multipart form
+func (c *FaceBookClient) writeAccessToken(writer *multipart.Writer) {
+	params := c.authParams()
+	for key := range params {
+		_ = writer.WriteField(key, params.Get(key))
+	}
+}
+
 // Response represents a general Facebook API response
 type Response struct {
 	ID      string `json:"id,omitempty"`
@@ -43,22 +74,76 @@ type Error struct {
 	Message string `json:"message"`
 	Type    string `json:"type"`
 	Code    int    `json:"code"`
+	Subcode int    `json:"error_subcode,omitempty"`
+}
+
+// Graph API codes for a rejected access token. Code 190 comes with a subcode
+// telling why; only an expired token can be refreshed.
+const (
+	facebookInvalidTokenCode    = 190
+	facebookExpiredTokenSubcode = 463
+)
+
+// FacebookAPIError is returned when the Graph API responds with an error
+// object. It keeps the fields of the error so callers can branch on them with
+// errors.As, e.g. code 190 for an invalid access token or 4 for rate limiting.
+// A code 190 error also matches ErrTokenExpired or ErrTokenInvalid with
+// errors.Is.
+type FacebookAPIError struct {
+	Message    string
+	Type       string
+	Code       int
+	Subcode    int
+	StatusCode int // HTTP status of the response
+}
+
+func (e *FacebookAPIError) Error() string {
+	return fmt.Sprintf("Facebook API error: %s", e.Message)
+}
+
+// Unwrap makes a rejected token error match ErrTokenExpired (subcode 463) or
+// ErrTokenInvalid (revoked, password changed, app removed...)
+func (e *FacebookAPIError) Unwrap() error {
+	if e.Code != facebookInvalidTokenCode {
+		return nil
+	}
+	if e.Subcode == facebookExpiredTokenSubcode {
+		return ErrTokenExpired
+	}
+	return ErrTokenInvalid
+}
+
+// newFacebookAPIError converts the error object of a response into a
+// FacebookAPIError
+func newFacebookAPIError(statusCode int, apiErr *Error) *FacebookAPIError {
+	return &FacebookAPIError{
+		Message:    apiErr.Message,
+		Type:       apiErr.Type,
+		Code:       apiErr.Code,
+		Subcode:    apiErr.Subcode,
+		StatusCode: statusCode,
+	}
 }
 
 // CreatePost creates a new post on a Facebook page or profile
 // pageID can be "me" for posting on the user's own timeline
 func (c *FaceBookClient) CreatePost(pageID, message string, link string) (*Response, error) {
+	return c.CreatePostContext(context.Background(), pageID, message, link)
+}
+
+// CreatePostContext is CreatePost with a context
+func (c *FaceBookClient) CreatePostContext(ctx context.Context, pageID, message string, link string) (*Response, error) {
 	endpoint := fmt.Sprintf("%s/%s/feed", FacebookAPIBaseURL, pageID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
+	c.setAccessToken(data)
 	data.Set("message", message)
 
 	if link != "" {
 		data.Set("link", link)
 	}
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -72,28 +157,59 @@ func (c *FaceBookClient) CreatePost(pageID, message string, link string) (*Respo
 	defer resp.Body.Close()
 
 	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
 }
 
+// Facebook only accepts scheduled publish times inside this window
+const (
+	facebookMinScheduleLead = 10 * time.Minute
+	facebookMaxScheduleLead = 75 * 24 * time.Hour
+)
+
 // CreateScheduledPost creates a post scheduled for future publication
 func (c *FaceBookClient) CreateScheduledPost(pageID, message string, scheduledTime int64) (*Response, error) {
+	return c.CreateScheduledPostContext(context.Background(), pageID, message, scheduledTime)
+}
+
+// CreateScheduledPostContext is CreateScheduledPost with a context
+func (c *FaceBookClient) CreateScheduledPostContext(ctx context.Context, pageID, message string, scheduledTime int64) (*Response, error) {
+	return c.SchedulePostContext(ctx, pageID, message, "", time.Unix(scheduledTime, 0))
+}
+
+// SchedulePost creates an unpublished page post that Facebook publishes at
+// publishAt. publishAt must be between 10 minutes and 75 days from now.
+func (c *FaceBookClient) SchedulePost(pageID, message, link string, publishAt time.Time) (*Response, error) {
+	return c.SchedulePostContext(context.Background(), pageID, message, link, publishAt)
+}
+
+// SchedulePostContext is SchedulePost with a context
+func (c *FaceBookClient) SchedulePostContext(ctx context.Context, pageID, message, link string, publishAt time.Time) (*Response, error) {
+	lead := time.Until(publishAt)
+	if lead < facebookMinScheduleLead || lead > facebookMaxScheduleLead {
+		return nil, fmt.Errorf("scheduled time must be between %s and %s from now", facebookMinScheduleLead, facebookMaxScheduleLead)
+	}
+
 	endpoint := fmt.Sprintf("%s/%s/feed", FacebookAPIBaseURL, pageID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
+	c.setAccessToken(data)
 	data.Set("message", message)
 	data.Set("published", "false")
-	data.Set("scheduled_publish_time", fmt.Sprintf("%d", scheduledTime))
+	data.Set("scheduled_publish_time", fmt.Sprintf("%d", publishAt.Unix()))
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(data.Encode()))
+	if link != "" {
+		data.Set("link", link)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -107,12 +223,12 @@ func (c *FaceBookClient) CreateScheduledPost(pageID, message string, scheduledTi
 	defer resp.Body.Close()
 
 	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
@@ -120,6 +236,11 @@ func (c *FaceBookClient) CreateScheduledPost(pageID, message string, scheduledTi
 
 // UploadPhoto uploads a photo to a Facebook page or profile
 func (c *FaceBookClient) UploadPhoto(pageID, message, photoPath string) (*Response, error) {
+	return c.UploadPhotoContext(context.Background(), pageID, message, photoPath)
+}
+
+// UploadPhotoContext is UploadPhoto with a context
+func (c *FaceBookClient) UploadPhotoContext(ctx context.Context, pageID, message, photoPath string) (*Response, error) {
 	endpoint := fmt.Sprintf("%s/%s/photos", FacebookAPIBaseURL, pageID)
 
 	file, err := os.Open(photoPath)
@@ -132,7 +253,7 @@ func (c *FaceBookClient) UploadPhoto(pageID, message, photoPath string) (*Respon
 	writer := multipart.NewWriter(body)
 
 	// Add form fields
-	_ = writer.WriteField("access_token", c.AccessToken)
+	c.writeAccessToken(writer)
 	if message != "" {
 		_ = writer.WriteField("message", message)
 	}
@@ -153,7 +274,7 @@ func (c *FaceBookClient) UploadPhoto(pageID, message, photoPath string) (*Respon
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", endpoint, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, body)
 	if err != nil {
 		return nil, err
 	}
@@ -167,12 +288,73 @@ func (c *FaceBookClient) UploadPhoto(pageID, message, photoPath string) (*Respon
 	defer resp.Body.Close()
 
 	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
+	}
+
+	return &result, nil
+}
+
+// FacebookVideoBaseURL is the host Facebook requires for video uploads
+const FacebookVideoBaseURL = "https://graph-video.facebook.com/v18.0"
+
+// UploadVideo uploads a video to a Facebook page, reporting progress through opts
+func (c *FaceBookClient) UploadVideo(pageID, description, videoPath string, opts UploadOptions) (*Response, error) {
+	return c.UploadVideoContext(context.Background(), pageID, description, videoPath, opts)
+}
+
+// UploadVideoContext is UploadVideo with a context
+func (c *FaceBookClient) UploadVideoContext(ctx context.Context, pageID, description, videoPath string, opts UploadOptions) (*Response, error) {
+	endpoint := fmt.Sprintf("%s/%s/videos", FacebookVideoBaseURL, pageID)
+
+	file, err := os.Open(videoPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	// Stream the video as the last part instead of buffering it
+	body, contentType, size, err := multipartFileBody(func(writer *multipart.Writer) error {
+		c.writeAccessToken(writer)
+		if description != "" {
+			_ = writer.WriteField("description", description)
+		}
+		return nil
+	}, "source", filepath.Base(videoPath), file, info.Size())
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, opts.body(body, size))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result Response
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
@@ -180,13 +362,18 @@ func (c *FaceBookClient) UploadPhoto(pageID, message, photoPath string) (*Respon
 
 // CommentOnPost adds a comment to a post
 func (c *FaceBookClient) CommentOnPost(postID, message string) (*Response, error) {
+	return c.CommentOnPostContext(context.Background(), postID, message)
+}
+
+// CommentOnPostContext is CommentOnPost with a context
+func (c *FaceBookClient) CommentOnPostContext(ctx context.Context, postID, message string) (*Response, error) {
 	endpoint := fmt.Sprintf("%s/%s/comments", FacebookAPIBaseURL, postID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
+	c.setAccessToken(data)
 	data.Set("message", message)
 
-	req, err := http.NewRequest("POST", endpoint, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -200,12 +387,12 @@ func (c *FaceBookClient) CommentOnPost(postID, message string) (*Response, error
 	defer resp.Body.Close()
 
 	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
@@ -213,9 +400,64 @@ func (c *FaceBookClient) CommentOnPost(postID, message string) (*Response, error
 
 // ReplyToComment adds a reply to a specific comment
 func (c *FaceBookClient) ReplyToComment(commentID, message string) (*Response, error) {
+	return c.ReplyToCommentContext(context.Background(), commentID, message)
+}
+
+// ReplyToCommentContext is ReplyToComment with a context
+func (c *FaceBookClient) ReplyToCommentContext(ctx context.Context, commentID, message string) (*Response, error) {
 	// Replying to a comment is the same as commenting on a post in the API
 	// The commentID becomes the "post" that we're commenting on
-	return c.CommentOnPost(commentID, message)
+	return c.CommentOnPostContext(ctx, commentID, message)
+}
+
+// HideComment hides or unhides a comment. A hidden comment stays visible to
+// its author and their friends but not to anyone else.
+func (c *FaceBookClient) HideComment(commentID string, hidden bool) (*Response, error) {
+	return c.HideCommentContext(context.Background(), commentID, hidden)
+}
+
+// HideCommentContext is HideComment with a context
+func (c *FaceBookClient) HideCommentContext(ctx context.Context, commentID string, hidden bool) (*Response, error) {
+	endpoint := fmt.Sprintf("%s/%s", FacebookAPIBaseURL, commentID)
+
+	data := url.Values{}
+	c.setAccessToken(data)
+	data.Set("is_hidden", strconv.FormatBool(hidden))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result Response
+	if err := decodeJSONBody(resp, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Error != nil {
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
+	}
+
+	return &result, nil
+}
+
+// DeleteComment deletes a comment
+func (c *FaceBookClient) DeleteComment(commentID string) (*Response, error) {
+	return c.DeleteCommentContext(context.Background(), commentID)
+}
+
+// DeleteCommentContext is DeleteComment with a context
+func (c *FaceBookClient) DeleteCommentContext(ctx context.Context, commentID string) (*Response, error) {
+	// Comments are deleted like any other node, the same way as posts
+	return c.DeletePostContext(ctx, commentID)
 }
 
 // Comment represents a Facebook comment
@@ -223,10 +465,72 @@ type Comment struct {
 	ID        string `json:"id"`
 	Message   string `json:"message"`
 	CreatedAt string `json:"created_time"`
-	From      struct {
-		ID   string `json:"id"`
-		Name string `json:"name"`
-	} `json:"from"`
+	// From is nil when the author is hidden, which happens for users who
+	// have not granted the app access and for comments posted anonymously
+	From         *CommentAuthor `json:"from,omitempty"`
+	MessageTags  []MessageTag   `json:"message_tags,omitempty"`
+	CommentCount int            `json:"comment_count,omitempty"`
+	Parent       *struct {
+		ID string `json:"id"`
+	} `json:"parent,omitempty"`
+
+	// Extra holds the fields requested through CommentOptions.Fields that
+	// Comment has no field for
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a comment, keeping unknown fields in Extra
+func (c *Comment) UnmarshalJSON(data []byte) error {
+	type comment Comment
+	if err := json.Unmarshal(data, (*comment)(c)); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(data, commentFields)
+	c.Extra = extra
+	return err
+}
+
+// Anonymous reports whether the comment's author is hidden
+func (c *Comment) Anonymous() bool {
+	return c.From == nil || c.From.ID == ""
+}
+
+// CommentAuthor is the user or page that wrote a comment
+type CommentAuthor struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Picture *struct {
+		Data struct {
+			URL          string `json:"url"`
+			IsSilhouette bool   `json:"is_silhouette"` // default avatar
+		} `json:"data"`
+	} `json:"picture,omitempty"`
+}
+
+// PictureURL returns the URL of the author's profile picture, or "" if it was
+// not returned
+func (a *CommentAuthor) PictureURL() string {
+	if a == nil || a.Picture == nil {
+		return ""
+	}
+	return a.Picture.Data.URL
+}
+
+// MessageTag is a user, page or group mentioned in a comment. Offset and
+// Length locate the mention in the message, in UTF-16 code units.
+type MessageTag struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Type   string `json:"type,omitempty"` // "user", "page" or "group"
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// CommentNode is a comment together with its replies
+type CommentNode struct {
+	Comment
+	Replies []*CommentNode `json:"replies,omitempty"`
 }
 
 // CommentsResponse represents the response from getting comments
@@ -242,17 +546,96 @@ type CommentsResponse struct {
 	Error *Error `json:"error,omitempty"`
 }
 
+// Cursor returns the cursor of the page after this one
+func (r *CommentsResponse) Cursor() Cursor {
+	cursor := Cursor{Platform: PlatformFacebook, Previous: r.Paging.Cursors.Before}
+	// the after cursor is set on the last page too; only next says whether
+	// there is more
+	if r.Paging.Next != "" {
+		cursor.Next = r.Paging.Cursors.After
+	}
+	return cursor
+}
+
+// Comment orders and filters accepted by CommentOptions
+const (
+	CommentOrderChronological        = "chronological"
+	CommentOrderReverseChronological = "reverse_chronological"
+
+	CommentFilterTopLevel = "toplevel" // comments on the object itself
+	CommentFilterStream   = "stream"   // all comments, including replies
+)
+
+// CommentOptions controls the order and filter of GetCommentsWithOptions.
+// Empty fields leave the Graph API defaults in place.
+type CommentOptions struct {
+	Order  string
+	Filter string
+	After  string // paging cursor to continue from, see CommentsResponse.Cursor
+
+	// Fields are requested on top of the default comment fields, e.g.
+	// "like_count" or "attachment", and returned in Comment.Extra
+	Fields []string
+}
+
+// validate checks that the options hold values the Graph API accepts
+func (o CommentOptions) validate() error {
+	switch o.Order {
+	case "", CommentOrderChronological, CommentOrderReverseChronological:
+	default:
+		return fmt.Errorf("invalid comment order %q", o.Order)
+	}
+
+	switch o.Filter {
+	case "", CommentFilterTopLevel, CommentFilterStream:
+	default:
+		return fmt.Errorf("invalid comment filter %q", o.Filter)
+	}
+
+	return nil
+}
+
 // GetComments gets comments on a post
 func (c *FaceBookClient) GetComments(postID string, limit int) (*CommentsResponse, error) {
+	return c.GetCommentsWithOptionsContext(context.Background(), postID, limit, CommentOptions{})
+}
+
+// GetCommentsContext is GetComments with a context
+func (c *FaceBookClient) GetCommentsContext(ctx context.Context, postID string, limit int) (*CommentsResponse, error) {
+	return c.GetCommentsWithOptionsContext(ctx, postID, limit, CommentOptions{})
+}
+
+// GetCommentsWithOptions gets comments on a post in the order and with the
+// filter given by opts
+func (c *FaceBookClient) GetCommentsWithOptions(postID string, limit int, opts CommentOptions) (*CommentsResponse, error) {
+	return c.GetCommentsWithOptionsContext(context.Background(), postID, limit, opts)
+}
+
+// GetCommentsWithOptionsContext is GetCommentsWithOptions with a context
+func (c *FaceBookClient) GetCommentsWithOptionsContext(ctx context.Context, postID string, limit int, opts CommentOptions) (*CommentsResponse, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+
 	endpoint := fmt.Sprintf("%s/%s/comments", FacebookAPIBaseURL, postID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
+	c.setAccessToken(data)
+	data.Set("fields", fieldsParam(append([]string{commentFields}, opts.Fields...)...))
 	if limit > 0 {
 		data.Set("limit", fmt.Sprintf("%d", limit))
 	}
+	if opts.Order != "" {
+		data.Set("order", opts.Order)
+	}
+	if opts.Filter != "" {
+		data.Set("filter", opts.Filter)
+	}
+	if opts.After != "" {
+		data.Set("after", opts.After)
+	}
 
-	req, err := http.NewRequest("GET", endpoint+"?"+data.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+data.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -264,17 +647,122 @@ func (c *FaceBookClient) GetComments(postID string, limit int) (*CommentsRespons
 	defer resp.Body.Close()
 
 	var result CommentsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
 }
 
+// GetCommentsPage gets a page of comments on a post, starting at cursor.
+// Use the zero Cursor for the first page.
+func (c *FaceBookClient) GetCommentsPage(postID string, limit int, cursor Cursor) ([]Comment, Cursor, error) {
+	return c.GetCommentsPageContext(context.Background(), postID, limit, cursor)
+}
+
+// GetCommentsPageContext is GetCommentsPage with a context
+func (c *FaceBookClient) GetCommentsPageContext(ctx context.Context, postID string, limit int, cursor Cursor) ([]Comment, Cursor, error) {
+	result, err := c.GetCommentsWithOptionsContext(ctx, postID, limit, CommentOptions{After: cursor.Next})
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+	return result.Data, result.Cursor(), nil
+}
+
+// commentFields are the fields requested for comments, with the author
+// expanded to include their picture
+const commentFields = "id,message,created_time,from{id,name,picture},message_tags,comment_count,parent{id}"
+
+// GetCommentReplies gets all replies to a comment
+func (c *FaceBookClient) GetCommentReplies(commentID string) ([]Comment, error) {
+	return c.GetCommentRepliesContext(context.Background(), commentID)
+}
+
+// GetCommentRepliesContext is GetCommentReplies with a context
+func (c *FaceBookClient) GetCommentRepliesContext(ctx context.Context, commentID string) ([]Comment, error) {
+	return c.listComments(ctx, commentID, CommentFilterTopLevel)
+}
+
+// GetCommentTree gets all comments on a post and assembles them into threads.
+// Replies whose parent is not part of the result are returned as top-level nodes.
+func (c *FaceBookClient) GetCommentTree(postID string) ([]*CommentNode, error) {
+	return c.GetCommentTreeContext(context.Background(), postID)
+}
+
+// GetCommentTreeContext is GetCommentTree with a context
+func (c *FaceBookClient) GetCommentTreeContext(ctx context.Context, postID string) ([]*CommentNode, error) {
+	comments, err := c.listComments(ctx, postID, CommentFilterStream)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make(map[string]*CommentNode, len(comments))
+	for _, comment := range comments {
+		nodes[comment.ID] = &CommentNode{Comment: comment}
+	}
+
+	var roots []*CommentNode
+	for _, comment := range comments {
+		node := nodes[comment.ID]
+		if comment.Parent != nil {
+			if parent, ok := nodes[comment.Parent.ID]; ok && parent != node {
+				parent.Replies = append(parent.Replies, node)
+				continue
+			}
+		}
+		roots = append(roots, node)
+	}
+
+	return roots, nil
+}
+
+// listComments gets every comment on an object, following pagination.
+// filter is "toplevel" for direct comments only or "stream" for all comments
+// including replies, in chronological order.
+func (c *FaceBookClient) listComments(ctx context.Context, objectID, filter string) ([]Comment, error) {
+	data := url.Values{}
+	c.setAccessToken(data)
+	data.Set("fields", commentFields)
+	data.Set("filter", filter)
+	data.Set("order", CommentOrderChronological)
+	data.Set("limit", "100")
+
+	endpoint := fmt.Sprintf("%s/%s/comments?%s", FacebookAPIBaseURL, objectID, data.Encode())
+
+	var comments []Comment
+	for endpoint != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var result CommentsResponse
+		err = decodeJSONBody(resp, &result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if result.Error != nil {
+			return nil, newFacebookAPIError(resp.StatusCode, result.Error)
+		}
+
+		comments = append(comments, result.Data...)
+		endpoint = result.Paging.Next
+	}
+
+	return comments, nil
+}
+
 // PostInsights represents insights for a post
 type PostInsights struct {
 	Data []struct {
@@ -289,16 +777,18 @@ type PostInsights struct {
 
 // GetPostInsights gets insights (stats) for a post
 func (c *FaceBookClient) GetPostInsights(postID string) (*PostInsights, error) {
+	return c.GetPostInsightsContext(context.Background(), postID)
+}
+
+// GetPostInsightsContext is GetPostInsights with a context
+func (c *FaceBookClient) GetPostInsightsContext(ctx context.Context, postID string) (*PostInsights, error) {
 	endpoint := fmt.Sprintf("%s/%s/insights", FacebookAPIBaseURL, postID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
-	data.Set(
-		"metric",
-		"post_impressions,post_impressions_unique,post_reactions_by_type_total,post_clicks,post_engaged_users",
-	)
+	c.setAccessToken(data)
+	data.Set("metric", postInsightMetrics)
 
-	req, err := http.NewRequest("GET", endpoint+"?"+data.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+data.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -310,17 +800,212 @@ func (c *FaceBookClient) GetPostInsights(postID string) (*PostInsights, error) {
 	defer resp.Body.Close()
 
 	var result PostInsights
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
 }
 
+// GetStats implements StatsProvider with GetPostInsights, plus the post's
+// comment and share counts which insights do not report. Views are
+// impressions and Likes are reactions of every type; clicks only count towards
+// Engagement.
+func (c *FaceBookClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	insights, err := c.GetPostInsightsContext(ctx, postID)
+	if err != nil {
+		return PostStats{}, err
+	}
+
+	comments, shares, err := c.postCounts(ctx, postID)
+	if err != nil {
+		return PostStats{}, err
+	}
+
+	views := int64(insights.value("post_impressions"))
+	var likes int64
+	if reactions, ok := insights.metric("post_reactions_by_type_total").(map[string]interface{}); ok {
+		for _, count := range reactions {
+			if n, ok := count.(float64); ok {
+				likes += int64(n)
+			}
+		}
+	}
+	clicks := int64(insights.value("post_clicks"))
+
+	return PostStats{
+		Views:      views,
+		Likes:      likes,
+		Comments:   comments,
+		Shares:     shares,
+		Engagement: engagementPercent(likes+comments+shares+clicks, views),
+	}, nil
+}
+
+// metric returns the latest value of the named metric, or nil if it is missing
+func (p *PostInsights) metric(name string) interface{} {
+	for _, data := range p.Data {
+		if data.Name == name && len(data.Values) > 0 {
+			return data.Values[len(data.Values)-1]["value"]
+		}
+	}
+	return nil
+}
+
+// value returns the latest value of a numeric metric, or 0 if it is missing
+func (p *PostInsights) value(name string) float64 {
+	n, _ := p.metric(name).(float64)
+	return n
+}
+
+// postCounts gets the number of comments and shares of a post
+func (c *FaceBookClient) postCounts(ctx context.Context, postID string) (comments, shares int64, err error) {
+	data := url.Values{}
+	c.setAccessToken(data)
+	data.Set("fields", "shares,comments.summary(true).limit(0)")
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/%s?%s", FacebookAPIBaseURL, postID, data.Encode()), nil)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Shares struct {
+			Count int64 `json:"count"`
+		} `json:"shares"`
+		Comments struct {
+			Summary struct {
+				TotalCount int64 `json:"total_count"`
+			} `json:"summary"`
+		} `json:"comments"`
+		Error *Error `json:"error,omitempty"`
+	}
+	if err := decodeJSONBody(resp, &result); err != nil {
+		return 0, 0, err
+	}
+
+	if result.Error != nil {
+		return 0, 0, newFacebookAPIError(resp.StatusCode, result.Error)
+	}
+
+	return result.Comments.Summary.TotalCount, result.Shares.Count, nil
+}
+
+// postInsightMetrics are the metrics fetched by GetPostInsights
+const postInsightMetrics = "post_impressions,post_impressions_unique,post_reactions_by_type_total,post_clicks,post_engaged_users"
+
+// facebookBatchSize is the largest number of requests the Graph API accepts
+// in one batch
+const facebookBatchSize = 50
+
+// GetPostInsightsBatch gets insights for many posts using Graph API batch
+// requests of up to facebookBatchSize posts each. The result is keyed by post
+// ID; a post whose request failed has its Error set rather than failing the
+// whole call. An error is only returned when a batch request itself fails.
+func (c *FaceBookClient) GetPostInsightsBatch(postIDs []string) (map[string]*PostInsights, error) {
+	return c.GetPostInsightsBatchContext(context.Background(), postIDs)
+}
+
+// GetPostInsightsBatchContext is GetPostInsightsBatch with a context
+func (c *FaceBookClient) GetPostInsightsBatchContext(ctx context.Context, postIDs []string) (map[string]*PostInsights, error) {
+	insights := make(map[string]*PostInsights, len(postIDs))
+
+	for start := 0; start < len(postIDs); start += facebookBatchSize {
+		chunk := postIDs[start:min(start+facebookBatchSize, len(postIDs))]
+		if err := c.getPostInsightsBatch(ctx, chunk, insights); err != nil {
+			return insights, err
+		}
+	}
+
+	return insights, nil
+}
+
+// facebookBatchResponse is one entry of a batch response. Body holds the
+// JSON response of the request as a string.
+type facebookBatchResponse struct {
+	Code int    `json:"code"`
+	Body string `json:"body"`
+}
+
+// getPostInsightsBatch sends one batch request for postIDs and stores the
+// insights of each post in insights
+func (c *FaceBookClient) getPostInsightsBatch(ctx context.Context, postIDs []string, insights map[string]*PostInsights) error {
+	query := url.Values{}
+	query.Set("metric", postInsightMetrics)
+
+	batch := make([]map[string]string, len(postIDs))
+	for i, postID := range postIDs {
+		batch[i] = map[string]string{
+			"method":       "GET",
+			"relative_url": fmt.Sprintf("%s/insights?%s", url.PathEscape(postID), query.Encode()),
+		}
+	}
+
+	batchJSON, err := json.Marshal(batch)
+	if err != nil {
+		return err
+	}
+
+	data := url.Values{}
+	c.setAccessToken(data)
+	data.Set("batch", string(batchJSON))
+	data.Set("include_headers", "false")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", FacebookAPIBaseURL+"/", strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var result Response
+		if err := decodeJSONBody(resp, &result); err == nil && result.Error != nil {
+			return newFacebookAPIError(resp.StatusCode, result.Error)
+		}
+		return fmt.Errorf("failed to get post insights: status: %d", resp.StatusCode)
+	}
+
+	// Requests that did not complete come back as null entries
+	var results []*facebookBatchResponse
+	if err := decodeJSONBody(resp, &results); err != nil {
+		return err
+	}
+
+	for i, postID := range postIDs {
+		var result PostInsights
+		switch {
+		case i >= len(results) || results[i] == nil:
+			result.Error = &Error{Message: "batch request did not complete"}
+		default:
+			if err := json.Unmarshal([]byte(results[i].Body), &result); err != nil {
+				result.Error = &Error{Message: fmt.Sprintf("failed to decode response: %v", err)}
+			} else if result.Error == nil && results[i].Code != http.StatusOK {
+				result.Error = &Error{Message: fmt.Sprintf("request failed with status %d", results[i].Code)}
+			}
+		}
+		insights[postID] = &result
+	}
+
+	return nil
+}
+
 // PageInsights represents insights for a page
 type PageInsights struct {
 	Data []struct {
@@ -333,12 +1018,23 @@ type PageInsights struct {
 	Error *Error `json:"error,omitempty"`
 }
 
-// GetPageInsights gets insights (stats) for a page
-func (c *FaceBookClient) GetPageInsights(pageID string, metrics []string, period string) (*PageInsights, error) {
+// GetPageInsights gets insights (stats) for a page. since and until limit the
+// report to a date range; a zero time leaves that end of the range open.
+func (c *FaceBookClient) GetPageInsights(pageID string, metrics []string, period string, since, until time.Time) (*PageInsights, error) {
+	return c.GetPageInsightsContext(context.Background(), pageID, metrics, period, since, until)
+}
+
+// GetPageInsightsContext is GetPageInsights with a context
+func (c *FaceBookClient) GetPageInsightsContext(ctx context.Context, pageID string, metrics []string, period string, since, until time.Time) (*PageInsights, error) {
+	if !since.IsZero() && !until.IsZero() && until.Before(since) {
+		return nil, fmt.Errorf("invalid insights range: until %s is before since %s",
+			until.Format(time.RFC3339), since.Format(time.RFC3339))
+	}
+
 	endpoint := fmt.Sprintf("%s/%s/insights", FacebookAPIBaseURL, pageID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
+	c.setAccessToken(data)
 
 	if len(metrics) == 0 {
 		// Default metrics if none provided
@@ -356,7 +1052,14 @@ func (c *FaceBookClient) GetPageInsights(pageID string, metrics []string, period
 		data.Set("period", period) // day, week, month, etc.
 	}
 
-	req, err := http.NewRequest("GET", endpoint+"?"+data.Encode(), nil)
+	if !since.IsZero() {
+		data.Set("since", strconv.FormatInt(since.Unix(), 10))
+	}
+	if !until.IsZero() {
+		data.Set("until", strconv.FormatInt(until.Unix(), 10))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+data.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -368,12 +1071,12 @@ func (c *FaceBookClient) GetPageInsights(pageID string, metrics []string, period
 	defer resp.Body.Close()
 
 	var result PageInsights
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
@@ -394,17 +1097,43 @@ type Page struct {
 	Followers_count int    `json:"followers_count"`
 	Link            string `json:"link"`
 	Error           *Error `json:"error,omitempty"`
+
+	// Extra holds the fields requested through GetPageInfo's fields that
+	// Page has no field for
+	Extra map[string]json.RawMessage `json:"-"`
 }
 
-// GetPageInfo gets information about a Facebook page
-func (c *FaceBookClient) GetPageInfo(pageID string) (*Page, error) {
+// pageFields are the fields always requested for a page
+const pageFields = "id,name,category,category_list,about,description,fan_count,followers_count,link"
+
+// UnmarshalJSON decodes a page, keeping unknown fields in Extra
+func (p *Page) UnmarshalJSON(data []byte) error {
+	type page Page
+	if err := json.Unmarshal(data, (*page)(p)); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(data, pageFields+",error")
+	p.Extra = extra
+	return err
+}
+
+// GetPageInfo gets information about a Facebook page. fields are requested on
+// top of the default ones, e.g. "website" or "picture{url}", and returned in
+// Page.Extra.
+func (c *FaceBookClient) GetPageInfo(pageID string, fields ...string) (*Page, error) {
+	return c.GetPageInfoContext(context.Background(), pageID, fields...)
+}
+
+// GetPageInfoContext is GetPageInfo with a context
+func (c *FaceBookClient) GetPageInfoContext(ctx context.Context, pageID string, fields ...string) (*Page, error) {
 	endpoint := fmt.Sprintf("%s/%s", FacebookAPIBaseURL, pageID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
-	data.Set("fields", "id,name,category,category_list,about,description,fan_count,followers_count,link")
+	c.setAccessToken(data)
+	data.Set("fields", fieldsParam(append([]string{pageFields}, fields...)...))
 
-	req, err := http.NewRequest("GET", endpoint+"?"+data.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint+"?"+data.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -416,12 +1145,12 @@ func (c *FaceBookClient) GetPageInfo(pageID string) (*Page, error) {
 	defer resp.Body.Close()
 
 	var result Page
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
@@ -429,12 +1158,17 @@ func (c *FaceBookClient) GetPageInfo(pageID string) (*Page, error) {
 
 // DeletePost deletes a post
 func (c *FaceBookClient) DeletePost(postID string) (*Response, error) {
+	return c.DeletePostContext(context.Background(), postID)
+}
+
+// DeletePostContext is DeletePost with a context
+func (c *FaceBookClient) DeletePostContext(ctx context.Context, postID string) (*Response, error) {
 	endpoint := fmt.Sprintf("%s/%s", FacebookAPIBaseURL, postID)
 
 	data := url.Values{}
-	data.Set("access_token", c.AccessToken)
+	c.setAccessToken(data)
 
-	req, err := http.NewRequest("DELETE", endpoint+"?"+data.Encode(), nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", endpoint+"?"+data.Encode(), nil)
 	if err != nil {
 		return nil, err
 	}
@@ -446,12 +1180,12 @@ func (c *FaceBookClient) DeletePost(postID string) (*Response, error) {
 	defer resp.Body.Close()
 
 	var result Response
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	if result.Error != nil {
-		return &result, fmt.Errorf("Facebook API error: %s", result.Error.Message)
+		return &result, newFacebookAPIError(resp.StatusCode, result.Error)
 	}
 
 	return &result, nil
@@ -0,0 +1,580 @@
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestFacebookClient(t *testing.T, handler http.HandlerFunc) *FaceBookClient {
+	t.Helper()
+
+	_, client := newTestServer(t, handler)
+	c := NewFaceBookClient("token")
+	c.HTTPClient = client
+	return c
+}
+
+func TestFacebookGetCommentTreeBuildsThreads(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v18.0/post_1/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("filter"); got != CommentFilterStream {
+			t.Errorf("filter = %q, want stream", got)
+		}
+
+		if r.URL.Query().Get("after") == "" {
+			fmt.Fprint(w, `{"data":[
+				{"id":"c1","message":"first"},
+				{"id":"c2","message":"reply to first","parent":{"id":"c1"}}
+			],"paging":{"next":"https://graph.facebook.com/v18.0/post_1/comments?filter=stream&after=p2"}}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":[
+			{"id":"c3","message":"reply to reply","parent":{"id":"c2"}},
+			{"id":"c4","message":"second"},
+			{"id":"c5","message":"orphan","parent":{"id":"gone"}}
+		],"paging":{}}`)
+	})
+
+	roots, err := c.GetCommentTree("post_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var ids []string
+	for _, root := range roots {
+		ids = append(ids, root.ID)
+	}
+	if fmt.Sprint(ids) != "[c1 c4 c5]" {
+		t.Fatalf("roots = %v, want [c1 c4 c5]", ids)
+	}
+
+	first := roots[0]
+	if len(first.Replies) != 1 || first.Replies[0].ID != "c2" {
+		t.Fatalf("c1 replies = %+v, want c2", first.Replies)
+	}
+	reply := first.Replies[0]
+	if len(reply.Replies) != 1 || reply.Replies[0].ID != "c3" || reply.Replies[0].Message != "reply to reply" {
+		t.Fatalf("c2 replies = %+v, want c3", reply.Replies)
+	}
+	if len(roots[1].Replies) != 0 || len(roots[2].Replies) != 0 {
+		t.Error("comments without replies got replies")
+	}
+}
+
+func TestFacebookSchedulePost(t *testing.T) {
+	publishAt := time.Now().Add(time.Hour).Truncate(time.Second)
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v18.0/page_1/feed" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if got := r.PostForm.Get("published"); got != "false" {
+			t.Errorf("published = %q, want false", got)
+		}
+		if got := r.PostForm.Get("scheduled_publish_time"); got != strconv.FormatInt(publishAt.Unix(), 10) {
+			t.Errorf("scheduled_publish_time = %q, want %d", got, publishAt.Unix())
+		}
+		if got := r.PostForm.Get("link"); got != "https://example.com" {
+			t.Errorf("link = %q", got)
+		}
+		fmt.Fprint(w, `{"id":"page_1_post_9"}`)
+	})
+
+	resp, err := c.SchedulePost("page_1", "hello", "https://example.com", publishAt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "page_1_post_9" {
+		t.Errorf("ID = %q", resp.ID)
+	}
+}
+
+func TestFacebookSchedulePostRejectsOutOfWindow(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, publishAt := range []time.Time{
+		time.Now().Add(time.Minute),
+		time.Now().Add(76 * 24 * time.Hour),
+	} {
+		if _, err := c.SchedulePost("page_1", "hello", "", publishAt); err == nil {
+			t.Errorf("SchedulePost(%v) succeeded, want an error", publishAt)
+		}
+	}
+}
+
+func TestFacebookUploadVideoStreamsWithProgress(t *testing.T) {
+	video := bytes.Repeat([]byte("v"), 200_000)
+	path := writeTempFile(t, "clip.mp4", video)
+
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if host := r.Header.Get(originalHostHeader); host != "graph-video.facebook.com" || r.URL.Path != "/v18.0/page_1/videos" {
+			t.Errorf("unexpected request to %s%s", host, r.URL.Path)
+		}
+		if r.ContentLength <= int64(len(video)) {
+			t.Errorf("Content-Length = %d, want the video plus the form framing", r.ContentLength)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Fatal(err)
+		}
+		if r.FormValue("access_token") != "token" || r.FormValue("description") != "launch" {
+			t.Errorf("form = %v", r.MultipartForm.Value)
+		}
+		file, _, err := r.FormFile("source")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer file.Close()
+		got, _ := io.ReadAll(file)
+		if !bytes.Equal(got, video) {
+			t.Errorf("uploaded %d bytes, want the %d bytes of the video", len(got), len(video))
+		}
+		fmt.Fprint(w, `{"id":"vid_1"}`)
+	})
+
+	var rec progressRecorder
+	resp, err := c.UploadVideo("page_1", "launch", path, UploadOptions{Progress: rec.record})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "vid_1" {
+		t.Errorf("ID = %q", resp.ID)
+	}
+	rec.check(t, int64(len(video)))
+}
+
+func TestFacebookGetPageInsightsRange(t *testing.T) {
+	since := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		since, until time.Time
+		wantSince    string
+		wantUntil    string
+	}{
+		{"both ends", since, until, "1709251200", "1711843200"},
+		{"open end", since, time.Time{}, "1709251200", ""},
+		{"no range", time.Time{}, time.Time{}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v18.0/page_1/insights" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				q := r.URL.Query()
+				if got := q.Get("since"); got != tt.wantSince {
+					t.Errorf("since = %q, want %q", got, tt.wantSince)
+				}
+				if got := q.Get("until"); got != tt.wantUntil {
+					t.Errorf("until = %q, want %q", got, tt.wantUntil)
+				}
+				if got := q.Get("period"); got != "day" {
+					t.Errorf("period = %q, want day", got)
+				}
+				fmt.Fprint(w, `{"data":[{"name":"page_impressions","period":"day","values":[{"value":12,"end_time":"2024-03-02T08:00:00+0000"}]}]}`)
+			})
+
+			insights, err := c.GetPageInsights("page_1", nil, "day", tt.since, tt.until)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(insights.Data) != 1 || insights.Data[0].Name != "page_impressions" {
+				t.Errorf("insights decoded as %+v", insights.Data)
+			}
+		})
+	}
+}
+
+func TestFacebookGetPageInsightsRejectsInvertedRange(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	since := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	if _, err := c.GetPageInsights("page_1", nil, "day", since, since.AddDate(0, 0, -1)); err == nil {
+		t.Fatal("expected an error when until is before since")
+	}
+}
+
+func TestFacebookAPIErrorFields(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		code    int
+		subcode int
+		target  error
+	}{
+		{"expired token", `{"error":{"message":"Error validating access token: Session has expired","type":"OAuthException","code":190,"error_subcode":463}}`, 190, 463, ErrTokenExpired},
+		{"revoked token", `{"error":{"message":"Error validating access token: The user has not authorized application","type":"OAuthException","code":190,"error_subcode":458}}`, 190, 458, ErrTokenInvalid},
+		{"rate limited", `{"error":{"message":"(#4) Application request limit reached","type":"OAuthException","code":4}}`, 4, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, tt.body)
+			})
+
+			_, err := c.CreatePost("page_1", "hello", "")
+			var apiErr *FacebookAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want a *FacebookAPIError", err)
+			}
+			if apiErr.Code != tt.code || apiErr.Subcode != tt.subcode || apiErr.Type != "OAuthException" || apiErr.StatusCode != http.StatusBadRequest {
+				t.Errorf("error fields = %+v", apiErr)
+			}
+			if want := "Facebook API error: " + apiErr.Message; err.Error() != want {
+				t.Errorf("Error() = %q, want %q", err.Error(), want)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("errors.Is(err, %v) = false", tt.target)
+			}
+			if tt.target == nil && (errors.Is(err, ErrTokenExpired) || errors.Is(err, ErrTokenInvalid)) {
+				t.Error("a non-token error matches a token sentinel")
+			}
+		})
+	}
+}
+
+func TestAppSecretProof(t *testing.T) {
+	// RFC 4231 test case 2: the proof is HMAC-SHA256 keyed with the app secret
+	got := appSecretProof("what do ya want for nothing?", "Jefe")
+	if want := "5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843"; got != want {
+		t.Errorf("appSecretProof() = %s, want %s", got, want)
+	}
+}
+
+func TestFacebookSendsAppSecretProof(t *testing.T) {
+	for _, appSecret := range []string{"Jefe", ""} {
+		t.Run(fmt.Sprintf("secret %q", appSecret), func(t *testing.T) {
+			c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if err := r.ParseForm(); err != nil {
+					t.Fatal(err)
+				}
+				proof, ok := r.Form["appsecret_proof"]
+				switch {
+				case appSecret == "" && ok:
+					t.Error("appsecret_proof sent without an app secret")
+				case appSecret != "" && (len(proof) != 1 || proof[0] != appSecretProof("token", appSecret)):
+					t.Errorf("appsecret_proof = %v", proof)
+				}
+				fmt.Fprint(w, `{"id":"page_1_post_1"}`)
+			})
+			c.AppSecret = appSecret
+
+			if _, err := c.CreatePost("page_1", "hello", ""); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestFacebookGetCommentsDecodesAuthors(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fields := r.URL.Query().Get("fields")
+		if !strings.Contains(fields, "from{id,name,picture}") || !strings.Contains(fields, "message_tags") {
+			t.Errorf("fields = %v, want the author expansion and message_tags", fields)
+		}
+		fmt.Fprint(w, `{"data":[
+			{
+				"id": "post_1_c1",
+				"message": "Thanks Jane Doe!",
+				"created_time": "2024-03-01T10:00:00+0000",
+				"from": {
+					"id": "10158",
+					"name": "John Smith",
+					"picture": {"data": {"height": 50, "width": 50, "is_silhouette": false, "url": "https://scontent.xx.fbcdn.net/p50x50/john.jpg"}}
+				},
+				"message_tags": [{"id": "20264", "name": "Jane Doe", "type": "user", "offset": 7, "length": 8}],
+				"comment_count": 2
+			},
+			{
+				"id": "post_1_c2",
+				"message": "Who am I?",
+				"created_time": "2024-03-01T11:00:00+0000"
+			}
+		]}`)
+	})
+
+	resp, err := c.GetComments("post_1", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Data) != 2 {
+		t.Fatalf("got %d comments, want 2", len(resp.Data))
+	}
+
+	named := resp.Data[0]
+	if named.From == nil || named.From.ID != "10158" || named.From.Name != "John Smith" {
+		t.Fatalf("from = %+v", named.From)
+	}
+	if got := named.From.PictureURL(); got != "https://scontent.xx.fbcdn.net/p50x50/john.jpg" {
+		t.Errorf("PictureURL() = %q", got)
+	}
+	if named.From.Picture.Data.IsSilhouette {
+		t.Error("IsSilhouette = true, want false")
+	}
+	want := MessageTag{ID: "20264", Name: "Jane Doe", Type: "user", Offset: 7, Length: 8}
+	if len(named.MessageTags) != 1 || named.MessageTags[0] != want {
+		t.Errorf("message tags = %+v, want [%+v]", named.MessageTags, want)
+	}
+	if named.CommentCount != 2 || len(named.Extra) != 0 {
+		t.Errorf("comment_count = %d, extra = %v", named.CommentCount, named.Extra)
+	}
+
+	hidden := resp.Data[1]
+	if hidden.From != nil || hidden.From.PictureURL() != "" || hidden.MessageTags != nil {
+		t.Errorf("hidden author decoded as from %+v, tags %+v", hidden.From, hidden.MessageTags)
+	}
+}
+
+func TestFacebookGetCommentsForwardsOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          CommentOptions
+		order, filter string
+	}{
+		{"defaults", CommentOptions{}, "", ""},
+		{"reverse stream", CommentOptions{Order: CommentOrderReverseChronological, Filter: CommentFilterStream}, "reverse_chronological", "stream"},
+		{"chronological toplevel", CommentOptions{Order: CommentOrderChronological, Filter: CommentFilterTopLevel}, "chronological", "toplevel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v18.0/post_1/comments" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				q := r.URL.Query()
+				if _, ok := q["order"]; ok != (tt.order != "") || q.Get("order") != tt.order {
+					t.Errorf("order = %v, want %q", q["order"], tt.order)
+				}
+				if _, ok := q["filter"]; ok != (tt.filter != "") || q.Get("filter") != tt.filter {
+					t.Errorf("filter = %v, want %q", q["filter"], tt.filter)
+				}
+				if q.Get("limit") != "25" {
+					t.Errorf("limit = %q, want 25", q.Get("limit"))
+				}
+				fmt.Fprint(w, `{"data":[{"id":"c1","message":"hi"}]}`)
+			})
+
+			resp, err := c.GetCommentsWithOptions("post_1", 25, tt.opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Data) != 1 || resp.Data[0].ID != "c1" {
+				t.Errorf("comments decoded as %+v", resp.Data)
+			}
+		})
+	}
+}
+
+func TestFacebookGetCommentsRejectsInvalidOptions(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, opts := range []CommentOptions{
+		{Order: "newest"},
+		{Filter: "replies"},
+		{Order: "Chronological"},
+	} {
+		if _, err := c.GetCommentsWithOptions("post_1", 0, opts); err == nil {
+			t.Errorf("GetCommentsWithOptions(%+v) succeeded, want an error", opts)
+		}
+	}
+}
+
+func TestFacebookHideComment(t *testing.T) {
+	for _, hidden := range []bool{true, false} {
+		t.Run(strconv.FormatBool(hidden), func(t *testing.T) {
+			c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v18.0/post_1_comment_2" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				if err := r.ParseForm(); err != nil {
+					t.Fatal(err)
+				}
+				if got := r.PostForm.Get("is_hidden"); got != strconv.FormatBool(hidden) {
+					t.Errorf("is_hidden = %q, want %v", got, hidden)
+				}
+				if got := r.PostForm.Get("access_token"); got != "token" {
+					t.Errorf("access_token = %q", got)
+				}
+				fmt.Fprint(w, `{"success":true}`)
+			})
+
+			resp, err := c.HideComment("post_1_comment_2", hidden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !resp.Success {
+				t.Error("Success = false")
+			}
+		})
+	}
+}
+
+func TestFacebookDeleteComment(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/v18.0/post_1_comment_2" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.URL.Query().Get("access_token"); got != "token" {
+			t.Errorf("access_token = %q", got)
+		}
+		fmt.Fprint(w, `{"success":true}`)
+	})
+
+	resp, err := c.DeleteComment("post_1_comment_2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Success {
+		t.Error("Success = false")
+	}
+}
+
+func TestFacebookModerationSurfacesAPIError(t *testing.T) {
+	const body = `{"error":{"message":"(#200) Permissions error","type":"OAuthException","code":200,"fbtrace_id":"AbC"}}`
+
+	for name, moderate := range map[string]func(*FaceBookClient) (*Response, error){
+		"hide":   func(c *FaceBookClient) (*Response, error) { return c.HideComment("comment_1", true) },
+		"unhide": func(c *FaceBookClient) (*Response, error) { return c.HideComment("comment_1", false) },
+		"delete": func(c *FaceBookClient) (*Response, error) { return c.DeleteComment("comment_1") },
+	} {
+		t.Run(name, func(t *testing.T) {
+			c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, body)
+			})
+
+			resp, err := moderate(c)
+			var apiErr *FacebookAPIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want a *FacebookAPIError", err)
+			}
+			if apiErr.Code != 200 || apiErr.StatusCode != http.StatusForbidden || apiErr.Message != "(#200) Permissions error" {
+				t.Errorf("error fields = %+v", apiErr)
+			}
+			if resp == nil || resp.Success {
+				t.Errorf("response = %+v, want the decoded failure", resp)
+			}
+		})
+	}
+}
+
+func TestFacebookGetPostInsightsBatchMixedResponses(t *testing.T) {
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v18.0/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		var batch []map[string]string
+		if err := json.Unmarshal([]byte(r.PostForm.Get("batch")), &batch); err != nil {
+			t.Fatal(err)
+		}
+		if len(batch) != 6 || batch[0]["method"] != "GET" || !strings.HasPrefix(batch[0]["relative_url"], "ok_1/insights?metric=post_impressions") {
+			t.Errorf("batch = %v", batch)
+		}
+
+		// the sixth request gets no entry at all
+		fmt.Fprint(w, `[
+			{"code": 200, "body": "{\"data\":[{\"name\":\"post_impressions\",\"period\":\"lifetime\",\"values\":[{\"value\":1234}],\"id\":\"ok_1/insights/post_impressions/lifetime\"}]}"},
+			{"code": 400, "body": "{\"error\":{\"message\":\"Unsupported get request.\",\"type\":\"GraphMethodException\",\"code\":100,\"error_subcode\":33}}"},
+			null,
+			{"code": 200, "body": "not json"},
+			{"code": 500, "body": "{}"}
+		]`)
+	})
+
+	ids := []string{"ok_1", "missing_2", "timeout_3", "garbled_4", "failed_5", "dropped_6"}
+	insights, err := c.GetPostInsightsBatch(ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(insights) != len(ids) {
+		t.Fatalf("got insights for %d posts, want %d", len(insights), len(ids))
+	}
+
+	ok := insights["ok_1"]
+	if ok.Error != nil || len(ok.Data) != 1 || ok.Data[0].Name != "post_impressions" || ok.Data[0].Values[0]["value"] != float64(1234) {
+		t.Errorf("ok_1 = %+v", ok)
+	}
+
+	want := map[string]Error{
+		"missing_2": {Message: "Unsupported get request.", Type: "GraphMethodException", Code: 100, Subcode: 33},
+		"timeout_3": {Message: "batch request did not complete"},
+		"failed_5":  {Message: "request failed with status 500"},
+		"dropped_6": {Message: "batch request did not complete"},
+	}
+	for id, wantErr := range want {
+		if got := insights[id].Error; got == nil || *got != wantErr {
+			t.Errorf("%s error = %+v, want %+v", id, got, wantErr)
+		}
+	}
+	if got := insights["garbled_4"].Error; got == nil || !strings.HasPrefix(got.Message, "failed to decode response") {
+		t.Errorf("garbled_4 error = %+v, want a decoding error", got)
+	}
+}
+
+func TestFacebookGetPostInsightsBatchSplitsBatches(t *testing.T) {
+	var sizes []int
+	c := newTestFacebookClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		var batch []map[string]string
+		if err := json.Unmarshal([]byte(r.PostForm.Get("batch")), &batch); err != nil {
+			t.Fatal(err)
+		}
+		sizes = append(sizes, len(batch))
+		if len(sizes) == 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Too many requests in batch","type":"OAuthException","code":1}}`)
+			return
+		}
+
+		entries := make([]string, len(batch))
+		for i := range entries {
+			entries[i] = `{"code":200,"body":"{\"data\":[]}"}`
+		}
+		fmt.Fprint(w, "["+strings.Join(entries, ",")+"]")
+	})
+
+	ids := make([]string, facebookBatchSize+3)
+	for i := range ids {
+		ids[i] = "post_" + strconv.Itoa(i)
+	}
+	insights, err := c.GetPostInsightsBatch(ids)
+
+	var apiErr *FacebookAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != 1 {
+		t.Errorf("error = %v, want the failed batch's API error", err)
+	}
+	if fmt.Sprint(sizes) != fmt.Sprintf("[%d 3]", facebookBatchSize) {
+		t.Errorf("batch sizes = %v", sizes)
+	}
+	if len(insights) != facebookBatchSize {
+		t.Errorf("got insights for %d posts, want the %d of the first batch", len(insights), facebookBatchSize)
+	}
+}
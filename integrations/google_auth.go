@@ -3,6 +3,7 @@ package integrations
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
@@ -11,15 +12,48 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"sync"
 	"time"
 )
 
+// googleHTTPClient is shared by the login flow requests, which are retried on
+// transient Google failures
+var googleHTTPClient = &http.Client{Timeout: 30 * time.Second}
+
+// Google OAuth scopes supported by the integrations
+const (
+	ScopeOpenID                   = "openid"
+	ScopeUserinfoEmail            = "https://www.googleapis.com/auth/userinfo.email"
+	ScopeUserinfoProfile          = "https://www.googleapis.com/auth/userinfo.profile"
+	ScopeYouTube                  = "https://www.googleapis.com/auth/youtube"
+	ScopeYouTubeUpload            = "https://www.googleapis.com/auth/youtube.upload"
+	ScopeYouTubeReadonly          = "https://www.googleapis.com/auth/youtube.readonly"
+	ScopeYouTubeForceSSL          = "https://www.googleapis.com/auth/youtube.force-ssl"
+	ScopeYouTubeAnalyticsReadonly = "https://www.googleapis.com/auth/yt-analytics.readonly"
+)
+
+// knownGoogleScopes is the set of scopes accepted by WithScopes
+var knownGoogleScopes = map[string]bool{
+	ScopeOpenID:                   true,
+	ScopeUserinfoEmail:            true,
+	ScopeUserinfoProfile:          true,
+	ScopeYouTube:                  true,
+	ScopeYouTubeUpload:            true,
+	ScopeYouTubeReadonly:          true,
+	ScopeYouTubeForceSSL:          true,
+	ScopeYouTubeAnalyticsReadonly: true,
+}
+
 // GoogleOAuthConfig holds the configuration for Google OAuth
 type GoogleOAuthConfig struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURL  string
 	Scopes       []string
+
+	// HTTPClient sends the OAuth requests; nil uses a shared client with a
+	// 30 second timeout
+	HTTPClient *http.Client
 }
 
 // GoogleToken represents an OAuth token
@@ -29,9 +63,26 @@ type GoogleToken struct {
 	RefreshToken string `json:"refresh_token,omitempty"`
 	ExpiresIn    int    `json:"expires_in,omitempty"`
 	IDToken      string `json:"id_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 	Expiry       time.Time
 }
 
+// googleTokenExpirySkew is how long before Expiry a token is treated as
+// expired, so requests are not sent with a token that lapses in flight
+const googleTokenExpirySkew = 30 * time.Second
+
+// IsExpired reports whether the access token is missing or expires within
+// googleTokenExpirySkew. Tokens without an Expiry are assumed to be valid.
+func (t *GoogleToken) IsExpired() bool {
+	if t == nil || t.AccessToken == "" {
+		return true
+	}
+	if t.Expiry.IsZero() {
+		return false
+	}
+	return time.Now().Add(googleTokenExpirySkew).After(t.Expiry)
+}
+
 // GoogleUserInfo represents the user information returned from Google
 type GoogleUserInfo struct {
 	ID            string `json:"id"`
@@ -44,13 +95,64 @@ type GoogleUserInfo struct {
 	Locale        string `json:"locale"`
 }
 
+// googleError builds the error for a failed Google request. Rejected
+// credentials are reported as an *APIError:
+//
+//   - a 401 from an API is ErrTokenExpired. Google does not say why it
+//     rejected an access token, and as they only live an hour a refresh is
+//     the right first step; a revoked grant then fails the refresh.
+//   - invalid_grant from the token endpoint is ErrTokenInvalid: the refresh
+//     token or code was revoked or has expired and the user has to log in
+//     again.
+//
+// Other failures keep the "<op> failed with status" form.
+func googleError(op string, statusCode int, body []byte) error {
+	var errBody struct {
+		Error            json.RawMessage `json:"error"`
+		ErrorDescription string          `json:"error_description"`
+	}
+	_ = json.Unmarshal(body, &errBody)
+
+	// OAuth endpoints report {"error": "code", "error_description": "..."},
+	// APIs report {"error": {"code": 401, "status": "...", "message": "..."}}
+	var code, message string
+	var apiErr struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(errBody.Error, &code) == nil {
+		message = errBody.ErrorDescription
+	} else if json.Unmarshal(errBody.Error, &apiErr) == nil {
+		code, message = apiErr.Status, apiErr.Message
+	}
+
+	var tokenErr error
+	switch {
+	case code == "invalid_grant":
+		tokenErr = ErrTokenInvalid
+	case statusCode == http.StatusUnauthorized:
+		tokenErr = ErrTokenExpired
+	}
+	if tokenErr == nil {
+		return fmt.Errorf("%s failed with status %d: %s", op, statusCode, string(body))
+	}
+
+	return &APIError{
+		Platform:   "Google",
+		StatusCode: statusCode,
+		Code:       code,
+		Message:    message,
+		Err:        tokenErr,
+	}
+}
+
 // NewGoogleOAuth creates a new Google OAuth config
 func NewGoogleOAuth(clientID, clientSecret, redirectURL string, scopes []string) *GoogleOAuthConfig {
 	// If no scopes provided, use the default profile and email scopes
 	if len(scopes) == 0 {
 		scopes = []string{
-			"https://www.googleapis.com/auth/userinfo.profile",
-			"https://www.googleapis.com/auth/userinfo.email",
+			ScopeUserinfoProfile,
+			ScopeUserinfoEmail,
 		}
 	}
 
@@ -62,6 +164,47 @@ func NewGoogleOAuth(clientID, clientSecret, redirectURL string, scopes []string)
 	}
 }
 
+// WithScopes replaces the configured scopes, dropping duplicates while keeping
+// the order of first occurrence. Scopes that are not known Google scope URLs
+// are rejected so typos surface here rather than as consent failures.
+func (g *GoogleOAuthConfig) WithScopes(scopes ...string) (*GoogleOAuthConfig, error) {
+	seen := make(map[string]bool, len(scopes))
+	deduped := make([]string, 0, len(scopes))
+
+	for _, scope := range scopes {
+		scope = strings.TrimSpace(scope)
+		if !knownGoogleScopes[scope] {
+			return nil, fmt.Errorf("unknown Google OAuth scope: %q", scope)
+		}
+		if seen[scope] {
+			continue
+		}
+		seen[scope] = true
+		deduped = append(deduped, scope)
+	}
+
+	if len(deduped) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	g.Scopes = deduped
+	return g, nil
+}
+
+// WithHTTPClient sets the client used for the OAuth requests
+func (g *GoogleOAuthConfig) WithHTTPClient(client *http.Client) *GoogleOAuthConfig {
+	g.HTTPClient = client
+	return g
+}
+
+// httpClient returns the configured client or the shared default
+func (g *GoogleOAuthConfig) httpClient() *http.Client {
+	if g.HTTPClient != nil {
+		return g.HTTPClient
+	}
+	return googleHTTPClient
+}
+
 // GenerateStateToken creates a random state token to prevent CSRF attacks
 func GenerateStateToken() (string, error) {
 	b := make([]byte, 32)
@@ -72,12 +215,45 @@ func GenerateStateToken() (string, error) {
 	return base64.URLEncoding.EncodeToString(b), nil
 }
 
+// GeneratePKCEVerifier creates a random PKCE code verifier and its S256 code
+// challenge. Send the challenge with GetLoginURLWithPKCE and keep the
+// verifier, like the state token, until the callback passes it to
+// ExchangeCodeForTokenPKCE.
+func GeneratePKCEVerifier() (verifier, challenge string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	verifier = base64.RawURLEncoding.EncodeToString(b)
+	return verifier, PKCEChallenge(verifier), nil
+}
+
+// PKCEChallenge returns the S256 code challenge for a code verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
 // GetLoginURL returns the Google OAuth login URL
 func (g *GoogleOAuthConfig) GetLoginURL(state string) string {
+	return g.loginURL(state, url.Values{})
+}
+
+// GetLoginURLWithPKCE returns the Google OAuth login URL with a PKCE code
+// challenge, as made by GeneratePKCEVerifier. The code returned to the
+// callback must be exchanged with ExchangeCodeForTokenPKCE.
+func (g *GoogleOAuthConfig) GetLoginURLWithPKCE(state, codeChallenge string) string {
+	params := url.Values{}
+	params.Add("code_challenge", codeChallenge)
+	params.Add("code_challenge_method", "S256")
+	return g.loginURL(state, params)
+}
+
+// loginURL builds the login URL with extra query parameters
+func (g *GoogleOAuthConfig) loginURL(state string, params url.Values) string {
 	authURL := "https://accounts.google.com/o/oauth2/auth"
 
 	// Build query parameters
-	params := url.Values{}
 	params.Add("client_id", g.ClientID)
 	params.Add("redirect_uri", g.RedirectURL)
 	params.Add("response_type", "code")
@@ -90,10 +266,27 @@ func (g *GoogleOAuthConfig) GetLoginURL(state string) string {
 
 // ExchangeCodeForToken exchanges the authorization code for an access token
 func (g *GoogleOAuthConfig) ExchangeCodeForToken(ctx context.Context, code string) (*GoogleToken, error) {
+	return g.exchangeCode(ctx, code, url.Values{})
+}
+
+// ExchangeCodeForTokenPKCE exchanges an authorization code obtained through
+// GetLoginURLWithPKCE, proving the request with the code verifier
+func (g *GoogleOAuthConfig) ExchangeCodeForTokenPKCE(ctx context.Context, code, codeVerifier string) (*GoogleToken, error) {
+	if codeVerifier == "" {
+		return nil, errors.New("code verifier is required")
+	}
+
+	data := url.Values{}
+	data.Set("code_verifier", codeVerifier)
+	return g.exchangeCode(ctx, code, data)
+}
+
+// exchangeCode exchanges an authorization code, adding its own fields to the
+// form data
+func (g *GoogleOAuthConfig) exchangeCode(ctx context.Context, code string, data url.Values) (*GoogleToken, error) {
 	tokenURL := "https://oauth2.googleapis.com/token"
 
 	// Build the form data
-	data := url.Values{}
 	data.Set("code", code)
 	data.Set("client_id", g.ClientID)
 	data.Set("client_secret", g.ClientSecret)
@@ -108,9 +301,13 @@ func (g *GoogleOAuthConfig) ExchangeCodeForToken(ctx context.Context, code strin
 
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
+	// Replaying the exchange is safe: Google rejects a code that was already
+	// used, so a code is never exchanged twice
+	retry := DefaultRetryConfig
+	retry.RetryNonIdempotent = true
+
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doWithRetry(g.httpClient(), req, retry)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send token request: %w", err)
 	}
@@ -118,12 +315,12 @@ func (g *GoogleOAuthConfig) ExchangeCodeForToken(ctx context.Context, code strin
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("token request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, googleError("token request", resp.StatusCode, body)
 	}
 
 	// Parse the response
 	var token GoogleToken
-	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+	if err := decodeJSONBody(resp, &token); err != nil {
 		return nil, fmt.Errorf("failed to decode token response: %w", err)
 	}
 
@@ -137,6 +334,11 @@ func (g *GoogleOAuthConfig) ExchangeCodeForToken(ctx context.Context, code strin
 
 // GetUserInfo retrieves the Google user info using the access token
 func GetUserInfo(ctx context.Context, token *GoogleToken) (*GoogleUserInfo, error) {
+	return (&GoogleOAuthConfig{}).GetUserInfo(ctx, token)
+}
+
+// GetUserInfo retrieves the Google user info using the access token
+func (g *GoogleOAuthConfig) GetUserInfo(ctx context.Context, token *GoogleToken) (*GoogleUserInfo, error) {
 	// Make a request to the userinfo endpoint
 	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.googleapis.com/oauth2/v2/userinfo", nil)
 	if err != nil {
@@ -146,8 +348,7 @@ func GetUserInfo(ctx context.Context, token *GoogleToken) (*GoogleUserInfo, erro
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := doWithRetry(g.httpClient(), req, DefaultRetryConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
@@ -155,12 +356,12 @@ func GetUserInfo(ctx context.Context, token *GoogleToken) (*GoogleUserInfo, erro
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("user info request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, googleError("user info request", resp.StatusCode, body)
 	}
 
 	// Parse the response
 	var userInfo GoogleUserInfo
-	if err := json.NewDecoder(resp.Body).Decode(&userInfo); err != nil {
+	if err := decodeJSONBody(resp, &userInfo); err != nil {
 		return nil, fmt.Errorf("failed to decode user info: %w", err)
 	}
 
@@ -187,8 +388,7 @@ func (g *GoogleOAuthConfig) RefreshToken(ctx context.Context, refreshToken strin
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := g.httpClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send refresh token request: %w", err)
 	}
@@ -196,12 +396,12 @@ func (g *GoogleOAuthConfig) RefreshToken(ctx context.Context, refreshToken strin
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("refresh token request failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, googleError("refresh token request", resp.StatusCode, body)
 	}
 
 	// Parse the response
 	var token GoogleToken
-	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+	if err := decodeJSONBody(resp, &token); err != nil {
 		return nil, fmt.Errorf("failed to decode token response: %w", err)
 	}
 
@@ -218,8 +418,82 @@ func (g *GoogleOAuthConfig) RefreshToken(ctx context.Context, refreshToken strin
 	return &token, nil
 }
 
+// Client returns an HTTP client that authorizes every request with the given
+// token, transparently refreshing it through RefreshToken once it expires.
+// ctx is used for the refresh requests.
+func (g *GoogleOAuthConfig) Client(ctx context.Context, token *GoogleToken) *http.Client {
+	base := http.DefaultTransport
+	if g.HTTPClient != nil && g.HTTPClient.Transport != nil {
+		base = g.HTTPClient.Transport
+	}
+
+	return &http.Client{
+		Transport: &googleTransport{
+			ctx:    ctx,
+			config: g,
+			token:  token,
+			base:   base,
+		},
+	}
+}
+
+// googleTransport is an http.RoundTripper that attaches a Google bearer token,
+// refreshing it when it has expired
+type googleTransport struct {
+	ctx    context.Context
+	config *GoogleOAuthConfig
+	base   http.RoundTripper
+
+	mu    sync.Mutex
+	token *GoogleToken
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *googleTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.validToken()
+	if err != nil {
+		if req.Body != nil {
+			req.Body.Close()
+		}
+		return nil, err
+	}
+
+	// A RoundTripper must not modify the caller's request
+	authReq := req.Clone(req.Context())
+	authReq.Header.Set("Authorization", "Bearer "+token.AccessToken)
+
+	return t.base.RoundTrip(authReq)
+}
+
+// validToken returns the current token, refreshing it first if it has expired
+func (t *googleTransport) validToken() (*GoogleToken, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !t.token.IsExpired() {
+		return t.token, nil
+	}
+
+	if t.token == nil || t.token.RefreshToken == "" {
+		return nil, errors.New("google token expired and no refresh token is available")
+	}
+
+	refreshed, err := t.config.RefreshToken(t.ctx, t.token.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	t.token = refreshed
+	return t.token, nil
+}
+
 // VerifyIDToken verifies and decodes a Google ID token
 func VerifyIDToken(ctx context.Context, idToken string) (map[string]interface{}, error) {
+	return (&GoogleOAuthConfig{}).VerifyIDToken(ctx, idToken)
+}
+
+// VerifyIDToken verifies and decodes a Google ID token
+func (g *GoogleOAuthConfig) VerifyIDToken(ctx context.Context, idToken string) (map[string]interface{}, error) {
 	// Google's tokeninfo endpoint for verifying ID tokens
 	tokenInfoURL := "https://oauth2.googleapis.com/tokeninfo"
 
@@ -234,8 +508,7 @@ func VerifyIDToken(ctx context.Context, idToken string) (map[string]interface{},
 	}
 
 	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := g.httpClient().Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to verify ID token: %w", err)
 	}
@@ -247,7 +520,7 @@ func VerifyIDToken(ctx context.Context, idToken string) (map[string]interface{},
 
 	// Parse the response
 	var tokenInfo map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&tokenInfo); err != nil {
+	if err := decodeJSONBody(resp, &tokenInfo); err != nil {
 		return nil, fmt.Errorf("failed to decode token info: %w", err)
 	}
 
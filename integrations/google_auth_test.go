@@ -0,0 +1,280 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGoogleTokenIsExpired(t *testing.T) {
+	tests := []struct {
+		name  string
+		token *GoogleToken
+		want  bool
+	}{
+		{"nil", nil, true},
+		{"no access token", &GoogleToken{}, true},
+		{"no expiry", &GoogleToken{AccessToken: "a"}, false},
+		{"expired", &GoogleToken{AccessToken: "a", Expiry: time.Now().Add(-time.Minute)}, true},
+		{"within skew", &GoogleToken{AccessToken: "a", Expiry: time.Now().Add(10 * time.Second)}, true},
+		{"valid", &GoogleToken{AccessToken: "a", Expiry: time.Now().Add(time.Hour)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.token.IsExpired(); got != tt.want {
+				t.Errorf("IsExpired() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGoogleClientRefreshesExpiredToken(t *testing.T) {
+	var refreshes int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			atomic.AddInt32(&refreshes, 1)
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("parsing the refresh form: %v", err)
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh" {
+				t.Errorf("unexpected refresh form %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"access_token":"fresh","token_type":"Bearer","expires_in":3600}`)
+		case "/oauth2/v2/userinfo":
+			if got := r.Header.Get("Authorization"); got != "Bearer fresh" {
+				t.Errorf("Authorization = %q, want Bearer fresh", got)
+			}
+			fmt.Fprint(w, `{"id":"1"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	g := NewGoogleOAuth("id", "secret", "http://localhost/callback", nil).WithHTTPClient(client)
+	token := &GoogleToken{
+		AccessToken:  "stale",
+		RefreshToken: "refresh",
+		Expiry:       time.Now().Add(-time.Minute),
+	}
+	authClient := g.Client(context.Background(), token)
+
+	for i := 0; i < 2; i++ {
+		resp, err := authClient.Get("https://www.googleapis.com/oauth2/v2/userinfo")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("status = %d", resp.StatusCode)
+		}
+	}
+
+	if n := atomic.LoadInt32(&refreshes); n != 1 {
+		t.Errorf("refreshes = %d, want 1", n)
+	}
+}
+
+func TestGoogleClientWithoutRefreshToken(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	g := NewGoogleOAuth("id", "secret", "", nil).WithHTTPClient(client)
+	authClient := g.Client(context.Background(), &GoogleToken{
+		AccessToken: "stale",
+		Expiry:      time.Now().Add(-time.Minute),
+	})
+
+	if _, err := authClient.Get("https://www.googleapis.com/oauth2/v2/userinfo"); err == nil {
+		t.Fatal("expected an error without a refresh token")
+	}
+}
+
+func TestGoogleGetUserInfoRetriesTransientFailure(t *testing.T) {
+	var requests int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"id":"42","email":"user@example.com","verified_email":true,"name":"User"}`)
+	})
+
+	g := NewGoogleOAuth("id", "secret", "", nil).WithHTTPClient(client)
+	info, err := g.GetUserInfo(context.Background(), &GoogleToken{AccessToken: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+	if info.ID != "42" || info.Email != "user@example.com" || !info.VerifiedEmail {
+		t.Errorf("user info decoded as %+v", info)
+	}
+}
+
+func TestGoogleExchangeCodeRetriesServerError(t *testing.T) {
+	var requests int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing the token form: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("code") != "code" {
+			t.Errorf("code = %q after retry", r.PostForm.Get("code"))
+		}
+		fmt.Fprint(w, `{"access_token":"a","token_type":"Bearer","expires_in":3600}`)
+	})
+
+	g := NewGoogleOAuth("id", "secret", "", nil).WithHTTPClient(client)
+	token, err := g.ExchangeCodeForToken(context.Background(), "code")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+	if token.AccessToken != "a" || token.Expiry.IsZero() {
+		t.Errorf("token decoded as %+v", token)
+	}
+}
+
+func TestGoogleWithScopesDedups(t *testing.T) {
+	g := NewGoogleOAuth("id", "secret", "", nil)
+	if _, err := g.WithScopes(ScopeUserinfoEmail, ScopeYouTubeUpload, " "+ScopeUserinfoEmail, ScopeYouTubeUpload); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{ScopeUserinfoEmail, ScopeYouTubeUpload}
+	if len(g.Scopes) != len(want) {
+		t.Fatalf("scopes = %v, want %v", g.Scopes, want)
+	}
+	for i := range want {
+		if g.Scopes[i] != want[i] {
+			t.Errorf("scopes[%d] = %q, want %q", i, g.Scopes[i], want[i])
+		}
+	}
+}
+
+func TestGoogleWithScopesValidates(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+	}{
+		{"none", nil},
+		{"typo", []string{ScopeUserinfoEmail, "https://www.googleapis.com/auth/youtube.uplaod"}},
+		{"empty", []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewGoogleOAuth("id", "secret", "", nil)
+			before := append([]string(nil), g.Scopes...)
+
+			if _, err := g.WithScopes(tt.scopes...); err == nil {
+				t.Fatal("expected an error")
+			}
+			if len(g.Scopes) != len(before) {
+				t.Errorf("scopes changed to %v after a rejected WithScopes", g.Scopes)
+			}
+		})
+	}
+}
+
+func TestPKCEChallenge(t *testing.T) {
+	// the example from RFC 7636, appendix B
+	if got := PKCEChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"); got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("PKCEChallenge() = %q", got)
+	}
+
+	verifier, challenge, err := GeneratePKCEVerifier()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// RFC 7636 requires 43 to 128 characters from the unreserved set
+	if len(verifier) < 43 || len(verifier) > 128 || strings.Trim(verifier, "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-._~") != "" {
+		t.Errorf("verifier %q is not a valid PKCE code verifier", verifier)
+	}
+	if challenge != PKCEChallenge(verifier) {
+		t.Errorf("challenge = %q, want the S256 challenge of the verifier", challenge)
+	}
+	if other, _, _ := GeneratePKCEVerifier(); other == verifier {
+		t.Error("GeneratePKCEVerifier returned the same verifier twice")
+	}
+}
+
+func TestGoogleLoginURLWithPKCE(t *testing.T) {
+	g := NewGoogleOAuth("id", "secret", "http://localhost/callback", nil)
+
+	loginURL, err := url.Parse(g.GetLoginURLWithPKCE("state-1", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := loginURL.Query()
+	if q.Get("code_challenge") != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" || q.Get("code_challenge_method") != "S256" {
+		t.Errorf("challenge params = %q, %q", q.Get("code_challenge"), q.Get("code_challenge_method"))
+	}
+	if q.Get("state") != "state-1" || q.Get("client_id") != "id" || q.Get("response_type") != "code" {
+		t.Errorf("query = %v", q)
+	}
+
+	plain, err := url.Parse(g.GetLoginURL("state-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain.Query().Has("code_challenge") || plain.Query().Has("code_challenge_method") {
+		t.Errorf("GetLoginURL sent PKCE params: %v", plain.Query())
+	}
+}
+
+func TestGoogleExchangeCodeForTokenPKCE(t *testing.T) {
+	var requests int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		if r.URL.Path != "/token" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parsing the token form: %v", err)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.PostForm.Get("code_verifier") != "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk" {
+			t.Errorf("code_verifier = %q", r.PostForm.Get("code_verifier"))
+		}
+		if r.PostForm.Get("code") != "code" || r.PostForm.Get("grant_type") != "authorization_code" {
+			t.Errorf("form = %v", r.PostForm)
+		}
+		fmt.Fprint(w, `{"access_token":"a","token_type":"Bearer","expires_in":3600}`)
+	})
+
+	g := NewGoogleOAuth("id", "secret", "http://localhost/callback", nil).WithHTTPClient(client)
+	token, err := g.ExchangeCodeForTokenPKCE(context.Background(), "code", "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.AccessToken != "a" {
+		t.Errorf("token decoded as %+v", token)
+	}
+
+	if _, err := g.ExchangeCodeForTokenPKCE(context.Background(), "code", ""); err == nil {
+		t.Error("expected an error without a code verifier")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
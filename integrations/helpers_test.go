@@ -0,0 +1,42 @@
+package integrations
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// originalHostHeader carries the host a redirected request was meant for
+const originalHostHeader = "X-Original-Host"
+
+// redirectTransport sends every request to a test server, keeping the path
+// and query, so clients with hardcoded API hosts can be tested. The intended
+// host is sent in originalHostHeader.
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set(originalHostHeader, req.URL.Host)
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	req.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// newTestServer starts a server for handler and returns it with an
+// http.Client that routes every request to it
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*httptest.Server, *http.Client) {
+	t.Helper()
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return srv, &http.Client{Transport: redirectTransport{target: target}}
+}
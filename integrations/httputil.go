@@ -0,0 +1,462 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryConfig controls how doWithRetry retries transient failures
+type RetryConfig struct {
+	MaxAttempts int           // total attempts, including the first one
+	BaseDelay   time.Duration // delay before the first retry
+	MaxDelay    time.Duration // upper bound for the backoff delay
+
+	// Prepare, when set, is called before every attempt, e.g. to sign
+	// requests whose signature must not be replayed
+	Prepare func(*http.Request)
+
+	// RetryNonIdempotent allows retrying POST and PATCH requests without an
+	// idempotency key, for platforms that deduplicate them server side
+	RetryNonIdempotent bool
+
+	// Throttled, when set, inspects the body of a response whose status
+	// does not signal rate limiting, for platforms that report throttling in
+	// the body, and returns how long to wait before retrying
+	Throttled func(resp *http.Response, body []byte) (wait time.Duration, ok bool)
+}
+
+// DefaultRetryConfig is used by clients that do not configure their own retries
+var DefaultRetryConfig = RetryConfig{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+}
+
+// doWithRetry sends req with client, retrying connection errors, rate limiting
+// and transient server errors with jittered exponential backoff. Requests that
+// are not idempotent (see isIdempotent) are only retried when rate limited,
+// since any other failure may have happened after the platform acted on them,
+// unless cfg.RetryNonIdempotent is set. A Retry-After header on the
+// response, in seconds or as an HTTP date, takes precedence over the backoff;
+// if it asks for a longer wait than cfg.MaxDelay, the response is returned
+// instead. Throttling detected by cfg.Throttled is handled like a 429 whose
+// Retry-After is the wait it returns. The request body is buffered before the
+// first attempt so it can be replayed.
+func doWithRetry(client *http.Client, req *http.Request, cfg RetryConfig) (*http.Response, error) {
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+
+	if err := makeReplayable(req); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		if cfg.Prepare != nil {
+			cfg.Prepare(req)
+		}
+
+		resp, err := client.Do(req)
+		if attempt >= cfg.MaxAttempts {
+			return resp, err
+		}
+
+		// A throttled request was rejected before it was processed, so it is
+		// retried whatever its method, as with a 429
+		wait, throttled := bodyThrottle(resp, err, cfg)
+		if !throttled && !shouldRetry(req, resp, err, cfg) {
+			return resp, err
+		}
+
+		delay := backoffDelay(cfg, attempt)
+		if resp != nil {
+			hasWait := throttled
+			if !hasWait {
+				wait, hasWait = parseRetryAfter(resp.Header.Get("Retry-After"))
+			}
+			if hasWait {
+				if cfg.MaxDelay > 0 && wait > cfg.MaxDelay {
+					return resp, nil
+				}
+				delay = wait
+			}
+
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		if err := sleepContext(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// makeReplayable buffers the request body, if any, so it can be sent again
+func makeReplayable(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.GetBody != nil {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return err
+	}
+
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+	req.Body, _ = req.GetBody()
+	req.ContentLength = int64(len(body))
+
+	return nil
+}
+
+// bodyThrottle runs cfg.Throttled on a response whose status does not already
+// call for a retry. The body is buffered and put back so the caller can still
+// decode it.
+func bodyThrottle(resp *http.Response, err error, cfg RetryConfig) (time.Duration, bool) {
+	if err != nil || cfg.Throttled == nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return 0, false
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		return 0, false
+	}
+
+	return cfg.Throttled(resp, body)
+}
+
+// shouldRetry reports whether a request that produced resp/err is worth retrying
+func shouldRetry(req *http.Request, resp *http.Response, err error, cfg RetryConfig) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+
+	if !cfg.RetryNonIdempotent && !isIdempotent(req) {
+		// A rate limited request was rejected before it was processed
+		return err == nil && resp.StatusCode == http.StatusTooManyRequests
+	}
+
+	if err != nil {
+		return true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// idempotencyKeyHeader carries the key set by WithIdempotencyKey
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// isIdempotent reports whether sending req twice has the same effect as
+// sending it once. GET, HEAD, OPTIONS, PUT and DELETE are idempotent by
+// definition; other methods only when they carry an idempotency key.
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get(idempotencyKeyHeader) != ""
+}
+
+// RequestOption customizes a single API request
+type RequestOption func(*http.Request)
+
+// WithIdempotencyKey sets an idempotency key on the request so the platform
+// can deduplicate it, which also allows doWithRetry to retry it
+func WithIdempotencyKey(key string) RequestOption {
+	return func(req *http.Request) {
+		if key != "" {
+			req.Header.Set(idempotencyKeyHeader, key)
+		}
+	}
+}
+
+// WithHeaders sets extra headers on the request, such as a LinkedIn-Version
+// for a versioned API. They replace headers of the same name the client sets
+// by default.
+func WithHeaders(headers map[string]string) RequestOption {
+	return func(req *http.Request) {
+		for name, value := range headers {
+			req.Header.Set(name, value)
+		}
+	}
+}
+
+// newAPIRequest builds a request and applies opts to it
+func newAPIRequest(ctx context.Context, method, url string, body io.Reader, opts ...RequestOption) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		opt(req)
+	}
+
+	return req, nil
+}
+
+// backoffDelay returns the delay before the retry following the given attempt.
+// The delay doubles with every attempt, up to cfg.MaxDelay, and is jittered
+// into its upper half so concurrent clients do not retry in lockstep.
+func backoffDelay(cfg RetryConfig, attempt int) time.Duration {
+	delay := cfg.BaseDelay << (attempt - 1)
+	if cfg.MaxDelay > 0 && (delay > cfg.MaxDelay || delay <= 0) {
+		delay = cfg.MaxDelay
+	}
+	if delay <= 1 {
+		return delay
+	}
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(delay-half)+1))
+}
+
+// maxRetryAfter caps the wait taken from a Retry-After header, so a bogus
+// value cannot stall a caller indefinitely
+const maxRetryAfter = time.Hour
+
+// parseRetryAfter returns the wait requested by a Retry-After header, given
+// either as a number of seconds or as an HTTP date. Negative waits and dates
+// in the past are clamped to zero and waits longer than maxRetryAfter to
+// maxRetryAfter. It returns false for an empty or malformed header.
+func parseRetryAfter(header string) (time.Duration, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0, false
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.ParseInt(header, 10, 64); err == nil {
+		if seconds > int64(maxRetryAfter/time.Second) {
+			return maxRetryAfter, true
+		}
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(header); err == nil {
+		wait = time.Until(date)
+	} else {
+		return 0, false
+	}
+
+	return min(max(wait, 0), maxRetryAfter), true
+}
+
+// DefaultOperationTimeout bounds multi-step flows, such as upload, wait for
+// processing and publish, as a whole
+const DefaultOperationTimeout = 5 * time.Minute
+
+// withOperationTimeout derives a context that expires after timeout. A
+// non-positive timeout leaves ctx unbounded.
+func withOperationTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// operationError reports that op was aborted by its operation timeout when ctx
+// expired; other errors are returned unchanged. The result always matches
+// context.DeadlineExceeded with errors.Is in the timeout case.
+func operationError(ctx context.Context, op string, timeout time.Duration, err error) error {
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s exceeded operation timeout of %s: %w", op, timeout, err)
+	}
+	return fmt.Errorf("%s exceeded operation timeout of %s: %w: %v", op, timeout, context.DeadlineExceeded, err)
+}
+
+// sleepContext waits for d or until ctx is done
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// errPollTimeout is returned by pollUntil when the deadline passes before the
+// check reports completion
+var errPollTimeout = errors.New("polling deadline reached")
+
+// pollUntil calls check until it reports done or fails, waiting interval
+// between calls and doubling the wait up to maxInterval. It gives up with
+// errPollTimeout once deadline has passed; a zero deadline polls until ctx is
+// done.
+func pollUntil(ctx context.Context, interval, maxInterval time.Duration, deadline time.Time, check func() (done bool, err error)) error {
+	return pollUntilAfter(ctx, interval, maxInterval, deadline, func() (bool, time.Duration, error) {
+		done, err := check()
+		return done, 0, err
+	})
+}
+
+// pollUntilAfter is pollUntil for APIs that say when to check again: a
+// positive after returned by check replaces the next wait, which is still cut
+// short by the deadline. The backoff resumes for checks that give no hint.
+func pollUntilAfter(ctx context.Context, interval, maxInterval time.Duration, deadline time.Time, check func() (done bool, after time.Duration, err error)) error {
+	for {
+		done, after, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		wait := interval
+		if after > 0 {
+			wait = after
+		}
+		if !deadline.IsZero() {
+			remaining := time.Until(deadline)
+			if remaining <= 0 {
+				return errPollTimeout
+			}
+			wait = min(wait, remaining)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+
+		interval *= 2
+		if maxInterval > 0 && interval > maxInterval {
+			interval = maxInterval
+		}
+	}
+}
+
+// decodeJSONBody decodes a JSON response body into v. Responses that carry no
+// body (204 No Content, a zero Content-Length or an empty 200/202) leave v
+// untouched instead of failing with io.EOF.
+func decodeJSONBody(resp *http.Response, v interface{}) error {
+	if resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil && err != io.EOF {
+		return err
+	}
+
+	return nil
+}
+
+// clientOrDefault returns client, or http.DefaultClient for clients built
+// without a constructor
+func clientOrDefault(client *http.Client) *http.Client {
+	if client != nil {
+		return client
+	}
+	return http.DefaultClient
+}
+
+// fieldsParam builds the fields parameter of a Graph API request. Every
+// argument can itself be a comma separated list. Fields keep their order and a
+// field whose name was already given is dropped, so when required defaults
+// are passed first a caller's "from{id}" cannot replace "from{id,name}".
+func fieldsParam(fields ...string) string {
+	seen := make(map[string]bool)
+	var merged []string
+	for _, list := range fields {
+		for _, field := range splitFields(list) {
+			name := fieldName(field)
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			merged = append(merged, field)
+		}
+	}
+	return strings.Join(merged, ",")
+}
+
+// splitFields splits a comma separated fields list, leaving the commas of
+// nested selections such as from{id,name} alone
+func splitFields(list string) []string {
+	var fields []string
+	add := func(field string) {
+		if field = strings.TrimSpace(field); field != "" {
+			fields = append(fields, field)
+		}
+	}
+
+	depth, start := 0, 0
+	for i, r := range list {
+		switch r {
+		case '{', '(':
+			depth++
+		case '}', ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				add(list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	add(list[start:])
+	return fields
+}
+
+// fieldName returns the name of a field without its nested selection or
+// modifiers, e.g. "comments" for "comments.summary(true)"
+func fieldName(field string) string {
+	if i := strings.IndexAny(field, "{.("); i >= 0 {
+		return field[:i]
+	}
+	return field
+}
+
+// extraFields returns the members of the JSON object data that are not named
+// in known, a fields list, or nil if there are none. Graph API results use it
+// to keep fields requested by callers that their struct has no field for.
+func extraFields(data []byte, known string) (map[string]json.RawMessage, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+	for _, field := range splitFields(known) {
+		delete(members, fieldName(field))
+	}
+	if len(members) == 0 {
+		return nil, nil
+	}
+	return members, nil
+}
@@ -0,0 +1,527 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testRetryConfig retries quickly so tests do not wait on the backoff
+var testRetryConfig = RetryConfig{
+	MaxAttempts: 3,
+	BaseDelay:   time.Millisecond,
+	MaxDelay:    10 * time.Millisecond,
+}
+
+func TestDoWithRetryReplaysBodyAfterServerError(t *testing.T) {
+	var requests atomic.Int32
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != "payload" {
+			t.Errorf("attempt %d sent body %q, want payload", requests.Load()+1, body)
+		}
+		if requests.Add(1) < 3 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := http.NewRequest(http.MethodPut, srv.URL+"/upload", io.NopCloser(strings.NewReader("payload")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doWithRetry(client, req, testRetryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("status = %d, want 201", resp.StatusCode)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestDoWithRetryRetriesConnectionErrors(t *testing.T) {
+	var requests atomic.Int32
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				t.Fatal(err)
+			}
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doWithRetry(client, req, testRetryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+}
+
+func TestDoWithRetryStopsAfterMaxAttempts(t *testing.T) {
+	var requests atomic.Int32
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doWithRetry(client, req, testRetryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want the last 503", resp.StatusCode)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("requests = %d, want 3", n)
+	}
+}
+
+func TestDoWithRetryHonorsRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		want       int32
+	}{
+		{"within max delay", "0", 2},
+		{"beyond max delay", "120", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				if requests.Add(1) == 1 {
+					w.Header().Set("Retry-After", tt.retryAfter)
+					w.WriteHeader(http.StatusTooManyRequests)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/me", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := doWithRetry(client, req, testRetryConfig)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if n := requests.Load(); n != tt.want {
+				t.Errorf("requests = %d, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	httpDate := func(d time.Duration) string {
+		return time.Now().Add(d).UTC().Format(http.TimeFormat)
+	}
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+		slack  time.Duration // allowed shortfall, for HTTP dates with second resolution
+		ok     bool
+	}{
+		{"seconds", "120", 2 * time.Minute, 0, true},
+		{"zero", "0", 0, 0, true},
+		{"padded", " 7 ", 7 * time.Second, 0, true},
+		{"negative seconds", "-5", 0, 0, true},
+		{"seconds beyond the cap", "86400", maxRetryAfter, 0, true},
+		{"seconds overflowing a duration", "99999999999999", maxRetryAfter, 0, true},
+		{"HTTP date", httpDate(90 * time.Second), 90 * time.Second, 2 * time.Second, true},
+		{"HTTP date in the past", httpDate(-time.Hour), 0, 0, true},
+		{"HTTP date beyond the cap", httpDate(48 * time.Hour), maxRetryAfter, 0, true},
+		{"RFC 850 date", time.Now().Add(time.Minute).UTC().Format(time.RFC850), time.Minute, 2 * time.Second, true},
+		{"empty", "", 0, 0, false},
+		{"fractional seconds", "1.5", 0, 0, false},
+		{"words", "soon", 0, 0, false},
+		{"ISO date", time.Now().Add(time.Minute).Format(time.RFC3339), 0, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.header)
+			if ok != tt.ok {
+				t.Fatalf("parseRetryAfter(%q) ok = %v, want %v", tt.header, ok, tt.ok)
+			}
+			if got > tt.want || got < tt.want-tt.slack {
+				t.Errorf("parseRetryAfter(%q) = %v, want %v", tt.header, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoWithRetryStopsWhenContextIsDone(t *testing.T) {
+	var requests atomic.Int32
+	ctx, cancel := context.WithCancel(context.Background())
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		cancel()
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/me", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doWithRetry(client, req, testRetryConfig)
+	if err == nil {
+		resp.Body.Close()
+	}
+
+	if n := requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1", n)
+	}
+}
+
+func TestDecodeJSONBodyEmptyResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		flush  bool // flush the headers so the length is unknown
+	}{
+		{"204 no content", http.StatusNoContent, false},
+		{"200 with zero content length", http.StatusOK, false},
+		{"200 with unknown length", http.StatusOK, true},
+		{"202 with unknown length", http.StatusAccepted, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				if tt.flush {
+					w.(http.Flusher).Flush()
+				}
+			})
+
+			resp, err := client.Get(srv.URL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			v := map[string]string{"kept": "yes"}
+			if err := decodeJSONBody(resp, &v); err != nil {
+				t.Fatalf("decodeJSONBody() = %v, want nil", err)
+			}
+			if v["kept"] != "yes" || len(v) != 1 {
+				t.Errorf("v = %v, want it untouched", v)
+			}
+		})
+	}
+}
+
+func TestDecodeJSONBodyDecodesAndRejectsInvalidJSON(t *testing.T) {
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/bad" {
+			w.Write([]byte(`{"id":`))
+			return
+		}
+		w.Write([]byte(`{"id":"42"}`))
+	})
+
+	var v struct {
+		ID string `json:"id"`
+	}
+	resp, err := client.Get(srv.URL + "/good")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := decodeJSONBody(resp, &v); err != nil || v.ID != "42" {
+		t.Errorf("decodeJSONBody() = %v, id = %q", err, v.ID)
+	}
+
+	resp, err = client.Get(srv.URL + "/bad")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if err := decodeJSONBody(resp, &v); err == nil {
+		t.Error("expected an error for truncated JSON")
+	}
+}
+
+func TestDoWithRetryOnlyRetriesIdempotentRequests(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		opts   []RequestOption
+		retry  bool // RetryNonIdempotent
+		want   int32
+	}{
+		{"GET", http.MethodGet, nil, false, 3},
+		{"DELETE", http.MethodDelete, nil, false, 3},
+		{"POST", http.MethodPost, nil, false, 1},
+		{"PATCH", http.MethodPatch, nil, false, 1},
+		{"POST with idempotency key", http.MethodPost, []RequestOption{WithIdempotencyKey("k1")}, false, 3},
+		{"POST with an empty key", http.MethodPost, []RequestOption{WithIdempotencyKey("")}, false, 1},
+		{"POST when the platform deduplicates", http.MethodPost, nil, true, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+
+			req, err := newAPIRequest(context.Background(), tt.method, srv.URL+"/items", strings.NewReader("{}"), tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cfg := testRetryConfig
+			cfg.RetryNonIdempotent = tt.retry
+			resp, err := doWithRetry(client, req, cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if n := requests.Load(); n != tt.want {
+				t.Errorf("requests = %d, want %d", n, tt.want)
+			}
+		})
+	}
+}
+
+func TestDoWithRetryRetriesRateLimitedPost(t *testing.T) {
+	var requests atomic.Int32
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/items", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := doWithRetry(client, req, testRetryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated || requests.Load() != 2 {
+		t.Errorf("status = %d after %d requests, want 201 after 2", resp.StatusCode, requests.Load())
+	}
+}
+
+func TestDoWithRetryWaitsForBodyThrottling(t *testing.T) {
+	var requests atomic.Int32
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			io.WriteString(w, "throttled")
+			return
+		}
+		io.WriteString(w, "ok")
+	})
+
+	const wait = 20 * time.Millisecond
+	cfg := testRetryConfig
+	cfg.MaxDelay = time.Second
+	cfg.Throttled = func(resp *http.Response, body []byte) (time.Duration, bool) {
+		return wait, string(body) == "throttled"
+	}
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/publish", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	resp, err := doWithRetry(client, req, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if elapsed := time.Since(start); elapsed < wait {
+		t.Errorf("retried after %v, want at least the %v throttling wait", elapsed, wait)
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("requests = %d, want 2", n)
+	}
+	if body, _ := io.ReadAll(resp.Body); string(body) != "ok" {
+		t.Errorf("body = %q, want the inspected body to still be readable", body)
+	}
+}
+
+func TestPollUntilCompletes(t *testing.T) {
+	var calls []time.Time
+	err := pollUntil(context.Background(), 5*time.Millisecond, 10*time.Millisecond, time.Now().Add(5*time.Second), func() (bool, error) {
+		calls = append(calls, time.Now())
+		return len(calls) == 4, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 4 {
+		t.Fatalf("check called %d times, want 4", len(calls))
+	}
+
+	// The waits double from 5ms and are capped at 10ms
+	for i, want := range []time.Duration{5 * time.Millisecond, 10 * time.Millisecond, 10 * time.Millisecond} {
+		if gap := calls[i+1].Sub(calls[i]); gap < want {
+			t.Errorf("wait %d = %v, want at least %v", i+1, gap, want)
+		}
+	}
+}
+
+func TestPollUntilTimesOut(t *testing.T) {
+	var calls int
+	start := time.Now()
+	err := pollUntil(context.Background(), time.Millisecond, 5*time.Millisecond, time.Now().Add(30*time.Millisecond), func() (bool, error) {
+		calls++
+		return false, nil
+	})
+	if !errors.Is(err, errPollTimeout) {
+		t.Fatalf("error = %v, want errPollTimeout", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond || elapsed > time.Second {
+		t.Errorf("gave up after %v, want about 30ms", elapsed)
+	}
+	if calls < 2 {
+		t.Errorf("check called %d times, want it polled until the deadline", calls)
+	}
+}
+
+func TestPollUntilStopsOnCheckErrorAndContext(t *testing.T) {
+	failed := errors.New("processing failed")
+	var calls int
+	err := pollUntil(context.Background(), time.Millisecond, 0, time.Time{}, func() (bool, error) {
+		calls++
+		if calls == 2 {
+			return false, failed
+		}
+		return false, nil
+	})
+	if !errors.Is(err, failed) || calls != 2 {
+		t.Errorf("error = %v after %d calls, want the check error after 2", err, calls)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err = pollUntil(ctx, time.Millisecond, 2*time.Millisecond, time.Time{}, func() (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded without a deadline", err)
+	}
+}
+
+func TestPollUntilAfterUsesHints(t *testing.T) {
+	// a 1h interval would hang the test unless the hints replace it
+	var calls []time.Time
+	hints := []time.Duration{5 * time.Millisecond, 20 * time.Millisecond}
+	err := pollUntilAfter(context.Background(), time.Hour, time.Hour, time.Time{}, func() (bool, time.Duration, error) {
+		calls = append(calls, time.Now())
+		if len(calls) > len(hints) {
+			return true, 0, nil
+		}
+		return false, hints[len(calls)-1], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 3 {
+		t.Fatalf("check called %d times, want 3", len(calls))
+	}
+	for i, want := range hints {
+		if gap := calls[i+1].Sub(calls[i]); gap < want || gap > want+time.Second {
+			t.Errorf("wait %d = %v, want about %v", i+1, gap, want)
+		}
+	}
+
+	// the deadline still cuts a long hint short
+	start := time.Now()
+	err = pollUntilAfter(context.Background(), time.Millisecond, 0, time.Now().Add(20*time.Millisecond), func() (bool, time.Duration, error) {
+		return false, time.Hour, nil
+	})
+	if !errors.Is(err, errPollTimeout) || time.Since(start) > time.Second {
+		t.Errorf("error = %v after %v, want errPollTimeout after about 20ms", err, time.Since(start))
+	}
+}
+
+func TestSplitFields(t *testing.T) {
+	tests := []struct {
+		list string
+		want []string
+	}{
+		{"id,name", []string{"id", "name"}},
+		{" id , name ,", []string{"id", "name"}},
+		{"", nil},
+		{"id,from{id,name},message", []string{"id", "from{id,name}", "message"}},
+		{"comments.summary(true).limit(0),likes.summary(true)", []string{"comments.summary(true).limit(0)", "likes.summary(true)"}},
+		{"from{id,picture.type(large){url}},id", []string{"from{id,picture.type(large){url}}", "id"}},
+	}
+
+	for _, tt := range tests {
+		if got := splitFields(tt.list); fmt.Sprintf("%q", got) != fmt.Sprintf("%q", tt.want) {
+			t.Errorf("splitFields(%q) = %q, want %q", tt.list, got, tt.want)
+		}
+	}
+}
+
+func TestFieldsParamMergesAndDedups(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields []string
+		want   string
+	}{
+		{"defaults only", []string{"id,name"}, "id,name"},
+		{"caller fields are appended", []string{"id,name", "about", "fan_count"}, "id,name,about,fan_count"},
+		{"duplicates are dropped", []string{"id,name", "name,id,website", "website"}, "id,name,website"},
+		{"a default selection wins", []string{"id,from{id,name}", "from{id}"}, "id,from{id,name}"},
+		{"nested commas are kept", []string{"id", "from{id,name},message"}, "id,from{id,name},message"},
+		{"modifiers name the same field", []string{"id,comments.summary(true)", "comments.limit(5)", "likes.summary(true)"}, "id,comments.summary(true),likes.summary(true)"},
+		{"blank fields are skipped", []string{"id", "", " , "}, "id"},
+		{"no fields", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fieldsParam(tt.fields...); got != tt.want {
+				t.Errorf("fieldsParam(%q) = %q, want %q", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
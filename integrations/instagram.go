@@ -1,13 +1,20 @@
 package integrations
 
 import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -25,19 +32,64 @@ type InstagramClient struct {
 	AccessToken string
 	UserID      string
 	HTTPClient  *http.Client
+
+	// OperationTimeout bounds multi-step publishes such as PostReel as a
+	// whole; zero means no bound beyond the per-request timeout
+	OperationTimeout time.Duration
+
+	// Recorder, when set, receives the account metrics fetched by
+	// GetUserInsights
+	Recorder MetricsRecorder
+
+	// TrendThreshold is the relative change in average engagement per post
+	// between the older and newer half of the analysed posts above which
+	// GetUserEngagement reports a "Rising" or "Falling" trend; zero uses
+	// defaultTrendThreshold
+	TrendThreshold float64
+
+	// InsightsConcurrency bounds how many media insights GetUserEngagement
+	// and CompareEngagement fetch at once; zero uses
+	// defaultInsightsConcurrency
+	InsightsConcurrency int
+
+	// PageID and PageAccessToken identify the Facebook Page linked to the
+	// Instagram account; SendDM needs the page access token. An empty PageID
+	// sends as the page the token belongs to.
+	PageID          string
+	PageAccessToken string
+
+	// PollInterval, PollMaxInterval and ProcessingTimeout control how
+	// uploaded videos are polled until processed: the first wait is
+	// PollInterval, doubling up to PollMaxInterval, and polling gives up with
+	// ErrMediaProcessingTimeout after ProcessingTimeout. Zero values use
+	// instagramPollInterval, instagramPollMaxInterval and
+	// instagramProcessingTimeout.
+	PollInterval      time.Duration
+	PollMaxInterval   time.Duration
+	ProcessingTimeout time.Duration
+
+	tokenMu sync.RWMutex
+	refresh flightGroup
 }
 
 // TokenResponse represents the OAuth token response
 type TokenResponse struct {
-	AccessToken string `json:"access_token"`
-	UserID      int64  `json:"user_id"`
-	ExpiresIn   int    `json:"expires_in,omitempty"`
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type,omitempty"`
+	UserID       int64  `json:"user_id"`
+	ExpiresIn    int    `json:"expires_in,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
 }
 
 // MediaResponse represents the media creation response
 type MediaResponse struct {
 	ID        string `json:"id"`
 	StatusURL string `json:"status_url,omitempty"`
+
+	// Story is set by PostStory when the published media reports a
+	// media_product_type of STORY; stories expire 24 hours after publishing
+	Story bool `json:"story,omitempty"`
 }
 
 // MediaInsights represents engagement metrics for a post
@@ -70,7 +122,150 @@ func NewInstagramClient(appID, appSecret, redirectURI string) *InstagramClient {
 		AppSecret:   appSecret,
 		RedirectURI: redirectURI,
 		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
+
+		OperationTimeout: DefaultOperationTimeout,
+	}
+}
+
+// setAccessToken adds the access token to Graph API query parameters, along
+// with the appsecret_proof Meta requires when "Require App Secret" is enabled
+// for the app. The proof is only sent when AppSecret is set.
+func (c *InstagramClient) setAccessToken(params url.Values) {
+	token := c.currentToken()
+	params.Set("access_token", token)
+	if c.AppSecret != "" {
+		params.Set("appsecret_proof", appSecretProof(token, c.AppSecret))
+	}
+}
+
+// currentToken returns the access token under the token lock
+func (c *InstagramClient) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.AccessToken
+}
+
+// setToken replaces the access token under the token lock
+func (c *InstagramClient) setToken(token string) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.AccessToken = token
+}
+
+// appSecretProof returns the hex-encoded HMAC-SHA256 of an access token keyed
+// with the app secret
+func appSecretProof(accessToken, appSecret string) string {
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write([]byte(accessToken))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// metaSignaturePrefix precedes the hex digest in X-Hub-Signature-256
+const metaSignaturePrefix = "sha256="
+
+// VerifyMetaSignature reports whether headerValue, the X-Hub-Signature-256
+// header of a Facebook or Instagram webhook, is the HMAC-SHA256 of the raw
+// request body keyed with the app secret
+func VerifyMetaSignature(appSecret string, body []byte, headerValue string) bool {
+	if appSecret == "" || !strings.HasPrefix(headerValue, metaSignaturePrefix) {
+		return false
+	}
+
+	got, err := hex.DecodeString(strings.TrimPrefix(headerValue, metaSignaturePrefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(appSecret))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// graphRateLimitCodes are the Graph API error codes that signal throttling:
+// application (4), user (17), page (32) and custom (613) rate limits
+var graphRateLimitCodes = map[int]bool{4: true, 17: true, 32: true, 613: true}
+
+// graphRateLimitBackoff is the suggested wait after a throttling error when
+// the response carries no Retry-After header
+const graphRateLimitBackoff = time.Minute
+
+// graphRateLimitError returns a *RateLimitError when body is a Graph API
+// error object with a throttling code, and nil otherwise. The Graph API
+// reports throttling in the body, with a 200 or 4xx status.
+func graphRateLimitError(resp *http.Response, body []byte) error {
+	var errResp struct {
+		Error *Error `json:"error"`
+	}
+	if err := json.Unmarshal(body, &errResp); err != nil || errResp.Error == nil {
+		return nil
+	}
+	if !graphRateLimitCodes[errResp.Error.Code] {
+		return nil
+	}
+
+	backoff := graphRateLimitBackoff
+	if wait, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		backoff = wait
+	}
+
+	return &RateLimitError{
+		Platform:   "Instagram",
+		Code:       errResp.Error.Code,
+		Message:    errResp.Error.Message,
+		RetryAfter: backoff,
+	}
+}
+
+// graphThrottled is the RetryConfig.Throttled hook for Graph API responses,
+// waiting for the backoff suggested by graphRateLimitError
+func graphThrottled(resp *http.Response, body []byte) (time.Duration, bool) {
+	var rateErr *RateLimitError
+	if errors.As(graphRateLimitError(resp, body), &rateErr) {
+		return rateErr.RetryAfter, true
+	}
+	return 0, false
+}
+
+// graphRetryConfig retries Graph API requests like DefaultRetryConfig, and
+// also when throttling is reported in the body. MaxDelay allows waiting out
+// graphRateLimitBackoff.
+var graphRetryConfig = RetryConfig{
+	MaxAttempts: DefaultRetryConfig.MaxAttempts,
+	BaseDelay:   DefaultRetryConfig.BaseDelay,
+	MaxDelay:    graphRateLimitBackoff,
+	Throttled:   graphThrottled,
+}
+
+// instagramError builds the error for a failed Graph API call, translating
+// throttling into a *RateLimitError
+func instagramError(resp *http.Response, op string) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if err := graphRateLimitError(resp, bodyBytes); err != nil {
+		return err
+	}
+	return fmt.Errorf("failed to %s: %s, status: %d", op, string(bodyBytes), resp.StatusCode)
+}
+
+// decodeGraphBody is decodeJSONBody for Graph API responses, returning a
+// *RateLimitError when a successful response carries a throttling error
+func decodeGraphBody(resp *http.Response, v interface{}) error {
+	if resp.StatusCode == http.StatusNoContent || resp.ContentLength == 0 {
+		return nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return nil
+	}
+
+	if err := graphRateLimitError(resp, body); err != nil {
+		return err
 	}
+
+	return json.Unmarshal(body, v)
 }
 
 // GetAuthURL generates the OAuth URL to authorize the app
@@ -86,6 +281,11 @@ func (c *InstagramClient) GetAuthURL() string {
 
 // GetAccessToken exchanges the authorization code for an access token
 func (c *InstagramClient) GetAccessToken(code string) (*TokenResponse, error) {
+	return c.GetAccessTokenContext(context.Background(), code)
+}
+
+// GetAccessTokenContext is GetAccessToken with a context
+func (c *InstagramClient) GetAccessTokenContext(ctx context.Context, code string) (*TokenResponse, error) {
 	params := url.Values{}
 	params.Add("client_id", c.AppID)
 	params.Add("client_secret", c.AppSecret)
@@ -93,7 +293,7 @@ func (c *InstagramClient) GetAccessToken(code string) (*TokenResponse, error) {
 	params.Add("redirect_uri", c.RedirectURI)
 	params.Add("code", code)
 
-	req, err := http.NewRequest("POST", InstagramAPIURL, strings.NewReader(params.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", InstagramAPIURL, strings.NewReader(params.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -107,16 +307,15 @@ func (c *InstagramClient) GetAccessToken(code string) (*TokenResponse, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get access token: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, instagramError(resp, "get access token")
 	}
 
 	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	if err := decodeGraphBody(resp, &tokenResp); err != nil {
 		return nil, err
 	}
 
-	c.AccessToken = tokenResp.AccessToken
+	c.setToken(tokenResp.AccessToken)
 	c.UserID = fmt.Sprintf("%d", tokenResp.UserID)
 
 	return &tokenResp, nil
@@ -124,18 +323,23 @@ func (c *InstagramClient) GetAccessToken(code string) (*TokenResponse, error) {
 
 // GetLongLivedAccessToken exchanges short-lived token for a long-lived one
 func (c *InstagramClient) GetLongLivedAccessToken() (*TokenResponse, error) {
-	if c.AccessToken == "" {
+	return c.GetLongLivedAccessTokenContext(context.Background())
+}
+
+// GetLongLivedAccessTokenContext is GetLongLivedAccessToken with a context
+func (c *InstagramClient) GetLongLivedAccessTokenContext(ctx context.Context) (*TokenResponse, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("no access token available")
 	}
 
 	params := url.Values{}
 	params.Add("grant_type", "ig_exchange_token")
 	params.Add("client_secret", c.AppSecret)
-	params.Add("access_token", c.AccessToken)
+	c.setAccessToken(params)
 
 	url := fmt.Sprintf("%s/access_token?%s", BaseURL, params.Encode())
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -147,29 +351,46 @@ func (c *InstagramClient) GetLongLivedAccessToken() (*TokenResponse, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get long lived token: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, instagramError(resp, "get long lived token")
 	}
 
 	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	if err := decodeGraphBody(resp, &tokenResp); err != nil {
 		return nil, err
 	}
 
-	c.AccessToken = tokenResp.AccessToken
+	c.setToken(tokenResp.AccessToken)
 
 	return &tokenResp, nil
 }
 
-// RefreshAccessToken refreshes a long-lived access token
+// RefreshAccessToken refreshes a long-lived access token.
+// Concurrent calls are collapsed into a single refresh request.
 func (c *InstagramClient) RefreshAccessToken() (*TokenResponse, error) {
-	if c.AccessToken == "" {
+	stale := c.currentToken()
+	v, err := c.refresh.Do("token", func() (interface{}, error) {
+		// Another caller may have refreshed the token we saw while we were
+		// waiting; refreshing it again would only burn a request
+		if token := c.currentToken(); token != "" && token != stale {
+			return &TokenResponse{AccessToken: token}, nil
+		}
+		return c.refreshAccessToken()
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*TokenResponse), nil
+}
+
+// refreshAccessToken performs the token refresh request
+func (c *InstagramClient) refreshAccessToken() (*TokenResponse, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("no access token available")
 	}
 
 	params := url.Values{}
 	params.Add("grant_type", "ig_refresh_token")
-	params.Add("access_token", c.AccessToken)
+	c.setAccessToken(params)
 
 	url := fmt.Sprintf("%s/refresh_access_token?%s", BaseURL, params.Encode())
 
@@ -185,23 +406,27 @@ func (c *InstagramClient) RefreshAccessToken() (*TokenResponse, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to refresh token: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, instagramError(resp, "refresh token")
 	}
 
 	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	if err := decodeGraphBody(resp, &tokenResp); err != nil {
 		return nil, err
 	}
 
-	c.AccessToken = tokenResp.AccessToken
+	c.setToken(tokenResp.AccessToken)
 
 	return &tokenResp, nil
 }
 
 // PostImage uploads and publishes an image to Instagram
 func (c *InstagramClient) PostImage(imagePath, caption string) (*MediaResponse, error) {
-	if c.AccessToken == "" || c.UserID == "" {
+	return c.PostImageContext(context.Background(), imagePath, caption)
+}
+
+// PostImageContext is PostImage with a context
+func (c *InstagramClient) PostImageContext(ctx context.Context, imagePath, caption string) (*MediaResponse, error) {
+	if c.currentToken() == "" || c.UserID == "" {
 		return nil, errors.New("access token and user ID are required")
 	}
 
@@ -209,56 +434,54 @@ func (c *InstagramClient) PostImage(imagePath, caption string) (*MediaResponse,
 	params := url.Values{}
 	params.Add("image_url", imagePath) // You can also use a URL directly
 	params.Add("caption", caption)
-	params.Add("access_token", c.AccessToken)
+	c.setAccessToken(params)
 
 	uploadURL := fmt.Sprintf("%s/%s/media?%s", BaseURL, c.UserID, params.Encode())
 
-	req, err := http.NewRequest("POST", uploadURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := doWithRetry(c.HTTPClient, req, graphRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to create media container: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, instagramError(resp, "create media container")
 	}
 
 	var mediaResp MediaResponse
-	if err := json.NewDecoder(resp.Body).Decode(&mediaResp); err != nil {
+	if err := decodeGraphBody(resp, &mediaResp); err != nil {
 		return nil, err
 	}
 
 	// Step 2: Publish the container
 	publishParams := url.Values{}
 	publishParams.Add("creation_id", mediaResp.ID)
-	publishParams.Add("access_token", c.AccessToken)
+	c.setAccessToken(publishParams)
 
 	publishURL := fmt.Sprintf("%s/%s/media_publish?%s", BaseURL, c.UserID, publishParams.Encode())
 
-	pubReq, err := http.NewRequest("POST", publishURL, nil)
+	pubReq, err := http.NewRequestWithContext(ctx, "POST", publishURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	pubResp, err := c.HTTPClient.Do(pubReq)
+	pubResp, err := doWithRetry(c.HTTPClient, pubReq, graphRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer pubResp.Body.Close()
 
 	if pubResp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(pubResp.Body)
-		return nil, fmt.Errorf("failed to publish media: %s, status: %d", string(bodyBytes), pubResp.StatusCode)
+		return nil, instagramError(pubResp, "publish media")
 	}
 
 	var publishedMedia MediaResponse
-	if err := json.NewDecoder(pubResp.Body).Decode(&publishedMedia); err != nil {
+	if err := decodeGraphBody(pubResp, &publishedMedia); err != nil {
 		return nil, err
 	}
 
@@ -270,7 +493,29 @@ func (c *InstagramClient) PostReel(
 	videoPath, caption, coverImagePath string,
 	shareToFeed bool,
 ) (*MediaResponse, error) {
-	if c.AccessToken == "" || c.UserID == "" {
+	return c.PostReelContext(context.Background(), videoPath, caption, coverImagePath, shareToFeed)
+}
+
+// PostReelContext is PostReel with a context. The whole create, wait and
+// publish flow is aborted once OperationTimeout has elapsed.
+func (c *InstagramClient) PostReelContext(
+	ctx context.Context,
+	videoPath, caption, coverImagePath string,
+	shareToFeed bool,
+) (*MediaResponse, error) {
+	ctx, cancel := withOperationTimeout(ctx, c.OperationTimeout)
+	defer cancel()
+
+	media, err := c.postReel(ctx, videoPath, caption, coverImagePath, shareToFeed)
+	return media, operationError(ctx, "reel publish", c.OperationTimeout, err)
+}
+
+func (c *InstagramClient) postReel(
+	ctx context.Context,
+	videoPath, caption, coverImagePath string,
+	shareToFeed bool,
+) (*MediaResponse, error) {
+	if c.currentToken() == "" || c.UserID == "" {
 		return nil, errors.New("access token and user ID are required")
 	}
 
@@ -279,7 +524,7 @@ func (c *InstagramClient) PostReel(
 	params.Add("media_type", "REELS")
 	params.Add("video_url", videoPath) // You can use a URL directly
 	params.Add("caption", caption)
-	params.Add("access_token", c.AccessToken)
+	c.setAccessToken(params)
 
 	if coverImagePath != "" {
 		params.Add("thumb_url", coverImagePath)
@@ -291,30 +536,29 @@ func (c *InstagramClient) PostReel(
 
 	uploadURL := fmt.Sprintf("%s/%s/media?%s", BaseURL, c.UserID, params.Encode())
 
-	req, err := http.NewRequest("POST", uploadURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := doWithRetry(c.HTTPClient, req, graphRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to create reel container: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, instagramError(resp, "create reel container")
 	}
 
 	var mediaResp MediaResponse
-	if err := json.NewDecoder(resp.Body).Decode(&mediaResp); err != nil {
+	if err := decodeGraphBody(resp, &mediaResp); err != nil {
 		return nil, err
 	}
 
 	// Step 2: Check status until ready
 	if mediaResp.StatusURL != "" {
-		err = c.waitForMediaProcessing(mediaResp.StatusURL)
+		err = c.waitForMediaProcessing(ctx, mediaResp.StatusURL)
 		if err != nil {
 			return nil, err
 		}
@@ -323,76 +567,347 @@ func (c *InstagramClient) PostReel(
 	// Step 3: Publish the container
 	publishParams := url.Values{}
 	publishParams.Add("creation_id", mediaResp.ID)
-	publishParams.Add("access_token", c.AccessToken)
+	c.setAccessToken(publishParams)
 
 	publishURL := fmt.Sprintf("%s/%s/media_publish?%s", BaseURL, c.UserID, publishParams.Encode())
 
-	pubReq, err := http.NewRequest("POST", publishURL, nil)
+	pubReq, err := http.NewRequestWithContext(ctx, "POST", publishURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	pubResp, err := c.HTTPClient.Do(pubReq)
+	pubResp, err := doWithRetry(c.HTTPClient, pubReq, graphRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer pubResp.Body.Close()
 
 	if pubResp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(pubResp.Body)
-		return nil, fmt.Errorf("failed to publish reel: %s, status: %d", string(bodyBytes), pubResp.StatusCode)
+		return nil, instagramError(pubResp, "publish reel")
 	}
 
 	var publishedMedia MediaResponse
-	if err := json.NewDecoder(pubResp.Body).Decode(&publishedMedia); err != nil {
+	if err := decodeGraphBody(pubResp, &publishedMedia); err != nil {
 		return nil, err
 	}
 
 	return &publishedMedia, nil
 }
 
-// waitForMediaProcessing checks media status until ready
-func (c *InstagramClient) waitForMediaProcessing(statusURL string) error {
-	maxAttempts := 30
-	for i := 0; i < maxAttempts; i++ {
-		time.Sleep(2 * time.Second)
+// Default polling of Instagram media containers: every instagramPollInterval
+// at first, backing off to instagramPollMaxInterval, for at most
+// instagramProcessingTimeout
+const (
+	instagramPollInterval      = 2 * time.Second
+	instagramPollMaxInterval   = 10 * time.Second
+	instagramProcessingTimeout = time.Minute
+)
+
+// ErrMediaProcessingTimeout is returned when an uploaded video is still being
+// processed after the client's ProcessingTimeout. The error is a
+// *MediaProcessingTimeoutError carrying the last status seen; the container
+// may still finish, so callers can keep waiting or publish it later.
+var ErrMediaProcessingTimeout = errors.New("media processing timed out")
+
+// MediaProcessingTimeoutError reports a media container that did not finish
+// processing in time. It matches ErrMediaProcessingTimeout with errors.Is.
+type MediaProcessingTimeoutError struct {
+	StatusCode string        // last status_code, such as "IN_PROGRESS"
+	Status     string        // last status description, if any
+	Waited     time.Duration // time spent polling
+}
+
+func (e *MediaProcessingTimeoutError) Error() string {
+	return fmt.Sprintf("media processing timed out after %s, last status %s: %s", e.Waited.Round(time.Millisecond), e.StatusCode, e.Status)
+}
+
+// Unwrap makes the error match ErrMediaProcessingTimeout
+func (e *MediaProcessingTimeoutError) Unwrap() error {
+	return ErrMediaProcessingTimeout
+}
+
+// pollSettings returns the configured polling intervals and timeout, falling
+// back to the defaults for unset values
+func (c *InstagramClient) pollSettings() (interval, maxInterval, timeout time.Duration) {
+	interval, maxInterval, timeout = c.PollInterval, c.PollMaxInterval, c.ProcessingTimeout
+	if interval <= 0 {
+		interval = instagramPollInterval
+	}
+	if maxInterval <= 0 {
+		maxInterval = instagramPollMaxInterval
+	}
+	if maxInterval < interval {
+		maxInterval = interval
+	}
+	if timeout <= 0 {
+		timeout = instagramProcessingTimeout
+	}
+	return interval, maxInterval, timeout
+}
+
+// waitForMediaProcessing checks media status until ready or ctx is done
+func (c *InstagramClient) waitForMediaProcessing(ctx context.Context, statusURL string) error {
+	interval, maxInterval, timeout := c.pollSettings()
+	start := time.Now()
 
-		statusReq, err := http.NewRequest("GET", statusURL, nil)
+	type containerStatus struct {
+		StatusCode string `json:"status_code"`
+		Status     string `json:"status"`
+	}
+	var last containerStatus
+	err := pollUntil(ctx, interval, maxInterval, start.Add(timeout), func() (bool, error) {
+		statusReq, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		statusResp, err := c.HTTPClient.Do(statusReq)
 		if err != nil {
-			return err
+			return false, err
 		}
 
 		bodyBytes, _ := io.ReadAll(statusResp.Body)
 		statusResp.Body.Close()
 
-		var statusData map[string]interface{}
-		if err := json.Unmarshal(bodyBytes, &statusData); err != nil {
-			return err
+		if err := graphRateLimitError(statusResp, bodyBytes); err != nil {
+			return false, err
 		}
 
-		status, ok := statusData["status_code"].(string)
-		if !ok {
-			return errors.New("invalid status response")
+		var status containerStatus
+		if err := json.Unmarshal(bodyBytes, &status); err != nil {
+			return false, err
 		}
+		last = status
+
+		switch last.StatusCode {
+		case "":
+			return false, errors.New("invalid status response")
+		case "FINISHED":
+			return true, nil
+		case "ERROR":
+			return false, fmt.Errorf("media processing failed: %s", string(bodyBytes))
+		}
+		return false, nil
+	})
+	if errors.Is(err, errPollTimeout) {
+		return &MediaProcessingTimeoutError{
+			StatusCode: last.StatusCode,
+			Status:     last.Status,
+			Waited:     time.Since(start),
+		}
+	}
+	return err
+}
 
-		if status == "FINISHED" {
-			return nil
-		} else if status == "ERROR" {
-			return fmt.Errorf("media processing failed: %s", string(bodyBytes))
+// PostStory publishes an image or video story from a public URL. mediaType
+// must be "IMAGE" or "VIDEO".
+func (c *InstagramClient) PostStory(mediaURL string, mediaType string) (*MediaResponse, error) {
+	return c.PostStoryContext(context.Background(), mediaURL, mediaType)
+}
+
+// PostStoryContext is PostStory with a context. The whole create, wait and
+// publish flow is aborted once OperationTimeout has elapsed. If the story was
+// published but its product type could not be read, the media is returned
+// along with the error.
+func (c *InstagramClient) PostStoryContext(ctx context.Context, mediaURL string, mediaType string) (*MediaResponse, error) {
+	var paramName string
+	switch strings.ToUpper(mediaType) {
+	case "IMAGE":
+		paramName = "image_url"
+	case "VIDEO":
+		paramName = "video_url"
+	default:
+		return nil, fmt.Errorf("unsupported story media type %q, must be IMAGE or VIDEO", mediaType)
+	}
+
+	ctx, cancel := withOperationTimeout(ctx, c.OperationTimeout)
+	defer cancel()
+
+	media, err := c.postStory(ctx, mediaURL, paramName)
+	return media, operationError(ctx, "story publish", c.OperationTimeout, err)
+}
+
+func (c *InstagramClient) postStory(ctx context.Context, mediaURL, paramName string) (*MediaResponse, error) {
+	if c.currentToken() == "" || c.UserID == "" {
+		return nil, errors.New("access token and user ID are required")
+	}
+
+	// Step 1: Create the story container
+	params := url.Values{}
+	params.Add("media_type", "STORIES")
+	params.Add(paramName, mediaURL)
+	c.setAccessToken(params)
+
+	uploadURL := fmt.Sprintf("%s/%s/media?%s", BaseURL, c.UserID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doWithRetry(c.HTTPClient, req, graphRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, instagramError(resp, "create story container")
+	}
+
+	var mediaResp MediaResponse
+	if err := decodeGraphBody(resp, &mediaResp); err != nil {
+		return nil, err
+	}
+
+	// Step 2: Check status until ready
+	if mediaResp.StatusURL != "" {
+		err = c.waitForMediaProcessing(ctx, mediaResp.StatusURL)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	return errors.New("media processing timed out")
+	// Step 3: Publish the container
+	publishParams := url.Values{}
+	publishParams.Add("creation_id", mediaResp.ID)
+	c.setAccessToken(publishParams)
+
+	publishURL := fmt.Sprintf("%s/%s/media_publish?%s", BaseURL, c.UserID, publishParams.Encode())
+
+	pubReq, err := http.NewRequestWithContext(ctx, "POST", publishURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	pubResp, err := doWithRetry(c.HTTPClient, pubReq, graphRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer pubResp.Body.Close()
+
+	if pubResp.StatusCode != http.StatusOK {
+		return nil, instagramError(pubResp, "publish story")
+	}
+
+	var publishedMedia MediaResponse
+	if err := decodeGraphBody(pubResp, &publishedMedia); err != nil {
+		return nil, err
+	}
+
+	// The publish response only carries the ID; the product type tells
+	// whether the media went out as a story
+	productType, err := c.mediaProductType(ctx, publishedMedia.ID)
+	if err != nil {
+		return &publishedMedia, fmt.Errorf("story %s was published but its type could not be read: %w", publishedMedia.ID, err)
+	}
+	publishedMedia.Story = productType == "STORY"
+
+	return &publishedMedia, nil
+}
+
+// mediaProductType returns the media_product_type of a published media, such
+// as "FEED", "REELS" or "STORY"
+func (c *InstagramClient) mediaProductType(ctx context.Context, mediaID string) (string, error) {
+	params := url.Values{}
+	params.Set("fields", "media_product_type")
+	c.setAccessToken(params)
+
+	mediaURL := fmt.Sprintf("%s/%s?%s", BaseURL, mediaID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := doWithRetry(c.HTTPClient, req, graphRetryConfig)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", instagramError(resp, "get media product type")
+	}
+
+	var media struct {
+		MediaProductType string `json:"media_product_type"`
+	}
+	if err := decodeGraphBody(resp, &media); err != nil {
+		return "", err
+	}
+
+	return media.MediaProductType, nil
+}
+
+// SendDM sends a text message to an Instagram-scoped user ID through the
+// Messenger Platform and returns the message ID. Meta only allows messaging
+// users who have messaged the account within the last 24 hours.
+func (c *InstagramClient) SendDM(ctx context.Context, recipientID, text string) (string, error) {
+	if c.PageAccessToken == "" {
+		return "", fmt.Errorf("%w: sending direct messages requires a page access token", ErrNotConfigured)
+	}
+	if recipientID == "" {
+		return "", errors.New("recipient ID is required")
+	}
+
+	pageID := c.PageID
+	if pageID == "" {
+		pageID = "me"
+	}
+
+	params := url.Values{}
+	params.Set("access_token", c.PageAccessToken)
+	if c.AppSecret != "" {
+		params.Set("appsecret_proof", appSecretProof(c.PageAccessToken, c.AppSecret))
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"recipient": map[string]string{"id": recipientID},
+		"message":   map[string]string{"text": text},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	messagesURL := fmt.Sprintf("%s/%s/messages?%s", BaseURL, pageID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "POST", messagesURL, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := doWithRetry(c.HTTPClient, req, graphRetryConfig)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", instagramError(resp, "send message")
+	}
+
+	var result struct {
+		RecipientID string `json:"recipient_id"`
+		MessageID   string `json:"message_id"`
+	}
+	if err := decodeGraphBody(resp, &result); err != nil {
+		return "", err
+	}
+
+	return result.MessageID, nil
 }
 
 // PostCarousel uploads and publishes multiple images/videos as a carousel
 func (c *InstagramClient) PostCarousel(mediaPaths []string, caption string) (*MediaResponse, error) {
-	if c.AccessToken == "" || c.UserID == "" {
+	return c.PostCarouselContext(context.Background(), mediaPaths, caption)
+}
+
+// PostCarouselContext is PostCarousel with a context
+func (c *InstagramClient) PostCarouselContext(ctx context.Context, mediaPaths []string, caption string) (*MediaResponse, error) {
+	if c.currentToken() == "" || c.UserID == "" {
 		return nil, errors.New("access token and user ID are required")
 	}
 
@@ -412,32 +927,28 @@ func (c *InstagramClient) PostCarousel(mediaPaths []string, caption string) (*Me
 		params.Add("media_type", mediaType)
 		params.Add(paramName, mediaPath)
 		params.Add("is_carousel_item", "true")
-		params.Add("access_token", c.AccessToken)
+		c.setAccessToken(params)
 
 		uploadURL := fmt.Sprintf("%s/%s/media?%s", BaseURL, c.UserID, params.Encode())
 
-		req, err := http.NewRequest("POST", uploadURL, nil)
+		req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, nil)
 		if err != nil {
 			return nil, err
 		}
 
-		resp, err := c.HTTPClient.Do(req)
+		resp, err := doWithRetry(c.HTTPClient, req, graphRetryConfig)
 		if err != nil {
 			return nil, err
 		}
 
 		if resp.StatusCode != http.StatusOK {
-			bodyBytes, _ := io.ReadAll(resp.Body)
+			err := instagramError(resp, "create media container")
 			resp.Body.Close()
-			return nil, fmt.Errorf(
-				"failed to create media container: %s, status: %d",
-				string(bodyBytes),
-				resp.StatusCode,
-			)
+			return nil, err
 		}
 
 		var mediaResp MediaResponse
-		if err := json.NewDecoder(resp.Body).Decode(&mediaResp); err != nil {
+		if err := decodeGraphBody(resp, &mediaResp); err != nil {
 			resp.Body.Close()
 			return nil, err
 		}
@@ -445,7 +956,7 @@ func (c *InstagramClient) PostCarousel(mediaPaths []string, caption string) (*Me
 
 		// Wait for processing if needed
 		if mediaResp.StatusURL != "" {
-			err = c.waitForMediaProcessing(mediaResp.StatusURL)
+			err = c.waitForMediaProcessing(ctx, mediaResp.StatusURL)
 			if err != nil {
 				return nil, err
 			}
@@ -458,61 +969,55 @@ func (c *InstagramClient) PostCarousel(mediaPaths []string, caption string) (*Me
 	carouselParams := url.Values{}
 	carouselParams.Add("media_type", "CAROUSEL")
 	carouselParams.Add("caption", caption)
-	carouselParams.Add("access_token", c.AccessToken)
+	c.setAccessToken(carouselParams)
 	carouselParams.Add("children", strings.Join(childrenIDs, ","))
 
 	carouselURL := fmt.Sprintf("%s/%s/media?%s", BaseURL, c.UserID, carouselParams.Encode())
 
-	carReq, err := http.NewRequest("POST", carouselURL, nil)
+	carReq, err := http.NewRequestWithContext(ctx, "POST", carouselURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	carResp, err := c.HTTPClient.Do(carReq)
+	carResp, err := doWithRetry(c.HTTPClient, carReq, graphRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer carResp.Body.Close()
 
 	if carResp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(carResp.Body)
-		return nil, fmt.Errorf(
-			"failed to create carousel container: %s, status: %d",
-			string(bodyBytes),
-			carResp.StatusCode,
-		)
+		return nil, instagramError(carResp, "create carousel container")
 	}
 
 	var carouselResp MediaResponse
-	if err := json.NewDecoder(carResp.Body).Decode(&carouselResp); err != nil {
+	if err := decodeGraphBody(carResp, &carouselResp); err != nil {
 		return nil, err
 	}
 
 	// Step 3: Publish the carousel
 	publishParams := url.Values{}
 	publishParams.Add("creation_id", carouselResp.ID)
-	publishParams.Add("access_token", c.AccessToken)
+	c.setAccessToken(publishParams)
 
 	publishURL := fmt.Sprintf("%s/%s/media_publish?%s", BaseURL, c.UserID, publishParams.Encode())
 
-	pubReq, err := http.NewRequest("POST", publishURL, nil)
+	pubReq, err := http.NewRequestWithContext(ctx, "POST", publishURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	pubResp, err := c.HTTPClient.Do(pubReq)
+	pubResp, err := doWithRetry(c.HTTPClient, pubReq, graphRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer pubResp.Body.Close()
 
 	if pubResp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(pubResp.Body)
-		return nil, fmt.Errorf("failed to publish carousel: %s, status: %d", string(bodyBytes), pubResp.StatusCode)
+		return nil, instagramError(pubResp, "publish carousel")
 	}
 
 	var publishedMedia MediaResponse
-	if err := json.NewDecoder(pubResp.Body).Decode(&publishedMedia); err != nil {
+	if err := decodeGraphBody(pubResp, &publishedMedia); err != nil {
 		return nil, err
 	}
 
@@ -521,57 +1026,28 @@ func (c *InstagramClient) PostCarousel(mediaPaths []string, caption string) (*Me
 
 // GetMediaInsights retrieves insights for a specific media item
 func (c *InstagramClient) GetMediaInsights(mediaID string) (*MediaInsights, error) {
-	if c.AccessToken == "" {
-		return nil, errors.New("access token is required")
-	}
-
-	params := url.Values{}
-	params.Add("metric", "engagement,impressions,reach,saved,video_views,likes,comments,shares")
-	params.Add("access_token", c.AccessToken)
-
-	insightsURL := fmt.Sprintf("%s/%s/insights?%s", BaseURL, mediaID, params.Encode())
+	return c.GetMediaInsightsContext(context.Background(), mediaID)
+}
 
-	req, err := http.NewRequest("GET", insightsURL, nil)
-	if err != nil {
-		return nil, err
+// GetMediaInsightsContext is GetMediaInsights with a context
+func (c *InstagramClient) GetMediaInsightsContext(ctx context.Context, mediaID string) (*MediaInsights, error) {
+	if c.currentToken() == "" {
+		return nil, errors.New("access token is required")
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	metrics, err := c.getInsights(ctx, mediaID, instagramMediaMetrics, url.Values{}, "get media insights")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get media insights: %s, status: %d", string(bodyBytes), resp.StatusCode)
-	}
-
-	// Parse the complex Instagram Insights response
-	type InsightsData struct {
-		Data []struct {
-			Name   string `json:"name"`
-			Period string `json:"period"`
-			Values []struct {
-				Value int `json:"value"`
-			} `json:"values"`
-		} `json:"data"`
-	}
-
-	var insightsData InsightsData
-	if err := json.NewDecoder(resp.Body).Decode(&insightsData); err != nil {
-		return nil, err
-	}
 
 	// Map the insight values to our struct
 	insights := &MediaInsights{}
-	for _, metric := range insightsData.Data {
-		if len(metric.Values) == 0 {
+	for _, metric := range metrics {
+		value, ok := metric.value()
+		if !ok {
 			continue
 		}
 
-		value := metric.Values[0].Value
-
 		switch metric.Name {
 		case "engagement":
 			insights.Engagement = value
@@ -597,9 +1073,42 @@ func (c *InstagramClient) GetMediaInsights(mediaID string) (*MediaInsights, erro
 	return insights, nil
 }
 
+// GetStats implements StatsProvider with GetMediaInsights. Views are the
+// video views of videos and the impressions of other media; saves have no
+// PostStats field and only count towards Engagement.
+func (c *InstagramClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	insights, err := c.GetMediaInsightsContext(ctx, postID)
+	if err != nil {
+		return PostStats{}, err
+	}
+	return insights.PostStats(), nil
+}
+
+// PostStats converts the insights into the shared PostStats, see GetStats
+func (m *MediaInsights) PostStats() PostStats {
+	views := int64(m.Impressions)
+	if m.VideoViews > 0 {
+		views = int64(m.VideoViews)
+	}
+	interactions := int64(m.Likes + m.Comments + m.Shares + m.Saved)
+
+	return PostStats{
+		Views:      views,
+		Likes:      int64(m.Likes),
+		Comments:   int64(m.Comments),
+		Shares:     int64(m.Shares),
+		Engagement: engagementPercent(interactions, views),
+	}
+}
+
 // GetUserInsights retrieves insights for the user's profile
 func (c *InstagramClient) GetUserInsights(period string) (*UserInsights, error) {
-	if c.AccessToken == "" || c.UserID == "" {
+	return c.GetUserInsightsContext(context.Background(), period)
+}
+
+// GetUserInsightsContext is GetUserInsights with a context
+func (c *InstagramClient) GetUserInsightsContext(ctx context.Context, period string) (*UserInsights, error) {
+	if c.currentToken() == "" || c.UserID == "" {
 		return nil, errors.New("access token and user ID are required")
 	}
 
@@ -607,54 +1116,34 @@ func (c *InstagramClient) GetUserInsights(period string) (*UserInsights, error)
 		period = "day" // Other options: week, month
 	}
 
+	// follower_count is only available as a time series, while the other
+	// account metrics have to be requested as totals
 	params := url.Values{}
-	params.Add("metric", "follower_count,profile_views,reach,impressions,website_clicks")
-	params.Add("period", period)
-	params.Add("access_token", c.AccessToken)
-
-	insightsURL := fmt.Sprintf("%s/%s/insights?%s", BaseURL, c.UserID, params.Encode())
-
-	req, err := http.NewRequest("GET", insightsURL, nil)
+	params.Set("period", period)
+	series, err := c.getInsights(ctx, c.UserID, []string{"follower_count"}, params, "get user insights")
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := c.HTTPClient.Do(req)
+	params.Set("metric_type", "total_value")
+	totals, err := c.getInsights(ctx, c.UserID, instagramTotalValueMetrics, params, "get user insights")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get user insights: %s, status: %d", string(bodyBytes), resp.StatusCode)
-	}
+	// Map the insight values to our struct
+	insights := &UserInsights{}
+	now := time.Now()
+	for _, metric := range append(series, totals...) {
+		value, ok := metric.value()
+		if !ok {
+			continue
+		}
 
-	// Parse the complex Instagram Insights response
-	type InsightsData struct {
-		Data []struct {
-			Name   string `json:"name"`
-			Period string `json:"period"`
-			Values []struct {
-				Value int `json:"value"`
-			} `json:"values"`
-		} `json:"data"`
-	}
-
-	var insightsData InsightsData
-	if err := json.NewDecoder(resp.Body).Decode(&insightsData); err != nil {
-		return nil, err
-	}
-
-	// Map the insight values to our struct
-	insights := &UserInsights{}
-	for _, metric := range insightsData.Data {
-		if len(metric.Values) == 0 {
-			continue
+		if name, ok := instagramRecordedMetrics[metric.Name]; ok {
+			recordMetric(c.Recorder, PlatformInstagram, name, float64(value), now)
 		}
 
-		value := metric.Values[0].Value
-
 		switch metric.Name {
 		case "follower_count":
 			insights.Followers = value
@@ -670,7 +1159,7 @@ func (c *InstagramClient) GetUserInsights(period string) (*UserInsights, error)
 
 		// Calculate follower growth if we have data points
 		if metric.Name == "follower_count" && len(metric.Values) > 1 {
-			previousValue := metric.Values[1].Value
+			previousValue := metric.Values[len(metric.Values)-2].Value
 			insights.FollowersDelta = value - previousValue
 		}
 	}
@@ -678,25 +1167,79 @@ func (c *InstagramClient) GetUserInsights(period string) (*UserInsights, error)
 	return insights, nil
 }
 
-// GetUserEngagement retrieves overall engagement metrics
-func (c *InstagramClient) GetUserEngagement(days int) (map[string]interface{}, error) {
-	if c.AccessToken == "" || c.UserID == "" {
-		return nil, errors.New("access token and user ID are required")
+// instagramRecordedMetrics maps the account insights passed to Recorder to
+// their metric names
+var instagramRecordedMetrics = map[string]string{
+	"follower_count": MetricFollowers,
+	"profile_views":  MetricProfileViews,
+	"reach":          MetricReach,
+	"impressions":    MetricImpressions,
+	"website_clicks": MetricWebsiteClicks,
+}
+
+// instagramMediaMetrics are the insights requested for a media item
+var instagramMediaMetrics = []string{"engagement", "impressions", "reach", "saved", "video_views", "likes", "comments", "shares"}
+
+// instagramTotalValueMetrics are the account insights requested with
+// metric_type=total_value
+var instagramTotalValueMetrics = []string{"profile_views", "reach", "impressions", "website_clicks"}
+
+// instagramLatestMetrics are time series metrics whose latest value is the
+// current figure; the values of other time series are summed
+var instagramLatestMetrics = map[string]bool{
+	"follower_count": true,
+	"reach":          true, // unique accounts, so days do not add up
+}
+
+// insightMetric is one metric of an insights response. Metrics requested with
+// metric_type=total_value carry a total_value, the others a time series of
+// values ordered oldest first.
+type insightMetric struct {
+	Name       string         `json:"name"`
+	Period     string         `json:"period"`
+	Values     []insightValue `json:"values"`
+	TotalValue *insightValue  `json:"total_value"`
+}
+
+// insightValue is a single value of an insight metric
+type insightValue struct {
+	Value   int    `json:"value"`
+	EndTime string `json:"end_time,omitempty"`
+}
+
+// value returns the metric as a single number: its total value when present,
+// otherwise the latest or the sum of its time series depending on the metric.
+// ok is false when the response had no value for it.
+func (m insightMetric) value() (value int, ok bool) {
+	if m.TotalValue != nil {
+		return m.TotalValue.Value, true
+	}
+	if len(m.Values) == 0 {
+		return 0, false
 	}
 
-	if days <= 0 {
-		days = 30 // Default to 30 days
+	if instagramLatestMetrics[m.Name] {
+		return m.Values[len(m.Values)-1].Value, true
+	}
+	for _, v := range m.Values {
+		value += v.Value
 	}
+	return value, true
+}
 
-	// Get recent media first
-	params := url.Values{}
-	params.Add("fields", "id,media_type,timestamp")
-	params.Add("limit", fmt.Sprintf("%d", days))
-	params.Add("access_token", c.AccessToken)
+// getInsights requests metrics from the insights edge of objectID. params
+// holds extra query parameters such as period and metric_type.
+func (c *InstagramClient) getInsights(ctx context.Context, objectID string, metrics []string, params url.Values, op string) ([]insightMetric, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = values
+	}
+	query.Set("metric", strings.Join(metrics, ","))
+	c.setAccessToken(query)
 
-	mediaURL := fmt.Sprintf("%s/%s/media?%s", BaseURL, c.UserID, params.Encode())
+	insightsURL := fmt.Sprintf("%s/%s/insights?%s", BaseURL, objectID, query.Encode())
 
-	req, err := http.NewRequest("GET", mediaURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", insightsURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -708,143 +1251,615 @@ func (c *InstagramClient) GetUserEngagement(days int) (map[string]interface{}, e
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get media: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, instagramError(resp, op)
 	}
 
-	type MediaData struct {
-		Data []struct {
-			ID        string `json:"id"`
-			MediaType string `json:"media_type"`
-			Timestamp string `json:"timestamp"`
-		} `json:"data"`
-		Paging struct {
-			Cursors struct {
-				Before string `json:"before"`
-				After  string `json:"after"`
-			} `json:"cursors"`
-		} `json:"paging"`
+	var insightsData struct {
+		Data []insightMetric `json:"data"`
+	}
+	if err := decodeGraphBody(resp, &insightsData); err != nil {
+		return nil, err
+	}
+
+	return insightsData.Data, nil
+}
+
+// MediaItem is a media object as listed by the user's media or stories edge
+type MediaItem struct {
+	ID        string `json:"id"`
+	MediaType string `json:"media_type"`
+	Timestamp string `json:"timestamp"`
+	MediaURL  string `json:"media_url,omitempty"` // requested for stories, or through fields
+	Permalink string `json:"permalink,omitempty"` // requested for stories, or through fields
+
+	// Extra holds the fields requested through the fields of GetStories or
+	// GetMedia that MediaItem has no field for
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// UnmarshalJSON decodes a media item, keeping unknown fields in Extra
+func (m *MediaItem) UnmarshalJSON(data []byte) error {
+	type mediaItem MediaItem
+	if err := json.Unmarshal(data, (*mediaItem)(m)); err != nil {
+		return err
+	}
+
+	extra, err := extraFields(data, storyFields)
+	m.Extra = extra
+	return err
+}
+
+// mediaFields are the fields requested when listing the user's media
+const mediaFields = "id,media_type,timestamp"
+
+// storyFields are the fields requested for every story
+const storyFields = mediaFields + ",media_url,permalink"
+
+// GetStories lists the user's currently active stories, that is stories
+// published in the last 24 hours. It returns an empty slice when there are
+// none. fields are requested on top of the default ones, e.g. "caption" or
+// "thumbnail_url", and returned in MediaItem.Extra.
+func (c *InstagramClient) GetStories(fields ...string) ([]MediaItem, error) {
+	return c.GetStoriesContext(context.Background(), fields...)
+}
+
+// GetStoriesContext is GetStories with a context
+func (c *InstagramClient) GetStoriesContext(ctx context.Context, fields ...string) ([]MediaItem, error) {
+	if c.currentToken() == "" || c.UserID == "" {
+		return nil, errors.New("access token and user ID are required")
 	}
 
-	var mediaData MediaData
-	if err := json.NewDecoder(resp.Body).Decode(&mediaData); err != nil {
+	params := url.Values{}
+	params.Set("fields", fieldsParam(append([]string{storyFields}, fields...)...))
+
+	stories, err := c.listEdge(ctx, "stories", params, true)
+	if err != nil {
 		return nil, err
 	}
+	if stories == nil {
+		stories = []MediaItem{}
+	}
+	return stories, nil
+}
 
-	// Get insights for each media
-	totalEngagement := 0
-	totalImpressions := 0
-	totalReach := 0
-	totalLikes := 0
-	totalComments := 0
-	totalSaved := 0
-	mediaCount := len(mediaData.Data)
-
-	for _, media := range mediaData.Data {
-		insights, err := c.GetMediaInsights(media.ID)
-		if err != nil {
-			continue // Skip if we can't get insights for this media
-		}
+// engagementTotals sums the insights of a set of media
+type engagementTotals struct {
+	Posts       int
+	Engagement  int
+	Impressions int
+	Reach       int
+	Likes       int
+	Comments    int
+	Saved       int
+
+	// PerPost holds the engagement of every post whose insights were
+	// fetched, used for the day and trend analysis
+	PerPost []postEngagement
+}
+
+// postEngagement is the engagement of a single post along with when it was
+// published
+type postEngagement struct {
+	PublishedAt time.Time
+	Engagement  int
+}
+
+// average returns total divided by the number of posts, or 0 without posts
+func (t engagementTotals) average(total int) float64 {
+	if t.Posts == 0 {
+		return 0
+	}
+	return float64(total) / float64(t.Posts)
+}
+
+// engagementRate returns the average engagement per post as a percentage of followers
+func (t engagementTotals) engagementRate(followers int) float64 {
+	if followers <= 0 {
+		return 0
+	}
+	return t.average(t.Engagement) / float64(followers) * 100
+}
+
+// GetUserEngagement retrieves overall engagement metrics
+func (c *InstagramClient) GetUserEngagement(days int) (map[string]interface{}, error) {
+	return c.GetUserEngagementContext(context.Background(), days)
+}
 
-		totalEngagement += insights.Engagement
-		totalImpressions += insights.Impressions
-		totalReach += insights.Reach
-		totalLikes += insights.Likes
-		totalComments += insights.Comments
-		totalSaved += insights.Saved
+// GetUserEngagementContext is GetUserEngagement with a context
+func (c *InstagramClient) GetUserEngagementContext(ctx context.Context, days int) (map[string]interface{}, error) {
+	if c.currentToken() == "" || c.UserID == "" {
+		return nil, errors.New("access token and user ID are required")
 	}
 
+	if days <= 0 {
+		days = 30 // Default to 30 days
+	}
+
+	// Get recent media first
+	params := url.Values{}
+	params.Add("limit", fmt.Sprintf("%d", days))
+
+	media, err := c.listMedia(ctx, params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get insights for each media
+	totals := c.aggregateEngagement(ctx, media)
+
 	// Get user insights
-	userInsights, err := c.GetUserInsights("day")
+	userInsights, err := c.GetUserInsightsContext(ctx, "day")
 	if err != nil {
 		// Continue even if we can't get user insights
 		userInsights = &UserInsights{}
 	}
 
 	// Calculate averages and engagement rate
-	var avgEngagement, avgImpressions, avgReach, avgLikes, avgComments, engagementRate float64
-
-	if mediaCount > 0 {
-		avgEngagement = float64(totalEngagement) / float64(mediaCount)
-		avgImpressions = float64(totalImpressions) / float64(mediaCount)
-		avgReach = float64(totalReach) / float64(mediaCount)
-		avgLikes = float64(totalLikes) / float64(mediaCount)
-		avgComments = float64(totalComments) / float64(mediaCount)
-
-		if userInsights.Followers > 0 {
-			engagementRate = avgEngagement / float64(userInsights.Followers) * 100
-		}
-	}
+	avgEngagement := totals.average(totals.Engagement)
 
 	// Build comprehensive engagement report
 	engagement := map[string]interface{}{
 		"period_days":         days,
-		"posts_analyzed":      mediaCount,
+		"posts_analyzed":      totals.Posts,
 		"followers":           userInsights.Followers,
 		"followers_delta":     userInsights.FollowersDelta,
 		"profile_views":       userInsights.ProfileViews,
-		"total_engagement":    totalEngagement,
-		"total_impressions":   totalImpressions,
-		"total_reach":         totalReach,
-		"total_likes":         totalLikes,
-		"total_comments":      totalComments,
-		"total_saved":         totalSaved,
+		"total_engagement":    totals.Engagement,
+		"total_impressions":   totals.Impressions,
+		"total_reach":         totals.Reach,
+		"total_likes":         totals.Likes,
+		"total_comments":      totals.Comments,
+		"total_saved":         totals.Saved,
 		"avg_engagement":      avgEngagement,
-		"avg_impressions":     avgImpressions,
-		"avg_reach":           avgReach,
-		"avg_likes":           avgLikes,
-		"avg_comments":        avgComments,
-		"engagement_rate":     engagementRate,
+		"avg_impressions":     totals.average(totals.Impressions),
+		"avg_reach":           totals.average(totals.Reach),
+		"avg_likes":           totals.average(totals.Likes),
+		"avg_comments":        totals.average(totals.Comments),
+		"engagement_rate":     totals.engagementRate(userInsights.Followers),
 		"engagement_per_post": avgEngagement,
-		"most_engaging_day":   getMostEngagingDay(mediaData.Data),
-		"engagement_trend":    getEngagementTrend(mediaData.Data),
+		"most_engaging_day":   getMostEngagingDay(totals.PerPost),
+		"engagement_trend":    getEngagementTrend(totals.PerPost, c.trendThreshold()),
 	}
 
 	return engagement, nil
 }
 
-// Helper function to find most engaging day
-func getMostEngagingDay(mediaData []struct {
-	ID        string `json:"id"`
-	MediaType string `json:"media_type"`
-	Timestamp string `json:"timestamp"`
-}) string {
-	dayCount := make(map[string]int)
+// EngagementWindow summarises engagement over one time window
+type EngagementWindow struct {
+	Since          time.Time `json:"since"`
+	Until          time.Time `json:"until"`
+	Posts          int       `json:"posts"`
+	Engagement     int       `json:"engagement"`
+	Reach          int       `json:"reach"`
+	Impressions    int       `json:"impressions"`
+	FollowerGain   int       `json:"follower_gain"`
+	EngagementRate float64   `json:"engagement_rate"`
+}
 
-	for _, media := range mediaData {
-		t, err := time.Parse(time.RFC3339, media.Timestamp)
+// MetricChange is the period-over-period change of a single metric.
+// PercentChange is nil when the previous value is zero.
+type MetricChange struct {
+	Current       float64  `json:"current"`
+	Previous      float64  `json:"previous"`
+	Delta         float64  `json:"delta"`
+	PercentChange *float64 `json:"percent_change,omitempty"`
+}
+
+// EngagementComparison compares engagement between two consecutive windows
+type EngagementComparison struct {
+	Current        EngagementWindow `json:"current"`
+	Previous       EngagementWindow `json:"previous"`
+	EngagementRate MetricChange     `json:"engagement_rate"`
+	Followers      MetricChange     `json:"followers"`
+	Reach          MetricChange     `json:"reach"`
+}
+
+// CompareEngagement compares the last currentDays with the previousDays
+// before them. Engagement rates of both windows are computed against the
+// current follower count, since historical counts are not available.
+func (c *InstagramClient) CompareEngagement(currentDays, previousDays int) (*EngagementComparison, error) {
+	return c.CompareEngagementContext(context.Background(), currentDays, previousDays)
+}
+
+// CompareEngagementContext is CompareEngagement with a context
+func (c *InstagramClient) CompareEngagementContext(ctx context.Context, currentDays, previousDays int) (*EngagementComparison, error) {
+	if c.currentToken() == "" || c.UserID == "" {
+		return nil, errors.New("access token and user ID are required")
+	}
+
+	if currentDays <= 0 || previousDays <= 0 {
+		return nil, errors.New("window lengths must be positive")
+	}
+
+	followers, err := c.getFollowersCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	currentSince := now.AddDate(0, 0, -currentDays)
+	previousSince := currentSince.AddDate(0, 0, -previousDays)
+
+	current, err := c.engagementWindow(ctx, currentSince, now, followers)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := c.engagementWindow(ctx, previousSince, currentSince, followers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &EngagementComparison{
+		Current:        *current,
+		Previous:       *previous,
+		EngagementRate: newMetricChange(current.EngagementRate, previous.EngagementRate),
+		Followers:      newMetricChange(float64(current.FollowerGain), float64(previous.FollowerGain)),
+		Reach:          newMetricChange(float64(current.Reach), float64(previous.Reach)),
+	}, nil
+}
+
+// newMetricChange computes the change from previous to current
+func newMetricChange(current, previous float64) MetricChange {
+	change := MetricChange{
+		Current:  current,
+		Previous: previous,
+		Delta:    current - previous,
+	}
+	if previous != 0 {
+		percent := change.Delta / previous * 100
+		change.PercentChange = &percent
+	}
+	return change
+}
+
+// engagementWindow aggregates the media published in [since, until) and the
+// followers gained over the same period
+func (c *InstagramClient) engagementWindow(ctx context.Context, since, until time.Time, followers int) (*EngagementWindow, error) {
+	params := url.Values{}
+	params.Add("since", fmt.Sprintf("%d", since.Unix()))
+	params.Add("until", fmt.Sprintf("%d", until.Unix()))
+	params.Add("limit", "100")
+
+	media, err := c.listMedia(ctx, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	gain, err := c.getFollowerGain(ctx, since, until)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := c.aggregateEngagement(ctx, media)
+
+	return &EngagementWindow{
+		Since:          since,
+		Until:          until,
+		Posts:          totals.Posts,
+		Engagement:     totals.Engagement,
+		Reach:          totals.Reach,
+		Impressions:    totals.Impressions,
+		FollowerGain:   gain,
+		EngagementRate: totals.engagementRate(followers),
+	}, nil
+}
+
+// GetMedia lists the user's most recent media, newest first. limit caps the
+// number of items (Instagram's default of 25 when limit is 0). fields are
+// requested on top of the default ones, e.g. "caption" or "like_count", and
+// returned in MediaItem.Extra.
+func (c *InstagramClient) GetMedia(limit int, fields ...string) ([]MediaItem, error) {
+	return c.GetMediaContext(context.Background(), limit, fields...)
+}
+
+// GetMediaContext is GetMedia with a context
+func (c *InstagramClient) GetMediaContext(ctx context.Context, limit int, fields ...string) ([]MediaItem, error) {
+	if c.currentToken() == "" || c.UserID == "" {
+		return nil, errors.New("access token and user ID are required")
+	}
+
+	params := url.Values{}
+	if limit > 0 {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
+
+	media, err := c.listMedia(ctx, params, false, fields...)
+	if err != nil {
+		return nil, err
+	}
+	if media == nil {
+		media = []MediaItem{}
+	}
+	return media, nil
+}
+
+// listMedia lists the user's media with the given query parameters, following
+// pagination when allPages is set. fields are requested on top of mediaFields.
+func (c *InstagramClient) listMedia(ctx context.Context, params url.Values, allPages bool, fields ...string) ([]MediaItem, error) {
+	params.Set("fields", fieldsParam(append([]string{mediaFields}, fields...)...))
+	return c.listEdge(ctx, "media", params, allPages)
+}
+
+// listEdge lists the media on one of the user's edges (media, stories) with
+// the given query parameters, following pagination when allPages is set
+func (c *InstagramClient) listEdge(ctx context.Context, edge string, params url.Values, allPages bool) ([]MediaItem, error) {
+	c.setAccessToken(params)
+
+	mediaURL := fmt.Sprintf("%s/%s/%s?%s", BaseURL, c.UserID, edge, params.Encode())
+
+	var media []MediaItem
+	for mediaURL != "" {
+		req, err := http.NewRequestWithContext(ctx, "GET", mediaURL, nil)
 		if err != nil {
+			return nil, err
+		}
+
+		resp, err := c.HTTPClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			err := instagramError(resp, "get "+edge)
+			resp.Body.Close()
+			return nil, err
+		}
+
+		var mediaData struct {
+			Data   []MediaItem `json:"data"`
+			Paging struct {
+				Next string `json:"next"`
+			} `json:"paging"`
+		}
+
+		err = decodeGraphBody(resp, &mediaData)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		media = append(media, mediaData.Data...)
+
+		mediaURL = ""
+		if allPages {
+			mediaURL = mediaData.Paging.Next
+		}
+	}
+
+	return media, nil
+}
+
+// aggregateEngagement sums the insights of the given media, skipping media
+// whose insights cannot be fetched. Insights are fetched by up to
+// InsightsConcurrency requests at a time.
+func (c *InstagramClient) aggregateEngagement(ctx context.Context, media []MediaItem) engagementTotals {
+	totals := engagementTotals{Posts: len(media)}
+
+	// Results are kept in media order so the totals do not depend on which
+	// fetch finishes first
+	results := make([]*MediaInsights, len(media))
+	sem := make(chan struct{}, c.insightsConcurrency())
+	var wg sync.WaitGroup
+	for i, item := range media {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, mediaID string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			insights, err := c.GetMediaInsightsContext(ctx, mediaID)
+			if err != nil {
+				return // Skip if we can't get insights for this media
+			}
+			results[i] = insights
+		}(i, item.ID)
+	}
+	wg.Wait()
+
+	for i, item := range media {
+		insights := results[i]
+		if insights == nil {
 			continue
 		}
 
-		day := t.Weekday().String()
-		dayCount[day]++
+		totals.Engagement += insights.Engagement
+		totals.Impressions += insights.Impressions
+		totals.Reach += insights.Reach
+		totals.Likes += insights.Likes
+		totals.Comments += insights.Comments
+		totals.Saved += insights.Saved
+
+		if publishedAt, err := parseInstagramTime(item.Timestamp); err == nil {
+			totals.PerPost = append(totals.PerPost, postEngagement{PublishedAt: publishedAt, Engagement: insights.Engagement})
+		}
 	}
 
-	maxCount := 0
-	maxDay := "Unknown"
+	return totals
+}
+
+// defaultInsightsConcurrency is the InsightsConcurrency used when none is set
+const defaultInsightsConcurrency = 5
+
+// insightsConcurrency returns InsightsConcurrency or its default
+func (c *InstagramClient) insightsConcurrency() int {
+	if c.InsightsConcurrency > 0 {
+		return c.InsightsConcurrency
+	}
+	return defaultInsightsConcurrency
+}
+
+// getFollowersCount retrieves the account's current follower count
+func (c *InstagramClient) getFollowersCount(ctx context.Context) (int, error) {
+	params := url.Values{}
+	params.Add("fields", "followers_count")
+	c.setAccessToken(params)
+
+	userURL := fmt.Sprintf("%s/%s?%s", BaseURL, c.UserID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", userURL, nil)
+	if err != nil {
+		return 0, err
+	}
 
-	for day, count := range dayCount {
-		if count > maxCount {
-			maxCount = count
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, instagramError(resp, "get followers count")
+	}
+
+	var result struct {
+		FollowersCount int `json:"followers_count"`
+	}
+	if err := decodeGraphBody(resp, &result); err != nil {
+		return 0, err
+	}
+
+	return result.FollowersCount, nil
+}
+
+// getFollowerGain sums the daily follower_count insight, which reports new
+// followers per day, over [since, until)
+func (c *InstagramClient) getFollowerGain(ctx context.Context, since, until time.Time) (int, error) {
+	params := url.Values{}
+	params.Add("metric", "follower_count")
+	params.Add("period", "day")
+	params.Add("since", fmt.Sprintf("%d", since.Unix()))
+	params.Add("until", fmt.Sprintf("%d", until.Unix()))
+	c.setAccessToken(params)
+
+	insightsURL := fmt.Sprintf("%s/%s/insights?%s", BaseURL, c.UserID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", insightsURL, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, instagramError(resp, "get follower insights")
+	}
+
+	var insightsData struct {
+		Data []struct {
+			Name   string `json:"name"`
+			Values []struct {
+				Value int `json:"value"`
+			} `json:"values"`
+		} `json:"data"`
+	}
+	if err := decodeGraphBody(resp, &insightsData); err != nil {
+		return 0, err
+	}
+
+	gain := 0
+	for _, metric := range insightsData.Data {
+		if metric.Name != "follower_count" {
+			continue
+		}
+		for _, v := range metric.Values {
+			gain += v.Value
+		}
+	}
+
+	return gain, nil
+}
+
+// defaultTrendThreshold is the TrendThreshold used when none is set: a 10%
+// change in average engagement
+const defaultTrendThreshold = 0.1
+
+// trendThreshold returns TrendThreshold or its default
+func (c *InstagramClient) trendThreshold() float64 {
+	if c.TrendThreshold > 0 {
+		return c.TrendThreshold
+	}
+	return defaultTrendThreshold
+}
+
+// parseInstagramTime parses a media timestamp, which the Graph API formats
+// with a zone offset lacking a colon, e.g. "2024-01-02T15:04:05+0000"
+func parseInstagramTime(timestamp string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02T15:04:05-0700", timestamp)
+	if err != nil {
+		return time.Parse(time.RFC3339, timestamp)
+	}
+	return t, nil
+}
+
+// getMostEngagingDay returns the weekday whose posts gathered the most
+// engagement in total
+func getMostEngagingDay(posts []postEngagement) string {
+	if len(posts) == 0 {
+		return "Unknown"
+	}
+
+	var dayEngagement [7]int
+	for _, post := range posts {
+		dayEngagement[post.PublishedAt.Weekday()] += post.Engagement
+	}
+
+	maxDay := time.Sunday
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if dayEngagement[day] > dayEngagement[maxDay] {
 			maxDay = day
 		}
 	}
 
-	return maxDay
+	return maxDay.String()
 }
 
-// Helper function to calculate engagement trend
-func getEngagementTrend(mediaData []struct {
-	ID        string `json:"id"`
-	MediaType string `json:"media_type"`
-	Timestamp string `json:"timestamp"`
-}) string {
-	if len(mediaData) < 3 {
+// getEngagementTrend compares the average engagement per post of the newer
+// half of posts with the older half. The trend is "Rising" or "Falling" when
+// it changed by more than threshold, relative to the older half, and
+// "Stable" otherwise.
+func getEngagementTrend(posts []postEngagement, threshold float64) string {
+	if len(posts) < 3 {
 		return "Not enough data"
 	}
 
-	// This is a placeholder - in a real implementation
-	// you would analyze the trend of engagement over time
-	return "Stable" // Could be "Rising", "Falling", or "Stable"
+	sorted := append([]postEngagement(nil), posts...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].PublishedAt.Before(sorted[j].PublishedAt)
+	})
+
+	// With an odd number of posts the middle one goes to the older half
+	half := (len(sorted) + 1) / 2
+	older := averageEngagement(sorted[:half])
+	newer := averageEngagement(sorted[half:])
+
+	switch {
+	case older == 0 && newer == 0:
+		return "Stable"
+	case older == 0:
+		return "Rising"
+	}
+
+	change := (newer - older) / older
+	switch {
+	case change > threshold:
+		return "Rising"
+	case change < -threshold:
+		return "Falling"
+	default:
+		return "Stable"
+	}
+}
+
+// averageEngagement returns the mean engagement of posts
+func averageEngagement(posts []postEngagement) float64 {
+	total := 0
+	for _, post := range posts {
+		total += post.Engagement
+	}
+	return float64(total) / float64(len(posts))
 }
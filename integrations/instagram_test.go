@@ -0,0 +1,912 @@
+package integrations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestInstagramClient(t *testing.T, handler http.HandlerFunc) *InstagramClient {
+	t.Helper()
+
+	_, client := newTestServer(t, handler)
+	return &InstagramClient{AccessToken: "token", UserID: "ig1", HTTPClient: client}
+}
+
+func TestInstagramConcurrentRefreshRunsOnce(t *testing.T) {
+	var refreshes atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","token_type":"bearer","expires_in":5184000}`))
+	})
+
+	c := &InstagramClient{AccessToken: "old", HTTPClient: client}
+
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-start
+			tokenResp, err := c.RefreshAccessToken()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if tokenResp.AccessToken != "new" {
+				t.Errorf("AccessToken = %q, want new", tokenResp.AccessToken)
+			}
+		}()
+	}
+	close(start)
+	wg.Wait()
+
+	if n := refreshes.Load(); n != 1 {
+		t.Fatalf("refresh requests = %d, want 1", n)
+	}
+	if got := c.currentToken(); got != "new" {
+		t.Fatalf("token = %q, want new", got)
+	}
+}
+
+func TestInstagramCompareEngagement(t *testing.T) {
+	// isCurrent reports whether a request is for the current window, which
+	// ends now
+	isCurrent := func(r *http.Request) bool {
+		until, err := strconv.ParseInt(r.URL.Query().Get("until"), 10, 64)
+		if err != nil {
+			t.Fatalf("bad until %q", r.URL.Query().Get("until"))
+		}
+		return time.Since(time.Unix(until, 0)) < time.Minute
+	}
+
+	insights := map[string]string{
+		"m1": `{"data":[{"name":"engagement","values":[{"value":100}]},{"name":"reach","values":[{"value":500}]}]}`,
+		"m2": `{"data":[{"name":"engagement","values":[{"value":50}]},{"name":"reach","values":[{"value":300}]}]}`,
+		"m3": `{"data":[{"name":"engagement","values":[{"value":60}]},{"name":"reach","values":[{"value":400}]}]}`,
+	}
+
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v17.0/ig1":
+			fmt.Fprint(w, `{"followers_count":1000,"id":"ig1"}`)
+		case "/v17.0/ig1/media":
+			if isCurrent(r) {
+				fmt.Fprint(w, `{"data":[
+					{"id":"m1","media_type":"IMAGE","timestamp":"2026-10-10T10:00:00+0000"},
+					{"id":"m2","media_type":"VIDEO","timestamp":"2026-10-12T10:00:00+0000"}
+				]}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[{"id":"m3","media_type":"IMAGE","timestamp":"2026-10-01T10:00:00+0000"}]}`)
+		case "/v17.0/ig1/insights":
+			if isCurrent(r) {
+				fmt.Fprint(w, `{"data":[{"name":"follower_count","period":"day","values":[{"value":10},{"value":20}]}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[{"name":"follower_count","period":"day","values":[]}]}`)
+		case "/v17.0/m1/insights", "/v17.0/m2/insights", "/v17.0/m3/insights":
+			fmt.Fprint(w, insights[strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v17.0/"), "/insights")])
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	cmp, err := c.CompareEngagement(7, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cmp.Current.Posts != 2 || cmp.Current.Engagement != 150 || cmp.Current.Reach != 800 || cmp.Current.FollowerGain != 30 {
+		t.Errorf("current window = %+v", cmp.Current)
+	}
+	if cmp.Previous.Posts != 1 || cmp.Previous.Engagement != 60 || cmp.Previous.Reach != 400 || cmp.Previous.FollowerGain != 0 {
+		t.Errorf("previous window = %+v", cmp.Previous)
+	}
+	if !cmp.Previous.Until.Equal(cmp.Current.Since) {
+		t.Errorf("windows are not consecutive: previous ends %v, current starts %v", cmp.Previous.Until, cmp.Current.Since)
+	}
+
+	// 75 and 60 average engagement per post against 1000 followers
+	assertChange(t, "engagement rate", cmp.EngagementRate, 7.5, 6, 25)
+	assertChange(t, "reach", cmp.Reach, 800, 400, 100)
+
+	if cmp.Followers.Delta != 30 || cmp.Followers.PercentChange != nil {
+		t.Errorf("followers = %+v, want a delta of 30 and no percentage from zero", cmp.Followers)
+	}
+}
+
+func assertChange(t *testing.T, name string, got MetricChange, current, previous, percent float64) {
+	t.Helper()
+
+	const eps = 1e-9
+	if math.Abs(got.Current-current) > eps || math.Abs(got.Previous-previous) > eps || math.Abs(got.Delta-(current-previous)) > eps {
+		t.Errorf("%s = %+v, want %v -> %v", name, got, previous, current)
+	}
+	if got.PercentChange == nil || math.Abs(*got.PercentChange-percent) > eps {
+		t.Errorf("%s percent change = %v, want %v", name, got.PercentChange, percent)
+	}
+}
+
+func TestInstagramPostReelAbortsAtOperationTimeout(t *testing.T) {
+	var published atomic.Bool
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v17.0/ig1/media":
+			fmt.Fprint(w, `{"id":"container_1","status_url":"https://graph.facebook.com/v17.0/container_1?fields=status_code"}`)
+		case "/v17.0/container_1":
+			fmt.Fprint(w, `{"status_code":"IN_PROGRESS","status":"Processing"}`)
+		case "/v17.0/ig1/media_publish":
+			published.Store(true)
+			fmt.Fprint(w, `{"id":"media_1"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	c.OperationTimeout = 100 * time.Millisecond
+	c.PollInterval = 5 * time.Millisecond
+	c.PollMaxInterval = 10 * time.Millisecond
+	c.ProcessingTimeout = time.Hour
+
+	start := time.Now()
+	_, err := c.PostReel("https://cdn.example.com/clip.mp4", "caption", "", true)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if !strings.Contains(err.Error(), "operation timeout") {
+		t.Errorf("error %q does not mention the operation timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("PostReel returned after %v, want it to stop near the 100ms timeout", elapsed)
+	}
+	if published.Load() {
+		t.Error("the container was published after the operation timed out")
+	}
+}
+
+func TestInstagramSendsAppSecretProof(t *testing.T) {
+	mac := hmac.New(sha256.New, []byte("app-secret"))
+	mac.Write([]byte("token"))
+	wantProof := hex.EncodeToString(mac.Sum(nil))
+
+	for _, appSecret := range []string{"app-secret", ""} {
+		t.Run(fmt.Sprintf("secret %q", appSecret), func(t *testing.T) {
+			var requests int
+			c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				q := r.URL.Query()
+				if q.Get("access_token") != "token" {
+					t.Errorf("%s: access_token = %q", r.URL.Path, q.Get("access_token"))
+				}
+				proof, ok := q["appsecret_proof"]
+				switch {
+				case appSecret == "" && ok:
+					t.Errorf("%s: appsecret_proof sent without an app secret", r.URL.Path)
+				case appSecret != "" && (len(proof) != 1 || proof[0] != wantProof):
+					t.Errorf("%s: appsecret_proof = %v, want %s", r.URL.Path, proof, wantProof)
+				}
+
+				if r.URL.Path == "/v17.0/ig1/media" {
+					fmt.Fprint(w, `{"id":"container_1"}`)
+					return
+				}
+				fmt.Fprint(w, `{"id":"media_1"}`)
+			})
+			c.AppSecret = appSecret
+
+			if _, err := c.PostImage("https://cdn.example.com/photo.jpg", "caption"); err != nil {
+				t.Fatal(err)
+			}
+			if requests != 2 {
+				t.Errorf("requests = %d, want 2", requests)
+			}
+		})
+	}
+}
+
+func TestInstagramPostStory(t *testing.T) {
+	tests := []struct {
+		mediaType string
+		param     string
+		mediaURL  string
+		status    bool
+	}{
+		{"IMAGE", "image_url", "https://cdn.example.com/story.jpg", false},
+		{"video", "video_url", "https://cdn.example.com/story.mp4", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mediaType, func(t *testing.T) {
+			var polled bool
+			c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				switch r.URL.Path {
+				case "/v17.0/ig1/media":
+					if q.Get("media_type") != "STORIES" || q.Get(tt.param) != tt.mediaURL {
+						t.Errorf("container params = %v", q)
+					}
+					if tt.status {
+						fmt.Fprint(w, `{"id":"container_1","status_url":"https://graph.facebook.com/v17.0/container_1?fields=status_code"}`)
+						return
+					}
+					fmt.Fprint(w, `{"id":"container_1"}`)
+				case "/v17.0/container_1":
+					polled = true
+					fmt.Fprint(w, `{"status_code":"FINISHED"}`)
+				case "/v17.0/ig1/media_publish":
+					if q.Get("creation_id") != "container_1" {
+						t.Errorf("creation_id = %q", q.Get("creation_id"))
+					}
+					fmt.Fprint(w, `{"id":"story_1"}`)
+				case "/v17.0/story_1":
+					if q.Get("fields") != "media_product_type" {
+						t.Errorf("fields = %q", q.Get("fields"))
+					}
+					fmt.Fprint(w, `{"media_product_type":"STORY","id":"story_1"}`)
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+			})
+			c.PollInterval = time.Millisecond
+
+			media, err := c.PostStory(tt.mediaURL, tt.mediaType)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if media.ID != "story_1" || !media.Story {
+				t.Errorf("media = %+v, want story_1 flagged as a story", media)
+			}
+			if polled != tt.status {
+				t.Errorf("polled = %v, want %v", polled, tt.status)
+			}
+		})
+	}
+}
+
+func TestInstagramPostStoryFlagsFromProductType(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v17.0/ig1/media":
+			fmt.Fprint(w, `{"id":"container_1"}`)
+		case "/v17.0/ig1/media_publish":
+			fmt.Fprint(w, `{"id":"media_1"}`)
+		case "/v17.0/media_1":
+			fmt.Fprint(w, `{"media_product_type":"FEED","id":"media_1"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	media, err := c.PostStory("https://cdn.example.com/story.jpg", "IMAGE")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media.Story {
+		t.Error("Story = true for media published to the feed")
+	}
+}
+
+func TestInstagramPostStoryRejectsMediaType(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, mediaType := range []string{"CAROUSEL", "REELS", ""} {
+		if _, err := c.PostStory("https://cdn.example.com/story.jpg", mediaType); err == nil {
+			t.Errorf("PostStory(%q) succeeded, want an error", mediaType)
+		}
+	}
+}
+
+// graphThrottlingBody is the error the Graph API returns when the
+// application-level rate limit is reached
+const graphThrottlingBody = `{"error":{"message":"(#4) Application request limit reached","type":"OAuthException","is_transient":true,"code":4,"fbtrace_id":"AbCdEfGh"}}`
+
+func TestInstagramRetriesGraphThrottling(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusBadRequest} {
+		t.Run(strconv.Itoa(status), func(t *testing.T) {
+			var containers int
+			c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v17.0/ig1/media":
+					containers++
+					if containers == 1 {
+						w.Header().Set("Retry-After", "0")
+						w.WriteHeader(status)
+						fmt.Fprint(w, graphThrottlingBody)
+						return
+					}
+					fmt.Fprint(w, `{"id":"container_1"}`)
+				case "/v17.0/ig1/media_publish":
+					fmt.Fprint(w, `{"id":"media_1"}`)
+				default:
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+			})
+
+			media, err := c.PostImage("https://cdn.example.com/photo.jpg", "caption")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if media.ID != "media_1" {
+				t.Errorf("ID = %q", media.ID)
+			}
+			if containers != 2 {
+				t.Errorf("container requests = %d, want 2", containers)
+			}
+		})
+	}
+}
+
+func TestGraphThrottlingBeyondMaxDelayIsReturned(t *testing.T) {
+	var requests int
+	srv, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		fmt.Fprint(w, graphThrottlingBody)
+	})
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/v17.0/ig1/media", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := graphRetryConfig
+	cfg.MaxDelay = 10 * time.Millisecond
+	resp, err := doWithRetry(client, req, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1 when the suggested backoff exceeds MaxDelay", requests)
+	}
+
+	var media MediaResponse
+	err = decodeGraphBody(resp, &media)
+	var rateErr *RateLimitError
+	if !errors.As(err, &rateErr) {
+		t.Fatalf("error = %v, want a *RateLimitError", err)
+	}
+	if rateErr.Code != 4 || rateErr.RetryAfter != graphRateLimitBackoff || !errors.Is(err, ErrRateLimited) {
+		t.Errorf("rate limit error = %+v", rateErr)
+	}
+}
+
+func TestVerifyMetaSignature(t *testing.T) {
+	body := []byte(`{"object":"instagram","entry":[{"id":"17841400000000000","time":1700000000,"changes":[{"field":"comments","value":{"id":"c1","text":"nice"}}]}]}`)
+	mac := hmac.New(sha256.New, []byte("app-secret"))
+	mac.Write(body)
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	// RFC 4231 test case 2, as Meta would send it
+	if !VerifyMetaSignature("Jefe", []byte("what do ya want for nothing?"), "sha256=5bdcc146bf60754e6a042426089575c75a003f089d2739839dec58b964ec3843") {
+		t.Error("rejected the RFC 4231 test vector")
+	}
+
+	tampered := append([]byte(nil), body...)
+	tampered[len(tampered)-5] = 'X'
+
+	tests := []struct {
+		name      string
+		appSecret string
+		body      []byte
+		header    string
+		want      bool
+	}{
+		{"valid", "app-secret", body, valid, true},
+		{"uppercase hex", "app-secret", body, "sha256=" + strings.ToUpper(strings.TrimPrefix(valid, "sha256=")), true},
+		{"tampered body", "app-secret", tampered, valid, false},
+		{"wrong secret", "other-secret", body, valid, false},
+		{"empty secret", "", body, valid, false},
+		{"missing prefix", "app-secret", body, strings.TrimPrefix(valid, "sha256="), false},
+		{"sha1 header", "app-secret", body, "sha1=" + strings.TrimPrefix(valid, "sha256="), false},
+		{"not hex", "app-secret", body, "sha256=zz", false},
+		{"truncated", "app-secret", body, valid[:len(valid)-2], false},
+		{"empty header", "app-secret", body, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifyMetaSignature(tt.appSecret, tt.body, tt.header); got != tt.want {
+				t.Errorf("VerifyMetaSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstagramGetUserInsightsReadsBothShapes(t *testing.T) {
+	var requests int
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v17.0/ig1/insights" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("period") != "day" {
+			t.Errorf("period = %q, want day", q.Get("period"))
+		}
+
+		switch q.Get("metric") {
+		case "follower_count":
+			if _, ok := q["metric_type"]; ok {
+				t.Error("metric_type sent for the follower_count time series")
+			}
+			fmt.Fprint(w, `{"data":[{"name":"follower_count","period":"day","values":[
+				{"value":1200,"end_time":"2024-03-01T08:00:00+0000"},
+				{"value":1215,"end_time":"2024-03-02T08:00:00+0000"},
+				{"value":1240,"end_time":"2024-03-03T08:00:00+0000"}
+			],"title":"Follower Count","id":"ig1/insights/follower_count/day"}]}`)
+		case "profile_views,reach,impressions,website_clicks":
+			if q.Get("metric_type") != "total_value" {
+				t.Errorf("metric_type = %q, want total_value", q.Get("metric_type"))
+			}
+			fmt.Fprint(w, `{"data":[
+				{"name":"profile_views","period":"day","title":"Profile Views","total_value":{"value":87}},
+				{"name":"reach","period":"day","title":"Accounts reached","total_value":{"value":3400}},
+				{"name":"impressions","period":"day","title":"Impressions","total_value":{"value":5210}},
+				{"name":"website_clicks","period":"day","title":"Website Clicks","total_value":{"value":19}}
+			]}`)
+		default:
+			t.Errorf("unexpected metric %q", q.Get("metric"))
+		}
+	})
+
+	insights, err := c.GetUserInsights("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+
+	want := UserInsights{Followers: 1240, FollowersDelta: 25, ProfileViews: 87, Reach: 3400, Impressions: 5210, WebsiteClicks: 19}
+	if *insights != want {
+		t.Errorf("insights = %+v, want %+v", *insights, want)
+	}
+}
+
+func TestInstagramGetMediaInsightsReadsBothShapes(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v17.0/media_1/insights" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"data":[
+			{"name":"impressions","period":"day","values":[{"value":100},{"value":40},{"value":10}]},
+			{"name":"reach","period":"day","values":[{"value":90},{"value":120}]},
+			{"name":"likes","period":"lifetime","values":[{"value":33}]},
+			{"name":"comments","period":"lifetime","total_value":{"value":4}},
+			{"name":"shares","period":"lifetime","values":[]},
+			{"name":"saved","period":"lifetime","total_value":{"value":0}}
+		]}`)
+	})
+
+	insights, err := c.GetMediaInsights("media_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// impressions add up across days, reach counts unique accounts so the
+	// latest value is kept
+	want := MediaInsights{Impressions: 150, Reach: 120, Likes: 33, Comments: 4}
+	if *insights != want {
+		t.Errorf("insights = %+v, want %+v", *insights, want)
+	}
+}
+
+func TestInstagramGetUserEngagementTrend(t *testing.T) {
+	// Posts from Monday 4 to Thursday 7 March 2024, listed newest first like
+	// the media edge does
+	timestamps := map[string]string{
+		"m1": "2024-03-04T10:00:00+0000",
+		"m2": "2024-03-05T10:00:00+0000",
+		"m3": "2024-03-06T10:00:00+0000",
+		"m4": "2024-03-07T10:00:00+0000",
+	}
+
+	tests := []struct {
+		name       string
+		engagement map[string]int
+		threshold  float64
+		trend      string
+		day        string
+	}{
+		{"rising", map[string]int{"m1": 100, "m2": 100, "m3": 140, "m4": 160}, 0, "Rising", "Thursday"},
+		{"falling", map[string]int{"m1": 160, "m2": 140, "m3": 100, "m4": 100}, 0, "Falling", "Monday"},
+		{"stable", map[string]int{"m1": 100, "m2": 100, "m3": 104, "m4": 106}, 0, "Stable", "Thursday"},
+		{"rising past a lower threshold", map[string]int{"m1": 100, "m2": 100, "m3": 104, "m4": 106}, 0.01, "Rising", "Thursday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+				switch path := strings.TrimPrefix(r.URL.Path, "/v17.0/"); path {
+				case "ig1/media":
+					fmt.Fprintf(w, `{"data":[
+						{"id":"m4","timestamp":%q},
+						{"id":"m3","timestamp":%q},
+						{"id":"m2","timestamp":%q},
+						{"id":"m1","timestamp":%q}
+					]}`, timestamps["m4"], timestamps["m3"], timestamps["m2"], timestamps["m1"])
+				case "ig1/insights":
+					fmt.Fprint(w, `{"data":[]}`)
+				default:
+					id := strings.TrimSuffix(path, "/insights")
+					engagement, ok := tt.engagement[id]
+					if !ok {
+						t.Errorf("unexpected path %s", r.URL.Path)
+					}
+					fmt.Fprintf(w, `{"data":[{"name":"engagement","period":"lifetime","values":[{"value":%d}]}]}`, engagement)
+				}
+			})
+			c.TrendThreshold = tt.threshold
+
+			engagement, err := c.GetUserEngagement(30)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := engagement["engagement_trend"]; got != tt.trend {
+				t.Errorf("engagement_trend = %v, want %s", got, tt.trend)
+			}
+			if got := engagement["most_engaging_day"]; got != tt.day {
+				t.Errorf("most_engaging_day = %v, want %s", got, tt.day)
+			}
+		})
+	}
+}
+
+func TestGetMostEngagingDayWeighsEngagement(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 10, 0, 0, 0, time.UTC)
+	posts := []postEngagement{
+		{PublishedAt: monday, Engagement: 10},
+		{PublishedAt: monday.Add(time.Hour), Engagement: 10},
+		{PublishedAt: monday.Add(2 * time.Hour), Engagement: 10},
+		{PublishedAt: monday.AddDate(0, 0, 4), Engagement: 90},
+	}
+
+	// Monday has the most posts, Friday the most engagement
+	if got := getMostEngagingDay(posts); got != "Friday" {
+		t.Errorf("getMostEngagingDay() = %s, want Friday", got)
+	}
+	if got := getMostEngagingDay(nil); got != "Unknown" {
+		t.Errorf("getMostEngagingDay(nil) = %s, want Unknown", got)
+	}
+	if got := getEngagementTrend(posts[:2], defaultTrendThreshold); got != "Not enough data" {
+		t.Errorf("getEngagementTrend() with 2 posts = %s", got)
+	}
+}
+
+func TestInstagramAggregateEngagementConcurrently(t *testing.T) {
+	const latency = 30 * time.Millisecond
+
+	media := make([]MediaItem, 10)
+	for i := range media {
+		media[i] = MediaItem{ID: fmt.Sprintf("m%d", i), Timestamp: fmt.Sprintf("2024-03-%02dT10:00:00+0000", i+1)}
+	}
+
+	var inFlight, maxInFlight atomic.Int32
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		n := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			max := maxInFlight.Load()
+			if n <= max || maxInFlight.CompareAndSwap(max, n) {
+				break
+			}
+		}
+		time.Sleep(latency)
+
+		id := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v17.0/"), "/insights")
+		if id == "m7" {
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":{"message":"An unknown error occurred","code":1}}`)
+			return
+		}
+		i, _ := strconv.Atoi(strings.TrimPrefix(id, "m"))
+		fmt.Fprintf(w, `{"data":[
+			{"name":"engagement","values":[{"value":%d}]},
+			{"name":"impressions","values":[{"value":%d}]},
+			{"name":"reach","values":[{"value":%d}]},
+			{"name":"likes","values":[{"value":%d}]},
+			{"name":"comments","values":[{"value":%d}]},
+			{"name":"saved","values":[{"value":%d}]}
+		]}`, 10*i+5, 100*i, 80*i, 7*i, i, i%3)
+	})
+
+	aggregate := func(concurrency int) (engagementTotals, time.Duration) {
+		c.InsightsConcurrency = concurrency
+		maxInFlight.Store(0)
+		start := time.Now()
+		totals := c.aggregateEngagement(context.Background(), media)
+		return totals, time.Since(start)
+	}
+
+	serial, serialTime := aggregate(1)
+	if max := maxInFlight.Load(); max != 1 {
+		t.Errorf("%d requests in flight with a concurrency of 1", max)
+	}
+	concurrent, concurrentTime := aggregate(0)
+	if max := maxInFlight.Load(); max < 2 || max > defaultInsightsConcurrency {
+		t.Errorf("%d requests in flight, want between 2 and %d", max, defaultInsightsConcurrency)
+	}
+
+	if !reflect.DeepEqual(concurrent, serial) {
+		t.Errorf("concurrent totals = %+v, want the serial totals %+v", concurrent, serial)
+	}
+	if serial.Posts != 10 || len(serial.PerPost) != 9 || serial.Engagement != 9*5+10*(45-7) {
+		t.Errorf("totals = %+v, want 10 posts with m7 skipped", serial)
+	}
+	if concurrentTime > serialTime/2 {
+		t.Errorf("concurrent aggregation took %v, serial %v; want it at least twice as fast", concurrentTime, serialTime)
+	}
+}
+
+func TestInstagramSendDMPayload(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v17.0/page1/messages" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("access_token") != "page-token" || q.Get("appsecret_proof") != appSecretProof("page-token", "app-secret") {
+			t.Errorf("query = %v, want the page token and its proof", q)
+		}
+
+		var payload struct {
+			Recipient map[string]string `json:"recipient"`
+			Message   map[string]string `json:"message"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Recipient["id"] != "17841400000000001" || payload.Message["text"] != "Thanks for reaching out!" {
+			t.Errorf("payload = %+v", payload)
+		}
+
+		fmt.Fprint(w, `{"recipient_id":"17841400000000001","message_id":"aWdfZAG1faXRlbToxOklHTWVzc2FnZAUlEOjE3ODQxNDAw"}`)
+	})
+	c.PageAccessToken = "page-token"
+	c.PageID = "page1"
+	c.AppSecret = "app-secret"
+
+	id, err := c.SendDM(context.Background(), "17841400000000001", "Thanks for reaching out!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "aWdfZAG1faXRlbToxOklHTWVzc2FnZAUlEOjE3ODQxNDAw" {
+		t.Errorf("SendDM() = %q, want the message ID", id)
+	}
+
+	c.PageAccessToken = ""
+	if _, err := c.SendDM(context.Background(), "17841400000000001", "hi"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("SendDM() without a page token = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestInstagramWaitForMediaProcessing(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []string // status_code of each poll; the last one repeats
+		requests int      // minimum number of status checks
+		check    func(t *testing.T, err error)
+	}{
+		{
+			"finishes quickly", []string{"IN_PROGRESS", "IN_PROGRESS", "FINISHED"}, 3,
+			func(t *testing.T, err error) {
+				if err != nil {
+					t.Errorf("error = %v, want nil", err)
+				}
+			},
+		},
+		{
+			"error status", []string{"IN_PROGRESS", "ERROR"}, 2,
+			func(t *testing.T, err error) {
+				if err == nil || errors.Is(err, ErrMediaProcessingTimeout) || !strings.Contains(err.Error(), "media processing failed") {
+					t.Errorf("error = %v, want a processing failure", err)
+				}
+			},
+		},
+		{
+			"timeout", []string{"IN_PROGRESS"}, 2,
+			func(t *testing.T, err error) {
+				var timeoutErr *MediaProcessingTimeoutError
+				if !errors.As(err, &timeoutErr) || !errors.Is(err, ErrMediaProcessingTimeout) {
+					t.Fatalf("error = %v, want a *MediaProcessingTimeoutError", err)
+				}
+				if timeoutErr.StatusCode != "IN_PROGRESS" || timeoutErr.Status != "Still processing" || timeoutErr.Waited < 50*time.Millisecond {
+					t.Errorf("timeout error = %+v", timeoutErr)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v17.0/container_1" || r.URL.Query().Get("fields") != "status_code,status" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				status := tt.statuses[min(requests, len(tt.statuses)-1)]
+				requests++
+				fmt.Fprintf(w, `{"status_code":%q,"status":"Still processing","id":"container_1"}`, status)
+			})
+			c.PollInterval = time.Millisecond
+			c.PollMaxInterval = 5 * time.Millisecond
+			c.ProcessingTimeout = 50 * time.Millisecond
+
+			start := time.Now()
+			err := c.waitForMediaProcessing(context.Background(), "https://graph.facebook.com/v17.0/container_1?fields=status_code,status")
+			tt.check(t, err)
+			if requests < tt.requests {
+				t.Errorf("%d status checks, want at least %d", requests, tt.requests)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("waited %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestInstagramWaitForMediaProcessingStopsWithContext(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"status_code":"IN_PROGRESS"}`)
+	})
+	c.PollInterval = time.Millisecond
+	c.ProcessingTimeout = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.waitForMediaProcessing(ctx, "https://graph.facebook.com/v17.0/container_1?fields=status_code")
+	if !errors.Is(err, context.DeadlineExceeded) || errors.Is(err, ErrMediaProcessingTimeout) {
+		t.Errorf("error = %v, want the context error", err)
+	}
+}
+
+func TestInstagramGetStoriesDecodes(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v17.0/ig1/stories" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("fields"); got != "id,media_type,timestamp,media_url,permalink,caption,thumbnail_url" {
+			t.Errorf("fields = %q", got)
+		}
+		if q.Get("after") == "" {
+			fmt.Fprint(w, `{
+				"data": [{
+					"id": "17887498072083520",
+					"media_type": "IMAGE",
+					"timestamp": "2024-03-01T09:00:00+0000",
+					"media_url": "https://scontent.cdninstagram.com/story1.jpg",
+					"permalink": "https://www.instagram.com/stories/postly/17887498072083520",
+					"caption": "Morning"
+				}],
+				"paging": {
+					"cursors": {"before": "QVFI1", "after": "QVFI2"},
+					"next": "https://graph.facebook.com/v17.0/ig1/stories?fields=id,media_type,timestamp,media_url,permalink,caption,thumbnail_url&after=QVFI2"
+				}
+			}`)
+			return
+		}
+		fmt.Fprint(w, `{
+			"data": [{
+				"id": "17887498072083521",
+				"media_type": "VIDEO",
+				"timestamp": "2024-03-01T12:00:00+0000",
+				"media_url": "https://scontent.cdninstagram.com/story2.mp4",
+				"permalink": "https://www.instagram.com/stories/postly/17887498072083521",
+				"thumbnail_url": "https://scontent.cdninstagram.com/story2.jpg"
+			}],
+			"paging": {"cursors": {"before": "QVFI2", "after": "QVFI3"}}
+		}`)
+	})
+
+	stories, err := c.GetStories("caption", "thumbnail_url")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stories) != 2 {
+		t.Fatalf("got %d stories, want 2 across both pages", len(stories))
+	}
+
+	image := stories[0]
+	if image.ID != "17887498072083520" || image.MediaType != "IMAGE" || image.Timestamp != "2024-03-01T09:00:00+0000" ||
+		image.MediaURL != "https://scontent.cdninstagram.com/story1.jpg" || !strings.HasSuffix(image.Permalink, "/17887498072083520") {
+		t.Errorf("first story = %+v", image)
+	}
+	if string(image.Extra["caption"]) != `"Morning"` || len(image.Extra) != 1 {
+		t.Errorf("first story extra = %s", image.Extra)
+	}
+
+	video := stories[1]
+	if video.MediaType != "VIDEO" || string(video.Extra["thumbnail_url"]) != `"https://scontent.cdninstagram.com/story2.jpg"` {
+		t.Errorf("second story = %+v, extra %s", video, video.Extra)
+	}
+}
+
+func TestInstagramGetStoriesEmptyAndErrors(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+	stories, err := c.GetStories()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stories == nil || len(stories) != 0 {
+		t.Errorf("GetStories() = %#v, want an empty, non-nil slice", stories)
+	}
+
+	c = newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `{"error":{"message":"(#10) Application does not have permission for this action","type":"OAuthException","code":10}}`)
+	})
+	if stories, err := c.GetStories(); err == nil || stories != nil {
+		t.Errorf("GetStories() = %v, %v, want an error", stories, err)
+	}
+}
+
+func TestInstagramGetMediaFields(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v17.0/ig1/media" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("fields"); got != "id,media_type,timestamp,caption,permalink" {
+			t.Errorf("fields = %q", got)
+		}
+		if got := q.Get("limit"); got != "10" {
+			t.Errorf("limit = %q, want 10", got)
+		}
+		fmt.Fprint(w, `{"data":[{
+			"id": "17895695668004550",
+			"media_type": "CAROUSEL_ALBUM",
+			"timestamp": "2024-03-01T09:00:00+0000",
+			"caption": "Launch day",
+			"permalink": "https://www.instagram.com/p/C4abc"
+		}]}`)
+	})
+
+	media, err := c.GetMedia(10, "caption", "timestamp", "permalink")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(media) != 1 || media[0].MediaType != "CAROUSEL_ALBUM" || media[0].Permalink != "https://www.instagram.com/p/C4abc" {
+		t.Fatalf("GetMedia() = %+v", media)
+	}
+	if got := string(media[0].Extra["caption"]); got != `"Launch day"` {
+		t.Errorf("Extra = %v, want the caption", media[0].Extra)
+	}
+}
+
+func TestInstagramGetMediaDefaults(t *testing.T) {
+	c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("fields") != mediaFields || q.Has("limit") {
+			t.Errorf("unexpected query %s", r.URL.RawQuery)
+		}
+		fmt.Fprint(w, `{"data":[]}`)
+	})
+
+	media, err := c.GetMedia(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if media == nil || len(media) != 0 {
+		t.Errorf("GetMedia() = %#v, want an empty, non-nil slice", media)
+	}
+}
@@ -1 +1,31 @@
 package integrations
+
+// Platform names accepted by the cross-platform helpers
+const (
+	PlatformTwitter   = "twitter"
+	PlatformInstagram = "instagram"
+	PlatformFacebook  = "facebook"
+	PlatformLinkedIn  = "linkedin"
+	PlatformTikTok    = "tiktok"
+	PlatformYouTube   = "youtube"
+	PlatformPinterest = "pinterest"
+	PlatformThreads   = "threads"
+	PlatformReddit    = "reddit"
+	PlatformDribbble  = "dribbble"
+	PlatformWhatsApp  = "whatsapp"
+	PlatformTelegram  = "telegram"
+	PlatformSlack     = "slack"
+)
+
+// UniversalPost is post content that has not been shaped for a specific platform yet
+type UniversalPost struct {
+	Text  string      `json:"text"`
+	Media []PostMedia `json:"media,omitempty"`
+}
+
+// PostMedia is an image or video attached to a UniversalPost
+type PostMedia struct {
+	Path    string `json:"path"`
+	Type    string `json:"type"` // "image" or "video"
+	AltText string `json:"alt_text,omitempty"`
+}
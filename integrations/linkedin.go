@@ -2,14 +2,18 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"mime"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"postly.com/integrations/types"
@@ -26,16 +30,59 @@ const (
 	MediaUploadURL  = "https://api.linkedin.com/mediaUpload"
 )
 
-// LinkedInClient handles LinkedIn API operations
+// LinkedinVersionedBaseURL is the base of LinkedIn's versioned API, used when
+// UseVersionedAPI is set
+const LinkedinVersionedBaseURL = "https://api.linkedin.com/rest"
+
+// LinkedInClient handles LinkedIn API operations. The Context variants of its
+// methods accept RequestOptions, such as WithHeaders, that are applied to
+// every API call they make.
 type LinkedInClient struct {
 	ClientID     string
 	ClientSecret string
 	RedirectURI  string
 	AccessToken  string
+	RefreshToken string
+	TokenExpiry  time.Time // zero when the expiry is unknown
 	UserID       string
 	HTTPClient   *http.Client
+
+	// OrganizationID is the organization whose posts GetPostMetricsBatch
+	// reads; share statistics are only available for organization posts
+	OrganizationID string
+
+	// OperationTimeout bounds multi-step publishes such as PostWithImage as
+	// a whole; zero means no bound beyond the per-request timeout
+	OperationTimeout time.Duration
+
+	// UseVersionedAPI switches the endpoints that exist in LinkedIn's
+	// versioned API (organizations, share statistics and posts) to
+	// LinkedinVersionedBaseURL and sends the LinkedIn-Version header with
+	// them. UGC posts, asset uploads and the member profile stay on the
+	// legacy API.
+	UseVersionedAPI bool
+	// Version is the YYYYMM version sent as LinkedIn-Version; empty uses
+	// defaultLinkedInVersion
+	Version string
+
+	// Recorder, when set, receives the follower counts fetched by
+	// GetOrganizationFollowerCount
+	Recorder MetricsRecorder
+
+	tokenMu sync.RWMutex
+	refresh flightGroup
 }
 
+// linkedinRefreshWindow is how long before expiry the access token is refreshed
+const linkedinRefreshWindow = 60 * time.Second
+
+// restliProtocolVersion is the Rest.li protocol version sent with every API call
+const restliProtocolVersion = "2.0.0"
+
+// defaultLinkedInVersion is the versioned API release used when Version is
+// not set
+const defaultLinkedInVersion = "202401"
+
 // UserProfile represents a LinkedIn user profile
 
 // NewLinkedInClient creates a new LinkedIn API client
@@ -45,24 +92,173 @@ func NewLinkedInClient(clientID, clientSecret, redirectURI string) *LinkedInClie
 		ClientSecret: clientSecret,
 		RedirectURI:  redirectURI,
 		HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+
+		OperationTimeout: DefaultOperationTimeout,
+	}
+}
+
+// newRequest builds an authenticated LinkedIn API request. opts are applied
+// after the default headers so callers can override them. Token and binary
+// upload requests are built directly since they must not carry these headers.
+func (c *LinkedInClient) newRequest(ctx context.Context, method, url string, body io.Reader, opts ...RequestOption) (*http.Request, error) {
+	if err := c.ensureValidToken(); err != nil {
+		return nil, err
+	}
+
+	headers := map[string]string{
+		"Authorization":             fmt.Sprintf("Bearer %s", c.currentToken()),
+		"X-Restli-Protocol-Version": restliProtocolVersion,
+	}
+	if strings.HasPrefix(url, LinkedinVersionedBaseURL+"/") {
+		headers["LinkedIn-Version"] = c.apiVersion()
+	}
+	defaults := WithHeaders(headers)
+
+	return newAPIRequest(ctx, method, url, body, append([]RequestOption{defaults}, opts...)...)
+}
+
+// baseURL returns the base of the endpoints available in both APIs,
+// following UseVersionedAPI
+func (c *LinkedInClient) baseURL() string {
+	if c.UseVersionedAPI {
+		return LinkedinVersionedBaseURL
+	}
+	return LinkedinBaseURL
+}
+
+// apiVersion returns the LinkedIn-Version sent to the versioned API
+func (c *LinkedInClient) apiVersion() string {
+	if c.Version != "" {
+		return c.Version
+	}
+	return defaultLinkedInVersion
+}
+
+// LinkedIn service error codes for rejected access tokens
+const (
+	linkedinInvalidToken       = 65600
+	linkedinRevokedByUserToken = 65601
+	linkedinExpiredToken       = 65602
+	linkedinRevokedToken       = 65603
+	linkedinEmptyToken         = 65604
+)
+
+// linkedinError reads a failed response and builds its error. A rejected
+// access token is reported as an *APIError matching ErrTokenExpired or
+// ErrTokenInvalid; anything else keeps the body and status in the message.
+func linkedinError(resp *http.Response, op string) error {
+	bodyBytes, _ := io.ReadAll(resp.Body)
+
+	// REST errors carry serviceErrorCode and, on the versioned API, a
+	// symbolic code; the OAuth endpoints use error/error_description
+	var errBody struct {
+		ServiceErrorCode int    `json:"serviceErrorCode"`
+		Code             string `json:"code"`
+		Message          string `json:"message"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(bodyBytes, &errBody)
+
+	code, message := errBody.Code, errBody.Message
+	if code == "" && errBody.ServiceErrorCode != 0 {
+		code = strconv.Itoa(errBody.ServiceErrorCode)
+	}
+	if code == "" && errBody.Error != "" {
+		code, message = errBody.Error, errBody.ErrorDescription
+	}
+
+	var tokenErr error
+	switch {
+	case errBody.Code == "EXPIRED_ACCESS_TOKEN", errBody.ServiceErrorCode == linkedinExpiredToken:
+		tokenErr = ErrTokenExpired
+	case errBody.Code == "REVOKED_ACCESS_TOKEN", errBody.Code == "INVALID_ACCESS_TOKEN",
+		errBody.ServiceErrorCode == linkedinInvalidToken, errBody.ServiceErrorCode == linkedinRevokedByUserToken,
+		errBody.ServiceErrorCode == linkedinRevokedToken, errBody.ServiceErrorCode == linkedinEmptyToken,
+		errBody.Error == "invalid_grant":
+		tokenErr = ErrTokenInvalid
+	case resp.StatusCode == http.StatusUnauthorized:
+		// an unrecognised 401; the message is the only hint left
+		tokenErr = ErrTokenInvalid
+		if strings.Contains(strings.ToLower(message), "expired") {
+			tokenErr = ErrTokenExpired
+		}
+	}
+	if tokenErr == nil {
+		return fmt.Errorf("failed to %s: %s, status: %d", op, string(bodyBytes), resp.StatusCode)
+	}
+	if message == "" {
+		message = string(bodyBytes)
+	}
+
+	return &APIError{
+		Platform:   "LinkedIn",
+		StatusCode: resp.StatusCode,
+		Code:       code,
+		Message:    message,
+		Err:        tokenErr,
+	}
+}
+
+// linkedinScopes are the OAuth scopes LinkedIn grants
+var linkedinScopes = map[string]bool{
+	"openid":                 true,
+	"profile":                true,
+	"email":                  true,
+	"r_liteprofile":          true,
+	"r_basicprofile":         true,
+	"r_emailaddress":         true,
+	"w_member_social":        true,
+	"r_member_social":        true,
+	"r_organization_social":  true,
+	"w_organization_social":  true,
+	"rw_organization_admin":  true,
+	"r_organization_admin":   true,
+	"r_1st_connections_size": true,
+	"r_ads":                  true,
+	"rw_ads":                 true,
+	"r_ads_reporting":        true,
+}
+
+// GetAuthURL builds the authorization URL for scopes given as a JSON array of
+// strings
+func (c *LinkedInClient) GetAuthURL(scopes []byte) (string, error) {
+	var scopesStr []string
+	if err := json.Unmarshal(scopes, &scopesStr); err != nil {
+		return "", fmt.Errorf("invalid scopes, expected a JSON array of strings: %w", err)
 	}
+	return c.GetAuthURLWithScopes(scopesStr)
 }
 
-func (c *LinkedInClient) GetAuthURL(scopes []byte) string {
-	scopesStr := []string{}
-	_ = json.Unmarshal(scopes, &scopesStr)
+// GetAuthURLWithScopes builds the authorization URL for scopes. Every scope
+// must be one LinkedIn knows.
+func (c *LinkedInClient) GetAuthURLWithScopes(scopes []string) (string, error) {
+	if len(scopes) == 0 {
+		return "", errors.New("at least one scope is required")
+	}
+	for _, scope := range scopes {
+		if !linkedinScopes[scope] {
+			return "", fmt.Errorf("unknown LinkedIn scope %q", scope)
+		}
+	}
+
 	params := url.Values{}
 	params.Add("response_type", "code")
 	params.Add("client_id", c.ClientID)
 	params.Add("redirect_uri", c.RedirectURI)
-	params.Add("scope", strings.Join(scopesStr, " "))
+	params.Add("scope", strings.Join(scopes, " "))
 	// params.Add("state", generateRandomState())
 
-	return fmt.Sprintf("%s?%s", AuthURL, params.Encode())
+	return fmt.Sprintf("%s?%s", AuthURL, params.Encode()), nil
 }
 
 // GetAccessToken exchanges the authorization code for an access token
 func (c *LinkedInClient) GetAccessToken(code string) (*TokenResponse, error) {
+	return c.GetAccessTokenContext(context.Background(), code)
+}
+
+// GetAccessTokenContext is GetAccessToken with a context
+func (c *LinkedInClient) GetAccessTokenContext(ctx context.Context, code string) (*TokenResponse, error) {
 	params := url.Values{}
 	params.Add("grant_type", "authorization_code")
 	params.Add("code", code)
@@ -70,7 +266,7 @@ func (c *LinkedInClient) GetAccessToken(code string) (*TokenResponse, error) {
 	params.Add("client_id", c.ClientID)
 	params.Add("client_secret", c.ClientSecret)
 
-	req, err := http.NewRequest("POST", TokenURL, strings.NewReader(params.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", TokenURL, strings.NewReader(params.Encode()))
 	if err != nil {
 		return nil, err
 	}
@@ -84,22 +280,122 @@ func (c *LinkedInClient) GetAccessToken(code string) (*TokenResponse, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get access token: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "get access token")
 	}
 
 	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	if err := decodeJSONBody(resp, &tokenResp); err != nil {
 		return nil, err
 	}
 
-	c.AccessToken = tokenResp.AccessToken
+	c.storeToken(&tokenResp)
 
 	return &tokenResp, nil
 }
 
-// RefreshAccessToken refreshes an access token using refresh token
+// storeToken keeps the tokens and expiry of a token response on the client
+func (c *LinkedInClient) storeToken(tokenResp *TokenResponse) {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	c.AccessToken = tokenResp.AccessToken
+	if tokenResp.RefreshToken != "" {
+		c.RefreshToken = tokenResp.RefreshToken
+	}
+	if tokenResp.ExpiresIn > 0 {
+		c.TokenExpiry = time.Now().Add(time.Duration(tokenResp.ExpiresIn) * time.Second)
+	} else {
+		c.TokenExpiry = time.Time{}
+	}
+}
+
+// currentToken returns the access token under the token lock
+func (c *LinkedInClient) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.AccessToken
+}
+
+// currentRefreshToken returns the refresh token under the token lock
+func (c *LinkedInClient) currentRefreshToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.RefreshToken
+}
+
+// hasValidToken reports whether the access token is not due for a refresh.
+// Tokens with an unknown expiry are assumed to be valid.
+func (c *LinkedInClient) hasValidToken() bool {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.TokenExpiry.IsZero() || time.Until(c.TokenExpiry) > linkedinRefreshWindow
+}
+
+// tokenResponse returns the stored token as a TokenResponse, for callers
+// that joined a refresh somebody else already made
+func (c *LinkedInClient) tokenResponse() *TokenResponse {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+
+	tokenResp := &TokenResponse{
+		AccessToken:  c.AccessToken,
+		RefreshToken: c.RefreshToken,
+		TokenType:    "Bearer",
+	}
+	if !c.TokenExpiry.IsZero() {
+		tokenResp.ExpiresIn = int(time.Until(c.TokenExpiry).Seconds())
+	}
+	return tokenResp
+}
+
+// ensureValidToken refreshes the access token when it expires within
+// linkedinRefreshWindow. It returns ErrNoRefreshToken if a refresh is needed
+// but the client has no refresh token, in which case the user must re-authorize.
+func (c *LinkedInClient) ensureValidToken() error {
+	if c.hasValidToken() {
+		return nil
+	}
+
+	_, err := c.refresh.Do(linkedinRefreshKey, func() (interface{}, error) {
+		// Another caller may have refreshed while we were waiting
+		if c.hasValidToken() {
+			return c.tokenResponse(), nil
+		}
+
+		refreshToken := c.currentRefreshToken()
+		if refreshToken == "" {
+			return nil, ErrNoRefreshToken
+		}
+		return c.refreshAccessToken(refreshToken)
+	})
+	return err
+}
+
+// RefreshAccessToken refreshes an access token using refresh token.
+// Concurrent refreshes, including those started by ensureValidToken, share a
+// single request.
 func (c *LinkedInClient) RefreshAccessToken(refreshToken string) (*TokenResponse, error) {
+	v, err := c.refresh.Do(linkedinRefreshKey, func() (interface{}, error) {
+		// LinkedIn may rotate the refresh token; a caller still holding the
+		// old one gets the token of the refresh that rotated it
+		if refreshToken != c.currentRefreshToken() && c.currentToken() != "" && c.hasValidToken() {
+			return c.tokenResponse(), nil
+		}
+		return c.refreshAccessToken(refreshToken)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*TokenResponse), nil
+}
+
+// linkedinRefreshKey is the flightGroup key of token refreshes. It is fixed
+// rather than the refresh token so that callers holding a rotated refresh
+// token join the running refresh instead of starting one with a revoked token.
+const linkedinRefreshKey = "token"
+
+// refreshAccessToken performs the token refresh request
+func (c *LinkedInClient) refreshAccessToken(refreshToken string) (*TokenResponse, error) {
 	params := url.Values{}
 	params.Add("grant_type", "refresh_token")
 	params.Add("refresh_token", refreshToken)
@@ -120,23 +416,60 @@ func (c *LinkedInClient) RefreshAccessToken(refreshToken string) (*TokenResponse
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to refresh access token: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "refresh access token")
 	}
 
 	var tokenResp TokenResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tokenResp); err != nil {
+	if err := decodeJSONBody(resp, &tokenResp); err != nil {
 		return nil, err
 	}
 
-	c.AccessToken = tokenResp.AccessToken
+	c.storeToken(&tokenResp)
 
 	return &tokenResp, nil
 }
 
+// checkNoSchedule rejects post input that asks for a publish time, since
+// LinkedIn's UGC API publishes immediately
+func checkNoSchedule(inputmap map[string]interface{}) error {
+	if scheduleTime, _ := inputmap["schedule_time"].(string); scheduleTime != "" {
+		return ErrSchedulingUnsupported
+	}
+	return nil
+}
+
+// authorURN builds the URN of a post author. authorType defaults to
+// "person", and a person without an ID is the authenticated user.
+func (c *LinkedInClient) authorURN(authorType, authorID string) (string, error) {
+	if authorType == "" {
+		authorType = "person"
+	}
+
+	if authorID == "" && authorType == "person" {
+		if c.UserID == "" {
+			profileData, err := c.GetUserProfile()
+			if err != nil {
+				return "", fmt.Errorf("could not determine user ID: %v", err)
+			}
+			profile := types.LinkedInUserProfile{}
+			json.Unmarshal(profileData, &profile)
+			authorID = profile.ID
+		} else {
+			authorID = c.UserID
+		}
+	}
+
+	return fmt.Sprintf("urn:li:%s:%s", authorType, authorID), nil
+}
+
 // GetUserProfile retrieves the authenticated user's profile
 func (c *LinkedInClient) GetUserProfile() ([]byte, error) {
-	if c.AccessToken == "" {
+	return c.GetUserProfileContext(context.Background())
+}
+
+// GetUserProfileContext is GetUserProfile with a context
+func (c *LinkedInClient) GetUserProfileContext(ctx context.Context, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("access token is required")
 	}
 
@@ -144,15 +477,13 @@ func (c *LinkedInClient) GetUserProfile() ([]byte, error) {
 	params := url.Values{}
 	params.Add("projection", "(id,firstName,lastName,profilePicture,headline,email,industry)")
 
-	profileURL := fmt.Sprintf("%s/me?%s", BaseURL, params.Encode())
+	profileURL := fmt.Sprintf("%s/me?%s", LinkedinBaseURL, params.Encode())
 
-	req, err := http.NewRequest("GET", profileURL, nil)
+	req, err := c.newRequest(ctx, "GET", profileURL, nil, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
-
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		return nil, err
@@ -160,71 +491,196 @@ func (c *LinkedInClient) GetUserProfile() ([]byte, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get profile: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "get profile")
 	}
 
 	// LinkedIn returns a complex nested JSON structure
 	// This is simplified for readability
 	var rawProfile map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&rawProfile); err != nil {
+	if err := decodeJSONBody(resp, &rawProfile); err != nil {
 		return nil, err
 	}
 
 	// Extract the necessary fields from the complex structure
+	id, ok := rawProfile["id"].(string)
+	if !ok || id == "" {
+		if message, ok := rawProfile["message"].(string); ok {
+			return nil, fmt.Errorf("unexpected profile response: missing id: %s", message)
+		}
+		return nil, errors.New("unexpected profile response: missing id")
+	}
+
 	profile := &types.LinkedInUserProfile{
-		ID: rawProfile["id"].(string),
+		ID:        id,
+		FirstName: localizedField(rawProfile, "firstName"),
+		LastName:  localizedField(rawProfile, "lastName"),
+		Headline:  localizedField(rawProfile, "headline"),
 	}
 
 	// Set the user ID in the client
 	c.UserID = profile.ID
 
-	// Parse other fields from the complex structure
-	// In a real implementation, you would handle the nested localized fields properly
-	if firstName, ok := rawProfile["firstName"].(map[string]interface{}); ok {
-		if localized, ok := firstName["localized"].(map[string]interface{}); ok {
-			for _, v := range localized {
-				profile.FirstName = v.(string)
-				break
-			}
-		}
+	// The email address needs the r_emailaddress scope; without it the
+	// profile is returned without one
+	if email, err := c.getEmailAddress(ctx, opts...); err == nil {
+		profile.Email = email
 	}
 
-	if lastName, ok := rawProfile["lastName"].(map[string]interface{}); ok {
-		if localized, ok := lastName["localized"].(map[string]interface{}); ok {
-			for _, v := range localized {
-				profile.LastName = v.(string)
-				break
-			}
+	return json.Marshal(profile)
+}
+
+// localizedField returns a value of a LinkedIn localized field such as
+// {"localized": {"en_US": "..."}}, or "" if the field is absent or malformed
+func localizedField(raw map[string]interface{}, key string) string {
+	field, ok := raw[key].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	localized, ok := field["localized"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	for _, v := range localized {
+		if value, ok := v.(string); ok {
+			return value
 		}
 	}
+	return ""
+}
 
-	if headline, ok := rawProfile["headline"].(map[string]interface{}); ok {
-		if localized, ok := headline["localized"].(map[string]interface{}); ok {
-			for _, v := range localized {
-				profile.Headline = v.(string)
-				break
-			}
+// getEmailAddress retrieves the primary email address of the authenticated user
+func (c *LinkedInClient) getEmailAddress(ctx context.Context, opts ...RequestOption) (string, error) {
+	emailURL := fmt.Sprintf("%s/emailAddress?q=members&projection=(elements*(handle~))", LinkedinBaseURL)
+
+	req, err := c.newRequest(ctx, "GET", emailURL, nil, opts...)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", linkedinError(resp, "get email address")
+	}
+
+	var emailResp struct {
+		Elements []struct {
+			Handle struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"handle~"`
+		} `json:"elements"`
+	}
+	if err := decodeJSONBody(resp, &emailResp); err != nil {
+		return "", err
+	}
+
+	for _, element := range emailResp.Elements {
+		if element.Handle.EmailAddress != "" {
+			return element.Handle.EmailAddress, nil
 		}
 	}
 
-	return json.Marshal(profile)
+	return "", errors.New("unexpected email response: no email address")
 }
 
-// GetCompanyPages retrieves company pages administered by the user
+// GetCompanyPages retrieves all company pages administered by the user,
+// following the paging cursors until every page has been read
 func (c *LinkedInClient) GetCompanyPages() ([]byte, error) {
-	if c.AccessToken == "" {
+	return c.GetCompanyPagesContext(context.Background())
+}
+
+// GetCompanyPagesContext is GetCompanyPages with a context
+func (c *LinkedInClient) GetCompanyPagesContext(ctx context.Context, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("access token is required")
 	}
 
-	orgURL := fmt.Sprintf("%s/organizationAcls?q=roleAssignee&role=ADMINISTRATOR", BaseURL)
+	companyPages := []types.LinkedInCompanyPage{}
+	start := 0
+	for {
+		page, err := c.getCompanyPagesPage(ctx, start, linkedinPageSize, opts...)
+		if err != nil {
+			return nil, err
+		}
+		companyPages = append(companyPages, page.Pages...)
+
+		next, ok := nextLinkedInStart(start, page.Paging, page.elements)
+		if !ok {
+			break
+		}
+		start = next
+	}
+
+	return json.Marshal(companyPages)
+}
+
+// GetCompanyPagesPaged retrieves one page of the company pages administered by
+// the user, as a types.LinkedInCompanyPagesPage including LinkedIn's paging
+// fields
+func (c *LinkedInClient) GetCompanyPagesPaged(start, count int) ([]byte, error) {
+	return c.GetCompanyPagesPagedContext(context.Background(), start, count)
+}
+
+// GetCompanyPagesPagedContext is GetCompanyPagesPaged with a context
+func (c *LinkedInClient) GetCompanyPagesPagedContext(ctx context.Context, start, count int, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
+		return nil, errors.New("access token is required")
+	}
 
-	req, err := http.NewRequest("GET", orgURL, nil)
+	page, err := c.getCompanyPagesPage(ctx, start, count, opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	return json.Marshal(page.LinkedInCompanyPagesPage)
+}
+
+// linkedinPageSize is the number of elements requested per page
+const linkedinPageSize = 50
+
+// linkedinOrgDetailsTimeout bounds each organization detail fetch
+const linkedinOrgDetailsTimeout = 10 * time.Second
+
+// companyPagesPage is a page of company pages along with the number of ACL
+// elements it was built from, which may exceed len(Pages) when details fail
+type companyPagesPage struct {
+	types.LinkedInCompanyPagesPage
+	elements int
+}
+
+// nextLinkedInStart returns the start of the page following the one requested
+// at start, which held the given number of elements, or false when it was the
+// last page
+func nextLinkedInStart(start int, paging types.LinkedInPaging, elements int) (int, bool) {
+	if elements == 0 {
+		return 0, false
+	}
+
+	next := start + elements
+	if paging.Total > 0 {
+		return next, next < paging.Total
+	}
+
+	for _, link := range paging.Links {
+		if link.Rel == "next" {
+			return next, true
+		}
+	}
+	return 0, false
+}
+
+func (c *LinkedInClient) getCompanyPagesPage(ctx context.Context, start, count int, opts ...RequestOption) (*companyPagesPage, error) {
+	orgURL := fmt.Sprintf("%s/organizationAcls?q=roleAssignee&role=ADMINISTRATOR&start=%d&count=%d",
+		c.baseURL(), start, count)
+
+	req, err := c.newRequest(ctx, "GET", orgURL, nil, opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -233,8 +689,7 @@ func (c *LinkedInClient) GetCompanyPages() ([]byte, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get company pages: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "get company pages")
 	}
 
 	type OrganizationResponse struct {
@@ -242,105 +697,414 @@ func (c *LinkedInClient) GetCompanyPages() ([]byte, error) {
 			OrganizationTarget string `json:"organizationTarget"`
 			Role               string `json:"role"`
 		} `json:"elements"`
+		Paging types.LinkedInPaging `json:"paging"`
 	}
 
 	var orgResp OrganizationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&orgResp); err != nil {
+	if err := decodeJSONBody(resp, &orgResp); err != nil {
 		return nil, err
 	}
 
 	// Retrieve details for each company page
-	var companyPages []types.LinkedInCompanyPage
+	result := &companyPagesPage{elements: len(orgResp.Elements)}
+	result.Paging = orgResp.Paging
+	result.Pages = []types.LinkedInCompanyPage{}
 
 	for _, org := range orgResp.Elements {
-		orgID := org.OrganizationTarget
-
-		// Get organization details
-		orgDetailsURL := fmt.Sprintf("%s/organizations/%s", BaseURL, orgID)
-
-		detailsReq, err := http.NewRequest("GET", orgDetailsURL, nil)
+		page, err := c.getOrganizationDetails(ctx, org.OrganizationTarget, opts...)
 		if err != nil {
+			fmt.Printf("Skipping LinkedIn organization %s: %v\n", org.OrganizationTarget, err)
 			continue
 		}
+		result.Pages = append(result.Pages, page)
+	}
+
+	return result, nil
+}
+
+// getOrganizationDetails fetches the details of a single company page
+func (c *LinkedInClient) getOrganizationDetails(ctx context.Context, orgID string, opts ...RequestOption) (types.LinkedInCompanyPage, error) {
+	page := types.LinkedInCompanyPage{
+		ID: orgID,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, linkedinOrgDetailsTimeout)
+	defer cancel()
+
+	orgDetailsURL := fmt.Sprintf("%s/organizations/%s", c.baseURL(), orgID)
+
+	detailsReq, err := c.newRequest(ctx, "GET", orgDetailsURL, nil, opts...)
+	if err != nil {
+		return page, err
+	}
 
-		detailsReq.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
+	detailsResp, err := c.HTTPClient.Do(detailsReq)
+	if err != nil {
+		return page, err
+	}
+	defer detailsResp.Body.Close()
+
+	if detailsResp.StatusCode != http.StatusOK {
+		return page, linkedinError(detailsResp, "get organization")
+	}
+
+	var pageDetails map[string]interface{}
+	if err := decodeJSONBody(detailsResp, &pageDetails); err != nil {
+		return page, err
+	}
+
+	// Extract relevant fields from the response
+	if name, ok := pageDetails["name"].(string); ok {
+		page.Name = name
+	}
+
+	if description, ok := pageDetails["description"].(map[string]interface{}); ok {
+		if localized, ok := description["localized"].(map[string]interface{}); ok {
+			for _, v := range localized {
+				page.Description, _ = v.(string)
+				break
+			}
+		}
+	}
+
+	return page, nil
+}
+
+// GetOrganizationFollowerCount retrieves the number of members following an
+// organization. An empty orgID uses OrganizationID.
+func (c *LinkedInClient) GetOrganizationFollowerCount(orgID string) (int, error) {
+	return c.GetOrganizationFollowerCountContext(context.Background(), orgID)
+}
+
+// GetOrganizationFollowerCountContext is GetOrganizationFollowerCount with a context
+func (c *LinkedInClient) GetOrganizationFollowerCountContext(ctx context.Context, orgID string, opts ...RequestOption) (int, error) {
+	if c.currentToken() == "" {
+		return 0, errors.New("access token is required")
+	}
+	if orgID == "" {
+		orgID = c.OrganizationID
+	}
+	if orgID == "" {
+		return 0, errors.New("organization ID is required")
+	}
+
+	// The versioned API spells the edge type in upper snake case
+	edgeType := "CompanyFollowedByMember"
+	if c.UseVersionedAPI {
+		edgeType = "COMPANY_FOLLOWED_BY_MEMBER"
+	}
+	sizeURL := fmt.Sprintf("%s/networkSizes/%s?edgeType=%s",
+		c.baseURL(), url.QueryEscape("urn:li:organization:"+orgID), edgeType)
+
+	req, err := c.newRequest(ctx, "GET", sizeURL, nil, opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, linkedinError(resp, "get follower count")
+	}
+
+	var sizeResp struct {
+		FirstDegreeSize int `json:"firstDegreeSize"`
+	}
+	if err := decodeJSONBody(resp, &sizeResp); err != nil {
+		return 0, err
+	}
+
+	recordMetric(c.Recorder, PlatformLinkedIn, MetricFollowers, float64(sizeResp.FirstDegreeSize), time.Now())
+
+	return sizeResp.FirstDegreeSize, nil
+}
+
+// CreateTextPost creates a simple text post
+func (c *LinkedInClient) CreateTextPost(input []byte) ([]byte, error) {
+	return c.CreateTextPostContext(context.Background(), input)
+}
+
+// CreateTextPostContext is CreateTextPost with a context
+func (c *LinkedInClient) CreateTextPostContext(ctx context.Context, input []byte, opts ...RequestOption) ([]byte, error) {
+	var text, authorType, authorID string
+	inputmap := map[string]interface{}{}
+	json.Unmarshal(input, &inputmap)
+	text, _ = inputmap["text"].(string)
+	authorType, _ = inputmap["author_type"].(string)
+	authorID, _ = inputmap["author_id"].(string)
+	if c.currentToken() == "" {
+		return nil, errors.New("access token is required")
+	}
+
+	if err := checkNoSchedule(inputmap); err != nil {
+		return nil, err
+	}
+
+	author, err := c.authorURN(authorType, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	visibilityStr, _ := inputmap["visibility"].(string)
+	visibility, err := LinkedInVisibility(Visibility(visibilityStr))
+	if err != nil {
+		return nil, err
+	}
+
+	postJSON, contentType, err := textPostPayload(author, text, visibility)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, "POST", UGCPostURL, bytes.NewBuffer(postJSON), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", contentType)
+
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, linkedinError(resp, "create post")
+	}
+
+	var postResp map[string]interface{}
+	if err := decodeJSONBody(resp, &postResp); err != nil {
+		return nil, err
+	}
+
+	postID, ok := postResp["id"].(string)
+	if !ok {
+		return nil, errors.New("invalid post response, no ID found")
+	}
+
+	out := types.LinkedInPostResponse{
+		ID: postID,
+	}
+	return json.Marshal(out)
+
+}
+
+// CreateArticlePost shares an external link as an article. The input holds
+// "url" and, optionally, "title", "description" and "thumbnail_url" for the
+// preview card. When none of them are given LinkedIn scrapes the page for its
+// own preview; otherwise the supplied values are used as they are.
+func (c *LinkedInClient) CreateArticlePost(input []byte) ([]byte, error) {
+	return c.CreateArticlePostContext(context.Background(), input)
+}
+
+// CreateArticlePostContext is CreateArticlePost with a context
+func (c *LinkedInClient) CreateArticlePostContext(ctx context.Context, input []byte, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
+		return nil, errors.New("access token is required")
+	}
+
+	inputmap := map[string]interface{}{}
+	json.Unmarshal(input, &inputmap)
+	text, _ := inputmap["text"].(string)
+	articleURL, _ := inputmap["url"].(string)
+	title, _ := inputmap["title"].(string)
+	description, _ := inputmap["description"].(string)
+	thumbnailURL, _ := inputmap["thumbnail_url"].(string)
+	authorType, _ := inputmap["author_type"].(string)
+	authorID, _ := inputmap["author_id"].(string)
+	if articleURL == "" {
+		return nil, errors.New("article url is required")
+	}
+	if err := checkNoSchedule(inputmap); err != nil {
+		return nil, err
+	}
+
+	author, err := c.authorURN(authorType, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	visibilityStr, _ := inputmap["visibility"].(string)
+	visibility, err := LinkedInVisibility(Visibility(visibilityStr))
+	if err != nil {
+		return nil, err
+	}
+
+	// Without explicit values LinkedIn fills the preview in from the page
+	media := map[string]interface{}{
+		"status":      "READY",
+		"originalUrl": articleURL,
+	}
+	if title != "" {
+		media["title"] = map[string]interface{}{"text": title}
+	}
+	if description != "" {
+		media["description"] = map[string]interface{}{"text": description}
+	}
+	if thumbnailURL != "" {
+		media["thumbnails"] = []map[string]interface{}{{"url": thumbnailURL}}
+	}
+
+	postData := map[string]interface{}{
+		"author":         author,
+		"lifecycleState": "PUBLISHED",
+		"specificContent": map[string]interface{}{
+			"com.linkedin.ugc.ShareContent": map[string]interface{}{
+				"shareCommentary": map[string]interface{}{
+					"text": text,
+				},
+				"shareMediaCategory": "ARTICLE",
+				"media":              []map[string]interface{}{media},
+			},
+		},
+		"visibility": map[string]interface{}{
+			"com.linkedin.ugc.MemberNetworkVisibility": visibility,
+		},
+	}
+
+	postJSON, err := json.Marshal(postData)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, "POST", UGCPostURL, bytes.NewBuffer(postJSON), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return nil, linkedinError(resp, "create article post")
+	}
+
+	var postResp map[string]interface{}
+	if err := decodeJSONBody(resp, &postResp); err != nil {
+		return nil, err
+	}
+
+	postID, ok := postResp["id"].(string)
+	if !ok {
+		return nil, errors.New("invalid post response, no ID found")
+	}
+
+	out := types.LinkedInPostResponse{
+		ID: postID,
+	}
+	return json.Marshal(out)
+}
+
+// textPostPayload builds the UGC body of a text post and returns it with its
+// content type
+func textPostPayload(author, text, visibility string) ([]byte, string, error) {
+	postData := map[string]interface{}{
+		"author":         author,
+		"lifecycleState": "PUBLISHED",
+		"specificContent": map[string]interface{}{
+			"com.linkedin.ugc.ShareContent": map[string]interface{}{
+				"shareCommentary": map[string]interface{}{
+					"text": text,
+				},
+				"shareMediaCategory": "NONE",
+			},
+		},
+		"visibility": map[string]interface{}{
+			"com.linkedin.ugc.MemberNetworkVisibility": visibility,
+		},
+	}
+
+	postJSON, err := json.Marshal(postData)
+	if err != nil {
+		return nil, "", err
+	}
+	return postJSON, "application/json", nil
+}
+
+// BuildPayload returns the body and content type CreateTextPost would send
+// for post, without sending it. The commentary is the title and description
+// followed by the tags as hashtags, posted as the authenticated member; if
+// UserID is not set the profile is fetched to resolve it. Videos need an
+// uploaded asset first, so only text posts are supported.
+func (c *LinkedInClient) BuildPayload(post PostData) ([]byte, string, error) {
+	if post.VideoPath != "" || post.VideoReader != nil {
+		return nil, "", fmt.Errorf("%w: payloads can only be built for LinkedIn text posts", ErrUnsupported)
+	}
+	if post.ScheduleTime != nil {
+		return nil, "", ErrSchedulingUnsupported
+	}
+
+	visibility, err := LinkedInVisibility(post.Privacy)
+	if err != nil {
+		return nil, "", err
+	}
 
-		detailsResp, err := c.HTTPClient.Do(detailsReq)
-		if err != nil {
-			continue
-		}
+	author, err := c.authorURN("", "")
+	if err != nil {
+		return nil, "", err
+	}
 
-		var pageDetails map[string]interface{}
-		if err := json.NewDecoder(detailsResp.Body).Decode(&pageDetails); err != nil {
-			detailsResp.Body.Close()
-			continue
-		}
-		detailsResp.Body.Close()
+	return textPostPayload(author, postText(post), visibility)
+}
 
-		page := types.LinkedInCompanyPage{
-			ID: orgID,
-		}
+// resharePostURNPrefixes are the URN types that can be reshared
+var resharePostURNPrefixes = []string{"urn:li:share:", "urn:li:ugcPost:", "urn:li:activity:"}
 
-		// Extract relevant fields from the response
-		if name, ok := pageDetails["name"].(string); ok {
-			page.Name = name
+// validatePostURN checks that urn references a share, UGC post or activity
+func validatePostURN(urn string) error {
+	for _, prefix := range resharePostURNPrefixes {
+		id := strings.TrimPrefix(urn, prefix)
+		if id == urn || id == "" {
+			continue
 		}
-
-		if description, ok := pageDetails["description"].(map[string]interface{}); ok {
-			if localized, ok := description["localized"].(map[string]interface{}); ok {
-				for _, v := range localized {
-					page.Description = v.(string)
-					break
-				}
+		for _, r := range id {
+			if r < '0' || r > '9' {
+				return fmt.Errorf("invalid post URN: %q", urn)
 			}
 		}
-
-		companyPages = append(companyPages, page)
+		return nil
 	}
+	return fmt.Errorf("invalid post URN: %q", urn)
+}
 
-	return json.Marshal(companyPages)
+// ResharePost reshares an existing post as the authenticated user, with
+// optional commentary
+func (c *LinkedInClient) ResharePost(originalURN, commentary string) ([]byte, error) {
+	return c.ResharePostContext(context.Background(), originalURN, commentary)
 }
 
-// CreateTextPost creates a simple text post
-func (c *LinkedInClient) CreateTextPost(input []byte) ([]byte, error) {
-	var text, authorType, authorID string
-	inputmap := map[string]interface{}{}
-	json.Unmarshal(input, &inputmap)
-	text, _ = inputmap["text"].(string)
-	authorType, _ = inputmap["author_type"].(string)
-	authorID, _ = inputmap["author_id"].(string)
-	if c.AccessToken == "" {
+// ResharePostContext is ResharePost with a context
+func (c *LinkedInClient) ResharePostContext(ctx context.Context, originalURN, commentary string, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("access token is required")
 	}
 
-	if authorType == "" {
-		authorType = "person"
+	if err := validatePostURN(originalURN); err != nil {
+		return nil, err
 	}
 
-	if authorID == "" && authorType == "person" {
-		// If no author ID is provided and type is person, use the authenticated user
-		if c.UserID == "" {
-			// Try to get the user profile if we don't have the ID
-			inp, err := c.GetUserProfile()
-			var profile types.LinkedInUserProfile
-			_ = json.Unmarshal(inp, &profile)
-			if err != nil {
-				return nil, fmt.Errorf("could not determine user ID: %v", err)
-			}
-			authorID = profile.ID
-		} else {
-			authorID = c.UserID
-		}
+	author, err := c.authorURN("person", "")
+	if err != nil {
+		return nil, err
 	}
 
-	// Prepare the UGC post request
 	postData := map[string]interface{}{
-		"author":         fmt.Sprintf("urn:li:%s:%s", authorType, authorID),
+		"author":         author,
 		"lifecycleState": "PUBLISHED",
 		"specificContent": map[string]interface{}{
 			"com.linkedin.ugc.ShareContent": map[string]interface{}{
 				"shareCommentary": map[string]interface{}{
-					"text": text,
+					"text": commentary,
 				},
 				"shareMediaCategory": "NONE",
 			},
@@ -348,6 +1112,9 @@ func (c *LinkedInClient) CreateTextPost(input []byte) ([]byte, error) {
 		"visibility": map[string]interface{}{
 			"com.linkedin.ugc.MemberNetworkVisibility": "PUBLIC",
 		},
+		"reshareContext": map[string]interface{}{
+			"parent": originalURN,
+		},
 	}
 
 	postJSON, err := json.Marshal(postData)
@@ -355,28 +1122,25 @@ func (c *LinkedInClient) CreateTextPost(input []byte) ([]byte, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", UGCPostURL, bytes.NewBuffer(postJSON))
+	req, err := c.newRequest(ctx, "POST", UGCPostURL, bytes.NewBuffer(postJSON), opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("X-Restli-Protocol-Version", "2.0.0")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to create post: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "reshare post")
 	}
 
 	var postResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&postResp); err != nil {
+	if err := decodeJSONBody(resp, &postResp); err != nil {
 		return nil, err
 	}
 
@@ -389,12 +1153,16 @@ func (c *LinkedInClient) CreateTextPost(input []byte) ([]byte, error) {
 		ID: postID,
 	}
 	return json.Marshal(out)
-
 }
 
 // InitiateImageUpload prepares an image upload
 func (c *LinkedInClient) InitiateImageUpload(imageType string) (string, map[string]interface{}, error) {
-	if c.AccessToken == "" {
+	return c.InitiateImageUploadContext(context.Background(), imageType)
+}
+
+// InitiateImageUploadContext is InitiateImageUpload with a context
+func (c *LinkedInClient) InitiateImageUploadContext(ctx context.Context, imageType string, opts ...RequestOption) (string, map[string]interface{}, error) {
+	if c.currentToken() == "" {
 		return "", nil, errors.New("access token is required")
 	}
 
@@ -419,12 +1187,11 @@ func (c *LinkedInClient) InitiateImageUpload(imageType string) (string, map[stri
 		return "", nil, err
 	}
 
-	req, err := http.NewRequest("POST", AssetUploadURL, bytes.NewBuffer(assetJSON))
+	req, err := c.newRequest(ctx, "POST", AssetUploadURL, bytes.NewBuffer(assetJSON), opts...)
 	if err != nil {
 		return "", nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Add("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
@@ -434,16 +1201,11 @@ func (c *LinkedInClient) InitiateImageUpload(imageType string) (string, map[stri
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", nil, fmt.Errorf(
-			"failed to initiate image upload: %s, status: %d",
-			string(bodyBytes),
-			resp.StatusCode,
-		)
+		return "", nil, linkedinError(resp, "initiate image upload")
 	}
 
 	var uploadResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+	if err := decodeJSONBody(resp, &uploadResp); err != nil {
 		return "", nil, err
 	}
 
@@ -474,12 +1236,17 @@ func (c *LinkedInClient) InitiateImageUpload(imageType string) (string, map[stri
 
 // UploadImage uploads an image to LinkedIn
 func (c *LinkedInClient) UploadImage(imagePath string) (string, error) {
-	if c.AccessToken == "" {
+	return c.UploadImageContext(context.Background(), imagePath)
+}
+
+// UploadImageContext is UploadImage with a context
+func (c *LinkedInClient) UploadImageContext(ctx context.Context, imagePath string, opts ...RequestOption) (string, error) {
+	if c.currentToken() == "" {
 		return "", errors.New("access token is required")
 	}
 
 	// First, initiate the upload
-	assetURN, uploadMechanism, err := c.InitiateImageUpload("image")
+	assetURN, uploadMechanism, err := c.InitiateImageUploadContext(ctx, "image", opts...)
 	if err != nil {
 		return "", err
 	}
@@ -503,7 +1270,7 @@ func (c *LinkedInClient) UploadImage(imagePath string) (string, error) {
 	}
 
 	// Upload the image
-	uploadReq, err := http.NewRequest("PUT", uploadURL, bytes.NewReader(fileContents))
+	uploadReq, err := http.NewRequestWithContext(ctx, "PUT", uploadURL, bytes.NewReader(fileContents))
 	if err != nil {
 		return "", err
 	}
@@ -516,18 +1283,96 @@ func (c *LinkedInClient) UploadImage(imagePath string) (string, error) {
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated &&
 		resp.StatusCode != http.StatusNoContent {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to upload image: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return "", linkedinError(resp, "upload image")
 	}
 
 	return assetURN, nil
 }
 
-// CreateImagePost creates a post with an image
+// CreateImagePost creates a post with an image. image_url is either the URN
+// of an already uploaded asset (urn:li:digitalmediaAsset:...) or a public
+// http(s) URL, which is downloaded and uploaded first.
 func (c *LinkedInClient) CreateImagePost(
 	input []byte,
 ) ([]byte, error) {
-	if c.AccessToken == "" {
+	return c.CreateImagePostContext(context.Background(), input)
+}
+
+// resolveImageAsset returns the asset URN for the image_url of an image post,
+// uploading the image first when it is given as an http(s) URL
+func (c *LinkedInClient) resolveImageAsset(ctx context.Context, imageURL string, opts ...RequestOption) (string, error) {
+	switch {
+	case strings.HasPrefix(imageURL, "urn:"):
+		return imageURL, nil
+	case strings.HasPrefix(imageURL, "http://"), strings.HasPrefix(imageURL, "https://"):
+	default:
+		return "", fmt.Errorf("image_url must be an asset URN or an http(s) URL, got %q", imageURL)
+	}
+
+	imagePath, err := c.downloadImage(ctx, imageURL)
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(imagePath)
+
+	return c.UploadImageContext(ctx, imagePath, opts...)
+}
+
+// linkedInMaxImageSize caps the size of images downloaded for image posts
+const linkedInMaxImageSize = 10 << 20
+
+// downloadImage saves the image at imageURL to a temporary file and returns
+// its path. The caller removes the file. Responses that are not image/* or are
+// larger than linkedInMaxImageSize are rejected.
+func (c *LinkedInClient) downloadImage(ctx context.Context, imageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", imageURL, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download image: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to download image: %s, status: %d", string(bodyBytes), resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err != nil || !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("failed to download image: %s is %q, not an image", imageURL, contentType)
+	}
+	if resp.ContentLength > linkedInMaxImageSize {
+		return "", fmt.Errorf("failed to download image: %d bytes exceeds the %d byte limit", resp.ContentLength, linkedInMaxImageSize)
+	}
+
+	file, err := os.CreateTemp("", "linkedin-image-*")
+	if err != nil {
+		return "", err
+	}
+	// the limit is enforced on the body too, since Content-Length is optional
+	n, err := io.Copy(file, io.LimitReader(resp.Body, linkedInMaxImageSize+1))
+	if err == nil && n > linkedInMaxImageSize {
+		err = fmt.Errorf("image exceeds the %d byte limit", linkedInMaxImageSize)
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to download image: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// CreateImagePostContext is CreateImagePost with a context
+func (c *LinkedInClient) CreateImagePostContext(ctx context.Context, input []byte, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("access token is required")
 	}
 
@@ -542,27 +1387,29 @@ func (c *LinkedInClient) CreateImagePost(
 	imageAssetURN, _ = inputmap["image_url"].(string)
 	authorType, _ = inputmap["author_type"].(string)
 	authorID, _ = inputmap["author_id"].(string)
-	if authorType == "" {
-		authorType = "person"
+	if err := checkNoSchedule(inputmap); err != nil {
+		return nil, err
 	}
 
-	if authorID == "" && authorType == "person" {
-		if c.UserID == "" {
-			profileData, err := c.GetUserProfile()
-			if err != nil {
-				return nil, fmt.Errorf("could not determine user ID: %v", err)
-			}
-			profile := types.LinkedInUserProfile{}
-			json.Unmarshal(profileData, &profile)
-			authorID = profile.ID
-		} else {
-			authorID = c.UserID
-		}
+	author, err := c.authorURN(authorType, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	visibilityStr, _ := inputmap["visibility"].(string)
+	visibility, err := LinkedInVisibility(Visibility(visibilityStr))
+	if err != nil {
+		return nil, err
+	}
+
+	imageAssetURN, err = c.resolveImageAsset(ctx, imageAssetURN, opts...)
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare the UGC post request with image
 	postData := map[string]interface{}{
-		"author":         fmt.Sprintf("urn:li:%s:%s", authorType, authorID),
+		"author":         author,
 		"lifecycleState": "PUBLISHED",
 		"specificContent": map[string]interface{}{
 			"com.linkedin.ugc.ShareContent": map[string]interface{}{
@@ -585,7 +1432,7 @@ func (c *LinkedInClient) CreateImagePost(
 			},
 		},
 		"visibility": map[string]interface{}{
-			"com.linkedin.ugc.MemberNetworkVisibility": "PUBLIC",
+			"com.linkedin.ugc.MemberNetworkVisibility": visibility,
 		},
 	}
 
@@ -594,28 +1441,25 @@ func (c *LinkedInClient) CreateImagePost(
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", UGCPostURL, bytes.NewBuffer(postJSON))
+	req, err := c.newRequest(ctx, "POST", UGCPostURL, bytes.NewBuffer(postJSON), opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("X-Restli-Protocol-Version", "2.0.0")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to create image post: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "create image post")
 	}
 
 	var postResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&postResp); err != nil {
+	if err := decodeJSONBody(resp, &postResp); err != nil {
 		return nil, err
 	}
 
@@ -632,23 +1476,45 @@ func (c *LinkedInClient) CreateImagePost(
 
 // PostWithImage is a convenience function that handles both image upload and post creation
 func (c *LinkedInClient) PostWithImage(input []byte) ([]byte, error) {
+	return c.PostWithImageContext(context.Background(), input)
+}
+
+// PostWithImageContext is PostWithImage with a context. The whole register,
+// upload and post flow is aborted once OperationTimeout has elapsed.
+func (c *LinkedInClient) PostWithImageContext(ctx context.Context, input []byte, opts ...RequestOption) ([]byte, error) {
+	ctx, cancel := withOperationTimeout(ctx, c.OperationTimeout)
+	defer cancel()
+
+	output, err := c.postWithImage(ctx, input, opts...)
+	return output, operationError(ctx, "image post", c.OperationTimeout, err)
+}
+
+func (c *LinkedInClient) postWithImage(ctx context.Context, input []byte, opts ...RequestOption) ([]byte, error) {
 	// First upload the image
 	inputmap := map[string]interface{}{}
 	json.Unmarshal(input, &inputmap)
+	if err := checkNoSchedule(inputmap); err != nil {
+		return nil, err
+	}
 	imagepath, _ := inputmap["image_path"].(string)
-	assetURN, err := c.UploadImage(imagepath)
+	assetURN, err := c.UploadImageContext(ctx, imagepath, opts...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to upload image: %v", err)
+		return nil, fmt.Errorf("failed to upload image: %w", err)
 	}
 	inputmap["image_url"] = assetURN
 	// Then create the post with the image
 	bytes, _ := json.Marshal(inputmap)
-	return c.CreateImagePost(bytes)
+	return c.CreateImagePostContext(ctx, bytes, opts...)
 }
 
 // InitiateVideoUpload prepares a video upload
 func (c *LinkedInClient) InitiateVideoUpload() ([]byte, error) {
-	if c.AccessToken == "" {
+	return c.InitiateVideoUploadContext(context.Background())
+}
+
+// InitiateVideoUploadContext is InitiateVideoUpload with a context
+func (c *LinkedInClient) InitiateVideoUploadContext(ctx context.Context, opts ...RequestOption) ([]byte, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("access token is required")
 	}
 
@@ -673,12 +1539,11 @@ func (c *LinkedInClient) InitiateVideoUpload() ([]byte, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", AssetUploadURL, bytes.NewBuffer(assetJSON))
+	req, err := c.newRequest(ctx, "POST", AssetUploadURL, bytes.NewBuffer(assetJSON), opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Add("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
@@ -688,16 +1553,11 @@ func (c *LinkedInClient) InitiateVideoUpload() ([]byte, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf(
-			"failed to initiate video upload: %s, status: %d",
-			string(bodyBytes),
-			resp.StatusCode,
-		)
+		return nil, linkedinError(resp, "initiate video upload")
 	}
 
 	var uploadResp map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&uploadResp); err != nil {
+	if err := decodeJSONBody(resp, &uploadResp); err != nil {
 		return nil, err
 	}
 
@@ -725,7 +1585,23 @@ func (c *LinkedInClient) InitiateVideoUpload() ([]byte, error) {
 
 // UploadVideo uploads a video to LinkedIn
 func (c *LinkedInClient) UploadVideo(videoPath string) (string, error) {
-	if c.AccessToken == "" {
+	return c.UploadVideoContext(context.Background(), videoPath)
+}
+
+// UploadVideoContext is UploadVideo with a context
+func (c *LinkedInClient) UploadVideoContext(ctx context.Context, videoPath string, opts ...RequestOption) (string, error) {
+	return c.UploadVideoWithOptionsContext(ctx, videoPath, UploadOptions{}, opts...)
+}
+
+// UploadVideoWithOptions uploads a video to LinkedIn, reporting progress
+// through opts
+func (c *LinkedInClient) UploadVideoWithOptions(videoPath string, opts UploadOptions) (string, error) {
+	return c.UploadVideoWithOptionsContext(context.Background(), videoPath, opts)
+}
+
+// UploadVideoWithOptionsContext is UploadVideoWithOptions with a context
+func (c *LinkedInClient) UploadVideoWithOptionsContext(ctx context.Context, videoPath string, opts UploadOptions, reqOpts ...RequestOption) (string, error) {
+	if c.currentToken() == "" {
 		return "", errors.New("access token is required")
 	}
 
@@ -734,7 +1610,7 @@ func (c *LinkedInClient) UploadVideo(videoPath string) (string, error) {
 	var err error
 	uploadMechanism := map[string]interface{}{}
 	videoData := []byte{}
-	videoData, err = c.InitiateVideoUpload()
+	videoData, err = c.InitiateVideoUploadContext(ctx, reqOpts...)
 	if err != nil {
 		return "", err
 	}
@@ -754,23 +1630,25 @@ func (c *LinkedInClient) UploadVideo(videoPath string) (string, error) {
 		return "", errors.New("could not find upload URL")
 	}
 
-	// Read the video file
+	// Open the video file, which is streamed as the request body
 	file, err := os.Open(videoPath)
 	if err != nil {
 		return "", err
 	}
 	defer file.Close()
 
-	fileContents, err := io.ReadAll(file)
+	info, err := file.Stat()
 	if err != nil {
 		return "", err
 	}
 
 	// Upload the video
-	uploadReq, err := http.NewRequest("PUT", uploadURL, bytes.NewReader(fileContents))
+	size := info.Size()
+	uploadReq, err := http.NewRequestWithContext(ctx, "PUT", uploadURL, opts.body(file, size))
 	if err != nil {
 		return "", err
 	}
+	uploadReq.ContentLength = size
 
 	resp, err := c.HTTPClient.Do(uploadReq)
 	if err != nil {
@@ -780,8 +1658,7 @@ func (c *LinkedInClient) UploadVideo(videoPath string) (string, error) {
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated &&
 		resp.StatusCode != http.StatusNoContent {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("failed to upload video: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return "", linkedinError(resp, "upload video")
 	}
 
 	return assetURN, nil
@@ -791,42 +1668,47 @@ func (c *LinkedInClient) UploadVideo(videoPath string) (string, error) {
 func (c *LinkedInClient) CreateVideoPost(
 	input []byte,
 ) ([]byte, error) {
-	if c.AccessToken == "" {
+	return c.CreateVideoPostContext(context.Background(), input)
+}
+
+// CreateVideoPostContext is CreateVideoPost with a context
+func (c *LinkedInClient) CreateVideoPostContext(
+	ctx context.Context,
+	input []byte,
+	opts ...RequestOption,
+) ([]byte, error) {
+	if c.currentToken() == "" {
 		return nil, errors.New("access token is required")
 	}
 	var text,
-		vidoeAssetURL,
+		videoAssetURN,
 		authorType,
 		authorID string
 
 	inputmap := map[string]interface{}{}
 	json.Unmarshal(input, &inputmap)
 	text, _ = inputmap["text"].(string)
-	vidoeAssetURL, _ = inputmap["video_url"].(string)
+	videoAssetURN, _ = inputmap["video_url"].(string)
 	authorType, _ = inputmap["author_type"].(string)
 	authorID, _ = inputmap["author_id"].(string)
+	if err := checkNoSchedule(inputmap); err != nil {
+		return nil, err
+	}
 
-	if authorType == "" {
-		authorType = "person"
+	author, err := c.authorURN(authorType, authorID)
+	if err != nil {
+		return nil, err
 	}
 
-	if authorID == "" && authorType == "person" {
-		if c.UserID == "" {
-			profileData, err := c.GetUserProfile()
-			if err != nil {
-				return nil, fmt.Errorf("could not determine user ID: %v", err)
-			}
-			profile := types.LinkedInUserProfile{}
-			json.Unmarshal(profileData, &profile)
-			authorID = profile.ID
-		} else {
-			authorID = c.UserID
-		}
+	visibilityStr, _ := inputmap["visibility"].(string)
+	visibility, err := LinkedInVisibility(Visibility(visibilityStr))
+	if err != nil {
+		return nil, err
 	}
 
 	// Prepare the UGC post request with video
 	postData := map[string]interface{}{
-		"author":         fmt.Sprintf("urn:li:%s:%s", authorType, authorID),
+		"author":         author,
 		"lifecycleState": "PUBLISHED",
 		"specificContent": map[string]interface{}{
 			"com.linkedin.ugc.ShareContent": map[string]interface{}{
@@ -840,7 +1722,7 @@ func (c *LinkedInClient) CreateVideoPost(
 						"description": map[string]interface{}{
 							"text": "Video description",
 						},
-						"media": vidoeAssetURL,
+						"media": videoAssetURN,
 						"title": map[string]interface{}{
 							"text": "Video title",
 						},
@@ -849,7 +1731,7 @@ func (c *LinkedInClient) CreateVideoPost(
 			},
 		},
 		"visibility": map[string]interface{}{
-			"com.linkedin.ugc.MemberNetworkVisibility": "PUBLIC",
+			"com.linkedin.ugc.MemberNetworkVisibility": visibility,
 		},
 	}
 
@@ -858,28 +1740,278 @@ func (c *LinkedInClient) CreateVideoPost(
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", UGCPostURL, bytes.NewBuffer(postJSON))
+	req, err := c.newRequest(ctx, "POST", UGCPostURL, bytes.NewBuffer(postJSON), opts...)
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", c.AccessToken))
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("X-Restli-Protocol-Version", "2.0.0")
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to create video post: %s, status: %d", string(bodyBytes), resp.StatusCode)
+		return nil, linkedinError(resp, "create video post")
+	}
+
+	var postResp map[string]interface{}
+	if err := decodeJSONBody(resp, &postResp); err != nil {
+		return nil, err
+	}
+
+	postID, ok := postResp["id"].(string)
+	if !ok {
+		return nil, errors.New("invalid post response, no ID found")
+	}
+
+	output := types.LinkedInPostResponse{
+		ID: postID,
+	}
+	return json.Marshal(output)
+}
+
+// linkedinStatsBatchSize is the number of posts requested per share
+// statistics call
+const linkedinStatsBatchSize = 20
+
+// GetPostMetricsBatch retrieves the lifetime metrics of many posts of
+// OrganizationID, keyed by post URN. Share and UGC post URNs are accepted and
+// are requested in batches of linkedinStatsBatchSize.
+func (c *LinkedInClient) GetPostMetricsBatch(urns []string) (map[string]*types.LinkedInPostMetrics, error) {
+	return c.GetPostMetricsBatchContext(context.Background(), urns)
+}
+
+// GetPostMetricsBatchContext is GetPostMetricsBatch with a context
+func (c *LinkedInClient) GetPostMetricsBatchContext(ctx context.Context, urns []string, opts ...RequestOption) (map[string]*types.LinkedInPostMetrics, error) {
+	if c.currentToken() == "" {
+		return nil, errors.New("access token is required")
+	}
+	if c.OrganizationID == "" {
+		return nil, errors.New("organization ID is required")
+	}
+
+	var shares, ugcPosts []string
+	for _, urn := range urns {
+		switch {
+		case strings.HasPrefix(urn, "urn:li:share:"):
+			shares = append(shares, urn)
+		case strings.HasPrefix(urn, "urn:li:ugcPost:"):
+			ugcPosts = append(ugcPosts, urn)
+		default:
+			return nil, fmt.Errorf("invalid post URN for statistics: %q", urn)
+		}
+		if err := validatePostURN(urn); err != nil {
+			return nil, err
+		}
+	}
+
+	metrics := make(map[string]*types.LinkedInPostMetrics, len(urns))
+	for _, batch := range []struct {
+		param string
+		urns  []string
+	}{{"shares", shares}, {"ugcPosts", ugcPosts}} {
+		for start := 0; start < len(batch.urns); start += linkedinStatsBatchSize {
+			end := min(start+linkedinStatsBatchSize, len(batch.urns))
+			if err := c.getShareStatistics(ctx, batch.param, batch.urns[start:end], metrics, opts...); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return metrics, nil
+}
+
+// GetStats implements StatsProvider with the post's share statistics, so it
+// needs OrganizationID. Views are impressions; clicks have no PostStats field
+// and only count towards Engagement.
+func (c *LinkedInClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	metrics, err := c.GetPostMetricsBatchContext(ctx, []string{postID})
+	if err != nil {
+		return PostStats{}, err
+	}
+
+	m, ok := metrics[postID]
+	if !ok {
+		return PostStats{}, fmt.Errorf("linkedin post %q: %w", postID, ErrNotFound)
+	}
+
+	return PostStats{
+		Views:      int64(m.Impressions),
+		Likes:      int64(m.Likes),
+		Comments:   int64(m.Comments),
+		Shares:     int64(m.Shares),
+		Engagement: engagementPercent(int64(m.Engagement), int64(m.Impressions)),
+	}, nil
+}
+
+// getShareStatistics fetches the statistics of one batch of posts and adds
+// them to metrics. param is "shares" or "ugcPosts".
+func (c *LinkedInClient) getShareStatistics(ctx context.Context, param string, urns []string, metrics map[string]*types.LinkedInPostMetrics, opts ...RequestOption) error {
+	escaped := make([]string, len(urns))
+	for i, urn := range urns {
+		escaped[i] = url.QueryEscape(urn)
+	}
+
+	// Rest.li lists must not have their parentheses and commas escaped
+	statsURL := fmt.Sprintf("%s/organizationalEntityShareStatistics?q=organizationalEntity&organizationalEntity=%s&%s=List(%s)",
+		c.baseURL(), url.QueryEscape("urn:li:organization:"+c.OrganizationID), param, strings.Join(escaped, ","))
+
+	req, err := c.newRequest(ctx, "GET", statsURL, nil, opts...)
+	if err != nil {
+		return err
+	}
+
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return linkedinError(resp, "get share statistics")
+	}
+
+	var statsResp struct {
+		Elements []struct {
+			Share                string `json:"share"`
+			UGCPost              string `json:"ugcPost"`
+			TotalShareStatistics struct {
+				ImpressionCount int     `json:"impressionCount"`
+				ClickCount      int     `json:"clickCount"`
+				LikeCount       int     `json:"likeCount"`
+				CommentCount    int     `json:"commentCount"`
+				ShareCount      int     `json:"shareCount"`
+				Engagement      float64 `json:"engagement"`
+			} `json:"totalShareStatistics"`
+		} `json:"elements"`
+	}
+	if err := decodeJSONBody(resp, &statsResp); err != nil {
+		return err
+	}
+
+	for _, element := range statsResp.Elements {
+		urn := element.Share
+		if urn == "" {
+			urn = element.UGCPost
+		}
+		if urn == "" {
+			continue
+		}
+
+		stats := element.TotalShareStatistics
+		postMetrics := &types.LinkedInPostMetrics{
+			Impressions:    stats.ImpressionCount,
+			Clicks:         stats.ClickCount,
+			Likes:          stats.LikeCount,
+			Comments:       stats.CommentCount,
+			Shares:         stats.ShareCount,
+			Engagement:     stats.ClickCount + stats.LikeCount + stats.CommentCount + stats.ShareCount,
+			EngagementRate: stats.Engagement,
+		}
+		if stats.ImpressionCount > 0 {
+			postMetrics.CTR = float64(stats.ClickCount) / float64(stats.ImpressionCount)
+		}
+		metrics[urn] = postMetrics
+	}
+
+	return nil
+}
+
+// PollResults are the current tallies of a LinkedIn poll. Results of a poll
+// that is still open are the votes cast so far.
+type PollResults struct {
+	URN        string       `json:"urn"`
+	Question   string       `json:"question"`
+	Options    []PollOption `json:"options"`
+	TotalVotes int          `json:"totalVotes"`
+	Closed     bool         `json:"closed"`
+	EndsAt     time.Time    `json:"endsAt"` // zero when unknown
+}
+
+// PollOption is one answer of a poll and the number of votes it received
+type PollOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// linkedinPollDurations maps the duration setting of a poll to its length
+var linkedinPollDurations = map[string]time.Duration{
+	"ONE_DAY":       24 * time.Hour,
+	"THREE_DAYS":    3 * 24 * time.Hour,
+	"SEVEN_DAYS":    7 * 24 * time.Hour,
+	"FOURTEEN_DAYS": 14 * 24 * time.Hour,
+}
+
+// GetPollResults retrieves the options and vote counts of the poll in a post
+func (c *LinkedInClient) GetPollResults(pollURN string) (*PollResults, error) {
+	return c.GetPollResultsContext(context.Background(), pollURN)
+}
+
+// GetPollResultsContext is GetPollResults with a context
+func (c *LinkedInClient) GetPollResultsContext(ctx context.Context, pollURN string, opts ...RequestOption) (*PollResults, error) {
+	if c.currentToken() == "" {
+		return nil, errors.New("access token is required")
+	}
+	if err := validatePostURN(pollURN); err != nil {
+		return nil, err
+	}
+
+	req, err := c.newRequest(ctx, "GET", fmt.Sprintf("%s/posts/%s", c.baseURL(), url.QueryEscape(pollURN)), nil, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := doWithRetry(c.HTTPClient, req, DefaultRetryConfig)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, linkedinError(resp, "get poll results")
+	}
+
+	var postResp struct {
+		PublishedAt int64 `json:"publishedAt"` // epoch milliseconds
+		Content     struct {
+			Poll *struct {
+				Question string `json:"question"`
+				Options  []struct {
+					Text      string `json:"text"`
+					VoteCount int    `json:"voteCount"`
+				} `json:"options"`
+				Settings struct {
+					Duration string `json:"duration"`
+				} `json:"settings"`
+			} `json:"poll"`
+		} `json:"content"`
+	}
+	if err := decodeJSONBody(resp, &postResp); err != nil {
+		return nil, err
+	}
+
+	poll := postResp.Content.Poll
+	if poll == nil {
+		return nil, fmt.Errorf("post %s has no poll", pollURN)
+	}
+
+	results := &PollResults{
+		URN:      pollURN,
+		Question: poll.Question,
+		Options:  make([]PollOption, len(poll.Options)),
+	}
+	for i, option := range poll.Options {
+		results.Options[i] = PollOption{Text: option.Text, Votes: option.VoteCount}
+		results.TotalVotes += option.VoteCount
+	}
+
+	if duration, ok := linkedinPollDurations[poll.Settings.Duration]; ok && postResp.PublishedAt > 0 {
+		results.EndsAt = time.UnixMilli(postResp.PublishedAt).Add(duration)
+		results.Closed = !time.Now().Before(results.EndsAt)
 	}
 
-	repmap := map[string]interface{}{}
-	docoder := json.NewDecoder(req.Body)
-	docoder.Decode(&repmap)
-	return nil, nil
+	return results, nil
 }
@@ -66,6 +66,7 @@ func (c *Client) CreateJobPosting(jobPosting *JobPosting) (string, error) {
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("X-Restli-Protocol-Version", restliProtocolVersion)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
@@ -83,7 +84,7 @@ func (c *Client) CreateJobPosting(jobPosting *JobPosting) (string, error) {
 		ID string `json:"id"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return "", fmt.Errorf("error decoding response: %v", err)
 	}
 
@@ -100,6 +101,7 @@ func (c *Client) GetJobPosting(jobID string) (*JobPosting, error) {
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("X-Restli-Protocol-Version", restliProtocolVersion)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -113,7 +115,7 @@ func (c *Client) GetJobPosting(jobID string) (*JobPosting, error) {
 	}
 
 	var jobPosting JobPosting
-	if err := json.NewDecoder(resp.Body).Decode(&jobPosting); err != nil {
+	if err := decodeJSONBody(resp, &jobPosting); err != nil {
 		return nil, fmt.Errorf("error decoding response: %v", err)
 	}
 
@@ -135,6 +137,7 @@ func (c *Client) UpdateJobPosting(jobID string, jobPosting *JobPosting) error {
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("X-Restli-Protocol-Version", restliProtocolVersion)
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPClient.Do(req)
@@ -161,6 +164,7 @@ func (c *Client) DeleteJobPosting(jobID string) error {
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("X-Restli-Protocol-Version", restliProtocolVersion)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -186,6 +190,7 @@ func (c *Client) ListJobPostings(companyID string, limit int, offset int) ([]Job
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("X-Restli-Protocol-Version", restliProtocolVersion)
 
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
@@ -202,7 +207,7 @@ func (c *Client) ListJobPostings(companyID string, limit int, offset int) ([]Job
 		Elements []JobPosting `json:"elements"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, fmt.Errorf("error decoding response: %v", err)
 	}
 
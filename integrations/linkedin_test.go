@@ -0,0 +1,1064 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestLinkedInClient(t *testing.T, handler http.HandlerFunc) *LinkedInClient {
+	t.Helper()
+
+	_, client := newTestServer(t, handler)
+	c := NewLinkedInClient("id", "secret", "http://localhost/callback")
+	c.AccessToken = "token"
+	c.HTTPClient = client
+	return c
+}
+
+func TestLinkedInConcurrentRefreshRunsOnce(t *testing.T) {
+	var refreshes atomic.Int32
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		refreshes.Add(1)
+		time.Sleep(100 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","refresh_token":"rotated","expires_in":3600}`))
+	})
+
+	c := &LinkedInClient{
+		AccessToken:  "old",
+		RefreshToken: "refresh",
+		TokenExpiry:  time.Now().Add(-time.Minute),
+		HTTPClient:   client,
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, err := c.newRequest(context.Background(), "GET", LinkedinBaseURL+"/me", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if got := req.Header.Get("Authorization"); got != "Bearer new" {
+				t.Errorf("Authorization = %q, want the refreshed token", got)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := refreshes.Load(); n != 1 {
+		t.Fatalf("refresh requests = %d, want 1", n)
+	}
+
+	// a caller still holding the rotated-away refresh token gets the new
+	// token instead of refreshing with a revoked one
+	tokenResp, err := c.RefreshAccessToken("refresh")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokenResp.AccessToken != "new" || refreshes.Load() != 1 {
+		t.Fatalf("stale refresh = %q after %d requests", tokenResp.AccessToken, refreshes.Load())
+	}
+}
+
+func TestLinkedInGetSendsRestliHeader(t *testing.T) {
+	var requests int
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Method != http.MethodGet {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		if got := r.Header.Get("X-Restli-Protocol-Version"); got != "2.0.0" {
+			t.Errorf("%s: X-Restli-Protocol-Version = %q, want 2.0.0", r.URL.Path, got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("%s: Authorization = %q", r.URL.Path, got)
+		}
+
+		switch r.URL.Path {
+		case "/v2/organizationAcls":
+			fmt.Fprint(w, `{"elements":[{"organizationTarget":"123","role":"ADMINISTRATOR"}],"paging":{"start":0,"count":50,"total":1}}`)
+		case "/v2/organizations/123":
+			fmt.Fprint(w, `{"id":123,"name":"Acme"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	body, err := c.GetCompanyPages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+
+	var pages []map[string]interface{}
+	if err := json.Unmarshal(body, &pages); err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 1 {
+		t.Fatalf("got %d pages, want 1", len(pages))
+	}
+}
+
+func TestLinkedInRejectsScheduledPosts(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	c.UserID = "abc"
+
+	input := []byte(`{"text":"hello","schedule_time":"2030-01-01T00:00:00Z"}`)
+	if _, err := c.CreateTextPost(input); !errors.Is(err, ErrSchedulingUnsupported) {
+		t.Errorf("CreateTextPost error = %v, want ErrSchedulingUnsupported", err)
+	}
+
+	at := time.Now().Add(time.Hour)
+	if _, _, err := c.BuildPayload(PostData{Title: "hello", ScheduleTime: &at}); !errors.Is(err, ErrSchedulingUnsupported) {
+		t.Errorf("BuildPayload error = %v, want ErrSchedulingUnsupported", err)
+	}
+}
+
+func TestLinkedInCreateArticlePostPayload(t *testing.T) {
+	tests := []struct {
+		name  string
+		input map[string]string
+		want  map[string]interface{}
+	}{
+		{
+			"scraped preview",
+			map[string]string{"text": "Worth reading", "url": "https://example.com/post"},
+			map[string]interface{}{"status": "READY", "originalUrl": "https://example.com/post"},
+		},
+		{
+			"explicit preview",
+			map[string]string{
+				"text":          "Worth reading",
+				"url":           "https://example.com/post",
+				"title":         "A post",
+				"description":   "About things",
+				"thumbnail_url": "https://example.com/thumb.png",
+			},
+			map[string]interface{}{
+				"status":      "READY",
+				"originalUrl": "https://example.com/post",
+				"title":       map[string]interface{}{"text": "A post"},
+				"description": map[string]interface{}{"text": "About things"},
+				"thumbnails":  []interface{}{map[string]interface{}{"url": "https://example.com/thumb.png"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != "/v2/ugcPosts" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+
+				var payload struct {
+					Author          string `json:"author"`
+					SpecificContent struct {
+						Share struct {
+							Commentary struct {
+								Text string `json:"text"`
+							} `json:"shareCommentary"`
+							MediaCategory string                   `json:"shareMediaCategory"`
+							Media         []map[string]interface{} `json:"media"`
+						} `json:"com.linkedin.ugc.ShareContent"`
+					} `json:"specificContent"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatal(err)
+				}
+				share := payload.SpecificContent.Share
+				if payload.Author != "urn:li:person:abc" || share.Commentary.Text != "Worth reading" {
+					t.Errorf("author = %q, commentary = %q", payload.Author, share.Commentary.Text)
+				}
+				if share.MediaCategory != "ARTICLE" {
+					t.Errorf("shareMediaCategory = %q, want ARTICLE", share.MediaCategory)
+				}
+				if len(share.Media) != 1 || !reflect.DeepEqual(share.Media[0], tt.want) {
+					t.Errorf("media = %v, want [%v]", share.Media, tt.want)
+				}
+
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"id":"urn:li:share:6844785523593134082"}`)
+			})
+			c.UserID = "abc"
+
+			input, _ := json.Marshal(tt.input)
+			body, err := c.CreateArticlePost(input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(body), "urn:li:share:6844785523593134082") {
+				t.Errorf("response = %s", body)
+			}
+		})
+	}
+}
+
+func TestLinkedInCreateArticlePostRequiresURL(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	if _, err := c.CreateArticlePost([]byte(`{"text":"no link"}`)); err == nil {
+		t.Error("expected an error without a url")
+	}
+}
+
+func TestLinkedInResharePostPayload(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/ugcPosts" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var payload struct {
+			Author          string `json:"author"`
+			LifecycleState  string `json:"lifecycleState"`
+			SpecificContent struct {
+				Share struct {
+					Commentary struct {
+						Text string `json:"text"`
+					} `json:"shareCommentary"`
+					MediaCategory string `json:"shareMediaCategory"`
+				} `json:"com.linkedin.ugc.ShareContent"`
+			} `json:"specificContent"`
+			ReshareContext struct {
+				Parent string `json:"parent"`
+			} `json:"reshareContext"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Author != "urn:li:person:abc" || payload.LifecycleState != "PUBLISHED" {
+			t.Errorf("author = %q, lifecycleState = %q", payload.Author, payload.LifecycleState)
+		}
+		if payload.SpecificContent.Share.Commentary.Text != "Worth reading" || payload.SpecificContent.Share.MediaCategory != "NONE" {
+			t.Errorf("share content = %+v", payload.SpecificContent.Share)
+		}
+		if payload.ReshareContext.Parent != "urn:li:share:6844785523593134080" {
+			t.Errorf("reshareContext.parent = %q", payload.ReshareContext.Parent)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"urn:li:share:6844785523593134081"}`)
+	})
+	c.UserID = "abc"
+
+	body, err := c.ResharePost("urn:li:share:6844785523593134080", "Worth reading")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "urn:li:share:6844785523593134081" {
+		t.Errorf("id = %q", resp.ID)
+	}
+}
+
+func TestLinkedInResharePostRejectsInvalidURN(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	c.UserID = "abc"
+
+	for _, urn := range []string{"", "6844785523593134080", "urn:li:share:", "urn:li:share:12ab", "urn:li:person:123"} {
+		if _, err := c.ResharePost(urn, ""); err == nil {
+			t.Errorf("ResharePost(%q) succeeded, want an error", urn)
+		}
+	}
+}
+
+func TestLinkedInUploadVideoStreamsWithProgress(t *testing.T) {
+	video := bytes.Repeat([]byte("v"), 200_000)
+	path := writeTempFile(t, "clip.mp4", video)
+
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/v2/assets":
+			fmt.Fprint(w, `{"value":{
+				"asset":"urn:li:digitalmediaAsset:C5500AQG",
+				"uploadMechanism":{"com.linkedin.digitalmedia.uploading.MediaUploadHttpRequest":{
+					"uploadUrl":"https://api.linkedin.com/mediaUpload/C5500AQG/feedshare-uploadedVideo/0"
+				}}
+			}}`)
+		case r.Method == http.MethodPut && r.URL.Path == "/mediaUpload/C5500AQG/feedshare-uploadedVideo/0":
+			if r.ContentLength != int64(len(video)) {
+				t.Errorf("Content-Length = %d, want %d", r.ContentLength, len(video))
+			}
+			got, _ := io.ReadAll(r.Body)
+			if !bytes.Equal(got, video) {
+				t.Errorf("uploaded %d bytes, want the %d bytes of the video", len(got), len(video))
+			}
+			w.WriteHeader(http.StatusCreated)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+	c.UserID = "abc"
+
+	var rec progressRecorder
+	asset, err := c.UploadVideoWithOptions(path, UploadOptions{Progress: rec.record})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if asset != "urn:li:digitalmediaAsset:C5500AQG" {
+		t.Errorf("asset = %q", asset)
+	}
+	rec.check(t, int64(len(video)))
+}
+
+func TestLinkedInRefreshesTokenNearExpiry(t *testing.T) {
+	var refreshes atomic.Int32
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth/v2/accessToken":
+			refreshes.Add(1)
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if r.PostForm.Get("grant_type") != "refresh_token" || r.PostForm.Get("refresh_token") != "refresh" {
+				t.Errorf("unexpected refresh form %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"access_token":"fresh","refresh_token":"rotated","expires_in":3600}`)
+		case "/v2/me":
+			if got := r.Header.Get("Authorization"); got != "Bearer fresh" {
+				t.Errorf("Authorization = %q, want the refreshed token", got)
+			}
+			fmt.Fprint(w, `{"id":"abc"}`)
+		case "/v2/emailAddress":
+			fmt.Fprint(w, `{"elements":[]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	c.RefreshToken = "refresh"
+	c.TokenExpiry = time.Now().Add(30 * time.Second)
+
+	if _, err := c.GetUserProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshes = %d, want 1", n)
+	}
+	if c.RefreshToken != "rotated" {
+		t.Errorf("RefreshToken = %q, want the rotated token", c.RefreshToken)
+	}
+	if remaining := time.Until(c.TokenExpiry); remaining < 59*time.Minute || remaining > time.Hour {
+		t.Errorf("TokenExpiry is %v away, want about an hour from expires_in", remaining)
+	}
+
+	// the refreshed token is used as is
+	if _, err := c.GetUserProfile(); err != nil {
+		t.Fatal(err)
+	}
+	if n := refreshes.Load(); n != 1 {
+		t.Errorf("refreshes = %d after a second call, want 1", n)
+	}
+}
+
+func TestLinkedInExpiredTokenWithoutRefreshToken(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	c.TokenExpiry = time.Now().Add(-time.Minute)
+
+	if _, err := c.GetUserProfile(); !errors.Is(err, ErrNoRefreshToken) {
+		t.Fatalf("error = %v, want ErrNoRefreshToken", err)
+	}
+}
+
+func TestLinkedInCreateVideoPostReturnsID(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/ugcPosts" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var payload struct {
+			SpecificContent struct {
+				Share struct {
+					MediaCategory string `json:"shareMediaCategory"`
+					Media         []struct {
+						Media string `json:"media"`
+					} `json:"media"`
+				} `json:"com.linkedin.ugc.ShareContent"`
+			} `json:"specificContent"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		share := payload.SpecificContent.Share
+		if share.MediaCategory != "VIDEO" || len(share.Media) != 1 || share.Media[0].Media != "urn:li:digitalmediaAsset:C5605AQ" {
+			t.Errorf("share content = %+v", share)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"urn:li:ugcPost:6844785523593134082"}`)
+	})
+	c.UserID = "abc"
+
+	body, err := c.CreateVideoPost([]byte(`{"text":"Launch day","video_url":"urn:li:digitalmediaAsset:C5605AQ"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ID != "urn:li:ugcPost:6844785523593134082" {
+		t.Errorf("id = %q", resp.ID)
+	}
+}
+
+func TestLinkedInGetCompanyPagesReadsAllPages(t *testing.T) {
+	var aclRequests int
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/organizationAcls":
+			aclRequests++
+			q := r.URL.Query()
+			if q.Get("count") != "50" {
+				t.Errorf("count = %q, want 50", q.Get("count"))
+			}
+			switch q.Get("start") {
+			case "0":
+				fmt.Fprint(w, `{"elements":[
+					{"organizationTarget":"urn:li:organization:1","role":"ADMINISTRATOR"},
+					{"organizationTarget":"urn:li:organization:2","role":"ADMINISTRATOR"}
+				],"paging":{"start":0,"count":2,"total":3}}`)
+			case "2":
+				fmt.Fprint(w, `{"elements":[
+					{"organizationTarget":"urn:li:organization:3","role":"ADMINISTRATOR"}
+				],"paging":{"start":2,"count":1,"total":3}}`)
+			default:
+				t.Errorf("unexpected start %q", q.Get("start"))
+			}
+		case "/v2/organizations/urn:li:organization:1":
+			fmt.Fprint(w, `{"name":"Acme"}`)
+		case "/v2/organizations/urn:li:organization:2":
+			fmt.Fprint(w, `{"name":"Globex"}`)
+		case "/v2/organizations/urn:li:organization:3":
+			fmt.Fprint(w, `{"name":"Initech"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	body, err := c.GetCompanyPages()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if aclRequests != 2 {
+		t.Errorf("organizationAcls requests = %d, want 2", aclRequests)
+	}
+
+	var pages []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(body, &pages); err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, page := range pages {
+		names = append(names, page.Name)
+	}
+	if fmt.Sprint(names) != "[Acme Globex Initech]" {
+		t.Errorf("pages = %v, want [Acme Globex Initech]", names)
+	}
+}
+
+func TestLinkedInGetCompanyPagesPagedExposesPaging(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/organizationAcls":
+			if q := r.URL.Query(); q.Get("start") != "10" || q.Get("count") != "5" {
+				t.Errorf("start/count = %s/%s, want 10/5", q.Get("start"), q.Get("count"))
+			}
+			fmt.Fprint(w, `{"elements":[
+				{"organizationTarget":"urn:li:organization:1","role":"ADMINISTRATOR"},
+				{"organizationTarget":"urn:li:organization:2","role":"ADMINISTRATOR"}
+			],"paging":{"start":10,"count":5,"total":12}}`)
+		case "/v2/organizations/urn:li:organization:1":
+			fmt.Fprint(w, `{"name":"Acme"}`)
+		case "/v2/organizations/urn:li:organization:2":
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"status":403,"message":"Not enough permissions"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	body, err := c.GetCompanyPagesPaged(10, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var page struct {
+		Pages []struct {
+			Name string `json:"name"`
+		} `json:"pages"`
+		Paging struct {
+			Start int `json:"start"`
+			Count int `json:"count"`
+			Total int `json:"total"`
+		} `json:"paging"`
+	}
+	if err := json.Unmarshal(body, &page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Paging.Start != 10 || page.Paging.Count != 5 || page.Paging.Total != 12 {
+		t.Errorf("paging = %+v", page.Paging)
+	}
+	if len(page.Pages) != 1 || page.Pages[0].Name != "Acme" {
+		t.Errorf("pages = %+v, want only Acme after the failed detail fetch", page.Pages)
+	}
+}
+
+func TestLinkedInGetUserProfile(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/me":
+			fmt.Fprint(w, `{
+				"id": "yrZCpj2Z12",
+				"firstName": {"localized": {"en_US": "Bob"}, "preferredLocale": {"country": "US", "language": "en"}},
+				"lastName": {"localized": {"en_US": "Smith"}, "preferredLocale": {"country": "US", "language": "en"}},
+				"headline": {"localized": {"en_US": "API Enthusiast"}}
+			}`)
+		case "/v2/emailAddress":
+			fmt.Fprint(w, `{"elements":[{"handle":"urn:li:emailAddress:3775708763","handle~":{"emailAddress":"bob@example.com"}}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	body, err := c.GetUserProfile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var profile struct {
+		ID        string `json:"id"`
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+		Headline  string `json:"headline"`
+		Email     string `json:"email"`
+	}
+	if err := json.Unmarshal(body, &profile); err != nil {
+		t.Fatal(err)
+	}
+	if profile.ID != "yrZCpj2Z12" || profile.FirstName != "Bob" || profile.LastName != "Smith" || profile.Headline != "API Enthusiast" {
+		t.Errorf("profile = %+v", profile)
+	}
+	if profile.Email != "bob@example.com" {
+		t.Errorf("email = %q, want bob@example.com", profile.Email)
+	}
+	if c.UserID != "yrZCpj2Z12" {
+		t.Errorf("UserID = %q, want the profile ID", c.UserID)
+	}
+}
+
+func TestLinkedInGetUserProfileErrorBodies(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		want   string
+	}{
+		{"error body", http.StatusOK, `{"serviceErrorCode":100,"message":"Not enough permissions to access: GET /me","status":403}`, "Not enough permissions"},
+		{"numeric id", http.StatusOK, `{"id":12345}`, "missing id"},
+		{"forbidden", http.StatusForbidden, `{"serviceErrorCode":100,"message":"Not enough permissions to access: GET /me","status":403}`, "403"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/v2/me" {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			_, err := c.GetUserProfile()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want one mentioning %q", err, tt.want)
+			}
+			if c.UserID != "" {
+				t.Errorf("UserID = %q after a failed lookup", c.UserID)
+			}
+		})
+	}
+}
+
+func TestLinkedInGetPostMetricsBatchChunks(t *testing.T) {
+	var batches []string
+	var mu sync.Mutex
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/organizationalEntityShareStatistics" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if got := q.Get("organizationalEntity"); got != "urn:li:organization:2414183" {
+			t.Errorf("organizationalEntity = %q", got)
+		}
+
+		param, key := "shares", "share"
+		if q.Has("ugcPosts") {
+			param, key = "ugcPosts", "ugcPost"
+		}
+		list := q.Get(param)
+		if !strings.HasPrefix(list, "List(") || !strings.HasSuffix(list, ")") {
+			t.Fatalf("%s = %q, want a Rest.li list", param, list)
+		}
+		urns := strings.Split(strings.TrimSuffix(strings.TrimPrefix(list, "List("), ")"), ",")
+
+		mu.Lock()
+		batches = append(batches, fmt.Sprintf("%s:%d", param, len(urns)))
+		mu.Unlock()
+
+		elements := make([]string, len(urns))
+		for i, urn := range urns {
+			id := urn[strings.LastIndex(urn, ":")+1:]
+			elements[i] = fmt.Sprintf(`{%q:%q,"totalShareStatistics":{"impressionCount":%s0,"clickCount":1,"likeCount":2,"commentCount":3,"shareCount":4,"engagement":0.05}}`, key, urn, id)
+		}
+		fmt.Fprintf(w, `{"elements":[%s]}`, strings.Join(elements, ","))
+	})
+	c.OrganizationID = "2414183"
+
+	var urns []string
+	for i := 1; i <= 25; i++ {
+		urns = append(urns, fmt.Sprintf("urn:li:share:%d", i))
+	}
+	urns = append(urns, "urn:li:ugcPost:901", "urn:li:ugcPost:902")
+
+	metrics, err := c.GetPostMetricsBatch(urns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(batches) != "[shares:20 shares:5 ugcPosts:2]" {
+		t.Errorf("batches = %v, want 20 and 5 shares then 2 UGC posts", batches)
+	}
+	if len(metrics) != len(urns) {
+		t.Fatalf("got metrics for %d posts, want %d", len(metrics), len(urns))
+	}
+
+	share := metrics["urn:li:share:21"]
+	if share == nil || share.Impressions != 210 || share.Engagement != 10 || share.CTR != 1.0/210 {
+		t.Errorf("urn:li:share:21 metrics = %+v", share)
+	}
+	if ugc := metrics["urn:li:ugcPost:902"]; ugc == nil || ugc.Impressions != 9020 || ugc.EngagementRate != 0.05 {
+		t.Errorf("urn:li:ugcPost:902 metrics = %+v", ugc)
+	}
+}
+
+func TestLinkedInGetPostMetricsBatchRejectsInvalidURN(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+	c.OrganizationID = "2414183"
+
+	if _, err := c.GetPostMetricsBatch([]string{"urn:li:share:1", "urn:li:person:abc"}); err == nil {
+		t.Fatal("expected an error for a non-post URN")
+	}
+}
+
+func TestLinkedInGetAuthURL(t *testing.T) {
+	c := NewLinkedInClient("id", "secret", "http://localhost/callback")
+
+	authURL, err := c.GetAuthURL([]byte(`["r_liteprofile","w_member_social","r_organization_social"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if base := u.Scheme + "://" + u.Host + u.Path; base != AuthURL {
+		t.Errorf("auth URL base = %q, want %q", base, AuthURL)
+	}
+	q := u.Query()
+	if got := q.Get("scope"); got != "r_liteprofile w_member_social r_organization_social" {
+		t.Errorf("scope = %q", got)
+	}
+	if q.Get("client_id") != "id" || q.Get("redirect_uri") != "http://localhost/callback" || q.Get("response_type") != "code" {
+		t.Errorf("query = %v", q)
+	}
+
+	fromSlice, err := c.GetAuthURLWithScopes([]string{"r_liteprofile", "w_member_social", "r_organization_social"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fromSlice != authURL {
+		t.Errorf("GetAuthURLWithScopes = %q, want the same URL as GetAuthURL %q", fromSlice, authURL)
+	}
+}
+
+func TestLinkedInGetAuthURLRejectsInvalidJSON(t *testing.T) {
+	c := NewLinkedInClient("id", "secret", "http://localhost/callback")
+
+	for _, scopes := range []string{
+		``,
+		`r_liteprofile`,
+		`["r_liteprofile"`,
+		`{"scope":"r_liteprofile"}`,
+		`[1, 2]`,
+	} {
+		authURL, err := c.GetAuthURL([]byte(scopes))
+		if err == nil {
+			t.Errorf("GetAuthURL(%q) = %q, want an error", scopes, authURL)
+			continue
+		}
+		if !strings.Contains(err.Error(), "JSON array") {
+			t.Errorf("GetAuthURL(%q) error = %v, want it to explain the expected format", scopes, err)
+		}
+	}
+}
+
+func TestLinkedInGetAuthURLRejectsUnknownScopes(t *testing.T) {
+	c := NewLinkedInClient("id", "secret", "http://localhost/callback")
+
+	tests := []struct {
+		name   string
+		scopes []string
+		want   string
+	}{
+		{"typo", []string{"r_liteprofile", "w_member_socail"}, `"w_member_socail"`},
+		{"wrong case", []string{"R_LITEPROFILE"}, `"R_LITEPROFILE"`},
+		{"padded", []string{" r_liteprofile"}, `" r_liteprofile"`},
+		{"empty scope", []string{""}, `""`},
+		{"none", nil, "at least one scope"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.GetAuthURLWithScopes(tt.scopes)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want it to mention %s", err, tt.want)
+			}
+
+			raw, _ := json.Marshal(tt.scopes)
+			if _, err := c.GetAuthURL(raw); err == nil {
+				t.Errorf("GetAuthURL(%s) succeeded, want an error", raw)
+			}
+		})
+	}
+}
+
+func TestLinkedInGetPollResultsDecodesTallies(t *testing.T) {
+	tests := []struct {
+		name        string
+		publishedAt time.Time
+		duration    string
+		closed      bool
+	}{
+		{"open", time.Now().Add(-time.Hour), "THREE_DAYS", false},
+		{"closed", time.Now().Add(-48 * time.Hour), "ONE_DAY", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodGet || r.URL.Path != "/v2/posts/urn:li:share:7012345678901234567" {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				fmt.Fprintf(w, `{
+					"id": "urn:li:share:7012345678901234567",
+					"author": "urn:li:person:abc123",
+					"commentary": "Quick question for the team",
+					"publishedAt": %d,
+					"lifecycleState": "PUBLISHED",
+					"content": {"poll": {
+						"question": "Tabs or spaces?",
+						"options": [
+							{"text": "Tabs", "voteCount": 12, "isVotedByViewer": false},
+							{"text": "Spaces", "voteCount": 30, "isVotedByViewer": true},
+							{"text": "Both", "voteCount": 0}
+						],
+						"settings": {"duration": %q, "voteSelectionType": "SINGLE_VOTE", "isVoterVisibleToAuthor": false}
+					}}
+				}`, tt.publishedAt.UnixMilli(), tt.duration)
+			})
+
+			results, err := c.GetPollResults("urn:li:share:7012345678901234567")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := []PollOption{{"Tabs", 12}, {"Spaces", 30}, {"Both", 0}}
+			if len(results.Options) != len(want) {
+				t.Fatalf("options = %+v, want %+v", results.Options, want)
+			}
+			for i := range want {
+				if results.Options[i] != want[i] {
+					t.Errorf("option %d = %+v, want %+v", i, results.Options[i], want[i])
+				}
+			}
+			if results.URN != "urn:li:share:7012345678901234567" || results.Question != "Tabs or spaces?" || results.TotalVotes != 42 {
+				t.Errorf("results = %+v", results)
+			}
+			if results.Closed != tt.closed {
+				t.Errorf("Closed = %v, want %v", results.Closed, tt.closed)
+			}
+			wantEnd := time.UnixMilli(tt.publishedAt.UnixMilli()).Add(linkedinPollDurations[tt.duration])
+			if !results.EndsAt.Equal(wantEnd) {
+				t.Errorf("EndsAt = %v, want %v", results.EndsAt, wantEnd)
+			}
+		})
+	}
+}
+
+func TestLinkedInGetPollResultsWithoutPoll(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"urn:li:share:1","commentary":"no poll here","content":{"media":{"id":"urn:li:image:C4E"}}}`)
+	})
+
+	if _, err := c.GetPollResults("urn:li:share:1"); err == nil || !strings.Contains(err.Error(), "has no poll") {
+		t.Errorf("error = %v, want a no poll error", err)
+	}
+}
+
+func TestLinkedInWithHeadersAppliesCustomHeaders(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/networkSizes/urn:li:organization:2414183" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		want := map[string]string{
+			"X-Li-Format":               "json",
+			"LinkedIn-Version":          "202406",
+			"Authorization":             "Bearer token",
+			"X-Restli-Protocol-Version": restliProtocolVersion,
+		}
+		for name, value := range want {
+			if got := r.Header.Get(name); got != value {
+				t.Errorf("%s = %q, want %q", name, got, value)
+			}
+		}
+		fmt.Fprint(w, `{"firstDegreeSize":1520}`)
+	})
+	c.UseVersionedAPI = true
+
+	// LinkedIn-Version replaces the client's default version for this call
+	followers, err := c.GetOrganizationFollowerCountContext(context.Background(), "2414183",
+		WithHeaders(map[string]string{"X-Li-Format": "json", "LinkedIn-Version": "202406"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if followers != 1520 {
+		t.Errorf("followers = %d, want 1520", followers)
+	}
+}
+
+func TestLinkedInVersionedAPISwitch(t *testing.T) {
+	tests := []struct {
+		name        string
+		versioned   bool
+		version     string
+		wantPath    string
+		wantEdge    string
+		wantVersion string
+	}{
+		{"legacy", false, "202406", "/v2/networkSizes/urn:li:organization:2414183", "CompanyFollowedByMember", ""},
+		{"versioned default", true, "", "/rest/networkSizes/urn:li:organization:2414183", "COMPANY_FOLLOWED_BY_MEMBER", defaultLinkedInVersion},
+		{"versioned pinned", true, "202406", "/rest/networkSizes/urn:li:organization:2414183", "COMPANY_FOLLOWED_BY_MEMBER", "202406"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if host := r.Header.Get(originalHostHeader); host != "api.linkedin.com" {
+					t.Errorf("host = %q", host)
+				}
+				if r.URL.Path != tt.wantPath {
+					t.Errorf("path = %s, want %s", r.URL.Path, tt.wantPath)
+				}
+				if got := r.URL.Query().Get("edgeType"); got != tt.wantEdge {
+					t.Errorf("edgeType = %q, want %q", got, tt.wantEdge)
+				}
+				version, sent := r.Header["Linkedin-Version"]
+				if sent != (tt.wantVersion != "") || r.Header.Get("LinkedIn-Version") != tt.wantVersion {
+					t.Errorf("LinkedIn-Version = %v, want %q", version, tt.wantVersion)
+				}
+				fmt.Fprint(w, `{"firstDegreeSize":1520}`)
+			})
+			c.UseVersionedAPI = tt.versioned
+			c.Version = tt.version
+
+			if _, err := c.GetOrganizationFollowerCount("2414183"); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLinkedInVersionedAPIKeepsLegacyEndpoints(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/v2/") {
+			t.Errorf("path = %s, want the legacy API", r.URL.Path)
+		}
+		if got := r.Header.Get("LinkedIn-Version"); got != "" {
+			t.Errorf("LinkedIn-Version = %q sent to a legacy endpoint", got)
+		}
+		switch r.URL.Path {
+		case "/v2/me":
+			fmt.Fprint(w, `{"id":"abc123","localizedFirstName":"Ada","localizedLastName":"Lovelace"}`)
+		case "/v2/emailAddress":
+			fmt.Fprint(w, `{"elements":[{"handle~":{"emailAddress":"ada@example.com"}}]}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	c.UseVersionedAPI = true
+
+	if _, err := c.GetUserProfile(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLinkedInDownloadImage(t *testing.T) {
+	oversized := bytes.Repeat([]byte{0xff}, linkedInMaxImageSize+1)
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		wantErr string
+	}{
+		{"png", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/png")
+			w.Write(pngHeader)
+		}, ""},
+		{"html page", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html>login required</html>")
+		}, "not an image"},
+		{"no content type", func(w http.ResponseWriter, r *http.Request) {
+			w.Header()["Content-Type"] = nil
+			w.Write(pngHeader)
+		}, "not an image"},
+		{"declared too large", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Header().Set("Content-Length", strconv.Itoa(len(oversized)))
+			w.Write(oversized)
+		}, "byte limit"},
+		{"streamed too large", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/jpeg")
+			// flushing before the end makes the response chunked, without a
+			// Content-Length
+			w.Write(oversized[:1024])
+			w.(http.Flusher).Flush()
+			w.Write(oversized[1024:])
+		}, "byte limit"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			t.Setenv("TMPDIR", tmp)
+			c := newTestLinkedInClient(t, tt.handler)
+
+			path, err := c.downloadImage(context.Background(), "https://cdn.example.com/photo")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("downloadImage() error = %v, want %q", err, tt.wantErr)
+				}
+				if left, _ := os.ReadDir(tmp); len(left) != 0 {
+					t.Errorf("left %d temporary files behind", len(left))
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.Remove(path)
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, pngHeader) {
+				t.Errorf("downloaded %q, want the PNG", got)
+			}
+		})
+	}
+}
+
+func TestLinkedInErrorClassifiesTokenErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		want     error // nil for errors that are not about the token
+		wantCode string
+	}{
+		{"versioned expired", http.StatusUnauthorized, `{"status":401,"code":"EXPIRED_ACCESS_TOKEN","message":"The token used in the request has expired"}`, ErrTokenExpired, "EXPIRED_ACCESS_TOKEN"},
+		{"service code expired", http.StatusUnauthorized, `{"serviceErrorCode":65602,"message":"The token used in the request has expired","status":401}`, ErrTokenExpired, "65602"},
+		{"revoked", http.StatusUnauthorized, `{"status":401,"code":"REVOKED_ACCESS_TOKEN","message":"The token used in the request has been revoked by the user"}`, ErrTokenInvalid, "REVOKED_ACCESS_TOKEN"},
+		{"invalid", http.StatusUnauthorized, `{"status":401,"code":"INVALID_ACCESS_TOKEN","message":"Invalid access token"}`, ErrTokenInvalid, "INVALID_ACCESS_TOKEN"},
+		{"service code revoked by user", http.StatusUnauthorized, `{"serviceErrorCode":65601,"message":"The token used in the request has been revoked by the user","status":401}`, ErrTokenInvalid, "65601"},
+		{"empty token", http.StatusUnauthorized, `{"serviceErrorCode":65604,"message":"Empty oauth2 access token","status":401}`, ErrTokenInvalid, "65604"},
+		{"refresh token rejected", http.StatusBadRequest, `{"error":"invalid_grant","error_description":"The provided authorization grant or refresh token is invalid, expired or revoked"}`, ErrTokenInvalid, "invalid_grant"},
+		{"unknown 401 mentioning expiry", http.StatusUnauthorized, `{"message":"Token expired"}`, ErrTokenExpired, ""},
+		{"unknown 401", http.StatusUnauthorized, `not json`, ErrTokenInvalid, ""},
+		{"permission denied", http.StatusForbidden, `{"serviceErrorCode":100,"message":"Not enough permissions to access: ugcPosts.CREATE","status":403}`, nil, ""},
+		{"server error", http.StatusInternalServerError, `{"message":"Internal Server Error","status":500}`, nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Body: io.NopCloser(strings.NewReader(tt.body))}
+			err := linkedinError(resp, "create post")
+
+			var apiErr *APIError
+			if tt.want == nil {
+				if errors.As(err, &apiErr) || errors.Is(err, ErrTokenExpired) || errors.Is(err, ErrTokenInvalid) {
+					t.Fatalf("error = %v, want a plain error", err)
+				}
+				if !strings.Contains(err.Error(), "failed to create post") || !strings.Contains(err.Error(), strconv.Itoa(tt.status)) {
+					t.Errorf("error = %q, want the operation and status", err)
+				}
+				return
+			}
+
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("error = %v, want an *APIError", err)
+			}
+			other := ErrTokenInvalid
+			if tt.want == ErrTokenInvalid {
+				other = ErrTokenExpired
+			}
+			if !errors.Is(err, tt.want) || errors.Is(err, other) {
+				t.Errorf("error = %v, want only %v", err, tt.want)
+			}
+			if apiErr.Platform != "LinkedIn" || apiErr.StatusCode != tt.status || apiErr.Code != tt.wantCode || apiErr.Message == "" {
+				t.Errorf("APIError = %+v", apiErr)
+			}
+		})
+	}
+}
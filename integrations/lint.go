@@ -0,0 +1,136 @@
+package integrations
+
+import (
+	"fmt"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+// LintWarning is a non-fatal issue with post content. The post can still be
+// published, but it may be truncated, rendered poorly or reach fewer people.
+type LintWarning struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Lint warning codes
+const (
+	LintTextTooLong      = "text_too_long"
+	LintTooManyHashtags  = "too_many_hashtags"
+	LintBannedCharacters = "banned_characters"
+	LintMissingAltText   = "missing_alt_text"
+	LintLinkNotClickable = "link_not_clickable"
+	LintUnknownPlatform  = "unknown_platform"
+)
+
+// platformLimits are the content limits of a platform; zero means no limit
+type platformLimits struct {
+	maxChars     int
+	maxHashtags  int
+	linksInert   bool // links in the text are not clickable
+	supportsAlts bool // images can carry alt text
+}
+
+var lintLimits = map[string]platformLimits{
+	PlatformTwitter:   {maxChars: 280, supportsAlts: true},
+	PlatformInstagram: {maxChars: 2200, maxHashtags: 30, linksInert: true, supportsAlts: true},
+	PlatformFacebook:  {maxChars: 63206, supportsAlts: true},
+	PlatformLinkedIn:  {maxChars: 3000, supportsAlts: true},
+	PlatformTikTok:    {maxChars: 2200},
+	PlatformYouTube:   {maxChars: 5000},
+	PlatformPinterest: {maxChars: 500, supportsAlts: true},
+	PlatformThreads:   {maxChars: 500, supportsAlts: true},
+}
+
+// twitterURLLength is the length Twitter counts for any URL, since every link
+// is wrapped with its t.co shortener
+const twitterURLLength = 23
+
+var (
+	urlPattern     = regexp.MustCompile(`https?://\S+`)
+	hashtagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&#])#[\p{L}\p{N}_]+`)
+)
+
+// LintContent checks post against the conventions and limits of platform and
+// returns the issues found. It never fails; an unknown platform yields a
+// single LintUnknownPlatform warning.
+func LintContent(platform string, post UniversalPost) []LintWarning {
+	limits, ok := lintLimits[platform]
+	if !ok {
+		return []LintWarning{{
+			Code:    LintUnknownPlatform,
+			Message: fmt.Sprintf("no lint rules for platform %q", platform),
+		}}
+	}
+
+	var warnings []LintWarning
+
+	length := utf8.RuneCountInString(post.Text)
+	if platform == PlatformTwitter {
+		length = tweetLength(post.Text)
+	}
+	if limits.maxChars > 0 && length > limits.maxChars {
+		warnings = append(warnings, LintWarning{
+			Code:    LintTextTooLong,
+			Message: fmt.Sprintf("text is %d characters, %s allows %d", length, platform, limits.maxChars),
+		})
+	}
+
+	if limits.maxHashtags > 0 {
+		if n := len(hashtagPattern.FindAllString(post.Text, -1)); n > limits.maxHashtags {
+			warnings = append(warnings, LintWarning{
+				Code:    LintTooManyHashtags,
+				Message: fmt.Sprintf("text has %d hashtags, %s allows %d", n, platform, limits.maxHashtags),
+			})
+		}
+	}
+
+	if limits.linksInert && urlPattern.MatchString(post.Text) {
+		warnings = append(warnings, LintWarning{
+			Code:    LintLinkNotClickable,
+			Message: fmt.Sprintf("links in %s captions are not clickable", platform),
+		})
+	}
+
+	if hasControlCharacters(post.Text) {
+		warnings = append(warnings, LintWarning{
+			Code:    LintBannedCharacters,
+			Message: "text contains control characters that platforms strip or reject",
+		})
+	}
+
+	if limits.supportsAlts {
+		for i, media := range post.Media {
+			if media.Type == "image" && media.AltText == "" {
+				warnings = append(warnings, LintWarning{
+					Code:    LintMissingAltText,
+					Message: fmt.Sprintf("image %d has no alt text", i+1),
+				})
+			}
+		}
+	}
+
+	return warnings
+}
+
+// tweetLength returns the length Twitter counts for text, with every URL
+// counted as twitterURLLength characters
+func tweetLength(text string) int {
+	length := utf8.RuneCountInString(text)
+	for _, u := range urlPattern.FindAllString(text, -1) {
+		length += twitterURLLength - utf8.RuneCountInString(u)
+	}
+	return length
+}
+
+// hasControlCharacters reports whether text contains control characters other
+// than line breaks and tabs
+func hasControlCharacters(text string) bool {
+	for _, r := range text {
+		if unicode.IsControl(r) && r != '\n' && r != '\r' && r != '\t' {
+			return true
+		}
+	}
+	return false
+}
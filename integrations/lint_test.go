@@ -0,0 +1,85 @@
+package integrations
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func lintCodes(warnings []LintWarning) []string {
+	codes := []string{}
+	for _, w := range warnings {
+		codes = append(codes, w.Code)
+	}
+	return codes
+}
+
+func TestLintContent(t *testing.T) {
+	longURL := "https://example.com/" + strings.Repeat("a", 300)
+	image := PostMedia{Path: "photo.jpg", Type: "image"}
+
+	tests := []struct {
+		name     string
+		platform string
+		post     UniversalPost
+		want     []string
+	}{
+		{
+			"twitter counts URLs as 23 characters",
+			PlatformTwitter,
+			UniversalPost{Text: "read this " + longURL},
+			[]string{},
+		},
+		{
+			"twitter text too long",
+			PlatformTwitter,
+			UniversalPost{Text: strings.Repeat("a", 281)},
+			[]string{LintTextTooLong},
+		},
+		{
+			"twitter image without alt text",
+			PlatformTwitter,
+			UniversalPost{Text: "hi", Media: []PostMedia{image, {Path: "b.jpg", Type: "image", AltText: "a cat"}}},
+			[]string{LintMissingAltText},
+		},
+		{
+			"instagram hashtags and links",
+			PlatformInstagram,
+			UniversalPost{Text: strings.Repeat("#tag ", 31) + "https://example.com"},
+			[]string{LintTooManyHashtags, LintLinkNotClickable},
+		},
+		{
+			"instagram thirty hashtags are fine",
+			PlatformInstagram,
+			UniversalPost{Text: strings.Repeat("#tag ", 30), Media: []PostMedia{{Type: "image", AltText: "x"}}},
+			[]string{},
+		},
+		{
+			"control characters",
+			PlatformLinkedIn,
+			UniversalPost{Text: "line one\nline\u0000two"},
+			[]string{LintBannedCharacters},
+		},
+		{
+			"tiktok has no alt text",
+			PlatformTikTok,
+			UniversalPost{Text: "dance", Media: []PostMedia{image}},
+			[]string{},
+		},
+		{
+			"unknown platform",
+			"myspace",
+			UniversalPost{Text: "hi"},
+			[]string{LintUnknownPlatform},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := lintCodes(LintContent(tt.platform, tt.post))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("warnings = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package integrations
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metric names passed to a MetricsRecorder
+const (
+	MetricFollowers     = "followers"
+	MetricProfileViews  = "profile_views"
+	MetricReach         = "reach"
+	MetricImpressions   = "impressions"
+	MetricWebsiteClicks = "website_clicks"
+	MetricSubscribers   = "subscribers"
+)
+
+// MetricsRecorder receives account metrics as clients fetch them, so an app
+// can build time series such as follower growth without polling on its own.
+// Clients with a Recorder call it after every successful insights fetch.
+type MetricsRecorder interface {
+	Record(platform, metric string, value float64, at time.Time)
+}
+
+// recordMetric records a value if recorder is set
+func recordMetric(recorder MetricsRecorder, platform, metric string, value float64, at time.Time) {
+	if recorder != nil {
+		recorder.Record(platform, metric, value, at)
+	}
+}
+
+// MetricPoint is a single recorded metric value
+type MetricPoint struct {
+	Platform string    `json:"platform"`
+	Metric   string    `json:"metric"`
+	Value    float64   `json:"value"`
+	At       time.Time `json:"at"`
+}
+
+// MemoryRecorder is a MetricsRecorder that keeps every point in memory. It is
+// safe for concurrent use.
+type MemoryRecorder struct {
+	mu     sync.Mutex
+	points []MetricPoint
+}
+
+// NewMemoryRecorder creates an empty MemoryRecorder
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{}
+}
+
+// Record stores a metric value
+func (r *MemoryRecorder) Record(platform, metric string, value float64, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points = append(r.points, MetricPoint{Platform: platform, Metric: metric, Value: value, At: at})
+}
+
+// Series returns the recorded values of one metric of a platform, oldest first
+func (r *MemoryRecorder) Series(platform, metric string) []MetricPoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var series []MetricPoint
+	for _, point := range r.points {
+		if point.Platform == platform && point.Metric == metric {
+			series = append(series, point)
+		}
+	}
+
+	sort.SliceStable(series, func(i, j int) bool {
+		return series[i].At.Before(series[j].At)
+	})
+	return series
+}
+
+// Points returns a copy of every recorded point in the order they were recorded
+func (r *MemoryRecorder) Points() []MetricPoint {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]MetricPoint(nil), r.points...)
+}
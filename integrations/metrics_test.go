@@ -0,0 +1,126 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestMemoryRecorderSeries(t *testing.T) {
+	r := NewMemoryRecorder()
+	now := time.Now()
+	r.Record(PlatformInstagram, MetricFollowers, 120, now)
+	r.Record(PlatformInstagram, MetricReach, 900, now)
+	r.Record(PlatformInstagram, MetricFollowers, 100, now.Add(-time.Hour))
+	r.Record(PlatformLinkedIn, MetricFollowers, 50, now)
+
+	series := r.Series(PlatformInstagram, MetricFollowers)
+	if len(series) != 2 || series[0].Value != 100 || series[1].Value != 120 {
+		t.Errorf("series = %+v, want the two Instagram follower points oldest first", series)
+	}
+	if points := r.Points(); len(points) != 4 || points[0].Value != 120 {
+		t.Errorf("points = %+v, want all four in recording order", points)
+	}
+}
+
+func TestInsightsFetchesRecordMetrics(t *testing.T) {
+	before := time.Now()
+
+	t.Run("Instagram", func(t *testing.T) {
+		c := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("metric") == "follower_count" {
+				fmt.Fprint(w, `{"data":[{"name":"follower_count","period":"day","values":[{"value":1200},{"value":1240}]}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"data":[
+				{"name":"profile_views","period":"day","total_value":{"value":87}},
+				{"name":"reach","period":"day","total_value":{"value":3400}},
+				{"name":"impressions","period":"day","total_value":{"value":5210}},
+				{"name":"website_clicks","period":"day","total_value":{"value":19}}
+			]}`)
+		})
+		recorder := NewMemoryRecorder()
+		c.Recorder = recorder
+
+		if _, err := c.GetUserInsights("day"); err != nil {
+			t.Fatal(err)
+		}
+		checkRecorded(t, recorder, before, PlatformInstagram, map[string]float64{
+			MetricFollowers:     1240,
+			MetricProfileViews:  87,
+			MetricReach:         3400,
+			MetricImpressions:   5210,
+			MetricWebsiteClicks: 19,
+		})
+	})
+
+	t.Run("LinkedIn", func(t *testing.T) {
+		c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"firstDegreeSize":1520}`)
+		})
+		recorder := NewMemoryRecorder()
+		c.Recorder = recorder
+
+		if _, err := c.GetOrganizationFollowerCount("2414183"); err != nil {
+			t.Fatal(err)
+		}
+		checkRecorded(t, recorder, before, PlatformLinkedIn, map[string]float64{MetricFollowers: 1520})
+	})
+
+	t.Run("YouTube", func(t *testing.T) {
+		_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/youtube/v3/channels" || r.URL.Query().Get("mine") != "true" {
+				t.Errorf("unexpected request %s", r.URL)
+			}
+			fmt.Fprint(w, `{"items":[{"id":"UC_x5XG1OV2P6uZZ5FSM9Ttw","statistics":{"viewCount":"12345","subscriberCount":"4210","hiddenSubscriberCount":false,"videoCount":"42"}}]}`)
+		})
+		c := NewYouTubeClient("token")
+		c.httpClient = client
+		recorder := NewMemoryRecorder()
+		c.Recorder = recorder
+
+		if _, err := c.GetSubscriberCount(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		checkRecorded(t, recorder, before, PlatformYouTube, map[string]float64{MetricSubscribers: 4210})
+	})
+}
+
+func TestFailedInsightsFetchRecordsNothing(t *testing.T) {
+	c := newTestLinkedInClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"status":403,"serviceErrorCode":100,"message":"Not enough permissions to access: GET /networkSizes"}`)
+	})
+	recorder := NewMemoryRecorder()
+	c.Recorder = recorder
+
+	if _, err := c.GetOrganizationFollowerCount("2414183"); err == nil {
+		t.Fatal("expected an error")
+	}
+	if points := recorder.Points(); len(points) != 0 {
+		t.Errorf("recorded %+v after a failed fetch", points)
+	}
+}
+
+// checkRecorded checks that recorder holds exactly one point per metric in
+// want for platform, recorded no earlier than since
+func checkRecorded(t *testing.T, recorder *MemoryRecorder, since time.Time, platform string, want map[string]float64) {
+	t.Helper()
+
+	points := recorder.Points()
+	if len(points) != len(want) {
+		t.Errorf("recorded %d points, want %d: %+v", len(points), len(want), points)
+	}
+	for metric, value := range want {
+		series := recorder.Series(platform, metric)
+		if len(series) != 1 {
+			t.Errorf("%s %s recorded %d times, want once", platform, metric, len(series))
+			continue
+		}
+		if series[0].Value != value || series[0].At.Before(since) {
+			t.Errorf("%s %s = %+v, want %v recorded now", platform, metric, series[0], value)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package integrations
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// oauth1Signer signs requests with OAuth 1.0a HMAC-SHA1, as required by the
+// Twitter v1.1 endpoints (media upload in particular)
+type oauth1Signer struct {
+	consumerKey    string
+	consumerSecret string
+	token          string
+	tokenSecret    string
+
+	// nonce and now are overridable so signatures can be reproduced
+	nonce func() string
+	now   func() time.Time
+}
+
+// newOAuth1Signer creates a signer for the given consumer and token credentials
+func newOAuth1Signer(consumerKey, consumerSecret, token, tokenSecret string) *oauth1Signer {
+	return &oauth1Signer{
+		consumerKey:    consumerKey,
+		consumerSecret: consumerSecret,
+		token:          token,
+		tokenSecret:    tokenSecret,
+		nonce:          randomNonce,
+		now:            time.Now,
+	}
+}
+
+// Sign sets the OAuth Authorization header on req. form holds the
+// application/x-www-form-urlencoded body parameters, which are part of the
+// signature; pass nil for JSON or multipart bodies.
+func (s *oauth1Signer) Sign(req *http.Request, form url.Values) {
+	req.Header.Set("Authorization", s.authorizationHeader(req.Method, req.URL, form))
+}
+
+// authorizationHeader builds the OAuth Authorization header value
+func (s *oauth1Signer) authorizationHeader(method string, u *url.URL, form url.Values) string {
+	oauthParams := map[string]string{
+		"oauth_consumer_key":     s.consumerKey,
+		"oauth_nonce":            s.nonce(),
+		"oauth_signature_method": "HMAC-SHA1",
+		"oauth_timestamp":        strconv.FormatInt(s.now().Unix(), 10),
+		"oauth_version":          "1.0",
+	}
+	if s.token != "" {
+		oauthParams["oauth_token"] = s.token
+	}
+
+	oauthParams["oauth_signature"] = s.signature(s.signatureBase(method, u, form, oauthParams))
+
+	keys := make([]string, 0, len(oauthParams))
+	for k := range oauthParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = fmt.Sprintf(`%s="%s"`, percentEncode(k), percentEncode(oauthParams[k]))
+	}
+
+	return "OAuth " + strings.Join(parts, ", ")
+}
+
+// signatureBase builds the OAuth signature base string from the request
+// method, URL without query, and all query, body and oauth parameters
+func (s *oauth1Signer) signatureBase(method string, u *url.URL, form url.Values, oauthParams map[string]string) string {
+	var pairs []string
+	for k, vs := range u.Query() {
+		for _, v := range vs {
+			pairs = append(pairs, percentEncode(k)+"="+percentEncode(v))
+		}
+	}
+	for k, vs := range form {
+		for _, v := range vs {
+			pairs = append(pairs, percentEncode(k)+"="+percentEncode(v))
+		}
+	}
+	for k, v := range oauthParams {
+		pairs = append(pairs, percentEncode(k)+"="+percentEncode(v))
+	}
+	sort.Strings(pairs)
+
+	baseURL := fmt.Sprintf("%s://%s%s", strings.ToLower(u.Scheme), strings.ToLower(u.Host), u.EscapedPath())
+
+	return strings.ToUpper(method) + "&" + percentEncode(baseURL) + "&" + percentEncode(strings.Join(pairs, "&"))
+}
+
+// signature computes the base64 HMAC-SHA1 of the signature base string
+func (s *oauth1Signer) signature(base string) string {
+	key := percentEncode(s.consumerSecret) + "&" + percentEncode(s.tokenSecret)
+	mac := hmac.New(sha1.New, []byte(key))
+	mac.Write([]byte(base))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// percentEncode encodes s as specified by RFC 3986, which OAuth 1.0a requires
+func percentEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('A' <= ch && ch <= 'Z') || ('a' <= ch && ch <= 'z') || ('0' <= ch && ch <= '9') ||
+			ch == '-' || ch == '.' || ch == '_' || ch == '~' {
+			b.WriteByte(ch)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", ch)
+	}
+	return b.String()
+}
+
+// randomNonce returns a random hex string for oauth_nonce
+func randomNonce() string {
+	buf := make([]byte, 16)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
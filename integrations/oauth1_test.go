@@ -0,0 +1,64 @@
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestOAuth1SignatureKnownVector signs the example request from Twitter's
+// "Creating a signature" documentation and checks the documented signature
+func TestOAuth1SignatureKnownVector(t *testing.T) {
+	s := newOAuth1Signer(
+		"xvz1evFS4wEEPTGEFPHBog",
+		"kAcSOqF21Fu85e7zjz7ZN2U4ZRhfV3WpwPAoE3Z7kBw",
+		"370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb",
+		"LswwdoUaIvS8ltyTt5jkRh4J50vUPVVHtR2YPi5kE",
+	)
+	s.nonce = func() string { return "kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg" }
+	s.now = func() time.Time { return time.Unix(1318622958, 0) }
+
+	form := url.Values{"status": {"Hello Ladies + Gentlemen, a signed OAuth request!"}}
+	req, err := http.NewRequest("POST", "https://api.twitter.com/1.1/statuses/update.json?include_entities=true",
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s.Sign(req, form)
+
+	header := req.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "OAuth ") {
+		t.Fatalf("Authorization = %q, want an OAuth header", header)
+	}
+	for _, want := range []string{
+		`oauth_consumer_key="xvz1evFS4wEEPTGEFPHBog"`,
+		`oauth_nonce="kYjzVBB8Y0ZFabxSWbWovY3uYSQ2pTgmZeNu2VS4cg"`,
+		`oauth_signature="hCtSmYh%2BiHYCEqBWrE7C7hYmtUk%3D"`,
+		`oauth_signature_method="HMAC-SHA1"`,
+		`oauth_timestamp="1318622958"`,
+		`oauth_token="370773112-GmHxMAgYyLbNEtIKZeRNFsMKPR9EyMZeS9weJAEb"`,
+		`oauth_version="1.0"`,
+	} {
+		if !strings.Contains(header, want) {
+			t.Errorf("Authorization %q is missing %s", header, want)
+		}
+	}
+}
+
+func TestPercentEncode(t *testing.T) {
+	tests := map[string]string{
+		"Ladies + Gentlemen": "Ladies%20%2B%20Gentlemen",
+		"An encoded string!": "An%20encoded%20string%21",
+		"Dogs, Cats & Mice":  "Dogs%2C%20Cats%20%26%20Mice",
+		"☃":                  "%E2%98%83",
+		"-._~":               "-._~",
+	}
+	for in, want := range tests {
+		if got := percentEncode(in); got != want {
+			t.Errorf("percentEncode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -2,11 +2,14 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"mime"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,12 +33,19 @@ type Pin struct {
 	ImageURL    string `json:"image_url,omitempty"`
 }
 
-// Comment represents a Pinterest comment
+// PinterestComment represents a Pinterest comment
 type PinterestComment struct {
-	ID        string `json:"id,omitempty"`
-	Text      string `json:"text,omitempty"`
-	CreatedAt string `json:"created_at,omitempty"`
-	PinID     string `json:"pin_id,omitempty"`
+	ID        string                  `json:"id,omitempty"`
+	Text      string                  `json:"text,omitempty"`
+	CreatedAt string                  `json:"created_at,omitempty"` // e.g. "2024-01-02T15:04:05", no zone
+	PinID     string                  `json:"pin_id,omitempty"`
+	Author    *PinterestCommentAuthor `json:"user,omitempty"`
+}
+
+// PinterestCommentAuthor is the user who wrote a comment
+type PinterestCommentAuthor struct {
+	ID       string `json:"id,omitempty"`
+	Username string `json:"username,omitempty"`
 }
 
 // Stats represents statistics for a Pinterest resource
@@ -75,6 +85,11 @@ func NewPinterest(accessToken string) *Pinterest {
 
 // CreatePin creates a new pin on Pinterest
 func (c *Pinterest) CreatePin(pin Pin) (*Pin, error) {
+	return c.CreatePinContext(context.Background(), pin)
+}
+
+// CreatePinContext is CreatePin with a context
+func (c *Pinterest) CreatePinContext(ctx context.Context, pin Pin) (*Pin, error) {
 	url := fmt.Sprintf("%s/pins", c.BaseURL)
 
 	pinJSON, err := json.Marshal(pin)
@@ -82,7 +97,7 @@ func (c *Pinterest) CreatePin(pin Pin) (*Pin, error) {
 		return nil, fmt.Errorf("error marshaling pin: %w", err)
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(pinJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(pinJSON))
 	if err != nil {
 		return nil, err
 	}
@@ -102,15 +117,58 @@ func (c *Pinterest) CreatePin(pin Pin) (*Pin, error) {
 	}
 
 	var result Pin
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
+// UploadMediaForPin uploads an image or video to Pinterest and returns a media
+// ID. The media type is detected from the file contents, falling back to the
+// file extension.
+func (c *Pinterest) UploadMediaForPin(mediaPath string) (string, error) {
+	return c.UploadMediaForPinContext(context.Background(), mediaPath)
+}
+
+// UploadMediaForPinContext is UploadMediaForPin with a context
+func (c *Pinterest) UploadMediaForPinContext(ctx context.Context, mediaPath string) (string, error) {
+	contentType, err := detectContentType(mediaPath)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return c.uploadImage(ctx, mediaPath)
+	case strings.HasPrefix(contentType, "video/"):
+		return c.UploadVideoForPinContext(ctx, mediaPath)
+	}
+
+	return "", fmt.Errorf("unsupported media type %q for %s", contentType, filepath.Base(mediaPath))
+}
+
 // UploadImageForPin uploads an image to Pinterest and returns a media ID
 func (c *Pinterest) UploadImageForPin(imagePath string) (string, error) {
+	return c.UploadImageForPinContext(context.Background(), imagePath)
+}
+
+// UploadImageForPinContext is UploadImageForPin with a context
+func (c *Pinterest) UploadImageForPinContext(ctx context.Context, imagePath string) (string, error) {
+	contentType, err := detectContentType(imagePath)
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("%s is not an image (%s), use UploadMediaForPin", filepath.Base(imagePath), contentType)
+	}
+
+	return c.uploadImage(ctx, imagePath)
+}
+
+// uploadImage uploads an image file as multipart form data
+func (c *Pinterest) uploadImage(ctx context.Context, imagePath string) (string, error) {
 	url := fmt.Sprintf("%s/media", c.BaseURL)
 
 	file, err := os.Open(imagePath)
@@ -134,7 +192,7 @@ func (c *Pinterest) UploadImageForPin(imagePath string) (string, error) {
 
 	writer.Close()
 
-	req, err := http.NewRequest("POST", url, body)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, body)
 	if err != nil {
 		return "", err
 	}
@@ -157,98 +215,232 @@ func (c *Pinterest) UploadImageForPin(imagePath string) (string, error) {
 		MediaID string `json:"media_id"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return "", err
 	}
 
 	return result.MediaID, nil
 }
 
-// -----------------------------------------------
-// 2. Reply to Comment Functions
-// -----------------------------------------------
+// UploadVideoForPin registers a video upload with Pinterest, uploads the file
+// to the returned upload URL and returns the media ID. Pinterest processes the
+// video asynchronously, so the pin can only be created once it is ready.
+func (c *Pinterest) UploadVideoForPin(videoPath string) (string, error) {
+	return c.UploadVideoForPinContext(context.Background(), videoPath)
+}
 
-// GetComments gets comments on a pin
-func (c *Pinterest) GetComments(pinID string) ([]Comment, error) {
-	url := fmt.Sprintf("%s/pins/%s/comments", c.BaseURL, pinID)
+// UploadVideoForPinContext is UploadVideoForPin with a context
+func (c *Pinterest) UploadVideoForPinContext(ctx context.Context, videoPath string) (string, error) {
+	url := fmt.Sprintf("%s/media", c.BaseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	payload, err := json.Marshal(map[string]string{
+		"media_type": "video",
+	})
 	if err != nil {
-		return nil, err
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
+	if err != nil {
+		return "", err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPPinterest.Do(req)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to get comments: %s, status code: %d", string(body), resp.StatusCode)
+		return "", fmt.Errorf("failed to register video upload: %s, status code: %d", string(body), resp.StatusCode)
 	}
 
-	var result struct {
-		Items []Comment `json:"items"`
+	var registration struct {
+		MediaID          string            `json:"media_id"`
+		UploadURL        string            `json:"upload_url"`
+		UploadParameters map[string]string `json:"upload_parameters"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+	if err := decodeJSONBody(resp, &registration); err != nil {
+		return "", err
 	}
 
-	return result.Items, nil
+	if registration.UploadURL == "" {
+		return "", fmt.Errorf("video upload registration returned no upload URL")
+	}
+
+	if err := c.uploadToStorage(ctx, registration.UploadURL, registration.UploadParameters, videoPath); err != nil {
+		return "", err
+	}
+
+	return registration.MediaID, nil
 }
 
-// AddComment adds a comment to a pin
-func (c *Pinterest) AddComment(pinID, text string) (*Comment, error) {
-	url := fmt.Sprintf("%s/pins/%s/comments", c.BaseURL, pinID)
+// uploadToStorage posts a file to the storage URL returned by a media
+// registration. The signed upload parameters must precede the file field.
+func (c *Pinterest) uploadToStorage(ctx context.Context, uploadURL string, params map[string]string, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
 
-	payload := map[string]string{
-		"text": text,
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	for key, value := range params {
+		if err := writer.WriteField(key, value); err != nil {
+			return err
+		}
 	}
 
-	payloadJSON, err := json.Marshal(payload)
+	part, err := writer.CreateFormFile("file", filepath.Base(path))
 	if err != nil {
-		return nil, err
+		return err
+	}
+
+	if _, err := io.Copy(part, file); err != nil {
+		return err
+	}
+
+	writer.Close()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, body)
+	if err != nil {
+		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadJSON))
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := c.HTTPPinterest.Do(req)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to upload video: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	return nil
+}
+
+// detectContentType sniffs the MIME type of a file from its first bytes,
+// falling back to the extension when the contents are not recognised
+func detectContentType(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+
+	contentType := http.DetectContentType(head[:n])
+	if contentType == "application/octet-stream" {
+		if byExt := mime.TypeByExtension(filepath.Ext(path)); byExt != "" {
+			contentType = byExt
+		}
+	}
+
+	if i := strings.Index(contentType, ";"); i >= 0 {
+		contentType = contentType[:i]
+	}
+
+	return contentType, nil
+}
+
+// -----------------------------------------------
+// 2. Reply to Comment Functions
+// -----------------------------------------------
+
+// GetComments gets all comments on a pin, following the pagination bookmarks
+func (c *Pinterest) GetComments(pinID string) ([]PinterestComment, error) {
+	return c.GetCommentsContext(context.Background(), pinID)
+}
+
+// GetCommentsContext is GetComments with a context
+func (c *Pinterest) GetCommentsContext(ctx context.Context, pinID string) ([]PinterestComment, error) {
+	var comments []PinterestComment
+	bookmark := ""
+	for {
+		page, next, err := c.GetCommentsPageContext(ctx, pinID, bookmark)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, page...)
+
+		if next == "" {
+			return comments, nil
+		}
+		bookmark = next
+	}
+}
+
+// GetCommentsPage gets one page of comments on a pin. Pass an empty bookmark
+// for the first page, then the returned bookmark to resume from where the
+// previous page ended; the returned bookmark is empty after the last page.
+func (c *Pinterest) GetCommentsPage(pinID, bookmark string) ([]PinterestComment, string, error) {
+	return c.GetCommentsPageContext(context.Background(), pinID, bookmark)
+}
+
+// GetCommentsPageContext is GetCommentsPage with a context
+func (c *Pinterest) GetCommentsPageContext(ctx context.Context, pinID, bookmark string) ([]PinterestComment, string, error) {
+	endpoint := fmt.Sprintf("%s/pins/%s/comments", c.BaseURL, pinID)
+	if bookmark != "" {
+		endpoint += "?bookmark=" + url.QueryEscape(bookmark)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, "", err
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
-	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.HTTPPinterest.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to add comment: %s, status code: %d", string(body), resp.StatusCode)
+		return nil, "", fmt.Errorf("failed to get comments: %s, status code: %d", string(body), resp.StatusCode)
 	}
 
-	var result Comment
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, err
+	var result struct {
+		Items    []PinterestComment `json:"items"`
+		Bookmark string             `json:"bookmark"`
 	}
 
-	return &result, nil
+	if err := decodeJSONBody(resp, &result); err != nil {
+		return nil, "", err
+	}
+
+	return result.Items, result.Bookmark, nil
+}
+
+// AddComment adds a comment to a pin
+func (c *Pinterest) AddComment(pinID, text string) (*PinterestComment, error) {
+	return c.AddCommentContext(context.Background(), pinID, text)
 }
 
-// ReplyToComment adds a reply to an existing comment
-// Note: In Pinterest's API, a reply is just another comment that references the parent comment
-func (c *Pinterest) ReplyToComment(pinID, parentCommentID, text string) (*Comment, error) {
+// AddCommentContext is AddComment with a context
+func (c *Pinterest) AddCommentContext(ctx context.Context, pinID, text string) (*PinterestComment, error) {
 	url := fmt.Sprintf("%s/pins/%s/comments", c.BaseURL, pinID)
 
 	payload := map[string]string{
-		"text":              text,
-		"parent_comment_id": parentCommentID,
+		"text": text,
 	}
 
 	payloadJSON, err := json.Marshal(payload)
@@ -256,7 +448,7 @@ func (c *Pinterest) ReplyToComment(pinID, parentCommentID, text string) (*Commen
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payloadJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payloadJSON))
 	if err != nil {
 		return nil, err
 	}
@@ -272,30 +464,43 @@ func (c *Pinterest) ReplyToComment(pinID, parentCommentID, text string) (*Commen
 
 	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("failed to reply to comment: %s, status code: %d", string(body), resp.StatusCode)
+		return nil, fmt.Errorf("failed to add comment: %s, status code: %d", string(body), resp.StatusCode)
 	}
 
-	var result Comment
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	var result PinterestComment
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
+// ReplyToComment would add a threaded reply to an existing comment. The
+// Pinterest v5 API has no way to attach a comment to a parent comment, so this
+// always returns ErrUnsupported; use AddComment to comment on the pin itself.
+func (c *Pinterest) ReplyToComment(pinID, parentCommentID, text string) (*PinterestComment, error) {
+	return nil, fmt.Errorf("%w: Pinterest does not support threaded replies to comment %s on pin %s",
+		ErrUnsupported, parentCommentID, pinID)
+}
+
 // -----------------------------------------------
 // 3. Get Stats Functions
 // -----------------------------------------------
 
 // GetPinStats gets analytics for a specific pin
 func (c *Pinterest) GetPinStats(pinID string, timeframe string) (*Stats, error) {
+	return c.GetPinStatsContext(context.Background(), pinID, timeframe)
+}
+
+// GetPinStatsContext is GetPinStats with a context
+func (c *Pinterest) GetPinStatsContext(ctx context.Context, pinID string, timeframe string) (*Stats, error) {
 	if timeframe == "" {
 		timeframe = "30days" // Default timeframe
 	}
 
 	url := fmt.Sprintf("%s/pins/%s/analytics?timeframe=%s", c.BaseURL, pinID, timeframe)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -314,22 +519,59 @@ func (c *Pinterest) GetPinStats(pinID string, timeframe string) (*Stats, error)
 	}
 
 	var result Stats
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	return &result, nil
 }
 
+// GetStats implements StatsProvider with the pin's GetPinStats over the
+// default timeframe. Views are impressions (video views for video pins),
+// Likes are reactions and Shares are saves, Pinterest's repin. Engagement
+// uses Pinterest's own engagement count, which also includes clicks.
+func (c *Pinterest) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	stats, err := c.GetPinStatsContext(ctx, postID, "")
+	if err != nil {
+		return PostStats{}, err
+	}
+	return stats.PostStats(), nil
+}
+
+// PostStats converts pin stats into the shared PostStats, see GetStats
+func (s *Stats) PostStats() PostStats {
+	views := int64(s.Impressions)
+	if s.VideoViews > 0 {
+		views = int64(s.VideoViews)
+	}
+	interactions := int64(s.Engagements)
+	if interactions == 0 {
+		interactions = int64(s.Likes + s.Comments + s.Saves + s.Clicks)
+	}
+
+	return PostStats{
+		Views:      views,
+		Likes:      int64(s.Likes),
+		Comments:   int64(s.Comments),
+		Shares:     int64(s.Saves),
+		Engagement: engagementPercent(interactions, views),
+	}
+}
+
 // GetBoardStats gets analytics for a specific board
 func (c *Pinterest) GetBoardStats(boardID string, timeframe string) (*Stats, error) {
+	return c.GetBoardStatsContext(context.Background(), boardID, timeframe)
+}
+
+// GetBoardStatsContext is GetBoardStats with a context
+func (c *Pinterest) GetBoardStatsContext(ctx context.Context, boardID string, timeframe string) (*Stats, error) {
 	if timeframe == "" {
 		timeframe = "30days" // Default timeframe
 	}
 
 	url := fmt.Sprintf("%s/boards/%s/analytics?timeframe=%s", c.BaseURL, boardID, timeframe)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -348,7 +590,7 @@ func (c *Pinterest) GetBoardStats(boardID string, timeframe string) (*Stats, err
 	}
 
 	var result Stats
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
@@ -357,13 +599,18 @@ func (c *Pinterest) GetBoardStats(boardID string, timeframe string) (*Stats, err
 
 // GetUserStats gets analytics for the authenticated user account
 func (c *Pinterest) GetUserStats(timeframe string) (*Stats, error) {
+	return c.GetUserStatsContext(context.Background(), timeframe)
+}
+
+// GetUserStatsContext is GetUserStats with a context
+func (c *Pinterest) GetUserStatsContext(ctx context.Context, timeframe string) (*Stats, error) {
 	if timeframe == "" {
 		timeframe = "30days" // Default timeframe
 	}
 
 	url := fmt.Sprintf("%s/user/analytics?timeframe=%s", c.BaseURL, timeframe)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -382,7 +629,7 @@ func (c *Pinterest) GetUserStats(timeframe string) (*Stats, error) {
 	}
 
 	var result Stats
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
@@ -404,6 +651,12 @@ func GetOAuthURL(PinterestID, redirectURI, state string, scopes []string) string
 
 // ExchangeCodeForToken exchanges an authorization code for an access token
 func ExchangeCodeForToken(PinterestID, PinterestSecret, code, redirectURI string) (string, error) {
+	return ExchangeCodeForTokenWithClient(&http.Client{}, PinterestID, PinterestSecret, code, redirectURI)
+}
+
+// ExchangeCodeForTokenWithClient is ExchangeCodeForToken sending the request
+// with client
+func ExchangeCodeForTokenWithClient(client *http.Client, PinterestID, PinterestSecret, code, redirectURI string) (string, error) {
 	url := "https://api.pinterest.com/v5/oauth/token"
 
 	data := map[string]string{
@@ -426,8 +679,7 @@ func ExchangeCodeForToken(PinterestID, PinterestSecret, code, redirectURI string
 
 	req.Header.Set("Content-Type", "application/json")
 
-	Pinterest := &http.Client{}
-	resp, err := Pinterest.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
 	}
@@ -442,7 +694,7 @@ func ExchangeCodeForToken(PinterestID, PinterestSecret, code, redirectURI string
 		AccessToken string `json:"access_token"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return "", err
 	}
 
@@ -455,9 +707,14 @@ func ExchangeCodeForToken(PinterestID, PinterestSecret, code, redirectURI string
 
 // GetUserInfo gets information about the authenticated user
 func (c *Pinterest) GetUserInfo() (map[string]interface{}, error) {
+	return c.GetUserInfoContext(context.Background())
+}
+
+// GetUserInfoContext is GetUserInfo with a context
+func (c *Pinterest) GetUserInfoContext(ctx context.Context) (map[string]interface{}, error) {
 	url := fmt.Sprintf("%s/user_account", c.BaseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -476,7 +733,7 @@ func (c *Pinterest) GetUserInfo() (map[string]interface{}, error) {
 	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
@@ -489,13 +746,18 @@ func (c *Pinterest) GetUserInfo() (map[string]interface{}, error) {
 
 // SearchPins searches for pins with the given query
 func (c *Pinterest) SearchPins(query string, limit int) ([]Pin, error) {
+	return c.SearchPinsContext(context.Background(), query, limit)
+}
+
+// SearchPinsContext is SearchPins with a context
+func (c *Pinterest) SearchPinsContext(ctx context.Context, query string, limit int) ([]Pin, error) {
 	if limit <= 0 {
 		limit = 25 // Default limit
 	}
 
 	url := fmt.Sprintf("%s/pins/search?query=%s&limit=%d", c.BaseURL, query, limit)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -517,19 +779,152 @@ func (c *Pinterest) SearchPins(query string, limit int) ([]Pin, error) {
 		Items []Pin `json:"items"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
 	return result.Items, nil
 }
 
+// GetUserPins gets one page of the public pins of another user. Pass an empty
+// bookmark for the first page, then the returned bookmark to resume; it is
+// empty after the last page. ErrNotFound is returned for an unknown username
+// and ErrPrivateProfile when the user's pins are not visible.
+func (c *Pinterest) GetUserPins(username, bookmark string) ([]Pin, string, error) {
+	return c.GetUserPinsContext(context.Background(), username, bookmark)
+}
+
+// GetUserPinsContext is GetUserPins with a context
+func (c *Pinterest) GetUserPinsContext(ctx context.Context, username, bookmark string) ([]Pin, string, error) {
+	endpoint := fmt.Sprintf("%s/users/%s/pins", c.BaseURL, url.PathEscape(username))
+	if bookmark != "" {
+		endpoint += "?bookmark=" + url.QueryEscape(bookmark)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+
+	resp, err := c.HTTPPinterest.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return nil, "", fmt.Errorf("pinterest user %q: %w", username, ErrNotFound)
+	case http.StatusForbidden:
+		return nil, "", fmt.Errorf("pinterest user %q: %w", username, ErrPrivateProfile)
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, "", fmt.Errorf("failed to get user pins: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	var result struct {
+		Items    []Pin  `json:"items"`
+		Bookmark string `json:"bookmark"`
+	}
+
+	if err := decodeJSONBody(resp, &result); err != nil {
+		return nil, "", err
+	}
+
+	return result.Items, result.Bookmark, nil
+}
+
+// PinDetail is the full representation of a pin returned by the pin endpoints
+type PinDetail struct {
+	ID             string `json:"id"`
+	CreatedAt      string `json:"created_at"` // UTC, without a zone offset
+	Link           string `json:"link,omitempty"`
+	Title          string `json:"title,omitempty"`
+	Description    string `json:"description,omitempty"`
+	AltText        string `json:"alt_text,omitempty"`
+	Note           string `json:"note,omitempty"`
+	DominantColor  string `json:"dominant_color,omitempty"`
+	BoardID        string `json:"board_id,omitempty"`
+	BoardSectionID string `json:"board_section_id,omitempty"`
+	BoardOwner     struct {
+		Username string `json:"username"`
+	} `json:"board_owner"`
+	ParentPinID  string                      `json:"parent_pin_id,omitempty"`
+	CreativeType string                      `json:"creative_type,omitempty"`
+	IsOwner      bool                        `json:"is_owner"`
+	IsStandard   bool                        `json:"is_standard"`
+	Media        PinMedia                    `json:"media"`
+	PinMetrics   map[string]map[string]int64 `json:"pin_metrics,omitempty"` // keyed by range, e.g. "90d" or "lifetime_metrics"
+}
+
+// PinMedia is the media of a pin. Images carries one entry per size, keyed
+// like "150x150" or "originals"; the video fields are set for video pins.
+type PinMedia struct {
+	MediaType     string              `json:"media_type"`
+	Images        map[string]PinImage `json:"images,omitempty"`
+	CoverImageURL string              `json:"cover_image_url,omitempty"`
+	VideoURL      string              `json:"video_url,omitempty"`
+	Duration      float64             `json:"duration,omitempty"`
+	Width         int                 `json:"width,omitempty"`
+	Height        int                 `json:"height,omitempty"`
+}
+
+// PinImage is one size of a pin image
+type PinImage struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
+}
+
+// GetPin retrieves the full details of a pin, including its metrics
+func (c *Pinterest) GetPin(pinID string) (*PinDetail, error) {
+	return c.GetPinContext(context.Background(), pinID)
+}
+
+// GetPinContext is GetPin with a context
+func (c *Pinterest) GetPinContext(ctx context.Context, pinID string) (*PinDetail, error) {
+	url := fmt.Sprintf("%s/pins/%s?pin_metrics=true", c.BaseURL, pinID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+
+	resp, err := c.HTTPPinterest.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to get pin: %s, status code: %d", string(body), resp.StatusCode)
+	}
+
+	var pin PinDetail
+	if err := decodeJSONBody(resp, &pin); err != nil {
+		return nil, err
+	}
+
+	return &pin, nil
+}
+
 // -----------------------------------------------
 // 7. Board Management Functions
 // -----------------------------------------------
 
 // CreateBoard creates a new board
 func (c *Pinterest) CreateBoard(board Board) (*Board, error) {
+	return c.CreateBoardContext(context.Background(), board)
+}
+
+// CreateBoardContext is CreateBoard with a context
+func (c *Pinterest) CreateBoardContext(ctx context.Context, board Board) (*Board, error) {
 	url := fmt.Sprintf("%s/boards", c.BaseURL)
 
 	boardJSON, err := json.Marshal(board)
@@ -537,7 +932,7 @@ func (c *Pinterest) CreateBoard(board Board) (*Board, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(boardJSON))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(boardJSON))
 	if err != nil {
 		return nil, err
 	}
@@ -557,7 +952,7 @@ func (c *Pinterest) CreateBoard(board Board) (*Board, error) {
 	}
 
 	var result Board
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
@@ -566,6 +961,11 @@ func (c *Pinterest) CreateBoard(board Board) (*Board, error) {
 
 // UpdateBoard updates an existing board
 func (c *Pinterest) UpdateBoard(boardID string, board Board) (*Board, error) {
+	return c.UpdateBoardContext(context.Background(), boardID, board)
+}
+
+// UpdateBoardContext is UpdateBoard with a context
+func (c *Pinterest) UpdateBoardContext(ctx context.Context, boardID string, board Board) (*Board, error) {
 	url := fmt.Sprintf("%s/boards/%s", c.BaseURL, boardID)
 
 	boardJSON, err := json.Marshal(board)
@@ -573,7 +973,7 @@ func (c *Pinterest) UpdateBoard(boardID string, board Board) (*Board, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PATCH", url, bytes.NewBuffer(boardJSON))
+	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewBuffer(boardJSON))
 	if err != nil {
 		return nil, err
 	}
@@ -593,7 +993,7 @@ func (c *Pinterest) UpdateBoard(boardID string, board Board) (*Board, error) {
 	}
 
 	var result Board
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
@@ -602,9 +1002,14 @@ func (c *Pinterest) UpdateBoard(boardID string, board Board) (*Board, error) {
 
 // GetBoards gets all boards for the authenticated user
 func (c *Pinterest) GetBoards() ([]Board, error) {
+	return c.GetBoardsContext(context.Background())
+}
+
+// GetBoardsContext is GetBoards with a context
+func (c *Pinterest) GetBoardsContext(ctx context.Context) ([]Board, error) {
 	url := fmt.Sprintf("%s/boards", c.BaseURL)
 
-	req, err := http.NewRequest("GET", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -626,7 +1031,7 @@ func (c *Pinterest) GetBoards() ([]Board, error) {
 		Items []Board `json:"items"`
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, err
 	}
 
@@ -639,6 +1044,11 @@ func (c *Pinterest) GetBoards() ([]Board, error) {
 
 // FollowUser follows a user
 func (c *Pinterest) FollowUser(username string) error {
+	return c.FollowUserContext(context.Background(), username)
+}
+
+// FollowUserContext is FollowUser with a context
+func (c *Pinterest) FollowUserContext(ctx context.Context, username string) error {
 	url := fmt.Sprintf("%s/user/follows/users/", c.BaseURL)
 
 	data := map[string]string{
@@ -650,7 +1060,7 @@ func (c *Pinterest) FollowUser(username string) error {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(payload))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(payload))
 	if err != nil {
 		return err
 	}
@@ -674,9 +1084,14 @@ func (c *Pinterest) FollowUser(username string) error {
 
 // UnfollowUser unfollows a user
 func (c *Pinterest) UnfollowUser(username string) error {
+	return c.UnfollowUserContext(context.Background(), username)
+}
+
+// UnfollowUserContext is UnfollowUser with a context
+func (c *Pinterest) UnfollowUserContext(ctx context.Context, username string) error {
 	url := fmt.Sprintf("%s/user/follows/users/%s", c.BaseURL, username)
 
-	req, err := http.NewRequest("DELETE", url, nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
 		return err
 	}
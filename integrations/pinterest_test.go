@@ -0,0 +1,369 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Minimal file headers recognised by http.DetectContentType
+var (
+	pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+	mp4Header = []byte("\x00\x00\x00\x18ftypmp42\x00\x00\x00\x00mp42isom")
+)
+
+func newTestPinterest(t *testing.T, handler http.HandlerFunc) (*Pinterest, string) {
+	t.Helper()
+
+	srv, client := newTestServer(t, handler)
+	c := NewPinterest("token")
+	c.BaseURL = srv.URL + "/v5"
+	c.HTTPPinterest = client
+	return c, srv.URL
+}
+
+// writeTempFile writes data to a file named name in a test directory
+func writeTempFile(t *testing.T, name string, data []byte) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPinterestUploadMediaRoutesImages(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/media" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			t.Errorf("Content-Type = %q, want multipart", r.Header.Get("Content-Type"))
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Close()
+		if header.Filename != "photo.png" {
+			t.Errorf("filename = %q", header.Filename)
+		}
+		fmt.Fprint(w, `{"media_id":"img-1"}`)
+	})
+
+	mediaID, err := c.UploadMediaForPin(writeTempFile(t, "photo.png", pngHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaID != "img-1" {
+		t.Errorf("media ID = %q, want img-1", mediaID)
+	}
+}
+
+func TestPinterestUploadMediaRoutesVideos(t *testing.T) {
+	var registered, uploaded bool
+	var storageURL string
+	c, base := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v5/media":
+			registered = true
+			var payload map[string]string
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload["media_type"] != "video" {
+				t.Errorf("media_type = %q, want video", payload["media_type"])
+			}
+			fmt.Fprintf(w, `{"media_id":"vid-1","upload_url":%q,"upload_parameters":{"key":"uploads/vid-1"}}`, storageURL)
+		case "/storage":
+			uploaded = true
+			if r.Header.Get("Authorization") != "" {
+				t.Error("the signed storage upload must not carry the API token")
+			}
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatal(err)
+			}
+			if got := r.FormValue("key"); got != "uploads/vid-1" {
+				t.Errorf("key = %q", got)
+			}
+			if _, _, err := r.FormFile("file"); err != nil {
+				t.Errorf("file field: %v", err)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	storageURL = base + "/storage"
+
+	mediaID, err := c.UploadMediaForPin(writeTempFile(t, "clip.mp4", mp4Header))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaID != "vid-1" || !registered || !uploaded {
+		t.Errorf("media ID = %q, registered %v, uploaded %v", mediaID, registered, uploaded)
+	}
+}
+
+func TestPinterestUploadMediaRejectsUnsupportedTypes(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	if _, err := c.UploadMediaForPin(writeTempFile(t, "notes.txt", []byte("plain text"))); err == nil {
+		t.Error("UploadMediaForPin accepted a text file")
+	}
+	if _, err := c.UploadImageForPin(writeTempFile(t, "clip.mp4", mp4Header)); err == nil {
+		t.Error("UploadImageForPin accepted a video")
+	}
+}
+
+func TestPinterestGetPinDecodesFullPayload(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/pins/813744226420795884" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("pin_metrics"); got != "true" {
+			t.Errorf("pin_metrics = %q, want true", got)
+		}
+		fmt.Fprint(w, `{
+			"id": "813744226420795884",
+			"created_at": "2020-01-01T20:10:40",
+			"link": "https://www.pinterest.com/",
+			"title": "Cozy reading nook",
+			"description": "Reading corner ideas",
+			"dominant_color": "#6E7874",
+			"alt_text": null,
+			"board_id": "549755885175",
+			"board_section_id": "4",
+			"board_owner": {"username": "postly"},
+			"creative_type": "REGULAR",
+			"is_owner": true,
+			"is_standard": true,
+			"media": {
+				"media_type": "image",
+				"images": {
+					"150x150": {"width": 150, "height": 150, "url": "https://i.pinimg.com/150x150/d5/3b.jpg"},
+					"originals": {"width": 1000, "height": 1500, "url": "https://i.pinimg.com/originals/d5/3b.jpg"}
+				}
+			},
+			"pin_metrics": {
+				"90d": {"pin_click": 7, "impression": 2, "clickthrough": 3},
+				"lifetime_metrics": {"pin_click": 12, "impression": 50, "reaction": 5, "comment": 1}
+			}
+		}`)
+	})
+
+	pin, err := c.GetPin("813744226420795884")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pin.ID != "813744226420795884" || pin.CreatedAt != "2020-01-01T20:10:40" || pin.DominantColor != "#6E7874" {
+		t.Errorf("pin decoded as %+v", pin)
+	}
+	if pin.BoardOwner.Username != "postly" || !pin.IsOwner || pin.CreativeType != "REGULAR" {
+		t.Errorf("board owner = %q, is_owner = %v, creative_type = %q", pin.BoardOwner.Username, pin.IsOwner, pin.CreativeType)
+	}
+	if pin.Media.MediaType != "image" {
+		t.Errorf("media_type = %q", pin.Media.MediaType)
+	}
+	if original := pin.Media.Images["originals"]; original.Width != 1000 || original.Height != 1500 || original.URL == "" {
+		t.Errorf("original image = %+v", original)
+	}
+	if got := pin.PinMetrics["lifetime_metrics"]["impression"]; got != 50 {
+		t.Errorf("lifetime impressions = %d, want 50", got)
+	}
+	if got := pin.PinMetrics["90d"]["pin_click"]; got != 7 {
+		t.Errorf("90d pin clicks = %d, want 7", got)
+	}
+}
+
+func TestPinterestGetCommentsFollowsBookmark(t *testing.T) {
+	var requests int
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v5/pins/pin_1/comments" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch r.URL.Query().Get("bookmark") {
+		case "":
+			fmt.Fprint(w, `{"items":[
+				{"id":"c1","text":"Love this","created_at":"2024-01-02T15:04:05","pin_id":"pin_1","user":{"id":"u1","username":"alice"}}
+			],"bookmark":"b2"}`)
+		case "b2":
+			fmt.Fprint(w, `{"items":[
+				{"id":"c2","text":"Saved!","created_at":"2024-01-03T09:00:00","pin_id":"pin_1","user":{"id":"u2","username":"bob"}}
+			],"bookmark":null}`)
+		default:
+			t.Errorf("unexpected bookmark %q", r.URL.Query().Get("bookmark"))
+		}
+	})
+
+	comments, err := c.GetComments("pin_1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+	if len(comments) != 2 {
+		t.Fatalf("got %d comments, want 2", len(comments))
+	}
+
+	first := comments[0]
+	if first.ID != "c1" || first.Text != "Love this" || first.CreatedAt != "2024-01-02T15:04:05" || first.PinID != "pin_1" {
+		t.Errorf("first comment decoded as %+v", first)
+	}
+	if first.Author == nil || first.Author.ID != "u1" || first.Author.Username != "alice" {
+		t.Errorf("first comment author = %+v", first.Author)
+	}
+	if comments[1].Author == nil || comments[1].Author.Username != "bob" {
+		t.Errorf("second comment author = %+v", comments[1].Author)
+	}
+}
+
+func TestPinterestReplyToCommentIsUnsupported(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	reply, err := c.ReplyToComment("pin_1", "c1", "thanks")
+	if !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("error = %v, want ErrUnsupported", err)
+	}
+	if reply != nil {
+		t.Errorf("reply = %+v, want nil", reply)
+	}
+}
+
+func TestPinterestCancelAbortsInFlightRequest(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := c.GetPinContext(ctx, "pin_1")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("GetPinContext returned after %v, want it to abort on cancel", elapsed)
+	}
+}
+
+func TestPinterestAddCommentDecodesComment(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v5/pins/pin_1/comments" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload["text"] != "Great pin" {
+			t.Errorf("text = %q", payload["text"])
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"5190319487346411123","text":"Great pin","created_at":"2024-05-01T12:30:00","pin_id":"pin_1","user":{"id":"549755885175","username":"postly"}}`)
+	})
+
+	comment, err := c.AddComment("pin_1", "Great pin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comment.ID != "5190319487346411123" || comment.Text != "Great pin" || comment.CreatedAt != "2024-05-01T12:30:00" || comment.PinID != "pin_1" {
+		t.Errorf("comment = %+v", comment)
+	}
+	if comment.Author == nil || comment.Author.Username != "postly" {
+		t.Errorf("author = %+v", comment.Author)
+	}
+}
+
+func TestPinterestGetUserPinsPages(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/users/design studio/pins" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		switch bookmark := r.URL.Query().Get("bookmark"); bookmark {
+		case "":
+			fmt.Fprint(w, `{"items":[
+				{"id":"813744226420795884","title":"Modern kitchen","description":"Open plan","link":"https://example.com/kitchen","board_id":"549755885175"},
+				{"id":"813744226420795885","title":"Reading nook"}
+			],"bookmark":"Y2JVSG81V2sxcmNHRkdWbm"}`)
+		case "Y2JVSG81V2sxcmNHRkdWbm":
+			fmt.Fprint(w, `{"items":[{"id":"813744226420795886","title":"Tiny house"}],"bookmark":null}`)
+		default:
+			t.Errorf("unexpected bookmark %q", bookmark)
+		}
+	})
+
+	pins, bookmark, err := c.GetUserPins("design studio", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 2 || bookmark != "Y2JVSG81V2sxcmNHRkdWbm" {
+		t.Fatalf("first page = %d pins, bookmark %q", len(pins), bookmark)
+	}
+	want := Pin{ID: "813744226420795884", Title: "Modern kitchen", Description: "Open plan", Link: "https://example.com/kitchen", BoardID: "549755885175"}
+	if pins[0] != want {
+		t.Errorf("pin = %+v, want %+v", pins[0], want)
+	}
+
+	pins, bookmark, err = c.GetUserPins("design studio", bookmark)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pins) != 1 || pins[0].ID != "813744226420795886" || bookmark != "" {
+		t.Errorf("last page = %+v, bookmark %q", pins, bookmark)
+	}
+}
+
+func TestPinterestGetUserPinsErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		target error
+	}{
+		{"not found", http.StatusNotFound, `{"code":3,"message":"User not found."}`, ErrNotFound},
+		{"private", http.StatusForbidden, `{"code":29,"message":"You are not permitted to access that resource."}`, ErrPrivateProfile},
+		{"server error", http.StatusInternalServerError, `{"code":1,"message":"Something went wrong"}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			pins, _, err := c.GetUserPins("someone", "")
+			if err == nil || pins != nil {
+				t.Fatalf("GetUserPins() = %v, %v, want an error", pins, err)
+			}
+			if tt.target != nil && !errors.Is(err, tt.target) {
+				t.Errorf("error = %v, want %v", err, tt.target)
+			}
+			if tt.target == nil && (errors.Is(err, ErrNotFound) || errors.Is(err, ErrPrivateProfile)) {
+				t.Errorf("error = %v matches a typed error for status %d", err, tt.status)
+			}
+		})
+	}
+}
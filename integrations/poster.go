@@ -0,0 +1,743 @@
+package integrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"postly.com/integrations/types"
+)
+
+// Poster publishes a post to a single platform
+type Poster interface {
+	Post(ctx context.Context, req PostRequest) (PostResult, error)
+}
+
+// PostRequest is a post in a platform-neutral form. Media paths may be local
+// files or URLs, depending on what the platform accepts.
+type PostRequest struct {
+	Text    string
+	Media   []PostMedia
+	Options PostOptions
+}
+
+// PostOptions holds platform-specific settings for a PostRequest, keyed by the
+// Option* constants. Platforms ignore options they do not use.
+type PostOptions map[string]string
+
+// Keys understood by the Poster adapters
+const (
+	OptionTitle      = "title"       // Reddit, Pinterest, Dribbble, TikTok, YouTube
+	OptionLink       = "link"        // Facebook, Pinterest; turns Reddit and LinkedIn posts into link posts
+	OptionPageID     = "page_id"     // Facebook page, defaults to "me"
+	OptionAuthorType = "author_type" // LinkedIn, "person" or "organization"
+	OptionAuthorID   = "author_id"   // LinkedIn
+	OptionVisibility = "visibility"  // LinkedIn, TikTok, YouTube; see Visibility
+	OptionSubreddit  = "subreddit"   // Reddit, required
+	OptionFlairID    = "flair_id"    // Reddit, for subreddits that require a flair
+	OptionBoardID    = "board_id"    // Pinterest, required
+	OptionChannelID  = "channel_id"  // WhatsApp, Telegram and Slack, required
+)
+
+// Get returns the value of an option, or "" if it is not set
+func (o PostOptions) Get(key string) string {
+	return o[key]
+}
+
+// PostResult identifies a published post
+type PostResult struct {
+	Platform string `json:"platform"`
+	ID       string `json:"id"`
+}
+
+// remoteMediaKinds maps the extensions of media URLs to their kind, for URLs
+// given without a type
+var remoteMediaKinds = map[string]string{
+	".jpg":  "image",
+	".jpeg": "image",
+	".png":  "image",
+	".gif":  "image",
+	".webp": "image",
+	".mp4":  "video",
+	".m4v":  "video",
+	".mov":  "video",
+	".webm": "video",
+}
+
+// isRemoteMedia reports whether a media path is an http(s) URL rather than a
+// local file
+func isRemoteMedia(path string) bool {
+	return strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://")
+}
+
+// mediaKind returns "image" or "video" for a media item when the type is not
+// given, sniffing local files and going by the extension of URLs
+func mediaKind(m PostMedia) (string, error) {
+	if m.Type != "" {
+		return m.Type, nil
+	}
+
+	if isRemoteMedia(m.Path) {
+		u, err := url.Parse(m.Path)
+		if err != nil {
+			return "", err
+		}
+		if kind, ok := remoteMediaKinds[strings.ToLower(path.Ext(u.Path))]; ok {
+			return kind, nil
+		}
+		return "", fmt.Errorf("cannot tell whether %s is an image or a video, set its Type", m.Path)
+	}
+
+	contentType, err := detectContentType(m.Path)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case strings.HasPrefix(contentType, "image/"):
+		return "image", nil
+	case strings.HasPrefix(contentType, "video/"):
+		return "video", nil
+	}
+
+	return "", fmt.Errorf("unsupported media type %q for %s", contentType, m.Path)
+}
+
+// singleMedia returns the only media item of a request and its kind, or false
+// when the request has no media
+func singleMedia(platform string, req PostRequest) (PostMedia, string, bool, error) {
+	switch len(req.Media) {
+	case 0:
+		return PostMedia{}, "", false, nil
+	case 1:
+		kind, err := mediaKind(req.Media[0])
+		if err != nil {
+			return PostMedia{}, "", false, err
+		}
+		return req.Media[0], kind, true, nil
+	}
+
+	return PostMedia{}, "", false, fmt.Errorf("%w: %s posts take at most one media item", ErrUnsupported, platform)
+}
+
+// requireOption returns the value of an option the platform cannot post without
+func requireOption(platform string, opts PostOptions, key string) (string, error) {
+	value := opts.Get(key)
+	if value == "" {
+		return "", fmt.Errorf("%s post requires the %q option", platform, key)
+	}
+	return value, nil
+}
+
+// TwitterPoster adapts a TwitterClient to the Poster interface. Media must be
+// local files and needs OAuth 1.0a user credentials to upload.
+type TwitterPoster struct {
+	Client *TwitterClient
+}
+
+// Post publishes req as a tweet, uploading its media first
+func (p TwitterPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+
+	mediaIDs := make([]string, 0, len(req.Media))
+	for _, media := range req.Media {
+		mediaID, err := cachedUpload(ctx, PlatformTwitter, media.Path, func() (string, error) {
+			return p.uploadMedia(ctx, media)
+		})
+		if err != nil {
+			return PostResult{}, err
+		}
+		mediaIDs = append(mediaIDs, mediaID)
+	}
+
+	tweet, err := p.Client.CreateTweetWithMedia(req.Text, mediaIDs)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformTwitter, ID: tweet.ID}, nil
+}
+
+// uploadMedia uploads a local media file and returns its media ID
+func (p TwitterPoster) uploadMedia(ctx context.Context, media PostMedia) (string, error) {
+	contentType, err := detectContentType(media.Path)
+	if err != nil {
+		return "", err
+	}
+
+	file, err := os.Open(media.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	return p.Client.UploadMedia(ctx, file, contentType)
+}
+
+// FacebookPoster adapts a FaceBookClient to the Poster interface
+type FacebookPoster struct {
+	Client *FaceBookClient
+}
+
+// Post publishes req to the page given by OptionPageID, or the user's own
+// timeline, as a status, photo or video post. Facebook creates the post in the
+// same request that uploads its media, so there is no media ID to reuse and
+// PostToAll uploads a shared file once per Facebook post.
+func (p FacebookPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+
+	pageID := req.Options.Get(OptionPageID)
+	if pageID == "" {
+		pageID = "me"
+	}
+
+	media, kind, ok, err := singleMedia(PlatformFacebook, req)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	var resp *Response
+	switch {
+	case !ok:
+		resp, err = p.Client.CreatePostContext(ctx, pageID, req.Text, req.Options.Get(OptionLink))
+	case kind == "image":
+		resp, err = p.Client.UploadPhotoContext(ctx, pageID, req.Text, media.Path)
+	default:
+		resp, err = p.Client.UploadVideoContext(ctx, pageID, req.Text, media.Path, UploadOptions{})
+	}
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformFacebook, ID: resp.ID}, nil
+}
+
+// InstagramPoster adapts an InstagramClient to the Poster interface. Instagram
+// posts need at least one media item; several items become a carousel.
+type InstagramPoster struct {
+	Client *InstagramClient
+}
+
+// Post publishes req as an image, reel or carousel post
+func (p InstagramPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+
+	var (
+		resp *MediaResponse
+		err  error
+	)
+	switch len(req.Media) {
+	case 0:
+		return PostResult{}, fmt.Errorf("%w: instagram posts require media", ErrUnsupported)
+	case 1:
+		kind, kindErr := mediaKind(req.Media[0])
+		if kindErr != nil {
+			return PostResult{}, kindErr
+		}
+		if kind == "video" {
+			resp, err = p.Client.PostReelContext(ctx, req.Media[0].Path, req.Text, "", true)
+		} else {
+			resp, err = p.Client.PostImageContext(ctx, req.Media[0].Path, req.Text)
+		}
+	default:
+		paths := make([]string, len(req.Media))
+		for i, media := range req.Media {
+			paths[i] = media.Path
+		}
+		resp, err = p.Client.PostCarouselContext(ctx, paths, req.Text)
+	}
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformInstagram, ID: resp.ID}, nil
+}
+
+// LinkedInPoster adapts a LinkedInClient to the Poster interface
+type LinkedInPoster struct {
+	Client *LinkedInClient
+}
+
+// Post publishes req as a text, article, image or video share
+func (p LinkedInPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+
+	media, kind, ok, err := singleMedia(PlatformLinkedIn, req)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	input := map[string]interface{}{
+		"text":        req.Text,
+		"author_type": req.Options.Get(OptionAuthorType),
+		"author_id":   req.Options.Get(OptionAuthorID),
+		"visibility":  req.Options.Get(OptionVisibility),
+	}
+
+	var output []byte
+	switch {
+	case !ok && req.Options.Get(OptionLink) != "":
+		input["url"] = req.Options.Get(OptionLink)
+		output, err = p.linkedInCall(func(payload []byte) ([]byte, error) {
+			return p.Client.CreateArticlePostContext(ctx, payload)
+		}, input)
+	case !ok:
+		output, err = p.linkedInCall(func(payload []byte) ([]byte, error) {
+			return p.Client.CreateTextPostContext(ctx, payload)
+		}, input)
+	case kind == "image":
+		input["image_path"] = media.Path
+		output, err = p.linkedInCall(func(payload []byte) ([]byte, error) {
+			return p.Client.PostWithImageContext(ctx, payload)
+		}, input)
+	default:
+		var assetURN string
+		assetURN, err = cachedUpload(ctx, PlatformLinkedIn, media.Path, func() (string, error) {
+			return p.Client.UploadVideoContext(ctx, media.Path)
+		})
+		if err != nil {
+			return PostResult{}, fmt.Errorf("failed to upload video: %v", err)
+		}
+		input["video_url"] = assetURN
+		output, err = p.linkedInCall(func(payload []byte) ([]byte, error) {
+			return p.Client.CreateVideoPostContext(ctx, payload)
+		}, input)
+	}
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	var post types.LinkedInPostResponse
+	if err := json.Unmarshal(output, &post); err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformLinkedIn, ID: post.ID}, nil
+}
+
+// linkedInCall marshals input for one of the JSON-in, JSON-out client methods
+func (p LinkedInPoster) linkedInCall(call func([]byte) ([]byte, error), input map[string]interface{}) ([]byte, error) {
+	payload, err := json.Marshal(input)
+	if err != nil {
+		return nil, err
+	}
+	return call(payload)
+}
+
+// PinterestPoster adapts a Pinterest client to the Poster interface. Pins need
+// OptionBoardID and exactly one image or video; images may be URLs, videos
+// must be local files.
+type PinterestPoster struct {
+	Client *Pinterest
+}
+
+// Post publishes req as a pin
+func (p PinterestPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+
+	boardID, err := requireOption(PlatformPinterest, req.Options, OptionBoardID)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	media, kind, ok, err := singleMedia(PlatformPinterest, req)
+	if err != nil {
+		return PostResult{}, err
+	}
+	if !ok {
+		return PostResult{}, fmt.Errorf("%w: pinterest pins require an image or video", ErrUnsupported)
+	}
+
+	pin := Pin{
+		Title:       req.Options.Get(OptionTitle),
+		Description: req.Text,
+		Link:        req.Options.Get(OptionLink),
+		BoardID:     boardID,
+	}
+	switch {
+	case isRemoteMedia(media.Path) && kind == "video":
+		// Pins can only reference videos uploaded through the media API
+		return PostResult{}, fmt.Errorf("%w: pinterest video pins must be uploaded from a local file", ErrUnsupported)
+	case isRemoteMedia(media.Path):
+		pin.ImageURL = media.Path
+	default:
+		mediaID, err := cachedUpload(ctx, PlatformPinterest, media.Path, func() (string, error) {
+			return p.Client.UploadMediaForPinContext(ctx, media.Path)
+		})
+		if err != nil {
+			return PostResult{}, err
+		}
+		pin.MediaSource = mediaID
+	}
+
+	created, err := p.Client.CreatePinContext(ctx, pin)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformPinterest, ID: created.ID}, nil
+}
+
+// RedditPoster adapts a RedditClient to the Poster interface. Posts need
+// OptionSubreddit and OptionTitle; OptionLink makes a link post.
+type RedditPoster struct {
+	Client *RedditClient
+}
+
+// Post submits req to a subreddit
+func (p RedditPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+	if len(req.Media) > 0 {
+		return PostResult{}, fmt.Errorf("%w: reddit posts cannot carry media", ErrUnsupported)
+	}
+
+	subreddit, err := requireOption(PlatformReddit, req.Options, OptionSubreddit)
+	if err != nil {
+		return PostResult{}, err
+	}
+	title, err := requireOption(PlatformReddit, req.Options, OptionTitle)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	kind, content := "self", req.Text
+	if link := req.Options.Get(OptionLink); link != "" {
+		kind, content = "link", link
+	}
+
+	opts := RedditPostOptions{FlairID: req.Options.Get(OptionFlairID), SendReplies: true}
+	id, err := p.Client.CreatePostWithOptionsContext(ctx, subreddit, title, content, kind, opts)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformReddit, ID: id}, nil
+}
+
+// DribbblePoster adapts a DribbbleClient to the Poster interface. Shots need
+// OptionTitle and exactly one image.
+type DribbblePoster struct {
+	Client *DribbbleClient
+}
+
+// Post publishes req as a shot
+func (p DribbblePoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+
+	title, err := requireOption(PlatformDribbble, req.Options, OptionTitle)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	media, kind, ok, err := singleMedia(PlatformDribbble, req)
+	if err != nil {
+		return PostResult{}, err
+	}
+	if !ok || kind != "image" {
+		return PostResult{}, fmt.Errorf("%w: dribbble shots require one image", ErrUnsupported)
+	}
+
+	shot, err := p.Client.CreateShot(title, req.Text, nil, media.Path)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: PlatformDribbble, ID: fmt.Sprintf("%d", shot.ID)}, nil
+}
+
+// VideoPoster adapts a video platform client, such as TikTokClient or
+// YouTubeClient, to the Poster interface. Posts need exactly one video.
+type VideoPoster struct {
+	Platform string
+	Client   SocialPlatform
+}
+
+// Post uploads the video of req, using the text as its description
+func (p VideoPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	media, kind, ok, err := singleMedia(p.Platform, req)
+	if err != nil {
+		return PostResult{}, err
+	}
+	if !ok || kind != "video" {
+		return PostResult{}, fmt.Errorf("%w: %s posts require one video", ErrUnsupported, p.Platform)
+	}
+
+	id, err := p.Client.CreatePost(ctx, PostData{
+		VideoPath:   media.Path,
+		Title:       req.Options.Get(OptionTitle),
+		Description: req.Text,
+		Privacy:     Visibility(req.Options.Get(OptionVisibility)),
+	})
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: p.Platform, ID: id}, nil
+}
+
+// MessagingPoster adapts a MessagingService, such as WhatsApp, Telegram or
+// Slack, to the Poster interface. Posts need OptionChannelID.
+type MessagingPoster struct {
+	Platform string
+	Client   MessagingService
+}
+
+// Post sends req as a message to the channel given by OptionChannelID
+func (p MessagingPoster) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	if err := ctx.Err(); err != nil {
+		return PostResult{}, err
+	}
+	if len(req.Media) > 0 {
+		return PostResult{}, fmt.Errorf("%w: %s messages cannot carry media", ErrUnsupported, p.Platform)
+	}
+
+	channelID, err := requireOption(p.Platform, req.Options, OptionChannelID)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	id, err := p.Client.CreatePost(req.Text, channelID)
+	if err != nil {
+		return PostResult{}, err
+	}
+
+	return PostResult{Platform: p.Platform, ID: id}, nil
+}
+
+// ErrPlatformNotRegistered is returned by Registry.Post for unknown platforms
+var ErrPlatformNotRegistered = errors.New("platform is not registered")
+
+// Registry maps platform names to Posters. It is safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	posters map[string]Poster
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{posters: make(map[string]Poster)}
+}
+
+// Register adds or replaces the Poster for a platform
+func (r *Registry) Register(platform string, poster Poster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.posters[platform] = poster
+}
+
+// Get returns the Poster registered for a platform
+func (r *Registry) Get(platform string) (Poster, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	poster, ok := r.posters[platform]
+	return poster, ok
+}
+
+// Platforms returns the registered platform names in sorted order
+func (r *Registry) Platforms() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	platforms := make([]string, 0, len(r.posters))
+	for platform := range r.posters {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// Post publishes req with the Poster registered for platform
+func (r *Registry) Post(ctx context.Context, platform string, req PostRequest) (PostResult, error) {
+	poster, ok := r.Get(platform)
+	if !ok {
+		return PostResult{}, fmt.Errorf("%w: %s", ErrPlatformNotRegistered, platform)
+	}
+	return poster.Post(ctx, req)
+}
+
+// Registry returns a Registry with a Poster for every client in the config
+func (c *Config) Registry() *Registry {
+	r := NewRegistry()
+
+	if c.Twitter != nil {
+		r.Register(PlatformTwitter, TwitterPoster{Client: c.Twitter})
+	}
+	if c.Facebook != nil {
+		r.Register(PlatformFacebook, FacebookPoster{Client: c.Facebook})
+	}
+	if c.Instagram != nil {
+		r.Register(PlatformInstagram, InstagramPoster{Client: c.Instagram})
+	}
+	if c.LinkedIn != nil {
+		r.Register(PlatformLinkedIn, LinkedInPoster{Client: c.LinkedIn})
+	}
+	if c.Pinterest != nil {
+		r.Register(PlatformPinterest, PinterestPoster{Client: c.Pinterest})
+	}
+	if c.Reddit != nil {
+		r.Register(PlatformReddit, RedditPoster{Client: c.Reddit})
+	}
+	if c.Dribbble != nil {
+		r.Register(PlatformDribbble, DribbblePoster{Client: c.Dribbble})
+	}
+	if c.TikTok != nil {
+		r.Register(PlatformTikTok, VideoPoster{Platform: PlatformTikTok, Client: c.TikTok})
+	}
+	if c.YouTube != nil {
+		r.Register(PlatformYouTube, VideoPoster{Platform: PlatformYouTube, Client: c.YouTube})
+	}
+	if c.WhatsApp != nil {
+		r.Register(PlatformWhatsApp, MessagingPoster{Platform: PlatformWhatsApp, Client: c.WhatsApp})
+	}
+	if c.Telegram != nil {
+		r.Register(PlatformTelegram, MessagingPoster{Platform: PlatformTelegram, Client: c.Telegram})
+	}
+	if c.Slack != nil {
+		r.Register(PlatformSlack, MessagingPoster{Platform: PlatformSlack, Client: c.Slack})
+	}
+
+	return r
+}
+
+// PostToAllResult is the outcome of publishing one post of a PostToAll batch
+// to one platform
+type PostToAllResult struct {
+	Platform string
+	Index    int // position of the post in the batch
+	Result   PostResult
+	Err      error
+}
+
+// PostToAll publishes every request to each of platforms, or to every
+// registered platform if none are given. Platforms are posted to concurrently
+// and the requests of a batch in order. A local media file shared by several
+// requests is uploaded once per platform and its media ID reused, on
+// platforms that upload media separately from the post: Twitter, Pinterest and
+// LinkedIn videos. Facebook, Instagram and LinkedIn images send the media
+// with each post.
+func (r *Registry) PostToAll(ctx context.Context, platforms []string, reqs ...PostRequest) []PostToAllResult {
+	if len(platforms) == 0 {
+		platforms = r.Platforms()
+	}
+	ctx = withMediaCache(ctx, newMediaCache())
+
+	results := make([]PostToAllResult, len(platforms)*len(reqs))
+	var wg sync.WaitGroup
+	for i, platform := range platforms {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j, req := range reqs {
+				result, err := r.Post(ctx, platform, req)
+				results[i*len(reqs)+j] = PostToAllResult{Platform: platform, Index: j, Result: result, Err: err}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return results
+}
+
+type mediaCacheKey struct{}
+
+// mediaCache remembers the media IDs of files uploaded during a PostToAll
+// call, keyed by platform and file content so a renamed copy is reused too
+type mediaCache struct {
+	mu     sync.Mutex
+	hashes map[string]string // path -> content hash
+	ids    map[string]string // platform + content hash -> media ID
+}
+
+func newMediaCache() *mediaCache {
+	return &mediaCache{hashes: make(map[string]string), ids: make(map[string]string)}
+}
+
+// withMediaCache attaches a media cache to ctx for cachedUpload to use
+func withMediaCache(ctx context.Context, cache *mediaCache) context.Context {
+	return context.WithValue(ctx, mediaCacheKey{}, cache)
+}
+
+// hash returns the SHA-256 of a local file, reading it only once per path
+func (c *mediaCache) hash(path string) (string, error) {
+	c.mu.Lock()
+	sum, ok := c.hashes[path]
+	c.mu.Unlock()
+	if ok {
+		return sum, nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, file); err != nil {
+		return "", err
+	}
+	sum = hex.EncodeToString(h.Sum(nil))
+
+	c.mu.Lock()
+	c.hashes[path] = sum
+	c.mu.Unlock()
+	return sum, nil
+}
+
+// cachedUpload runs upload for a local media file unless the same content was
+// already uploaded to platform within the current PostToAll call, in which
+// case the earlier media ID is returned. Without a cache in ctx it always
+// uploads.
+func cachedUpload(ctx context.Context, platform, path string, upload func() (string, error)) (string, error) {
+	cache, ok := ctx.Value(mediaCacheKey{}).(*mediaCache)
+	if !ok {
+		return upload()
+	}
+
+	sum, err := cache.hash(path)
+	if err != nil {
+		return upload()
+	}
+	key := platform + ":" + sum
+
+	cache.mu.Lock()
+	id, ok := cache.ids[key]
+	cache.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	id, err = upload()
+	if err != nil {
+		return "", err
+	}
+
+	cache.mu.Lock()
+	cache.ids[key] = id
+	cache.mu.Unlock()
+	return id, nil
+}
@@ -0,0 +1,173 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestMediaKind(t *testing.T) {
+	image := writeTempFile(t, "photo", pngHeader)
+
+	tests := []struct {
+		name    string
+		media   PostMedia
+		want    string
+		wantErr bool
+	}{
+		{"explicit type", PostMedia{Path: "https://cdn.example.com/clip", Type: "video"}, "video", false},
+		{"sniffed local file", PostMedia{Path: image}, "image", false},
+		{"image URL", PostMedia{Path: "https://cdn.example.com/a/photo.JPG?size=large"}, "image", false},
+		{"video URL", PostMedia{Path: "https://cdn.example.com/clip.mp4#t=10"}, "video", false},
+		{"URL without extension", PostMedia{Path: "https://cdn.example.com/media/123"}, "", true},
+		{"missing local file", PostMedia{Path: "/does/not/exist.png"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := mediaKind(tt.media)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("mediaKind() = %q, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("mediaKind() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPinterestPosterImageURL(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v5/pins" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var pin Pin
+		if err := json.NewDecoder(r.Body).Decode(&pin); err != nil {
+			t.Fatal(err)
+		}
+		if pin.ImageURL != "https://cdn.example.com/photo.png" || pin.MediaSource != "" || pin.BoardID != "board_1" {
+			t.Errorf("pin = %+v", pin)
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"id":"pin_1"}`)
+	})
+
+	result, err := PinterestPoster{Client: c}.Post(context.Background(), PostRequest{
+		Text:    "hello",
+		Media:   []PostMedia{{Path: "https://cdn.example.com/photo.png"}},
+		Options: PostOptions{OptionBoardID: "board_1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ID != "pin_1" {
+		t.Errorf("ID = %q", result.ID)
+	}
+}
+
+func TestPinterestPosterRejectsVideoURL(t *testing.T) {
+	c, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, media := range []PostMedia{
+		{Path: "https://cdn.example.com/clip.mp4"},
+		{Path: "https://cdn.example.com/clip", Type: "video"},
+	} {
+		_, err := PinterestPoster{Client: c}.Post(context.Background(), PostRequest{
+			Media:   []PostMedia{media},
+			Options: PostOptions{OptionBoardID: "board_1"},
+		})
+		if !errors.Is(err, ErrUnsupported) {
+			t.Errorf("Post(%+v) error = %v, want ErrUnsupported", media, err)
+		}
+	}
+}
+
+func TestPostToAllUploadsSharedMediaOncePerPlatform(t *testing.T) {
+	var twitterUploads, pinterestUploads atomic.Int32
+	tw := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/2/tweets":
+			var payload struct {
+				Media struct {
+					MediaIDs []string `json:"media_ids"`
+				} `json:"media"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Fatal(err)
+			}
+			if len(payload.Media.MediaIDs) != 1 || payload.Media.MediaIDs[0] != "m1" {
+				t.Errorf("media_ids = %v, want [m1]", payload.Media.MediaIDs)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"data":{"id":"t1","text":"hello"}}`)
+		case strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data"):
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			if err := r.ParseForm(); err != nil {
+				t.Fatal(err)
+			}
+			if r.PostForm.Get("command") == "INIT" {
+				twitterUploads.Add(1)
+			}
+			fmt.Fprint(w, `{"media_id_string":"m1"}`)
+		}
+	})
+	pin, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v5/media":
+			pinterestUploads.Add(1)
+			fmt.Fprint(w, `{"media_id":"img-1"}`)
+		case "/v5/pins":
+			var p Pin
+			if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+				t.Fatal(err)
+			}
+			if p.MediaSource != "img-1" {
+				t.Errorf("media_source = %q, want img-1", p.MediaSource)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"pin_1"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	registry := NewRegistry()
+	registry.Register(PlatformTwitter, TwitterPoster{Client: tw})
+	registry.Register(PlatformPinterest, PinterestPoster{Client: pin})
+
+	// the second post uses a renamed copy of the same image
+	options := PostOptions{OptionBoardID: "board_1", OptionTitle: "hello"}
+	results := registry.PostToAll(context.Background(), nil,
+		PostRequest{Text: "first", Media: []PostMedia{{Path: writeTempFile(t, "photo.png", pngHeader)}}, Options: options},
+		PostRequest{Text: "second", Media: []PostMedia{{Path: writeTempFile(t, "copy.png", pngHeader)}}, Options: options},
+	)
+
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4", len(results))
+	}
+	for _, r := range results {
+		if r.Err != nil {
+			t.Errorf("%s post %d: %v", r.Platform, r.Index, r.Err)
+		}
+	}
+	if n := twitterUploads.Load(); n != 1 {
+		t.Errorf("Twitter uploads = %d, want 1", n)
+	}
+	if n := pinterestUploads.Load(); n != 1 {
+		t.Errorf("Pinterest uploads = %d, want 1", n)
+	}
+}
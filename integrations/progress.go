@@ -0,0 +1,39 @@
+package integrations
+
+import "io"
+
+// ProgressFunc receives the number of bytes sent so far and the total size of
+// an upload, which is -1 when the size is not known in advance
+type ProgressFunc func(sent, total int64)
+
+// UploadOptions configures large media uploads
+type UploadOptions struct {
+	// Progress, when set, is called every time a chunk of the upload is sent
+	Progress ProgressFunc
+}
+
+// body wraps an upload body of the given size so that Progress is reported
+// while it is read. Without a Progress callback body is returned as is.
+func (o UploadOptions) body(body io.Reader, total int64) io.Reader {
+	if o.Progress == nil {
+		return body
+	}
+	return &progressReader{r: body, total: total, progress: o.Progress}
+}
+
+// progressReader counts the bytes read through it
+type progressReader struct {
+	r        io.Reader
+	sent     int64
+	total    int64
+	progress ProgressFunc
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	if n > 0 {
+		p.sent += int64(n)
+		p.progress(p.sent, p.total)
+	}
+	return n, err
+}
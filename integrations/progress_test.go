@@ -0,0 +1,69 @@
+package integrations
+
+import (
+	"bytes"
+	"io"
+	"sync"
+	"testing"
+)
+
+// progressRecorder collects the calls of a ProgressFunc
+type progressRecorder struct {
+	mu    sync.Mutex
+	sent  []int64
+	total []int64
+}
+
+func (p *progressRecorder) record(sent, total int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.sent = append(p.sent, sent)
+	p.total = append(p.total, total)
+}
+
+// check asserts the progress was reported more than once, increased
+// monotonically and ended at the reported total, which is at least minSent.
+// The total includes any multipart framing around the file.
+func (p *progressRecorder) check(t *testing.T, minSent int64) {
+	t.Helper()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.sent) < 2 {
+		t.Fatalf("progress reported %d times, want several for a large upload", len(p.sent))
+	}
+	for i := 1; i < len(p.sent); i++ {
+		if p.sent[i] <= p.sent[i-1] {
+			t.Fatalf("progress went from %d to %d", p.sent[i-1], p.sent[i])
+		}
+	}
+	last := p.sent[len(p.sent)-1]
+	if last < minSent {
+		t.Errorf("final progress %d, want at least the %d bytes of the file", last, minSent)
+	}
+	for _, total := range p.total {
+		if total != last {
+			t.Fatalf("total = %d, want the %d bytes sent", total, last)
+		}
+	}
+}
+
+func TestUploadOptionsBodyReportsProgress(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 100_000)
+
+	var rec progressRecorder
+	body := UploadOptions{Progress: rec.record}.body(bytes.NewReader(data), int64(len(data)))
+
+	// read in small chunks like a transport would
+	buf := make([]byte, 4096)
+	for {
+		_, err := body.Read(buf)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	rec.check(t, int64(len(data)))
+}
@@ -0,0 +1,104 @@
+package integrations
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimiter is a token bucket that spaces out requests to stay within a
+// platform's rate limit. It allows bursts of up to the full limit and then
+// refills continuously.
+type RateLimiter struct {
+	mu       sync.Mutex
+	capacity float64
+	tokens   float64
+	interval time.Duration // time to refill one token
+	last     time.Time
+}
+
+// NewRateLimiter creates a limiter allowing requests requests per period,
+// e.g. NewRateLimiter(50, 15*time.Minute)
+func NewRateLimiter(requests int, per time.Duration) *RateLimiter {
+	if requests < 1 {
+		requests = 1
+	}
+	return &RateLimiter{
+		capacity: float64(requests),
+		tokens:   float64(requests),
+		interval: per / time.Duration(requests),
+		last:     time.Now(),
+	}
+}
+
+// Wait blocks until a request may be sent or ctx is done
+func (l *RateLimiter) Wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay == 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token if one is available and returns 0, or returns how
+// long to wait until the next token
+func (l *RateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if l.interval > 0 {
+		l.tokens += float64(now.Sub(l.last)) / float64(l.interval)
+	} else {
+		l.tokens = l.capacity
+	}
+	if l.tokens > l.capacity {
+		l.tokens = l.capacity
+	}
+	l.last = now
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	return time.Duration((1 - l.tokens) * float64(l.interval))
+}
+
+// RateLimit is the rate limit state a platform reported for an endpoint
+type RateLimit struct {
+	Limit     int       // requests allowed per window
+	Remaining int       // requests left in the current window
+	Reset     time.Time // when the window resets
+}
+
+// Exhausted reports whether no requests are left until Reset
+func (r RateLimit) Exhausted() bool {
+	return r.Remaining <= 0 && time.Now().Before(r.Reset)
+}
+
+// parseRateLimitHeaders reads the x-rate-limit-limit, x-rate-limit-remaining
+// and x-rate-limit-reset (Unix seconds) headers. ok is false when the
+// response carries no rate limit information.
+func parseRateLimitHeaders(h http.Header) (limit RateLimit, ok bool) {
+	remaining, err := strconv.Atoi(strings.TrimSpace(h.Get("x-rate-limit-remaining")))
+	if err != nil {
+		return RateLimit{}, false
+	}
+	reset, err := strconv.ParseInt(strings.TrimSpace(h.Get("x-rate-limit-reset")), 10, 64)
+	if err != nil {
+		return RateLimit{}, false
+	}
+
+	limit.Remaining = remaining
+	limit.Reset = time.Unix(reset, 0)
+	limit.Limit, _ = strconv.Atoi(strings.TrimSpace(h.Get("x-rate-limit-limit")))
+
+	return limit, true
+}
@@ -2,12 +2,16 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -21,6 +25,9 @@ type RedditClient struct {
 	AccessToken  string
 	TokenExpiry  time.Time
 	HTTPClient   *http.Client
+
+	tokenMu sync.RWMutex
+	refresh flightGroup
 }
 
 // NewRedditClient creates a new Reddit API client
@@ -35,19 +42,52 @@ func NewRedditClient(clientID, clientSecret, username, password, userAgent strin
 	}
 }
 
-// Authenticate authenticates with Reddit API using OAuth
+// Authenticate authenticates with Reddit API using OAuth.
+// Concurrent callers that find the token expired share a single refresh.
 func (c *RedditClient) Authenticate() error {
+	return c.AuthenticateContext(context.Background())
+}
+
+// AuthenticateContext is Authenticate with a context. Callers that join a
+// refresh already in flight wait on the context of the caller that started it.
+func (c *RedditClient) AuthenticateContext(ctx context.Context) error {
 	// Skip if we have a valid token
-	if c.AccessToken != "" && time.Now().Before(c.TokenExpiry) {
+	if c.hasValidToken() {
 		return nil
 	}
 
+	_, err := c.refresh.Do("token", func() (interface{}, error) {
+		// Another caller may have refreshed while we were waiting
+		if c.hasValidToken() {
+			return nil, nil
+		}
+		return nil, c.fetchToken(ctx)
+	})
+	return err
+}
+
+// hasValidToken reports whether the client holds an unexpired access token
+func (c *RedditClient) hasValidToken() bool {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.AccessToken != "" && time.Now().Before(c.TokenExpiry)
+}
+
+// currentToken returns the access token under the token lock
+func (c *RedditClient) currentToken() string {
+	c.tokenMu.RLock()
+	defer c.tokenMu.RUnlock()
+	return c.AccessToken
+}
+
+// fetchToken requests a new access token from Reddit
+func (c *RedditClient) fetchToken(ctx context.Context) error {
 	data := url.Values{}
 	data.Set("grant_type", "password")
 	data.Set("username", c.Username)
 	data.Set("password", c.Password)
 
-	req, err := http.NewRequest("POST", "https://www.reddit.com/api/v1/access_token", strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", "https://www.reddit.com/api/v1/access_token", strings.NewReader(data.Encode()))
 	if err != nil {
 		return err
 	}
@@ -74,20 +114,22 @@ func (c *RedditClient) Authenticate() error {
 		Scope       string `json:"scope"`
 	}
 
-	err = json.NewDecoder(resp.Body).Decode(&result)
+	err = decodeJSONBody(resp, &result)
 	if err != nil {
 		return err
 	}
 
+	c.tokenMu.Lock()
 	c.AccessToken = result.AccessToken
 	c.TokenExpiry = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+	c.tokenMu.Unlock()
 
 	return nil
 }
 
 // makeRequest makes an authenticated request to the Reddit API
-func (c *RedditClient) makeRequest(method, endpoint string, body interface{}, query url.Values) ([]byte, error) {
-	if err := c.Authenticate(); err != nil {
+func (c *RedditClient) makeRequest(ctx context.Context, method, endpoint string, body interface{}, query url.Values) ([]byte, error) {
+	if err := c.AuthenticateContext(ctx); err != nil {
 		return nil, err
 	}
 
@@ -107,13 +149,13 @@ func (c *RedditClient) makeRequest(method, endpoint string, body interface{}, qu
 		fullURL += "?" + query.Encode()
 	}
 
-	req, err := http.NewRequest(method, fullURL, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, fullURL, reqBody)
 	if err != nil {
 		return nil, err
 	}
 
 	req.Header.Set("User-Agent", c.UserAgent)
-	req.Header.Set("Authorization", "Bearer "+c.AccessToken)
+	req.Header.Set("Authorization", "Bearer "+c.currentToken())
 
 	if method == "POST" || method == "PUT" || method == "PATCH" {
 		req.Header.Set("Content-Type", "application/json")
@@ -139,27 +181,85 @@ func (c *RedditClient) makeRequest(method, endpoint string, body interface{}, qu
 
 // 1. CreatePost creates a new post in a subreddit
 func (c *RedditClient) CreatePost(subreddit, title, content, kind string) (string, error) {
-	// kind can be "self" for text post, "link" for link post, "image" for image, etc.
-	data := map[string]string{
-		"sr":    subreddit,
-		"title": title,
-		"kind":  kind,
+	return c.CreatePostContext(context.Background(), subreddit, title, content, kind)
+}
+
+// CreatePostContext is CreatePost with a context
+func (c *RedditClient) CreatePostContext(ctx context.Context, subreddit, title, content, kind string) (string, error) {
+	return c.CreatePostWithOptionsContext(ctx, subreddit, title, content, kind, RedditPostOptions{SendReplies: true})
+}
+
+// RedditPostOptions are the optional settings of a submission
+type RedditPostOptions struct {
+	FlairID     string // flair template ID, required by some subreddits
+	NSFW        bool
+	Spoiler     bool
+	SendReplies bool // send comment replies to the author's inbox
+}
+
+// RedditAPIError is an error Reddit reported in the errors array of a
+// response, e.g. Code SUBMIT_VALIDATION_FLAIR_REQUIRED with Field "flair"
+type RedditAPIError struct {
+	Code    string
+	Message string
+	Field   string
+}
+
+func (e *RedditAPIError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("Reddit API error: %s: %s (field %s)", e.Code, e.Message, e.Field)
 	}
+	return fmt.Sprintf("Reddit API error: %s: %s", e.Code, e.Message)
+}
+
+// redditErrors converts the errors array of an api_type=json response, whose
+// entries are [code, message, field] triples, into an error. It returns nil
+// when the array is empty.
+func redditErrors(entries [][]interface{}) error {
+	errs := make([]error, 0, len(entries))
+	for _, entry := range entries {
+		apiErr := &RedditAPIError{}
+		for i, dst := range []*string{&apiErr.Code, &apiErr.Message, &apiErr.Field} {
+			if i < len(entry) {
+				*dst, _ = entry[i].(string)
+			}
+		}
+		errs = append(errs, apiErr)
+	}
+	return errors.Join(errs...)
+}
+
+// CreatePostWithOptions creates a new post in a subreddit with a flair and
+// content flags. Errors Reddit reports for the submission, such as a missing
+// flair, are returned as *RedditAPIError.
+func (c *RedditClient) CreatePostWithOptions(subreddit, title, content, kind string, opts RedditPostOptions) (string, error) {
+	return c.CreatePostWithOptionsContext(context.Background(), subreddit, title, content, kind, opts)
+}
+
+// CreatePostWithOptionsContext is CreatePostWithOptions with a context
+func (c *RedditClient) CreatePostWithOptionsContext(ctx context.Context, subreddit, title, content, kind string, opts RedditPostOptions) (string, error) {
+	// kind can be "self" for text post, "link" for link post, "image" for image, etc.
+	formData := url.Values{}
+	formData.Set("api_type", "json")
+	formData.Set("sr", subreddit)
+	formData.Set("title", title)
+	formData.Set("kind", kind)
 
 	// Add content based on post type
 	if kind == "self" {
-		data["text"] = content
+		formData.Set("text", content)
 	} else if kind == "link" {
-		data["url"] = content
+		formData.Set("url", content)
 	}
 
-	// Make the API call
-	formData := url.Values{}
-	for key, value := range data {
-		formData.Add(key, value)
+	if opts.FlairID != "" {
+		formData.Set("flair_id", opts.FlairID)
 	}
+	formData.Set("nsfw", strconv.FormatBool(opts.NSFW))
+	formData.Set("spoiler", strconv.FormatBool(opts.Spoiler))
+	formData.Set("sendreplies", strconv.FormatBool(opts.SendReplies))
 
-	response, err := c.makeRequest("POST", "/api/submit", nil, formData)
+	response, err := c.makeRequest(ctx, "POST", "/api/submit", nil, formData)
 	if err != nil {
 		return "", err
 	}
@@ -167,8 +267,10 @@ func (c *RedditClient) CreatePost(subreddit, title, content, kind string) (strin
 	// Parse response to get post ID
 	var result struct {
 		JSON struct {
-			Data struct {
-				ID string `json:"id"`
+			Errors [][]interface{} `json:"errors"`
+			Data   struct {
+				ID   string `json:"id"`
+				Name string `json:"name"` // fullname, e.g. "t3_abc123"
 			} `json:"data"`
 		} `json:"json"`
 	}
@@ -177,17 +279,30 @@ func (c *RedditClient) CreatePost(subreddit, title, content, kind string) (strin
 		return "", err
 	}
 
+	if err := redditErrors(result.JSON.Errors); err != nil {
+		return "", err
+	}
+
+	if result.JSON.Data.ID == "" {
+		return "", fmt.Errorf("no post ID returned: %s", string(response))
+	}
+
 	return result.JSON.Data.ID, nil
 }
 
 // 2. ReplyToComment replies to a comment
 func (c *RedditClient) ReplyToComment(commentID, text string) (string, error) {
+	return c.ReplyToCommentContext(context.Background(), commentID, text)
+}
+
+// ReplyToCommentContext is ReplyToComment with a context
+func (c *RedditClient) ReplyToCommentContext(ctx context.Context, commentID, text string) (string, error) {
 	formData := url.Values{}
 	formData.Add("api_type", "json")
 	formData.Add("text", text)
 	formData.Add("thing_id", commentID) // Must include prefix, like "t1_" for comments
 
-	response, err := c.makeRequest("POST", "/api/comment", nil, formData)
+	response, err := c.makeRequest(ctx, "POST", "/api/comment", nil, formData)
 	if err != nil {
 		return "", err
 	}
@@ -217,7 +332,12 @@ func (c *RedditClient) ReplyToComment(commentID, text string) (string, error) {
 
 // 3. GetSubredditStats gets stats about a subreddit
 func (c *RedditClient) GetSubredditStats(subreddit string) (map[string]interface{}, error) {
-	response, err := c.makeRequest("GET", "/r/"+subreddit+"/about", nil, nil)
+	return c.GetSubredditStatsContext(context.Background(), subreddit)
+}
+
+// GetSubredditStatsContext is GetSubredditStats with a context
+func (c *RedditClient) GetSubredditStatsContext(ctx context.Context, subreddit string) (map[string]interface{}, error) {
+	response, err := c.makeRequest(ctx, "GET", "/r/"+subreddit+"/about", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -232,12 +352,12 @@ func (c *RedditClient) GetSubredditStats(subreddit string) (map[string]interface
 
 // GetPostStats gets stats about a specific post
 func (c *RedditClient) GetPostStats(postID string) (map[string]interface{}, error) {
-	// Make sure postID includes the t3_ prefix if not already present
-	if !strings.HasPrefix(postID, "t3_") {
-		postID = "t3_" + postID
-	}
+	return c.GetPostStatsContext(context.Background(), postID)
+}
 
-	response, err := c.makeRequest("GET", "/api/info", nil, url.Values{"id": {postID}})
+// GetPostStatsContext is GetPostStats with a context
+func (c *RedditClient) GetPostStatsContext(ctx context.Context, postID string) (map[string]interface{}, error) {
+	response, err := c.postInfo(ctx, postID)
 	if err != nil {
 		return nil, err
 	}
@@ -250,9 +370,65 @@ func (c *RedditClient) GetPostStats(postID string) (map[string]interface{}, erro
 	return result, nil
 }
 
+// postInfo fetches the /api/info listing of a post
+func (c *RedditClient) postInfo(ctx context.Context, postID string) ([]byte, error) {
+	// Make sure postID includes the t3_ prefix if not already present
+	if !strings.HasPrefix(postID, "t3_") {
+		postID = "t3_" + postID
+	}
+
+	return c.makeRequest(ctx, "GET", "/api/info", nil, url.Values{"id": {postID}})
+}
+
+// GetStats implements StatsProvider with the post's listing data. Likes are
+// upvotes and Shares are crossposts. Reddit rarely reports view counts, so
+// Views and Engagement are usually 0.
+func (c *RedditClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	response, err := c.postInfo(ctx, postID)
+	if err != nil {
+		return PostStats{}, err
+	}
+
+	var listing struct {
+		Data Listing `json:"data"`
+	}
+	if err := json.Unmarshal(response, &listing); err != nil {
+		return PostStats{}, err
+	}
+	if len(listing.Data.Children) == 0 {
+		return PostStats{}, fmt.Errorf("reddit post %q: %w", postID, ErrNotFound)
+	}
+
+	var post struct {
+		Ups           int64  `json:"ups"`
+		NumComments   int64  `json:"num_comments"`
+		NumCrossposts int64  `json:"num_crossposts"`
+		ViewCount     *int64 `json:"view_count"`
+	}
+	if err := json.Unmarshal(listing.Data.Children[0].Data, &post); err != nil {
+		return PostStats{}, err
+	}
+
+	stats := PostStats{
+		Likes:    post.Ups,
+		Comments: post.NumComments,
+		Shares:   post.NumCrossposts,
+	}
+	if post.ViewCount != nil {
+		stats.Views = *post.ViewCount
+		stats.Engagement = engagementPercent(stats.Likes+stats.Comments+stats.Shares, stats.Views)
+	}
+	return stats, nil
+}
+
 // GetUserInfo gets information about a user
 func (c *RedditClient) GetUserInfo(username string) (map[string]interface{}, error) {
-	response, err := c.makeRequest("GET", "/user/"+username+"/about", nil, nil)
+	return c.GetUserInfoContext(context.Background(), username)
+}
+
+// GetUserInfoContext is GetUserInfo with a context
+func (c *RedditClient) GetUserInfoContext(ctx context.Context, username string) (map[string]interface{}, error) {
+	response, err := c.makeRequest(ctx, "GET", "/user/"+username+"/about", nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -267,10 +443,15 @@ func (c *RedditClient) GetUserInfo(username string) (map[string]interface{}, err
 
 // GetComments gets comments from a post
 func (c *RedditClient) GetComments(postID, subreddit string) ([]interface{}, error) {
+	return c.GetCommentsContext(context.Background(), postID, subreddit)
+}
+
+// GetCommentsContext is GetComments with a context
+func (c *RedditClient) GetCommentsContext(ctx context.Context, postID, subreddit string) ([]interface{}, error) {
 	// Remove t3_ prefix if present
 	postID = strings.TrimPrefix(postID, "t3_")
 
-	response, err := c.makeRequest("GET", "/r/"+subreddit+"/comments/"+postID, nil, nil)
+	response, err := c.makeRequest(ctx, "GET", "/r/"+subreddit+"/comments/"+postID, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -283,36 +464,294 @@ func (c *RedditClient) GetComments(postID, subreddit string) ([]interface{}, err
 	return result, nil
 }
 
+// RedditComment is a comment with its replies, as returned by GetCommentTree
+type RedditComment struct {
+	ID         string
+	ParentID   string // fullname of the parent comment (t1_) or post (t3_)
+	Author     string
+	Body       string
+	Score      int
+	CreatedUTC time.Time
+	Replies    []RedditComment
+}
+
+// redditListingThing is a listing in its kind/data envelope, as found at the
+// top of a comments response and in the replies of a comment
+type redditListingThing struct {
+	Kind string  `json:"kind"`
+	Data Listing `json:"data"`
+}
+
+// redditCommentData is the data of a t1 thing
+type redditCommentData struct {
+	ID         string          `json:"id"`
+	ParentID   string          `json:"parent_id"`
+	Author     string          `json:"author"`
+	Body       string          `json:"body"`
+	Score      int             `json:"score"`
+	CreatedUTC float64         `json:"created_utc"`
+	Replies    json.RawMessage `json:"replies"` // a listing, or "" without replies
+}
+
+// GetCommentTree gets the comments of a post as a tree: the top level
+// comments, each with its replies. "Load more comments" stubs are skipped, so
+// long threads are only as complete as Reddit's first response.
+func (c *RedditClient) GetCommentTree(postID, subreddit string) ([]RedditComment, error) {
+	return c.GetCommentTreeContext(context.Background(), postID, subreddit)
+}
+
+// GetCommentTreeContext is GetCommentTree with a context
+func (c *RedditClient) GetCommentTreeContext(ctx context.Context, postID, subreddit string) ([]RedditComment, error) {
+	postID = strings.TrimPrefix(postID, "t3_")
+
+	response, err := c.makeRequest(ctx, "GET", "/r/"+subreddit+"/comments/"+postID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseCommentTree(response)
+}
+
+// parseCommentTree decodes a comments response, which is a pair of listings:
+// the post, then its comments
+func parseCommentTree(response []byte) ([]RedditComment, error) {
+	var listings []redditListingThing
+	if err := json.Unmarshal(response, &listings); err != nil {
+		return nil, err
+	}
+	if len(listings) < 2 {
+		return nil, errors.New("unexpected comments response: missing comment listing")
+	}
+
+	return commentsFromListing(listings[1].Data)
+}
+
+// commentsFromListing converts the t1 children of a listing, and their
+// replies, into comments
+func commentsFromListing(listing Listing) ([]RedditComment, error) {
+	comments := make([]RedditComment, 0, len(listing.Children))
+	for _, child := range listing.Children {
+		if child.Kind != "t1" {
+			continue
+		}
+
+		var data redditCommentData
+		if err := json.Unmarshal(child.Data, &data); err != nil {
+			return nil, err
+		}
+
+		comment := RedditComment{
+			ID:         data.ID,
+			ParentID:   data.ParentID,
+			Author:     data.Author,
+			Body:       data.Body,
+			Score:      data.Score,
+			CreatedUTC: time.Unix(int64(data.CreatedUTC), 0).UTC(),
+		}
+
+		if len(data.Replies) > 0 && data.Replies[0] == '{' {
+			var replies redditListingThing
+			if err := json.Unmarshal(data.Replies, &replies); err != nil {
+				return nil, err
+			}
+			nested, err := commentsFromListing(replies.Data)
+			if err != nil {
+				return nil, err
+			}
+			comment.Replies = nested
+		}
+
+		comments = append(comments, comment)
+	}
+	return comments, nil
+}
+
 // Vote upvotes or downvotes a post or comment
 // dir should be 1 for upvote, -1 for downvote, 0 for removing vote
 func (c *RedditClient) Vote(id string, dir int) error {
+	return c.VoteContext(context.Background(), id, dir)
+}
+
+// VoteContext is Vote with a context
+func (c *RedditClient) VoteContext(ctx context.Context, id string, dir int) error {
 	formData := url.Values{}
 	formData.Add("id", id) // Must include prefix, like "t3_" for posts
 	formData.Add("dir", fmt.Sprintf("%d", dir))
 
-	_, err := c.makeRequest("POST", "/api/vote", nil, formData)
+	_, err := c.makeRequest(ctx, "POST", "/api/vote", nil, formData)
 	return err
 }
 
-// SearchPosts searches for posts
+// SearchPosts searches for posts, following the listing across pages until
+// limit results have been collected. A limit of zero or less returns every
+// result Reddit serves for the query.
 func (c *RedditClient) SearchPosts(query, subreddit string, limit int) ([]interface{}, error) {
+	return c.SearchPostsContext(context.Background(), query, subreddit, limit)
+}
+
+// SearchPostsContext is SearchPosts with a context
+func (c *RedditClient) SearchPostsContext(ctx context.Context, query, subreddit string, limit int) ([]interface{}, error) {
 	params := url.Values{}
 	params.Add("q", query)
-	params.Add("limit", fmt.Sprintf("%d", limit))
+	params.Add("limit", fmt.Sprintf("%d", redditMaxPageSize))
+	if limit > 0 && limit < redditMaxPageSize {
+		params.Set("limit", fmt.Sprintf("%d", limit))
+	}
 
 	endpoint := "/search"
 	if subreddit != "" {
 		endpoint = "/r/" + subreddit + "/search"
 	}
 
-	response, err := c.makeRequest("GET", endpoint, nil, params)
+	var posts []interface{}
+	err := c.IterateContext(ctx, endpoint, params, func(child json.RawMessage) error {
+		var post interface{}
+		if err := json.Unmarshal(child, &post); err != nil {
+			return err
+		}
+		posts = append(posts, post)
+
+		if limit > 0 && len(posts) >= limit {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// redditMaxPageSize is the largest limit Reddit listing endpoints accept
+const redditMaxPageSize = 100
+
+// Iterate walks a listing endpoint such as "/r/golang/new", calling fn with
+// each raw child ({"kind": ..., "data": ...}) and following the after cursor
+// until the last page. The limit in params sets the page size and is capped at
+// redditMaxPageSize. fn can return ErrStopIteration to stop early; any other
+// error aborts the walk and is returned.
+func (c *RedditClient) Iterate(endpoint string, params url.Values, fn func(child json.RawMessage) error) error {
+	return c.IterateContext(context.Background(), endpoint, params, fn)
+}
+
+// IterateContext is Iterate with a context
+func (c *RedditClient) IterateContext(ctx context.Context, endpoint string, params url.Values, fn func(child json.RawMessage) error) error {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = append([]string(nil), values...)
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil && limit > redditMaxPageSize {
+		query.Set("limit", strconv.Itoa(redditMaxPageSize))
+	}
+
+	for {
+		response, err := c.makeRequest(ctx, "GET", endpoint, nil, query)
+		if err != nil {
+			return err
+		}
+
+		var result struct {
+			Data struct {
+				After    string            `json:"after"`
+				Children []json.RawMessage `json:"children"`
+			} `json:"data"`
+		}
+
+		if err := json.Unmarshal(response, &result); err != nil {
+			return err
+		}
+
+		for _, child := range result.Data.Children {
+			if err := fn(child); err != nil {
+				if errors.Is(err, ErrStopIteration) {
+					return nil
+				}
+				return err
+			}
+		}
+
+		if result.Data.After == "" || len(result.Data.Children) == 0 {
+			return nil
+		}
+		query.Set("after", result.Data.After)
+	}
+}
+
+// Moderator is a moderator of a subreddit
+type Moderator struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	AddedAt     float64  `json:"date"` // Unix time the user became a moderator
+	Permissions []string `json:"mod_permissions"`
+}
+
+// Listing is a page of things returned by a Reddit listing endpoint
+type Listing struct {
+	After    string         `json:"after"`
+	Before   string         `json:"before"`
+	Children []ListingChild `json:"children"`
+}
+
+// Cursor returns the cursor of the page after this one
+func (l *Listing) Cursor() Cursor {
+	return Cursor{Platform: PlatformReddit, Next: l.After, Previous: l.Before}
+}
+
+// ListingChild is a single thing in a Listing. Kind is the type prefix, such
+// as "t1" for comments or "t3" for links, and Data holds the raw thing.
+type ListingChild struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+// GetListing gets a page of a listing endpoint such as "/r/golang/new",
+// starting at cursor. Use the zero Cursor for the first page.
+func (c *RedditClient) GetListing(endpoint string, params url.Values, cursor Cursor) (*Listing, Cursor, error) {
+	return c.GetListingContext(context.Background(), endpoint, params, cursor)
+}
+
+// GetListingContext is GetListing with a context
+func (c *RedditClient) GetListingContext(ctx context.Context, endpoint string, params url.Values, cursor Cursor) (*Listing, Cursor, error) {
+	query := url.Values{}
+	for key, values := range params {
+		query[key] = append([]string(nil), values...)
+	}
+	if cursor.Next != "" {
+		query.Set("after", cursor.Next)
+	}
+
+	response, err := c.makeRequest(ctx, "GET", endpoint, nil, query)
+	if err != nil {
+		return nil, Cursor{}, err
+	}
+
+	var result struct {
+		Data Listing `json:"data"`
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, Cursor{}, err
+	}
+
+	return &result.Data, result.Data.Cursor(), nil
+}
+
+// GetModerators lists the moderators of a subreddit
+func (c *RedditClient) GetModerators(subreddit string) ([]Moderator, error) {
+	return c.GetModeratorsContext(context.Background(), subreddit)
+}
+
+// GetModeratorsContext is GetModerators with a context
+func (c *RedditClient) GetModeratorsContext(ctx context.Context, subreddit string) ([]Moderator, error) {
+	response, err := c.makeRequest(ctx, "GET", "/r/"+subreddit+"/about/moderators", nil, nil)
 	if err != nil {
 		return nil, err
 	}
 
 	var result struct {
 		Data struct {
-			Children []interface{} `json:"children"`
+			Children []Moderator `json:"children"`
 		} `json:"data"`
 	}
 
@@ -322,3 +761,78 @@ func (c *RedditClient) SearchPosts(query, subreddit string, limit int) ([]interf
 
 	return result.Data.Children, nil
 }
+
+// GetModQueue gets the items awaiting moderator review in a subreddit
+func (c *RedditClient) GetModQueue(subreddit string, limit int) (*Listing, error) {
+	return c.GetModQueueContext(context.Background(), subreddit, limit)
+}
+
+// GetModQueueContext is GetModQueue with a context
+func (c *RedditClient) GetModQueueContext(ctx context.Context, subreddit string, limit int) (*Listing, error) {
+	params := url.Values{}
+	if limit > 0 {
+		params.Add("limit", fmt.Sprintf("%d", limit))
+	}
+
+	response, err := c.makeRequest(ctx, "GET", "/r/"+subreddit+"/about/modqueue", nil, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data Listing `json:"data"`
+	}
+
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, err
+	}
+
+	return &result.Data, nil
+}
+
+// validateModerationTarget checks that fullname is a comment (t1_) or link (t3_)
+func validateModerationTarget(fullname string) error {
+	if (strings.HasPrefix(fullname, "t1_") || strings.HasPrefix(fullname, "t3_")) && len(fullname) > 3 {
+		return nil
+	}
+	return fmt.Errorf("invalid fullname %q: expected a t1_ comment or t3_ link", fullname)
+}
+
+// Approve approves a comment or link in the mod queue. fullname must include
+// its type prefix, like "t3_" for posts.
+func (c *RedditClient) Approve(fullname string) error {
+	return c.ApproveContext(context.Background(), fullname)
+}
+
+// ApproveContext is Approve with a context
+func (c *RedditClient) ApproveContext(ctx context.Context, fullname string) error {
+	if err := validateModerationTarget(fullname); err != nil {
+		return err
+	}
+
+	formData := url.Values{}
+	formData.Add("id", fullname)
+
+	_, err := c.makeRequest(ctx, "POST", "/api/approve", nil, formData)
+	return err
+}
+
+// Remove removes a comment or link, marking it as spam if spam is set.
+// fullname must include its type prefix, like "t3_" for posts.
+func (c *RedditClient) Remove(fullname string, spam bool) error {
+	return c.RemoveContext(context.Background(), fullname, spam)
+}
+
+// RemoveContext is Remove with a context
+func (c *RedditClient) RemoveContext(ctx context.Context, fullname string, spam bool) error {
+	if err := validateModerationTarget(fullname); err != nil {
+		return err
+	}
+
+	formData := url.Values{}
+	formData.Add("id", fullname)
+	formData.Add("spam", fmt.Sprintf("%t", spam))
+
+	_, err := c.makeRequest(ctx, "POST", "/api/remove", nil, formData)
+	return err
+}
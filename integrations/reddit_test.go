@@ -0,0 +1,452 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestRedditClient(t *testing.T, handler http.HandlerFunc) *RedditClient {
+	t.Helper()
+
+	_, client := newTestServer(t, handler)
+	c := NewRedditClient("id", "secret", "user", "pass", "postly-test")
+	c.HTTPClient = client
+	c.AccessToken = "token"
+	c.TokenExpiry = time.Now().Add(time.Hour)
+	return c
+}
+
+func TestRedditGetModeratorsDecodes(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/r/golang/about/moderators" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q", got)
+		}
+		fmt.Fprint(w, `{"kind":"UserList","data":{"children":[
+			{"name":"alice","id":"t2_a1","date":1500000000.0,"mod_permissions":["all"]},
+			{"name":"bob","id":"t2_b2","date":1600000000.0,"mod_permissions":["posts","wiki"]}
+		]}}`)
+	})
+
+	mods, err := c.GetModerators("golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mods) != 2 {
+		t.Fatalf("got %d moderators, want 2", len(mods))
+	}
+	if mods[0].Name != "alice" || mods[0].ID != "t2_a1" || mods[0].AddedAt != 1500000000 {
+		t.Errorf("first moderator decoded as %+v", mods[0])
+	}
+	if fmt.Sprint(mods[1].Permissions) != "[posts wiki]" {
+		t.Errorf("permissions = %v, want [posts wiki]", mods[1].Permissions)
+	}
+}
+
+func TestRedditGetModQueueDecodes(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/r/golang/about/modqueue" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("limit"); got != "25" {
+			t.Errorf("limit = %q, want 25", got)
+		}
+		fmt.Fprint(w, `{"kind":"Listing","data":{"after":"t3_next","before":null,"children":[
+			{"kind":"t3","data":{"name":"t3_abc","title":"spam?"}},
+			{"kind":"t1","data":{"name":"t1_def","body":"reported"}}
+		]}}`)
+	})
+
+	queue, err := c.GetModQueue("golang", 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if queue.After != "t3_next" || queue.Before != "" {
+		t.Errorf("after/before = %q/%q", queue.After, queue.Before)
+	}
+	if len(queue.Children) != 2 {
+		t.Fatalf("got %d children, want 2", len(queue.Children))
+	}
+	if queue.Children[0].Kind != "t3" || queue.Children[1].Kind != "t1" {
+		t.Errorf("kinds = %q, %q", queue.Children[0].Kind, queue.Children[1].Kind)
+	}
+	if cursor := queue.Cursor(); cursor.Next != "t3_next" || cursor.Platform != PlatformReddit {
+		t.Errorf("cursor = %+v", cursor)
+	}
+}
+
+func TestRedditModerationPayloads(t *testing.T) {
+	tests := []struct {
+		name     string
+		call     func(c *RedditClient) error
+		path     string
+		wantID   string
+		wantSpam string
+	}{
+		{"approve", func(c *RedditClient) error { return c.Approve("t3_abc") }, "/api/approve", "t3_abc", ""},
+		{"remove", func(c *RedditClient) error { return c.Remove("t1_def", false) }, "/api/remove", "t1_def", "false"},
+		{"remove as spam", func(c *RedditClient) error { return c.Remove("t3_ghi", true) }, "/api/remove", "t3_ghi", "true"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.Method != http.MethodPost || r.URL.Path != tt.path {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				q := r.URL.Query()
+				if got := q.Get("id"); got != tt.wantID {
+					t.Errorf("id = %q, want %q", got, tt.wantID)
+				}
+				if got := q.Get("spam"); got != tt.wantSpam {
+					t.Errorf("spam = %q, want %q", got, tt.wantSpam)
+				}
+				fmt.Fprint(w, `{}`)
+			})
+
+			if err := tt.call(c); err != nil {
+				t.Fatal(err)
+			}
+			if requests != 1 {
+				t.Errorf("requests = %d, want 1", requests)
+			}
+		})
+	}
+}
+
+func TestRedditModerationRejectsInvalidFullname(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, fullname := range []string{"", "abc", "t3_", "t2_user", "t5_sub"} {
+		if err := c.Approve(fullname); err == nil {
+			t.Errorf("Approve(%q) succeeded, want an error", fullname)
+		}
+		if err := c.Remove(fullname, true); err == nil {
+			t.Errorf("Remove(%q) succeeded, want an error", fullname)
+		}
+	}
+}
+
+func TestRedditCreatePostWithOptionsSendsFlairAndFlags(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/submit" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		q := r.URL.Query()
+		want := map[string]string{
+			"api_type":    "json",
+			"sr":          "golang",
+			"title":       "Release notes",
+			"kind":        "self",
+			"text":        "What changed",
+			"flair_id":    "2b9a7c3e-1f4d-11ee-be56-0242ac120002",
+			"nsfw":        "true",
+			"spoiler":     "false",
+			"sendreplies": "true",
+		}
+		for field, value := range want {
+			if got := q.Get(field); got != value {
+				t.Errorf("%s = %q, want %q", field, got, value)
+			}
+		}
+		if _, ok := q["url"]; ok {
+			t.Error("url sent for a self post")
+		}
+		fmt.Fprint(w, `{"json":{"errors":[],"data":{"url":"https://www.reddit.com/r/golang/comments/18xk2ab/release_notes/","drafts_count":0,"id":"18xk2ab","name":"t3_18xk2ab"}}}`)
+	})
+
+	id, err := c.CreatePostWithOptions("golang", "Release notes", "What changed", "self", RedditPostOptions{
+		FlairID:     "2b9a7c3e-1f4d-11ee-be56-0242ac120002",
+		NSFW:        true,
+		SendReplies: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "18xk2ab" {
+		t.Errorf("id = %q, want 18xk2ab", id)
+	}
+}
+
+func TestRedditCreatePostWithOptionsSurfacesFlairRequired(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()["flair_id"]; ok {
+			t.Error("flair_id sent without a flair")
+		}
+		fmt.Fprint(w, `{"json":{"errors":[["SUBMIT_VALIDATION_FLAIR_REQUIRED","Your post must contain post flair.","flair"]]}}`)
+	})
+
+	id, err := c.CreatePostWithOptions("golang", "Release notes", "https://go.dev/doc/devel/release", "link", RedditPostOptions{})
+	if id != "" {
+		t.Errorf("id = %q, want none for a rejected submission", id)
+	}
+	var apiErr *RedditAPIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("error = %v, want a *RedditAPIError", err)
+	}
+	want := RedditAPIError{Code: "SUBMIT_VALIDATION_FLAIR_REQUIRED", Message: "Your post must contain post flair.", Field: "flair"}
+	if *apiErr != want {
+		t.Errorf("error = %+v, want %+v", *apiErr, want)
+	}
+}
+
+// redditListingPage returns a listing page of link children with the given
+// ids, pointing to after
+func redditListingPage(after string, ids ...string) string {
+	children := make([]string, len(ids))
+	for i, id := range ids {
+		children[i] = fmt.Sprintf(`{"kind":"t3","data":{"id":%q,"name":"t3_%s","title":"post %s"}}`, id, id, id)
+	}
+	cursor := "null"
+	if after != "" {
+		cursor = strconv.Quote(after)
+	}
+	return fmt.Sprintf(`{"kind":"Listing","data":{"after":%s,"dist":%d,"children":[%s],"before":null}}`, cursor, len(ids), strings.Join(children, ","))
+}
+
+func TestRedditIterateFollowsAfterCursor(t *testing.T) {
+	var requests int
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/r/golang/new" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("limit") != "100" || q.Get("t") != "week" {
+			t.Errorf("query = %v, want the capped limit and the caller's params", q)
+		}
+		switch after := q.Get("after"); after {
+		case "":
+			fmt.Fprint(w, redditListingPage("t3_b", "a", "b"))
+		case "t3_b":
+			fmt.Fprint(w, redditListingPage("t3_d", "c", "d"))
+		case "t3_d":
+			fmt.Fprint(w, redditListingPage("", "e"))
+		default:
+			t.Errorf("unexpected after %q", after)
+		}
+	})
+
+	params := url.Values{"limit": {"500"}, "t": {"week"}}
+	var ids []string
+	err := c.Iterate("/r/golang/new", params, func(child json.RawMessage) error {
+		var thing struct {
+			Kind string `json:"kind"`
+			Data struct {
+				ID string `json:"id"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(child, &thing); err != nil {
+			return err
+		}
+		if thing.Kind != "t3" {
+			t.Errorf("kind = %q, want t3", thing.Kind)
+		}
+		ids = append(ids, thing.Data.ID)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(ids) != "[a b c d e]" {
+		t.Errorf("ids = %v, want [a b c d e]", ids)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want 3", requests)
+	}
+	if params.Get("limit") != "500" || params.Get("after") != "" {
+		t.Errorf("Iterate modified the caller's params: %v", params)
+	}
+}
+
+func TestRedditIterateStopsEarly(t *testing.T) {
+	var requests int
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Query().Get("after") == "" {
+			fmt.Fprint(w, redditListingPage("t3_b", "a", "b"))
+			return
+		}
+		fmt.Fprint(w, redditListingPage("t3_d", "c", "d"))
+	})
+
+	var seen int
+	err := c.Iterate("/r/golang/new", nil, func(child json.RawMessage) error {
+		seen++
+		if seen == 3 {
+			return ErrStopIteration
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("error = %v, want nil after ErrStopIteration", err)
+	}
+	if seen != 3 || requests != 2 {
+		t.Errorf("saw %d children in %d requests, want 3 in 2", seen, requests)
+	}
+
+	failed := errors.New("handler failed")
+	requests = 0
+	err = c.Iterate("/r/golang/new", nil, func(child json.RawMessage) error {
+		return failed
+	})
+	if !errors.Is(err, failed) || requests != 1 {
+		t.Errorf("error = %v after %d requests, want the callback error after 1", err, requests)
+	}
+}
+
+func TestRedditSearchPostsCapsResults(t *testing.T) {
+	var requests int
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/r/golang/search" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("q") != "generics" || q.Get("limit") != "3" {
+			t.Errorf("query = %v", q)
+		}
+		if q.Get("after") == "" {
+			fmt.Fprint(w, redditListingPage("t3_c", "a", "b", "c"))
+			return
+		}
+		fmt.Fprint(w, redditListingPage("", "d", "e"))
+	})
+
+	posts, err := c.SearchPosts("generics", "golang", 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(posts) != 3 || requests != 1 {
+		t.Errorf("got %d posts in %d requests, want 3 in 1", len(posts), requests)
+	}
+}
+
+// redditCommentTreeFixture is a comments response: the post listing, then a
+// comment with two levels of replies, a comment without replies and a "load
+// more comments" stub
+const redditCommentTreeFixture = `[
+	{"kind":"Listing","data":{"after":null,"before":null,"children":[
+		{"kind":"t3","data":{"id":"abc123","title":"Go 1.22 is out"}}
+	]}},
+	{"kind":"Listing","data":{"after":null,"before":null,"children":[
+		{"kind":"t1","data":{
+			"id":"c1","parent_id":"t3_abc123","author":"alice","body":"Finally!","score":42,"created_utc":1700000000.0,
+			"replies":{"kind":"Listing","data":{"children":[
+				{"kind":"t1","data":{
+					"id":"c2","parent_id":"t1_c1","author":"bob","body":"Range over int!","score":7,"created_utc":1700000100.0,
+					"replies":{"kind":"Listing","data":{"children":[
+						{"kind":"t1","data":{"id":"c3","parent_id":"t1_c2","author":"alice","body":"Yes","score":1,"created_utc":1700000200.0,"replies":""}}
+					]}}
+				}},
+				{"kind":"more","data":{"id":"c9","count":12,"children":["c9","c10"]}}
+			]}}
+		}},
+		{"kind":"t1","data":{"id":"c4","parent_id":"t3_abc123","author":"carol","body":"Nice","score":-2,"created_utc":1700000300.0,"replies":""}},
+		{"kind":"more","data":{"id":"c5","count":30,"children":["c5","c6"]}}
+	]}}
+]`
+
+func TestRedditParseCommentTreeNested(t *testing.T) {
+	comments, err := parseCommentTree([]byte(redditCommentTreeFixture))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RedditComment{
+		{
+			ID: "c1", ParentID: "t3_abc123", Author: "alice", Body: "Finally!", Score: 42,
+			CreatedUTC: time.Unix(1700000000, 0).UTC(),
+			Replies: []RedditComment{{
+				ID: "c2", ParentID: "t1_c1", Author: "bob", Body: "Range over int!", Score: 7,
+				CreatedUTC: time.Unix(1700000100, 0).UTC(),
+				Replies: []RedditComment{{
+					ID: "c3", ParentID: "t1_c2", Author: "alice", Body: "Yes", Score: 1,
+					CreatedUTC: time.Unix(1700000200, 0).UTC(),
+				}},
+			}},
+		},
+		{
+			ID: "c4", ParentID: "t3_abc123", Author: "carol", Body: "Nice", Score: -2,
+			CreatedUTC: time.Unix(1700000300, 0).UTC(),
+		},
+	}
+	if !reflect.DeepEqual(comments, want) {
+		t.Errorf("parseCommentTree() =\n%+v\nwant\n%+v", comments, want)
+	}
+}
+
+func TestRedditParseCommentTreeErrors(t *testing.T) {
+	for _, response := range []string{
+		`{"kind":"Listing"}`,
+		`[{"kind":"Listing","data":{"children":[]}}]`,
+		`[{"kind":"Listing","data":{"children":[]}},{"kind":"Listing","data":{"children":[{"kind":"t1","data":"oops"}]}}]`,
+	} {
+		if comments, err := parseCommentTree([]byte(response)); err == nil {
+			t.Errorf("parseCommentTree(%s) = %+v, want an error", response, comments)
+		}
+	}
+}
+
+func TestRedditGetCommentTree(t *testing.T) {
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || r.URL.Path != "/r/golang/comments/abc123" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		fmt.Fprint(w, redditCommentTreeFixture)
+	})
+
+	comments, err := c.GetCommentTree("t3_abc123", "golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(comments) != 2 || len(comments[0].Replies) != 1 || len(comments[0].Replies[0].Replies) != 1 {
+		t.Errorf("GetCommentTree() = %+v, want the fixture's tree", comments)
+	}
+}
+
+func TestRedditTokenFetchHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	c := newTestRedditClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/access_token" {
+			t.Errorf("unexpected request %s before authenticating", r.URL.Path)
+			return
+		}
+		// hang until the test is over
+		<-release
+	})
+	// runs before the server is closed
+	t.Cleanup(func() { close(release) })
+	c.AccessToken = ""
+	c.TokenExpiry = time.Time{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.CreatePostContext(ctx, "golang", "title", "body", "self")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("token fetch ran for %v past the deadline", elapsed)
+	}
+	if c.hasValidToken() {
+		t.Error("client holds a token after a cancelled fetch")
+	}
+}
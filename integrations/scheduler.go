@@ -0,0 +1,348 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrJobNotFound is returned by Scheduler.Cancel for unknown or already
+// published jobs
+var ErrJobNotFound = errors.New("scheduled job not found")
+
+// Job is a post waiting to be published by a Scheduler
+type Job struct {
+	ID        string      `json:"id"`
+	Platform  string      `json:"platform"`
+	Request   PostRequest `json:"request"`
+	PublishAt time.Time   `json:"publish_at"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Store persists the pending jobs of a Scheduler so they survive a restart.
+// Implementations must be safe for concurrent use.
+type Store interface {
+	Save(job Job) error
+	Delete(id string) error
+	List() ([]Job, error)
+}
+
+// MemoryStore is a Store that keeps jobs in memory. Jobs are lost when the
+// process exits; use a persistent Store to keep them across restarts.
+type MemoryStore struct {
+	mu   sync.Mutex
+	jobs map[string]Job
+}
+
+// NewMemoryStore creates an empty MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{jobs: make(map[string]Job)}
+}
+
+// Save adds or replaces a job
+func (s *MemoryStore) Save(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jobs[job.ID] = job
+	return nil
+}
+
+// Delete removes a job. Deleting an unknown job is not an error.
+func (s *MemoryStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.jobs, id)
+	return nil
+}
+
+// List returns every stored job
+func (s *MemoryStore) List() ([]Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	return jobs, nil
+}
+
+// JobResult reports the outcome of publishing a scheduled job
+type JobResult struct {
+	Job    Job
+	Result PostResult
+	Err    error
+}
+
+// Scheduler publishes posts at a later time, for platforms that cannot
+// schedule natively (see ErrSchedulingUnsupported). Pending jobs are written
+// to Store; on Start, jobs left over from a previous run are loaded again and
+// their Poster is looked up in Registry by platform name.
+type Scheduler struct {
+	Store    Store
+	Registry *Registry
+
+	// OnResult, if set, is called after every job is published or fails
+	OnResult func(JobResult)
+
+	mu      sync.Mutex
+	jobs    map[string]Job
+	posters map[string]Poster
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// NewScheduler creates a Scheduler. A nil store defaults to a MemoryStore.
+func NewScheduler(store Store, registry *Registry) *Scheduler {
+	if store == nil {
+		store = NewMemoryStore()
+	}
+	return &Scheduler{
+		Store:    store,
+		Registry: registry,
+		jobs:     make(map[string]Job),
+		posters:  make(map[string]Poster),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+// Start loads pending jobs from the store and starts publishing them as they
+// become due. Jobs that are already overdue are published right away.
+func (s *Scheduler) Start(ctx context.Context) error {
+	jobs, err := s.Store.List()
+	if err != nil {
+		return fmt.Errorf("failed to load scheduled jobs: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return errors.New("scheduler is already running")
+	}
+	for _, job := range jobs {
+		s.jobs[job.ID] = job
+	}
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	s.mu.Unlock()
+
+	go s.run(ctx)
+	return nil
+}
+
+// Stop stops the scheduler and waits for a post in flight to finish. Pending
+// jobs stay in the store.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel, s.done = nil, nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// Schedule queues req to be published by platform at publishAt and returns
+// the queued job. Jobs for one of the built-in adapters are recorded with
+// their platform name so they can be resumed after a restart; other Posters
+// are only kept for the lifetime of the process.
+func (s *Scheduler) Schedule(platform Poster, req PostRequest, publishAt time.Time) (Job, error) {
+	if platform == nil {
+		return Job{}, errors.New("scheduled post requires a platform")
+	}
+
+	job := Job{
+		ID:        randomNonce(),
+		Platform:  posterPlatform(platform),
+		Request:   req,
+		PublishAt: publishAt,
+		CreatedAt: time.Now(),
+	}
+	if err := s.Store.Save(job); err != nil {
+		return Job{}, fmt.Errorf("failed to save scheduled job: %w", err)
+	}
+
+	s.mu.Lock()
+	s.jobs[job.ID] = job
+	s.posters[job.ID] = platform
+	s.mu.Unlock()
+
+	s.notify()
+	return job, nil
+}
+
+// Cancel removes a pending job
+func (s *Scheduler) Cancel(jobID string) error {
+	s.mu.Lock()
+	_, ok := s.jobs[jobID]
+	delete(s.jobs, jobID)
+	delete(s.posters, jobID)
+	s.mu.Unlock()
+
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrJobNotFound, jobID)
+	}
+	if err := s.Store.Delete(jobID); err != nil {
+		return fmt.Errorf("failed to delete scheduled job: %w", err)
+	}
+
+	s.notify()
+	return nil
+}
+
+// ListPending returns the jobs that have not been published yet, soonest first
+func (s *Scheduler) ListPending() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]Job, 0, len(s.jobs))
+	for _, job := range s.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].PublishAt.Before(jobs[j].PublishAt)
+	})
+	return jobs
+}
+
+// notify wakes the run loop so it picks up a changed schedule
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run publishes due jobs and sleeps until the next one, or until the schedule
+// changes
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.done)
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		for _, job := range s.takeDue(time.Now()) {
+			s.publish(ctx, job)
+		}
+
+		wait := time.Hour
+		if next, ok := s.nextPublishAt(); ok {
+			wait = max(time.Until(next), 0)
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// takeDue removes and returns the jobs due at now, together with their Posters
+func (s *Scheduler) takeDue(now time.Time) []scheduledPost {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var due []scheduledPost
+	for id, job := range s.jobs {
+		if job.PublishAt.After(now) {
+			continue
+		}
+		due = append(due, scheduledPost{job: job, poster: s.posters[id]})
+		delete(s.jobs, id)
+		delete(s.posters, id)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].job.PublishAt.Before(due[j].job.PublishAt)
+	})
+	return due
+}
+
+// nextPublishAt returns the publish time of the soonest pending job
+func (s *Scheduler) nextPublishAt() (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var next time.Time
+	for _, job := range s.jobs {
+		if next.IsZero() || job.PublishAt.Before(next) {
+			next = job.PublishAt
+		}
+	}
+	return next, !next.IsZero()
+}
+
+type scheduledPost struct {
+	job    Job
+	poster Poster
+}
+
+// publish posts a due job, removes it from the store and reports the result
+func (s *Scheduler) publish(ctx context.Context, p scheduledPost) {
+	result := JobResult{Job: p.job}
+
+	poster := p.poster
+	if poster == nil && s.Registry != nil {
+		poster, _ = s.Registry.Get(p.job.Platform)
+	}
+	if poster == nil {
+		result.Err = fmt.Errorf("%w: %q", ErrPlatformNotRegistered, p.job.Platform)
+	} else {
+		result.Result, result.Err = poster.Post(ctx, p.job.Request)
+	}
+
+	// a post interrupted by Stop stays in the store and runs again on the
+	// next Start
+	if result.Err != nil && ctx.Err() != nil {
+		return
+	}
+
+	if err := s.Store.Delete(p.job.ID); err != nil && result.Err == nil {
+		result.Err = fmt.Errorf("failed to delete scheduled job: %w", err)
+	}
+	if s.OnResult != nil {
+		s.OnResult(result)
+	}
+}
+
+// posterPlatform returns the platform name of a built-in Poster adapter, or ""
+// for other Posters
+func posterPlatform(poster Poster) string {
+	switch p := poster.(type) {
+	case TwitterPoster, *TwitterPoster:
+		return PlatformTwitter
+	case FacebookPoster, *FacebookPoster:
+		return PlatformFacebook
+	case InstagramPoster, *InstagramPoster:
+		return PlatformInstagram
+	case LinkedInPoster, *LinkedInPoster:
+		return PlatformLinkedIn
+	case PinterestPoster, *PinterestPoster:
+		return PlatformPinterest
+	case RedditPoster, *RedditPoster:
+		return PlatformReddit
+	case DribbblePoster, *DribbblePoster:
+		return PlatformDribbble
+	case VideoPoster:
+		return p.Platform
+	case MessagingPoster:
+		return p.Platform
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package integrations
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// posterFunc adapts a function to the Poster interface
+type posterFunc func(ctx context.Context, req PostRequest) (PostResult, error)
+
+func (f posterFunc) Post(ctx context.Context, req PostRequest) (PostResult, error) {
+	return f(ctx, req)
+}
+
+// startScheduler starts s and returns a channel receiving every JobResult
+func startScheduler(t *testing.T, s *Scheduler) <-chan JobResult {
+	t.Helper()
+
+	results := make(chan JobResult, 10)
+	s.OnResult = func(r JobResult) { results <- r }
+	if err := s.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+	return results
+}
+
+func waitForResult(t *testing.T, results <-chan JobResult) JobResult {
+	t.Helper()
+
+	select {
+	case r := <-results:
+		return r
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a scheduled job")
+		return JobResult{}
+	}
+}
+
+func TestSchedulerPublishesJobsInOrder(t *testing.T) {
+	poster := posterFunc(func(ctx context.Context, req PostRequest) (PostResult, error) {
+		return PostResult{Platform: "test", ID: req.Text}, nil
+	})
+
+	store := NewMemoryStore()
+	s := NewScheduler(store, nil)
+	results := startScheduler(t, s)
+
+	now := time.Now()
+	later, err := s.Schedule(poster, PostRequest{Text: "later"}, now.Add(80*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sooner, err := s.Schedule(poster, PostRequest{Text: "sooner"}, now.Add(40*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pending := s.ListPending()
+	if len(pending) != 2 || pending[0].ID != sooner.ID || pending[1].ID != later.ID {
+		t.Fatalf("ListPending() = %+v, want the sooner job first", pending)
+	}
+	if stored, _ := store.List(); len(stored) != 2 {
+		t.Errorf("store holds %d jobs, want 2", len(stored))
+	}
+
+	for _, want := range []Job{sooner, later} {
+		r := waitForResult(t, results)
+		if r.Err != nil {
+			t.Fatal(r.Err)
+		}
+		if r.Job.ID != want.ID || r.Result.ID != want.Request.Text {
+			t.Errorf("published %+v, want job %q", r, want.Request.Text)
+		}
+		if time.Now().Before(want.PublishAt) {
+			t.Errorf("job %q published before %v", want.Request.Text, want.PublishAt)
+		}
+	}
+
+	if pending := s.ListPending(); len(pending) != 0 {
+		t.Errorf("ListPending() = %+v after publishing", pending)
+	}
+	if stored, _ := store.List(); len(stored) != 0 {
+		t.Errorf("store still holds %+v after publishing", stored)
+	}
+}
+
+func TestSchedulerCancel(t *testing.T) {
+	poster := posterFunc(func(ctx context.Context, req PostRequest) (PostResult, error) {
+		t.Errorf("cancelled job %q was published", req.Text)
+		return PostResult{}, nil
+	})
+
+	store := NewMemoryStore()
+	s := NewScheduler(store, nil)
+	results := startScheduler(t, s)
+
+	job, err := s.Schedule(poster, PostRequest{Text: "cancelled"}, time.Now().Add(50*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Cancel(job.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Cancel(job.ID); !errors.Is(err, ErrJobNotFound) {
+		t.Errorf("second Cancel() = %v, want ErrJobNotFound", err)
+	}
+	if pending := s.ListPending(); len(pending) != 0 {
+		t.Errorf("ListPending() = %+v after Cancel", pending)
+	}
+	if stored, _ := store.List(); len(stored) != 0 {
+		t.Errorf("store still holds %+v after Cancel", stored)
+	}
+
+	select {
+	case r := <-results:
+		t.Errorf("got result %+v for a cancelled job", r)
+	case <-time.After(150 * time.Millisecond):
+	}
+}
+
+func TestSchedulerResumesStoredJobs(t *testing.T) {
+	store := NewMemoryStore()
+	overdue := Job{ID: "overdue", Platform: PlatformTwitter, Request: PostRequest{Text: "hello"}, PublishAt: time.Now().Add(-time.Minute)}
+	orphan := Job{ID: "orphan", Platform: PlatformPinterest, PublishAt: time.Now().Add(-time.Second)}
+	for _, job := range []Job{overdue, orphan} {
+		if err := store.Save(job); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry := NewRegistry()
+	registry.Register(PlatformTwitter, posterFunc(func(ctx context.Context, req PostRequest) (PostResult, error) {
+		return PostResult{Platform: PlatformTwitter, ID: "tweet-" + req.Text}, nil
+	}))
+
+	s := NewScheduler(store, registry)
+	results := startScheduler(t, s)
+
+	// overdue jobs are published oldest first
+	first := waitForResult(t, results)
+	if first.Job.ID != overdue.ID || first.Err != nil || first.Result.ID != "tweet-hello" {
+		t.Errorf("first result = %+v, want the resumed Twitter job", first)
+	}
+	second := waitForResult(t, results)
+	if second.Job.ID != orphan.ID || !errors.Is(second.Err, ErrPlatformNotRegistered) {
+		t.Errorf("second result = %+v, want ErrPlatformNotRegistered", second)
+	}
+
+	if stored, _ := store.List(); len(stored) != 0 {
+		t.Errorf("store still holds %+v", stored)
+	}
+}
+
+func TestSchedulerSchedulesBuiltInPlatformName(t *testing.T) {
+	s := NewScheduler(nil, nil)
+
+	job, err := s.Schedule(TwitterPoster{}, PostRequest{Text: "hi"}, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Platform != PlatformTwitter {
+		t.Errorf("Platform = %q, want %q", job.Platform, PlatformTwitter)
+	}
+	if _, err := s.Schedule(nil, PostRequest{}, time.Now()); err == nil {
+		t.Error("expected an error for a nil platform")
+	}
+}
@@ -2,10 +2,19 @@ package integrations
 
 import (
 	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 // Common structs and interfaces
@@ -16,12 +25,39 @@ type MessagingService interface {
 	GetCommunityStats(communityID string) (interface{}, error)
 }
 
+// DirectMessenger sends a private text message to a single user. recipientID
+// is the platform's ID for the user: a Twitter user ID, an Instagram-scoped
+// user ID, a WhatsApp phone number, a Telegram user ID or a Slack user ID.
+type DirectMessenger interface {
+	SendDM(ctx context.Context, recipientID, text string) (messageID string, err error)
+}
+
+var (
+	_ DirectMessenger = (*TwitterClient)(nil)
+	_ DirectMessenger = (*InstagramClient)(nil)
+	_ DirectMessenger = (*WhatsAppClient)(nil)
+	_ DirectMessenger = (*TelegramClient)(nil)
+	_ DirectMessenger = (*SlackClient)(nil)
+)
+
+// splitCompositeID splits an ID of the form "container:item", such as
+// "chatID:messageID". Only the first colon separates the parts, so the item
+// may itself contain colons.
+func splitCompositeID(id string) (string, string, error) {
+	parts := strings.SplitN(id, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid composite ID %q: expected format \"<container>:<id>\"", id)
+	}
+	return parts[0], parts[1], nil
+}
+
 // ==================== WhatsApp Business API ====================
 
 type WhatsAppClient struct {
 	AccessToken   string
 	PhoneNumberID string
 	BaseURL       string
+	HTTPClient    *http.Client
 }
 
 func NewWhatsAppClient(accessToken, phoneNumberID string) *WhatsAppClient {
@@ -29,11 +65,38 @@ func NewWhatsAppClient(accessToken, phoneNumberID string) *WhatsAppClient {
 		AccessToken:   accessToken,
 		PhoneNumberID: phoneNumberID,
 		BaseURL:       "https://graph.facebook.com/v17.0",
+		HTTPClient:    &http.Client{},
 	}
 }
 
+// WithHTTPClient sets the client used for API requests
+func (w *WhatsAppClient) WithHTTPClient(client *http.Client) *WhatsAppClient {
+	w.HTTPClient = client
+	return w
+}
+
+// Valid reports whether the client has the credentials it needs
+func (w *WhatsAppClient) Valid() error {
+	if w.AccessToken == "" {
+		return fmt.Errorf("%w: WhatsApp access token is empty", ErrNotConfigured)
+	}
+	if w.PhoneNumberID == "" {
+		return fmt.Errorf("%w: WhatsApp phone number ID is empty", ErrNotConfigured)
+	}
+	return nil
+}
+
 // CreatePost sends a message to a WhatsApp user
 func (w *WhatsAppClient) CreatePost(content string, recipientPhone string) (string, error) {
+	return w.sendText(context.Background(), content, recipientPhone)
+}
+
+// sendText sends a text message to a phone number and returns its message ID
+func (w *WhatsAppClient) sendText(ctx context.Context, content string, recipientPhone string) (string, error) {
+	if err := w.Valid(); err != nil {
+		return "", err
+	}
+
 	url := fmt.Sprintf("%s/%s/messages", w.BaseURL, w.PhoneNumberID)
 
 	requestBody, err := json.Marshal(map[string]interface{}{
@@ -49,7 +112,7 @@ func (w *WhatsAppClient) CreatePost(content string, recipientPhone string) (stri
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return "", err
 	}
@@ -57,7 +120,7 @@ func (w *WhatsAppClient) CreatePost(content string, recipientPhone string) (stri
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+w.AccessToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(w.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -90,8 +153,17 @@ func (w *WhatsAppClient) CreatePost(content string, recipientPhone string) (stri
 	return "", fmt.Errorf("failed to extract message ID")
 }
 
+// SendDM sends a text message to a phone number
+func (w *WhatsAppClient) SendDM(ctx context.Context, recipientID, text string) (string, error) {
+	return w.sendText(ctx, text, recipientID)
+}
+
 // ReplyToComment replies to a specific message in WhatsApp
 func (w *WhatsAppClient) ReplyToComment(messageID string, content string) (string, error) {
+	if err := w.Valid(); err != nil {
+		return "", err
+	}
+
 	url := fmt.Sprintf("%s/%s/messages", w.BaseURL, w.PhoneNumberID)
 
 	// In WhatsApp Business API, we need recipient phone
@@ -102,7 +174,11 @@ func (w *WhatsAppClient) ReplyToComment(messageID string, content string) (strin
 
 	// In a real implementation, you would retrieve the recipient phone from the messageID
 	// For this example, we assume it's provided in the messageID string as "phone:messageID"
-	fmt.Sscanf(messageID, "%s:%s", &parts.RecipientPhone, &parts.MessageID)
+	first, second, err := splitCompositeID(messageID)
+	if err != nil {
+		return "", err
+	}
+	parts.RecipientPhone, parts.MessageID = first, second
 
 	requestBody, err := json.Marshal(map[string]interface{}{
 		"messaging_product": "whatsapp",
@@ -128,7 +204,7 @@ func (w *WhatsAppClient) ReplyToComment(messageID string, content string) (strin
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+w.AccessToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(w.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -163,6 +239,10 @@ func (w *WhatsAppClient) ReplyToComment(messageID string, content string) (strin
 
 // GetPostStats gets message status information for a WhatsApp message
 func (w *WhatsAppClient) GetPostStats(messageID string) (interface{}, error) {
+	if err := w.Valid(); err != nil {
+		return nil, err
+	}
+
 	// WhatsApp Business API doesn't provide direct stats endpoint for a specific message
 	// Instead we can use the messages status webhook
 	// This function would retrieve stored message stats from your database
@@ -177,6 +257,10 @@ func (w *WhatsAppClient) GetPostStats(messageID string) (interface{}, error) {
 
 // GetCommunityStats gets statistics for a WhatsApp Business Account
 func (w *WhatsAppClient) GetCommunityStats(wabaID string) (interface{}, error) {
+	if err := w.Valid(); err != nil {
+		return nil, err
+	}
+
 	// For WhatsApp, we'd use the Insights API
 	url := fmt.Sprintf("%s/%s/insights", w.BaseURL, wabaID)
 
@@ -187,7 +271,7 @@ func (w *WhatsAppClient) GetCommunityStats(wabaID string) (interface{}, error) {
 
 	req.Header.Set("Authorization", "Bearer "+w.AccessToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(w.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -213,6 +297,10 @@ func (w *WhatsAppClient) GetCommunityStats(wabaID string) (interface{}, error) {
 
 // Additional WhatsApp functionalities
 func (w *WhatsAppClient) SendMediaMessage(recipientPhone, mediaType, mediaURL string) (string, error) {
+	if err := w.Valid(); err != nil {
+		return "", err
+	}
+
 	url := fmt.Sprintf("%s/%s/messages", w.BaseURL, w.PhoneNumberID)
 
 	requestBody, err := json.Marshal(map[string]interface{}{
@@ -236,7 +324,7 @@ func (w *WhatsAppClient) SendMediaMessage(recipientPhone, mediaType, mediaURL st
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+w.AccessToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(w.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -268,37 +356,189 @@ func (w *WhatsAppClient) SendMediaMessage(recipientPhone, mediaType, mediaURL st
 // ==================== Telegram API ====================
 
 type TelegramClient struct {
-	BotToken string
-	BaseURL  string
+	BotToken   string
+	BaseURL    string
+	HTTPClient *http.Client
+
+	statsMu    sync.Mutex
+	statsTTL   time.Duration
+	statsCache map[string]telegramStatsEntry
+}
+
+// defaultTelegramStatsCacheTTL is how long GetCommunityStats results are
+// reused by clients made with NewTelegramClient
+const defaultTelegramStatsCacheTTL = 30 * time.Second
+
+// telegramStatsEntry is a cached GetCommunityStats result
+type telegramStatsEntry struct {
+	stats   map[string]interface{}
+	expires time.Time
 }
 
 func NewTelegramClient(botToken string) *TelegramClient {
 	return &TelegramClient{
-		BotToken: botToken,
-		BaseURL:  "https://api.telegram.org/bot",
+		BotToken:   botToken,
+		BaseURL:    "https://api.telegram.org/bot",
+		HTTPClient: &http.Client{},
+		statsTTL:   defaultTelegramStatsCacheTTL,
+	}
+}
+
+// SetStatsCacheTTL sets how long GetCommunityStats results are reused for the
+// same chat before they are fetched again. Zero disables the cache and drops
+// any cached results.
+func (t *TelegramClient) SetStatsCacheTTL(ttl time.Duration) {
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	t.statsTTL = ttl
+	if ttl <= 0 {
+		t.statsCache = nil
+	}
+}
+
+// cachedStats returns a copy of the cached stats of a chat, if still fresh
+func (t *TelegramClient) cachedStats(chatID string) (map[string]interface{}, bool) {
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	entry, ok := t.statsCache[chatID]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, false
+	}
+
+	return copyJSONValue(entry.stats).(map[string]interface{}), true
+}
+
+// storeStats caches the stats of a chat when the cache is enabled
+func (t *TelegramClient) storeStats(chatID string, stats map[string]interface{}) {
+	t.statsMu.Lock()
+	defer t.statsMu.Unlock()
+
+	if t.statsTTL <= 0 {
+		return
+	}
+	if t.statsCache == nil {
+		t.statsCache = make(map[string]telegramStatsEntry)
+	}
+
+	now := time.Now()
+	for id, entry := range t.statsCache {
+		if now.After(entry.expires) {
+			delete(t.statsCache, id)
+		}
+	}
+	t.statsCache[chatID] = telegramStatsEntry{stats: copyJSONValue(stats).(map[string]interface{}), expires: now.Add(t.statsTTL)}
+}
+
+// copyJSONValue deep-copies a value decoded from JSON, so the nested maps and
+// slices of a cached result are not shared with callers
+func copyJSONValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, value := range v {
+			out[key] = copyJSONValue(value)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, value := range v {
+			out[i] = copyJSONValue(value)
+		}
+		return out
+	}
+	return v
+}
+
+// WithHTTPClient sets the client used for API requests
+func (t *TelegramClient) WithHTTPClient(client *http.Client) *TelegramClient {
+	t.HTTPClient = client
+	return t
+}
+
+// Valid reports whether the client has the credentials it needs
+func (t *TelegramClient) Valid() error {
+	if t.BotToken == "" {
+		return fmt.Errorf("%w: Telegram bot token is empty", ErrNotConfigured)
+	}
+	return nil
+}
+
+// Telegram parse modes for message text and captions
+const (
+	TelegramParseModeMarkdownV2 = "MarkdownV2"
+	TelegramParseModeHTML       = "HTML"
+)
+
+// InlineButton is a button of a Telegram inline keyboard. Set either URL or
+// CallbackData.
+type InlineButton struct {
+	Text         string `json:"text"`
+	URL          string `json:"url,omitempty"`
+	CallbackData string `json:"callback_data,omitempty"`
+}
+
+// SendMessageOptions holds optional settings for Telegram messages
+type SendMessageOptions struct {
+	ParseMode             string           // TelegramParseModeMarkdownV2 or TelegramParseModeHTML
+	DisableWebPagePreview bool             // text messages only
+	InlineKeyboard        [][]InlineButton // rows of buttons shown under the message
+}
+
+// apply adds the options to a Bot API request payload
+func (o SendMessageOptions) apply(payload map[string]interface{}) {
+	if o.ParseMode != "" {
+		payload["parse_mode"] = o.ParseMode
+	}
+	if len(o.InlineKeyboard) > 0 {
+		payload["reply_markup"] = map[string]interface{}{
+			"inline_keyboard": o.InlineKeyboard,
+		}
 	}
 }
 
 // CreatePost sends a message to a Telegram chat
 func (t *TelegramClient) CreatePost(content string, chatID string) (string, error) {
+	return t.CreatePostWithOptions(content, chatID, SendMessageOptions{})
+}
+
+// CreatePostWithOptions sends a message to a Telegram chat with formatting,
+// link preview and inline keyboard options
+func (t *TelegramClient) CreatePostWithOptions(content string, chatID string, opts SendMessageOptions) (string, error) {
+	return t.sendMessage(context.Background(), content, chatID, opts)
+}
+
+// sendMessage calls sendMessage and returns the ID of the sent message
+func (t *TelegramClient) sendMessage(ctx context.Context, content string, chatID string, opts SendMessageOptions) (string, error) {
+	if err := t.Valid(); err != nil {
+		return "", err
+	}
+
 	url := fmt.Sprintf("%s%s/sendMessage", t.BaseURL, t.BotToken)
 
-	requestBody, err := json.Marshal(map[string]interface{}{
+	payload := map[string]interface{}{
 		"chat_id": chatID,
 		"text":    content,
-	})
+	}
+	opts.apply(payload)
+	if opts.DisableWebPagePreview {
+		payload["disable_web_page_preview"] = true
+	}
+
+	requestBody, err := json.Marshal(payload)
 	if err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
 	if err != nil {
 		return "", err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := clientOrDefault(t.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -334,8 +574,18 @@ func (t *TelegramClient) CreatePost(content string, chatID string) (string, erro
 	return "", fmt.Errorf("failed to extract message ID")
 }
 
+// SendDM sends a message to a user's private chat with the bot. The user must
+// have started a conversation with the bot first.
+func (t *TelegramClient) SendDM(ctx context.Context, recipientID, text string) (string, error) {
+	return t.sendMessage(ctx, text, recipientID, SendMessageOptions{})
+}
+
 // ReplyToComment replies to a message in Telegram
 func (t *TelegramClient) ReplyToComment(messageID string, content string) (string, error) {
+	if err := t.Valid(); err != nil {
+		return "", err
+	}
+
 	// In Telegram, we need both the chat_id and message_id
 	parts := struct {
 		ChatID    string
@@ -344,7 +594,11 @@ func (t *TelegramClient) ReplyToComment(messageID string, content string) (strin
 
 	// In a real implementation, you would retrieve the chat_id from the messageID
 	// For this example, we assume it's provided in the messageID string as "chatID:messageID"
-	fmt.Sscanf(messageID, "%s:%s", &parts.ChatID, &parts.MessageID)
+	first, second, err := splitCompositeID(messageID)
+	if err != nil {
+		return "", err
+	}
+	parts.ChatID, parts.MessageID = first, second
 
 	url := fmt.Sprintf("%s%s/sendMessage", t.BaseURL, t.BotToken)
 
@@ -364,7 +618,7 @@ func (t *TelegramClient) ReplyToComment(messageID string, content string) (strin
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := clientOrDefault(t.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -402,6 +656,10 @@ func (t *TelegramClient) ReplyToComment(messageID string, content string) (strin
 
 // GetPostStats gets information about a message in Telegram
 func (t *TelegramClient) GetPostStats(messageID string) (interface{}, error) {
+	if err := t.Valid(); err != nil {
+		return nil, err
+	}
+
 	// Telegram API doesn't have a direct endpoint for message stats
 	// For group/channel posts, we can get view count via getMessages method
 	parts := struct {
@@ -409,7 +667,11 @@ func (t *TelegramClient) GetPostStats(messageID string) (interface{}, error) {
 		MessageID string
 	}{}
 
-	fmt.Sscanf(messageID, "%s:%s", &parts.ChatID, &parts.MessageID)
+	first, second, err := splitCompositeID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	parts.ChatID, parts.MessageID = first, second
 
 	url := fmt.Sprintf("%s%s/getMessages", t.BaseURL, t.BotToken)
 
@@ -428,7 +690,7 @@ func (t *TelegramClient) GetPostStats(messageID string) (interface{}, error) {
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := clientOrDefault(t.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -459,8 +721,17 @@ func (t *TelegramClient) GetPostStats(messageID string) (interface{}, error) {
 	return nil, fmt.Errorf("failed to get message stats")
 }
 
-// GetCommunityStats gets stats for a Telegram channel or group
+// GetCommunityStats gets stats for a Telegram channel or group. Results are
+// cached per chat for the TTL set with SetStatsCacheTTL.
 func (t *TelegramClient) GetCommunityStats(chatID string) (interface{}, error) {
+	if err := t.Valid(); err != nil {
+		return nil, err
+	}
+
+	if stats, ok := t.cachedStats(chatID); ok {
+		return stats, nil
+	}
+
 	url := fmt.Sprintf("%s%s/getChatMembersCount", t.BaseURL, t.BotToken)
 
 	requestBody, err := json.Marshal(map[string]interface{}{
@@ -477,7 +748,7 @@ func (t *TelegramClient) GetCommunityStats(chatID string) (interface{}, error) {
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := clientOrDefault(t.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -489,12 +760,8 @@ func (t *TelegramClient) GetCommunityStats(chatID string) (interface{}, error) {
 		return nil, err
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("error: %s", string(body))
-	}
-
-	var memberCountResult map[string]interface{}
-	if err := json.Unmarshal(body, &memberCountResult); err != nil {
+	memberCount, err := telegramResult(resp.StatusCode, body)
+	if err != nil {
 		return nil, err
 	}
 
@@ -526,22 +793,52 @@ func (t *TelegramClient) GetCommunityStats(chatID string) (interface{}, error) {
 		return nil, err
 	}
 
-	var chatInfoResult map[string]interface{}
-	if err := json.Unmarshal(chatInfoBody, &chatInfoResult); err != nil {
+	chatInfo, err := telegramResult(chatInfoResp.StatusCode, chatInfoBody)
+	if err != nil {
 		return nil, err
 	}
 
-	// Combine results
+	// Combine results; only complete stats reach the cache
 	stats := map[string]interface{}{
-		"member_count": memberCountResult["result"],
-		"chat_info":    chatInfoResult["result"],
+		"member_count": memberCount,
+		"chat_info":    chatInfo,
 	}
+	t.storeStats(chatID, stats)
 
 	return stats, nil
 }
 
+// telegramResult returns the result of a Bot API response, or an error when
+// the request failed or the response is not ok
+func telegramResult(statusCode int, body []byte) (interface{}, error) {
+	if statusCode != http.StatusOK {
+		return nil, fmt.Errorf("error: %s", string(body))
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	if ok, exists := result["ok"].(bool); !exists || !ok {
+		return nil, fmt.Errorf("telegram API error: %v", result["description"])
+	}
+
+	return result["result"], nil
+}
+
 // Additional Telegram functionalities
 func (t *TelegramClient) SendMediaMessage(chatID, mediaType, mediaURL, caption string) (string, error) {
+	return t.SendMediaMessageWithOptions(chatID, mediaType, mediaURL, caption, SendMessageOptions{})
+}
+
+// SendMediaMessageWithOptions is SendMediaMessage with options for the
+// caption and an inline keyboard. DisableWebPagePreview is ignored.
+func (t *TelegramClient) SendMediaMessageWithOptions(chatID, mediaType, mediaURL, caption string, opts SendMessageOptions) (string, error) {
+	if err := t.Valid(); err != nil {
+		return "", err
+	}
+
 	var endpoint string
 	switch mediaType {
 	case "photo":
@@ -558,11 +855,14 @@ func (t *TelegramClient) SendMediaMessage(chatID, mediaType, mediaURL, caption s
 
 	url := fmt.Sprintf("%s%s/%s", t.BaseURL, t.BotToken, endpoint)
 
-	requestBody, err := json.Marshal(map[string]interface{}{
+	payload := map[string]interface{}{
 		"chat_id": chatID,
 		mediaType: mediaURL,
 		"caption": caption,
-	})
+	}
+	opts.apply(payload)
+
+	requestBody, err := json.Marshal(payload)
 	if err != nil {
 		return "", err
 	}
@@ -574,7 +874,7 @@ func (t *TelegramClient) SendMediaMessage(chatID, mediaType, mediaURL, caption s
 
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := clientOrDefault(t.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -604,73 +904,178 @@ func (t *TelegramClient) SendMediaMessage(chatID, mediaType, mediaURL, caption s
 // ==================== Slack API ====================
 
 type SlackClient struct {
-	BotToken string
-	BaseURL  string
+	BotToken   string
+	BaseURL    string
+	HTTPClient *http.Client
 }
 
 func NewSlackClient(botToken string) *SlackClient {
 	return &SlackClient{
-		BotToken: botToken,
-		BaseURL:  "https://slack.com/api",
+		BotToken:   botToken,
+		BaseURL:    "https://slack.com/api",
+		HTTPClient: &http.Client{},
+	}
+}
+
+// WithHTTPClient sets the client used for API requests
+func (s *SlackClient) WithHTTPClient(client *http.Client) *SlackClient {
+	s.HTTPClient = client
+	return s
+}
+
+// Valid reports whether the client has the credentials it needs
+func (s *SlackClient) Valid() error {
+	if s.BotToken == "" {
+		return fmt.Errorf("%w: Slack bot token is empty", ErrNotConfigured)
 	}
+	return nil
 }
 
 // CreatePost sends a message to a Slack channel
 func (s *SlackClient) CreatePost(content string, channelID string) (string, error) {
-	url := fmt.Sprintf("%s/chat.postMessage", s.BaseURL)
-
-	requestBody, err := json.Marshal(map[string]interface{}{
+	return s.postMessage(context.Background(), channelID, map[string]interface{}{
 		"channel": channelID,
 		"text":    content,
 	})
+}
+
+// PostMessageBlocks sends a Block Kit message to a Slack channel. fallbackText
+// is shown in notifications and by clients that cannot render blocks.
+func (s *SlackClient) PostMessageBlocks(channelID string, blocks []map[string]interface{}, fallbackText string) (string, error) {
+	if len(blocks) == 0 {
+		return "", errors.New("slack message requires at least one block")
+	}
+
+	return s.postMessage(context.Background(), channelID, map[string]interface{}{
+		"channel": channelID,
+		"text":    fallbackText,
+		"blocks":  blocks,
+	})
+}
+
+// postMessage calls chat.postMessage and returns the message ID as
+// "channelID:ts"
+func (s *SlackClient) postMessage(ctx context.Context, channelID string, payload map[string]interface{}) (string, error) {
+	result, err := s.call(ctx, "chat.postMessage", payload)
 	if err != nil {
 		return "", err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(requestBody))
+	// Extract message timestamp (used as ID in Slack)
+	if ts, ok := result["ts"].(string); ok {
+		return fmt.Sprintf("%s:%s", channelID, ts), nil
+	}
+
+	return "", fmt.Errorf("failed to extract message timestamp")
+}
+
+// UpdateMessage replaces the text of a message the bot posted. messageID is
+// the "channel:ts" ID returned when the message was posted, and the same ID is
+// returned on success.
+func (s *SlackClient) UpdateMessage(messageID string, newText string) (string, error) {
+	channelID, ts, err := splitCompositeID(messageID)
 	if err != nil {
 		return "", err
 	}
 
+	result, err := s.call(context.Background(), "chat.update", map[string]interface{}{
+		"channel": channelID,
+		"ts":      ts,
+		"text":    newText,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if updatedTS, ok := result["ts"].(string); ok {
+		ts = updatedTS
+	}
+	return fmt.Sprintf("%s:%s", channelID, ts), nil
+}
+
+// DeleteMessage deletes a message the bot posted, given its "channel:ts" ID
+func (s *SlackClient) DeleteMessage(messageID string) error {
+	channelID, ts, err := splitCompositeID(messageID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.call(context.Background(), "chat.delete", map[string]interface{}{
+		"channel": channelID,
+		"ts":      ts,
+	})
+	return err
+}
+
+// SlackAPIError is a Web API response with "ok": false. Code is Slack's error
+// string, such as "message_not_found" or "cant_update_message".
+type SlackAPIError struct {
+	Method string
+	Code   string
+}
+
+func (e *SlackAPIError) Error() string {
+	return fmt.Sprintf("slack API error: %s (%s)", e.Code, e.Method)
+}
+
+// call posts a JSON payload to a Web API method and returns the decoded
+// response, or a *SlackAPIError when Slack reports a failure
+func (s *SlackClient) call(ctx context.Context, method string, payload map[string]interface{}) (map[string]interface{}, error) {
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/%s", s.BaseURL, method)
+
+	requestBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return nil, err
+	}
+
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+s.BotToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(s.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("error: %s", string(body))
+		return nil, fmt.Errorf("error: %s", string(body))
 	}
 
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
-		return "", err
+		return nil, err
 	}
 
 	// Check if request was successful
 	if ok, exists := result["ok"].(bool); !exists || !ok {
-		return "", fmt.Errorf("slack API error: %v", result["error"])
-	}
-
-	// Extract message timestamp (used as ID in Slack)
-	if ts, ok := result["ts"].(string); ok {
-		return fmt.Sprintf("%s:%s", channelID, ts), nil
+		code, _ := result["error"].(string)
+		return nil, &SlackAPIError{Method: method, Code: code}
 	}
 
-	return "", fmt.Errorf("failed to extract message timestamp")
+	return result, nil
 }
 
 // ReplyToComment replies to a thread in Slack
 func (s *SlackClient) ReplyToComment(threadID string, content string) (string, error) {
+	if err := s.Valid(); err != nil {
+		return "", err
+	}
+
 	// In Slack, we need both the channel_id and thread_ts
 	parts := struct {
 		ChannelID string
@@ -678,7 +1083,11 @@ func (s *SlackClient) ReplyToComment(threadID string, content string) (string, e
 	}{}
 
 	// Extract channel and thread timestamp
-	fmt.Sscanf(threadID, "%s:%s", &parts.ChannelID, &parts.ThreadTS)
+	first, second, err := splitCompositeID(threadID)
+	if err != nil {
+		return "", err
+	}
+	parts.ChannelID, parts.ThreadTS = first, second
 
 	url := fmt.Sprintf("%s/chat.postMessage", s.BaseURL)
 
@@ -699,7 +1108,7 @@ func (s *SlackClient) ReplyToComment(threadID string, content string) (string, e
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", "Bearer "+s.BotToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(s.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
@@ -735,13 +1144,21 @@ func (s *SlackClient) ReplyToComment(threadID string, content string) (string, e
 
 // GetPostStats gets information about a message or thread in Slack
 func (s *SlackClient) GetPostStats(messageID string) (interface{}, error) {
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+
 	// Extract channel and thread timestamp
 	parts := struct {
 		ChannelID string
 		MessageTS string
 	}{}
 
-	fmt.Sscanf(messageID, "%s:%s", &parts.ChannelID, &parts.MessageTS)
+	first, second, err := splitCompositeID(messageID)
+	if err != nil {
+		return nil, err
+	}
+	parts.ChannelID, parts.MessageTS = first, second
 
 	// Get message information
 	url := fmt.Sprintf("%s/conversations.history", s.BaseURL)
@@ -760,7 +1177,7 @@ func (s *SlackClient) GetPostStats(messageID string) (interface{}, error) {
 
 	req.Header.Set("Authorization", "Bearer "+s.BotToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(s.HTTPClient)
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
@@ -832,6 +1249,10 @@ func (s *SlackClient) GetPostStats(messageID string) (interface{}, error) {
 
 // GetCommunityStats gets information about a Slack channel
 func (s *SlackClient) GetCommunityStats(channelID string) (interface{}, error) {
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+
 	// Get channel info
 	infoUrl := fmt.Sprintf("%s/conversations.info", s.BaseURL)
 
@@ -846,7 +1267,7 @@ func (s *SlackClient) GetCommunityStats(channelID string) (interface{}, error) {
 
 	infoReq.Header.Set("Authorization", "Bearer "+s.BotToken)
 
-	client := &http.Client{}
+	client := clientOrDefault(s.HTTPClient)
 	infoResp, err := client.Do(infoReq)
 	if err != nil {
 		return nil, err
@@ -914,6 +1335,215 @@ func (s *SlackClient) GetCommunityStats(channelID string) (interface{}, error) {
 	return stats, nil
 }
 
+// SlackChannel represents a Slack conversation returned by conversations.list
+type SlackChannel struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	IsChannel  bool   `json:"is_channel"`
+	IsGroup    bool   `json:"is_group"`
+	IsIM       bool   `json:"is_im"`
+	IsMPIM     bool   `json:"is_mpim"`
+	IsPrivate  bool   `json:"is_private"`
+	IsArchived bool   `json:"is_archived"`
+	IsMember   bool   `json:"is_member"`
+	User       string `json:"user,omitempty"`
+	NumMembers int    `json:"num_members,omitempty"`
+}
+
+// OpenDM opens (or resumes) a direct message conversation with a user and
+// returns its channel ID
+func (s *SlackClient) OpenDM(userID string) (string, error) {
+	return s.openDM(context.Background(), userID)
+}
+
+// openDM calls conversations.open for a single user
+func (s *SlackClient) openDM(ctx context.Context, userID string) (string, error) {
+	if err := s.Valid(); err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/conversations.open", s.BaseURL)
+
+	requestBody, err := json.Marshal(map[string]interface{}{
+		"users": userID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+s.BotToken)
+
+	client := clientOrDefault(s.HTTPClient)
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("error: %s", string(body))
+	}
+
+	var result struct {
+		OK      bool   `json:"ok"`
+		Error   string `json:"error"`
+		Channel struct {
+			ID string `json:"id"`
+		} `json:"channel"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return "", err
+	}
+
+	if !result.OK {
+		return "", fmt.Errorf("slack API error: %v", result.Error)
+	}
+
+	if result.Channel.ID == "" {
+		return "", fmt.Errorf("failed to extract channel ID")
+	}
+
+	return result.Channel.ID, nil
+}
+
+// SendDM opens a direct message conversation with a user and posts text to
+// it, returning the "channel:ts" message ID
+func (s *SlackClient) SendDM(ctx context.Context, recipientID, text string) (string, error) {
+	channelID, err := s.openDM(ctx, recipientID)
+	if err != nil {
+		return "", err
+	}
+	return s.postMessage(ctx, channelID, map[string]interface{}{
+		"channel": channelID,
+		"text":    text,
+	})
+}
+
+// ListChannels lists the conversations visible to the bot, following the
+// cursor until every page has been read. types is a comma-separated list such
+// as "public_channel,private_channel,im,mpim"; empty uses Slack's default.
+func (s *SlackClient) ListChannels(types string) ([]SlackChannel, error) {
+	if err := s.Valid(); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/conversations.list", s.BaseURL)
+	client := clientOrDefault(s.HTTPClient)
+
+	var channels []SlackChannel
+	cursor := ""
+
+	for {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		q := req.URL.Query()
+		if types != "" {
+			q.Add("types", types)
+		}
+		q.Add("limit", "200")
+		if cursor != "" {
+			q.Add("cursor", cursor)
+		}
+		req.URL.RawQuery = q.Encode()
+
+		req.Header.Set("Authorization", "Bearer "+s.BotToken)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("error: %s", string(body))
+		}
+
+		var result struct {
+			OK               bool           `json:"ok"`
+			Error            string         `json:"error"`
+			Channels         []SlackChannel `json:"channels"`
+			ResponseMetadata struct {
+				NextCursor string `json:"next_cursor"`
+			} `json:"response_metadata"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return nil, err
+		}
+
+		if !result.OK {
+			return nil, fmt.Errorf("slack API error: %v", result.Error)
+		}
+
+		channels = append(channels, result.Channels...)
+
+		cursor = result.ResponseMetadata.NextCursor
+		if cursor == "" {
+			break
+		}
+	}
+
+	return channels, nil
+}
+
+// slackSignatureVersion is the version prefix of Slack request signatures
+const slackSignatureVersion = "v0"
+
+// slackSignatureMaxAge is how far a request timestamp may be from now before
+// the request is rejected as a possible replay
+const slackSignatureMaxAge = 5 * time.Minute
+
+// VerifySlackSignature reports whether headerValue, the X-Slack-Signature
+// header of an Events API request, matches the raw body signed with the app's
+// signing secret. timestamp is the X-Slack-Request-Timestamp header; requests
+// older or newer than five minutes are rejected.
+func VerifySlackSignature(signingSecret, timestamp string, body []byte, headerValue string) bool {
+	if signingSecret == "" {
+		return false
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	age := time.Since(time.Unix(ts, 0))
+	if age > slackSignatureMaxAge || age < -slackSignatureMaxAge {
+		return false
+	}
+
+	prefix := slackSignatureVersion + "="
+	if !strings.HasPrefix(headerValue, prefix) {
+		return false
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(headerValue, prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(slackSignatureVersion + ":" + timestamp + ":"))
+	mac.Write(body)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
 // // Additional Slack functionalities
 // func (s *SlackClient) SendMediaMessage(channelID, text string, files []string) (string, error) {
 //     url :=
@@ -0,0 +1,674 @@
+package integrations
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newTestSlackClient(t *testing.T, handler http.HandlerFunc) *SlackClient {
+	t.Helper()
+
+	srv, client := newTestServer(t, handler)
+	s := NewSlackClient("xoxb-test")
+	s.BaseURL = srv.URL
+	s.HTTPClient = client
+	return s
+}
+
+func TestSlackOpenDMDecodesChannelID(t *testing.T) {
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/conversations.open" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer xoxb-test" {
+			t.Errorf("Authorization = %q", got)
+		}
+
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload["users"] != "U123" {
+			t.Errorf("users = %q, want U123", payload["users"])
+		}
+
+		fmt.Fprint(w, `{"ok":true,"channel":{"id":"D456","is_im":true}}`)
+	})
+
+	channelID, err := s.OpenDM("U123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if channelID != "D456" {
+		t.Errorf("channel ID = %q, want D456", channelID)
+	}
+}
+
+func TestSlackOpenDMReturnsAPIError(t *testing.T) {
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"error":"user_not_found"}`)
+	})
+
+	if _, err := s.OpenDM("U404"); err == nil {
+		t.Fatal("expected an error for ok=false")
+	}
+}
+
+func TestSlackListChannelsFollowsCursor(t *testing.T) {
+	var requests int
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/conversations.list" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("types") != "public_channel,im" {
+			t.Errorf("types = %q", q.Get("types"))
+		}
+
+		switch q.Get("cursor") {
+		case "":
+			fmt.Fprint(w, `{"ok":true,"channels":[
+				{"id":"C1","name":"general","is_channel":true,"is_member":true,"num_members":42}
+			],"response_metadata":{"next_cursor":"page2"}}`)
+		case "page2":
+			fmt.Fprint(w, `{"ok":true,"channels":[
+				{"id":"D2","is_im":true,"user":"U9"}
+			],"response_metadata":{"next_cursor":""}}`)
+		default:
+			t.Errorf("unexpected cursor %q", q.Get("cursor"))
+		}
+	})
+
+	channels, err := s.ListChannels("public_channel,im")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("requests = %d, want 2", requests)
+	}
+	if len(channels) != 2 {
+		t.Fatalf("got %d channels, want 2", len(channels))
+	}
+
+	general := channels[0]
+	if general.ID != "C1" || general.Name != "general" || !general.IsChannel || !general.IsMember || general.NumMembers != 42 {
+		t.Errorf("first channel decoded as %+v", general)
+	}
+	im := channels[1]
+	if im.ID != "D2" || !im.IsIM || im.User != "U9" {
+		t.Errorf("second channel decoded as %+v", im)
+	}
+}
+
+func newTestTelegramClient(t *testing.T, handler http.HandlerFunc) *TelegramClient {
+	t.Helper()
+
+	srv, client := newTestServer(t, handler)
+	c := NewTelegramClient("123:abc")
+	c.BaseURL = srv.URL + "/bot"
+	c.HTTPClient = client
+	return c
+}
+
+func TestTelegramSendMessageOptionsPayload(t *testing.T) {
+	keyboard := [][]InlineButton{
+		{{Text: "Read more", URL: "https://example.com/post"}},
+		{{Text: "Like", CallbackData: "like:42"}, {Text: "Share", CallbackData: "share:42"}},
+	}
+	wantMarkup := map[string]interface{}{
+		"inline_keyboard": []interface{}{
+			[]interface{}{
+				map[string]interface{}{"text": "Read more", "url": "https://example.com/post"},
+			},
+			[]interface{}{
+				map[string]interface{}{"text": "Like", "callback_data": "like:42"},
+				map[string]interface{}{"text": "Share", "callback_data": "share:42"},
+			},
+		},
+	}
+
+	tests := []struct {
+		name string
+		send func(c *TelegramClient) (string, error)
+		path string
+		want map[string]interface{}
+	}{
+		{
+			"text with options",
+			func(c *TelegramClient) (string, error) {
+				return c.CreatePostWithOptions("*bold*", "-100123", SendMessageOptions{
+					ParseMode:             TelegramParseModeMarkdownV2,
+					DisableWebPagePreview: true,
+					InlineKeyboard:        keyboard,
+				})
+			},
+			"/bot123:abc/sendMessage",
+			map[string]interface{}{
+				"chat_id":                  "-100123",
+				"text":                     "*bold*",
+				"parse_mode":               "MarkdownV2",
+				"disable_web_page_preview": true,
+				"reply_markup":             wantMarkup,
+			},
+		},
+		{
+			"text without options",
+			func(c *TelegramClient) (string, error) { return c.CreatePost("plain", "-100123") },
+			"/bot123:abc/sendMessage",
+			map[string]interface{}{"chat_id": "-100123", "text": "plain"},
+		},
+		{
+			"photo caption with options",
+			func(c *TelegramClient) (string, error) {
+				return c.SendMediaMessageWithOptions("-100123", "photo", "https://example.com/a.png", "<b>new</b>", SendMessageOptions{
+					ParseMode:             TelegramParseModeHTML,
+					DisableWebPagePreview: true,
+					InlineKeyboard:        keyboard,
+				})
+			},
+			"/bot123:abc/sendPhoto",
+			map[string]interface{}{
+				"chat_id":      "-100123",
+				"photo":        "https://example.com/a.png",
+				"caption":      "<b>new</b>",
+				"parse_mode":   "HTML",
+				"reply_markup": wantMarkup,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestTelegramClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost || r.URL.Path != tt.path {
+					t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				var payload map[string]interface{}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(payload, tt.want) {
+					t.Errorf("payload = %v, want %v", payload, tt.want)
+				}
+				fmt.Fprint(w, `{"ok":true,"result":{"message_id":42,"chat":{"id":-100123}}}`)
+			})
+
+			if _, err := tt.send(c); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestSlackPostMessageBlocks(t *testing.T) {
+	blocks := []map[string]interface{}{
+		{
+			"type": "section",
+			"text": map[string]interface{}{"type": "mrkdwn", "text": "*New post* is live"},
+		},
+		{
+			"type": "actions",
+			"elements": []interface{}{
+				map[string]interface{}{
+					"type": "button",
+					"text": map[string]interface{}{"type": "plain_text", "text": "Open"},
+					"url":  "https://example.com/post",
+				},
+			},
+		},
+	}
+
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/chat.postMessage" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var payload struct {
+			Channel string                   `json:"channel"`
+			Text    string                   `json:"text"`
+			Blocks  []map[string]interface{} `json:"blocks"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload.Channel != "C1" || payload.Text != "New post is live" {
+			t.Errorf("channel = %q, text = %q", payload.Channel, payload.Text)
+		}
+		want, _ := json.Marshal(blocks)
+		got, _ := json.Marshal(payload.Blocks)
+		if string(got) != string(want) {
+			t.Errorf("blocks = %s, want %s", got, want)
+		}
+
+		fmt.Fprint(w, `{"ok":true,"channel":"C1","ts":"1503435956.000247"}`)
+	})
+
+	id, err := s.PostMessageBlocks("C1", blocks, "New post is live")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "C1:1503435956.000247" {
+		t.Errorf("message ID = %q, want C1:1503435956.000247", id)
+	}
+}
+
+func TestSlackPostMessageBlocksErrors(t *testing.T) {
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"error":"invalid_blocks"}`)
+	})
+
+	_, err := s.PostMessageBlocks("C1", []map[string]interface{}{{"type": "nope"}}, "fallback")
+	var apiErr *SlackAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "invalid_blocks" || apiErr.Method != "chat.postMessage" {
+		t.Errorf("error = %v, want a chat.postMessage invalid_blocks SlackAPIError", err)
+	}
+
+	if _, err := s.PostMessageBlocks("C1", nil, "fallback"); err == nil {
+		t.Error("expected an error without blocks")
+	}
+}
+
+func TestSlackUpdateAndDeleteMessage(t *testing.T) {
+	var methods []string
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.URL.Path)
+
+		var payload map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if payload["channel"] != "C024BE91L" || payload["ts"] != "1503435956.000247" {
+			t.Errorf("%s payload = %v", r.URL.Path, payload)
+		}
+
+		switch r.URL.Path {
+		case "/chat.update":
+			if payload["text"] != "edited" {
+				t.Errorf("text = %q, want edited", payload["text"])
+			}
+			fmt.Fprint(w, `{"ok":true,"channel":"C024BE91L","ts":"1503435956.000247","text":"edited"}`)
+		case "/chat.delete":
+			if _, ok := payload["text"]; ok {
+				t.Error("chat.delete sent a text field")
+			}
+			fmt.Fprint(w, `{"ok":true,"channel":"C024BE91L","ts":"1503435956.000247"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	id, err := s.UpdateMessage("C024BE91L:1503435956.000247", "edited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "C024BE91L:1503435956.000247" {
+		t.Errorf("UpdateMessage() = %q, want the same message ID", id)
+	}
+	if err := s.DeleteMessage("C024BE91L:1503435956.000247"); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(methods) != "[/chat.update /chat.delete]" {
+		t.Errorf("methods = %v", methods)
+	}
+}
+
+func TestSlackUpdateAndDeleteMessageNotFound(t *testing.T) {
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"ok":false,"error":"message_not_found"}`)
+	})
+
+	_, err := s.UpdateMessage("C1:1503435956.000247", "edited")
+	var apiErr *SlackAPIError
+	if !errors.As(err, &apiErr) || apiErr.Code != "message_not_found" || apiErr.Method != "chat.update" {
+		t.Errorf("UpdateMessage() error = %v, want chat.update message_not_found", err)
+	}
+
+	err = s.DeleteMessage("C1:1503435956.000247")
+	if !errors.As(err, &apiErr) || apiErr.Code != "message_not_found" || apiErr.Method != "chat.delete" {
+		t.Errorf("DeleteMessage() error = %v, want chat.delete message_not_found", err)
+	}
+}
+
+func TestSlackUpdateAndDeleteMessageRejectMalformedIDs(t *testing.T) {
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	if _, err := s.UpdateMessage("1503435956.000247", "edited"); err == nil {
+		t.Error("UpdateMessage accepted an ID without a channel")
+	}
+	if err := s.DeleteMessage("C1:"); err == nil {
+		t.Error("DeleteMessage accepted an ID without a timestamp")
+	}
+}
+
+// telegramStatsServer answers getChatMembersCount and getChat, failing getChat
+// while failChat is set
+type telegramStatsServer struct {
+	mu       sync.Mutex
+	requests map[string]int
+	failChat bool
+}
+
+func (s *telegramStatsServer) handle(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	method := strings.TrimPrefix(r.URL.Path, "/bot123:abc/")
+	s.requests[method]++
+	switch {
+	case method == "getChatMembersCount":
+		fmt.Fprint(w, `{"ok":true,"result":1500}`)
+	case method == "getChat" && s.failChat:
+		fmt.Fprint(w, `{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5"}`)
+	case method == "getChat":
+		fmt.Fprint(w, `{"ok":true,"result":{"id":-100123,"title":"Postly news","type":"channel","pinned_message":{"message_id":7}}}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *telegramStatsServer) count(method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[method]
+}
+
+func TestTelegramGetCommunityStatsCache(t *testing.T) {
+	srv := &telegramStatsServer{requests: make(map[string]int)}
+	c := newTestTelegramClient(t, srv.handle)
+
+	first, err := c.GetCommunityStats("-100123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats := first.(map[string]interface{})
+	chatInfo := stats["chat_info"].(map[string]interface{})
+	if stats["member_count"] != float64(1500) || chatInfo["title"] != "Postly news" {
+		t.Fatalf("stats = %v", stats)
+	}
+
+	// changes made by a caller must not reach the cache, however deep
+	chatInfo["title"] = "changed"
+	chatInfo["pinned_message"].(map[string]interface{})["message_id"] = 0
+
+	second, err := c.GetCommunityStats("-100123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.count("getChat"); n != 1 {
+		t.Errorf("getChat requests = %d, want 1 within the TTL", n)
+	}
+	cached := second.(map[string]interface{})["chat_info"].(map[string]interface{})
+	if cached["title"] != "Postly news" || cached["pinned_message"].(map[string]interface{})["message_id"] != float64(7) {
+		t.Errorf("cached chat_info = %v, want the original values", cached)
+	}
+
+	// a zero TTL drops the cache, and a short one lets entries expire
+	c.SetStatsCacheTTL(0)
+	if _, err := c.GetCommunityStats("-100123"); err != nil {
+		t.Fatal(err)
+	}
+	c.SetStatsCacheTTL(time.Millisecond)
+	if _, err := c.GetCommunityStats("-100123"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := c.GetCommunityStats("-100123"); err != nil {
+		t.Fatal(err)
+	}
+	if n := srv.count("getChat"); n != 4 {
+		t.Errorf("getChat requests = %d, want 4 once the cache is off or expired", n)
+	}
+}
+
+func TestTelegramGetCommunityStatsDoesNotCacheFailures(t *testing.T) {
+	srv := &telegramStatsServer{requests: make(map[string]int), failChat: true}
+	c := newTestTelegramClient(t, srv.handle)
+
+	stats, err := c.GetCommunityStats("-100123")
+	if err == nil || !strings.Contains(err.Error(), "Too Many Requests") {
+		t.Fatalf("GetCommunityStats() = %v, %v, want the getChat error", stats, err)
+	}
+
+	srv.mu.Lock()
+	srv.failChat = false
+	srv.mu.Unlock()
+
+	stats, err = c.GetCommunityStats("-100123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.(map[string]interface{})["chat_info"] == nil {
+		t.Errorf("stats = %v, want chat_info after the failure cleared", stats)
+	}
+	if n := srv.count("getChat"); n != 2 {
+		t.Errorf("getChat requests = %d, want the failure to be fetched again", n)
+	}
+}
+
+func TestValidRejectsEmptyCredentials(t *testing.T) {
+	tests := []struct {
+		name  string
+		valid func() error
+		send  func() error
+	}{
+		{
+			"Telegram",
+			NewTelegramClient("").Valid,
+			func() error { _, err := NewTelegramClient("").CreatePost("hi", "123"); return err },
+		},
+		{
+			"Slack",
+			NewSlackClient("").Valid,
+			func() error { _, err := NewSlackClient("").CreatePost("hi", "C1"); return err },
+		},
+		{
+			"WhatsApp token",
+			NewWhatsAppClient("", "phone").Valid,
+			func() error { _, err := NewWhatsAppClient("", "phone").CreatePost("hi", "+15550100"); return err },
+		},
+		{
+			"WhatsApp phone number",
+			NewWhatsAppClient("token", "").Valid,
+			func() error { _, err := NewWhatsAppClient("token", "").CreatePost("hi", "+15550100"); return err },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.valid(); !errors.Is(err, ErrNotConfigured) {
+				t.Errorf("Valid() = %v, want ErrNotConfigured", err)
+			}
+			if err := tt.send(); !errors.Is(err, ErrNotConfigured) {
+				t.Errorf("CreatePost error = %v, want ErrNotConfigured", err)
+			}
+		})
+	}
+
+	if err := NewWhatsAppClient("token", "phone").Valid(); err != nil {
+		t.Errorf("Valid() with credentials = %v", err)
+	}
+}
+
+func TestSplitCompositeID(t *testing.T) {
+	tests := []struct {
+		id              string
+		container, item string
+		wantErr         bool
+	}{
+		{"-1001234567890:42", "-1001234567890", "42", false},
+		{"C024BE91L:1503435956.000247", "C024BE91L", "1503435956.000247", false},
+		{"chat:item:with:colons", "chat", "item:with:colons", false},
+		{"", "", "", true},
+		{"no-separator", "", "", true},
+		{":42", "", "", true},
+		{"chat:", "", "", true},
+		{":", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			container, item, err := splitCompositeID(tt.id)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("splitCompositeID(%q) = %q, %q, want an error", tt.id, container, item)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if container != tt.container || item != tt.item {
+				t.Errorf("splitCompositeID(%q) = %q, %q, want %q, %q", tt.id, container, item, tt.container, tt.item)
+			}
+		})
+	}
+}
+
+// slackSignature signs body the way Slack does for the Events API
+func slackSignature(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v0:" + timestamp + ":"))
+	mac.Write(body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestVerifySlackSignature(t *testing.T) {
+	const secret = "8f742231b10e8888abcd99yyyzzz85a5"
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&command=%2Fwebhook-collect&text=hello")
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	valid := slackSignature(secret, now, body)
+
+	tampered := []byte(strings.Replace(string(body), "hello", "hellO", 1))
+	stale := strconv.FormatInt(time.Now().Add(-6*time.Minute).Unix(), 10)
+	future := strconv.FormatInt(time.Now().Add(6*time.Minute).Unix(), 10)
+	recent := strconv.FormatInt(time.Now().Add(-4*time.Minute).Unix(), 10)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		header    string
+		want      bool
+	}{
+		{"valid", secret, now, body, valid, true},
+		{"within the replay window", secret, recent, body, slackSignature(secret, recent, body), true},
+		{"tampered body", secret, now, tampered, valid, false},
+		{"timestamp swapped", secret, recent, body, valid, false},
+		{"wrong secret", "other", now, body, valid, false},
+		{"empty secret", "", now, body, slackSignature("", now, body), false},
+		{"stale", secret, stale, body, slackSignature(secret, stale, body), false},
+		{"from the future", secret, future, body, slackSignature(secret, future, body), false},
+		{"malformed timestamp", secret, "yesterday", body, slackSignature(secret, "yesterday", body), false},
+		{"wrong version", secret, now, body, "v1=" + strings.TrimPrefix(valid, "v0="), false},
+		{"not hex", secret, now, body, "v0=not-hex", false},
+		{"empty header", secret, now, body, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := VerifySlackSignature(tt.secret, tt.timestamp, tt.body, tt.header); got != tt.want {
+				t.Errorf("VerifySlackSignature() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifySlackSignatureRejectsDocumentedReplay(t *testing.T) {
+	// The example from Slack's request signing guide is correctly signed but
+	// its timestamp is years old
+	body := []byte("token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c")
+	const header = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+
+	if got := slackSignature("8f742231b10e8888abcd99yyyzzz85a5", "1531420618", body); got != header {
+		t.Fatalf("slackSignature() = %s, want the documented %s", got, header)
+	}
+	if VerifySlackSignature("8f742231b10e8888abcd99yyyzzz85a5", "1531420618", body, header) {
+		t.Error("accepted a signature outside the replay window")
+	}
+}
+
+func TestMessagingSendDMHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	hang := func(w http.ResponseWriter, r *http.Request) { <-release }
+
+	slack := newTestSlackClient(t, hang)
+	telegram := newTestTelegramClient(t, hang)
+	srv, client := newTestServer(t, hang)
+	whatsapp := NewWhatsAppClient("token", "1234567890").WithHTTPClient(client)
+	whatsapp.BaseURL = srv.URL
+	// runs before the servers are closed
+	t.Cleanup(func() { close(release) })
+
+	tests := []struct {
+		name      string
+		messenger DirectMessenger
+	}{
+		{"WhatsApp", whatsapp},
+		{"Telegram", telegram},
+		{"Slack", slack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			start := time.Now()
+			_, err := tt.messenger.SendDM(ctx, "12345", "hello")
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("SendDM() = %v, want context.DeadlineExceeded", err)
+			}
+			if elapsed := time.Since(start); elapsed > time.Second {
+				t.Errorf("SendDM() ran for %v past the deadline", elapsed)
+			}
+		})
+	}
+}
+
+func TestSlackSendDMOpensConversationAndPosts(t *testing.T) {
+	var methods []string
+	s := newTestSlackClient(t, func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, strings.TrimPrefix(r.URL.Path, "/"))
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding %s payload: %v", r.URL.Path, err)
+			return
+		}
+		switch r.URL.Path {
+		case "/conversations.open":
+			if payload["users"] != "U123" {
+				t.Errorf("users = %v", payload["users"])
+			}
+			fmt.Fprint(w, `{"ok":true,"channel":{"id":"D456"}}`)
+		case "/chat.postMessage":
+			if payload["channel"] != "D456" || payload["text"] != "hello" {
+				t.Errorf("postMessage payload = %v", payload)
+			}
+			fmt.Fprint(w, `{"ok":true,"channel":"D456","ts":"1712345678.000100"}`)
+		}
+	})
+
+	id, err := s.SendDM(context.Background(), "U123", "hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "D456:1712345678.000100" || fmt.Sprint(methods) != "[conversations.open chat.postMessage]" {
+		t.Errorf("SendDM() = %q after %v", id, methods)
+	}
+}
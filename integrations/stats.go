@@ -0,0 +1,40 @@
+package integrations
+
+import "context"
+
+// StatsProvider is implemented by the clients of every publishing platform to
+// report the stats of a post in the shared PostStats shape, so engagement can
+// be compared across platforms. Each implementation documents how the
+// platform's own counters map onto PostStats:
+//
+//   - Views is what the platform counts as the post being seen: views for
+//     video platforms, impressions elsewhere
+//   - Likes, Comments and Shares are the closest equivalent counters; Shares
+//     covers reposts, retweets, rebounds and Pinterest saves
+//   - Engagement is the interactions per view as a percentage, where the
+//     interactions include platform specific ones such as saves or bookmarks
+//     that have no field of their own. It is 0 when there are no views.
+type StatsProvider interface {
+	GetStats(ctx context.Context, postID string) (PostStats, error)
+}
+
+var (
+	_ StatsProvider = (*TwitterClient)(nil)
+	_ StatsProvider = (*FaceBookClient)(nil)
+	_ StatsProvider = (*InstagramClient)(nil)
+	_ StatsProvider = (*LinkedInClient)(nil)
+	_ StatsProvider = (*Pinterest)(nil)
+	_ StatsProvider = (*RedditClient)(nil)
+	_ StatsProvider = (*DribbbleClient)(nil)
+	_ StatsProvider = (*TikTokClient)(nil)
+	_ StatsProvider = (*YouTubeClient)(nil)
+)
+
+// engagementPercent returns interactions per view as a percentage, or 0
+// without views
+func engagementPercent(interactions, views int64) float64 {
+	if views <= 0 {
+		return 0
+	}
+	return float64(interactions) / float64(views) * 100
+}
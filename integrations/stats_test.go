@@ -0,0 +1,124 @@
+package integrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+)
+
+func TestInstagramPostStatsMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		insights MediaInsights
+		want     PostStats
+	}{
+		{
+			"image uses impressions",
+			MediaInsights{Impressions: 2000, Reach: 1500, Likes: 120, Comments: 15, Shares: 10, Saved: 55, Engagement: 200},
+			PostStats{Views: 2000, Likes: 120, Comments: 15, Shares: 10, Engagement: 10},
+		},
+		{
+			"video uses video views",
+			MediaInsights{Impressions: 9000, VideoViews: 4000, Likes: 300, Comments: 40, Shares: 20, Saved: 40},
+			PostStats{Views: 4000, Likes: 300, Comments: 40, Shares: 20, Engagement: 10},
+		},
+		{
+			"no views",
+			MediaInsights{Likes: 3, Saved: 1},
+			PostStats{Likes: 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.insights.PostStats(); got.Views != tt.want.Views || got.Likes != tt.want.Likes ||
+				got.Comments != tt.want.Comments || got.Shares != tt.want.Shares || got.Engagement != tt.want.Engagement {
+				t.Errorf("PostStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPinterestPostStatsMapping(t *testing.T) {
+	tests := []struct {
+		name  string
+		stats Stats
+		want  PostStats
+	}{
+		{
+			"uses Pinterest's engagement count",
+			Stats{Impressions: 5000, Likes: 80, Comments: 5, Saves: 140, Clicks: 75, Engagements: 400},
+			PostStats{Views: 5000, Likes: 80, Comments: 5, Shares: 140, Engagement: 8},
+		},
+		{
+			"sums interactions without an engagement count",
+			Stats{Impressions: 1000, Likes: 20, Comments: 5, Saves: 40, Clicks: 35},
+			PostStats{Views: 1000, Likes: 20, Comments: 5, Shares: 40, Engagement: 10},
+		},
+		{
+			"video pin uses video views",
+			Stats{Impressions: 8000, VideoViews: 2000, Saves: 60, Engagements: 100},
+			PostStats{Views: 2000, Shares: 60, Engagement: 5},
+		},
+		{
+			"account counters are ignored",
+			Stats{Followers: 900, Following: 10, Pins: 42},
+			PostStats{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stats.PostStats(); got.Views != tt.want.Views || got.Likes != tt.want.Likes ||
+				got.Comments != tt.want.Comments || got.Shares != tt.want.Shares || got.Engagement != tt.want.Engagement {
+				t.Errorf("PostStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstagramAndPinterestGetStats(t *testing.T) {
+	ig := newTestInstagramClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v17.0/17895695668004550/insights" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"data":[
+			{"name":"impressions","period":"lifetime","values":[{"value":2000}]},
+			{"name":"reach","period":"lifetime","values":[{"value":1500}]},
+			{"name":"likes","period":"lifetime","values":[{"value":120}]},
+			{"name":"comments","period":"lifetime","values":[{"value":15}]},
+			{"name":"shares","period":"lifetime","values":[{"value":10}]},
+			{"name":"saved","period":"lifetime","values":[{"value":55}]}
+		]}`)
+	})
+	pin, _ := newTestPinterest(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v5/pins/813744226420795884/analytics" || r.URL.Query().Get("timeframe") != "30days" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `{"impressions":5000,"likes":80,"comments":5,"saves":140,"clicks":75,"engagements":400}`)
+	})
+
+	tests := []struct {
+		name     string
+		provider StatsProvider
+		postID   string
+		want     PostStats
+	}{
+		{"Instagram", ig, "17895695668004550", PostStats{Views: 2000, Likes: 120, Comments: 15, Shares: 10, Engagement: 10}},
+		{"Pinterest", pin, "813744226420795884", PostStats{Views: 5000, Likes: 80, Comments: 5, Shares: 140, Engagement: 8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.GetStats(context.Background(), tt.postID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Views != tt.want.Views || got.Likes != tt.want.Likes || got.Comments != tt.want.Comments ||
+				got.Shares != tt.want.Shares || got.Engagement != tt.want.Engagement {
+				t.Errorf("GetStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,14 +2,26 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
 	"time"
 )
 
+var (
+	// ErrThreadNotFound is returned when the requested thread does not exist
+	ErrThreadNotFound = errors.New("thread not found")
+	// ErrReplyNotFound is returned when the requested reply does not exist
+	ErrReplyNotFound = errors.New("reply not found")
+)
+
+// maxConcurrentDeletes bounds the number of requests DeleteThreads has in flight
+const maxConcurrentDeletes = 5
+
 // Thread represents a discussion thread
 type Thread struct {
 	ID        string    `json:"id"`
@@ -87,7 +99,7 @@ func (s *ThreadService) CreateThread(title, content, authorID string) (*Thread,
 	}
 
 	var thread Thread
-	if err := json.NewDecoder(resp.Body).Decode(&thread); err != nil {
+	if err := decodeJSONBody(resp, &thread); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -114,7 +126,7 @@ func (s *ThreadService) GetThread(threadID string) (*Thread, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("thread not found")
+		return nil, ErrThreadNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -123,7 +135,7 @@ func (s *ThreadService) GetThread(threadID string) (*Thread, error) {
 	}
 
 	var thread Thread
-	if err := json.NewDecoder(resp.Body).Decode(&thread); err != nil {
+	if err := decodeJSONBody(resp, &thread); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -168,7 +180,7 @@ func (s *ThreadService) UpdateThread(threadID, title, content string) (*Thread,
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("thread not found")
+		return nil, ErrThreadNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -177,7 +189,7 @@ func (s *ThreadService) UpdateThread(threadID, title, content string) (*Thread,
 	}
 
 	var thread Thread
-	if err := json.NewDecoder(resp.Body).Decode(&thread); err != nil {
+	if err := decodeJSONBody(resp, &thread); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -186,11 +198,67 @@ func (s *ThreadService) UpdateThread(threadID, title, content string) (*Thread,
 
 // DeleteThread removes a thread
 func (s *ThreadService) DeleteThread(threadID string) error {
+	return s.deleteThread(context.Background(), threadID)
+}
+
+// DeleteThreads removes several threads concurrently. The returned map holds
+// the outcome for every requested ID, nil on success or the error for that
+// thread (ErrThreadNotFound if it did not exist). The error is non-nil only
+// when ctx ended before every deletion was attempted.
+func (s *ThreadService) DeleteThreads(ctx context.Context, threadIDs []string) (map[string]error, error) {
+	results := make(map[string]error, len(threadIDs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxConcurrentDeletes)
+
+	seen := make(map[string]bool, len(threadIDs))
+	var skipErr error
+
+	for _, threadID := range threadIDs {
+		if seen[threadID] {
+			continue
+		}
+		seen[threadID] = true
+
+		// select picks at random when both cases are ready, so ctx is checked
+		// first to not start deletions once it is done
+		if ctx.Err() == nil {
+			select {
+			case sem <- struct{}{}:
+				wg.Add(1)
+				go func(threadID string) {
+					defer wg.Done()
+					defer func() { <-sem }()
+
+					err := s.deleteThread(ctx, threadID)
+
+					mu.Lock()
+					results[threadID] = err
+					mu.Unlock()
+				}(threadID)
+				continue
+			case <-ctx.Done():
+			}
+		}
+
+		skipErr = ctx.Err()
+		mu.Lock()
+		results[threadID] = skipErr
+		mu.Unlock()
+	}
+
+	wg.Wait()
+
+	return results, skipErr
+}
+
+// deleteThread removes a thread, aborting if ctx is done
+func (s *ThreadService) deleteThread(ctx context.Context, threadID string) error {
 	if threadID == "" {
 		return errors.New("thread ID cannot be empty")
 	}
 
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/threads/%s", s.BaseURL, threadID), nil)
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/threads/%s", s.BaseURL, threadID), nil)
 	if err != nil {
 		return fmt.Errorf("error creating request: %w", err)
 	}
@@ -204,7 +272,7 @@ func (s *ThreadService) DeleteThread(threadID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return errors.New("thread not found")
+		return ErrThreadNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
@@ -237,7 +305,7 @@ func (s *ThreadService) ListThreads(page, limit int) ([]Thread, error) {
 	}
 
 	var threads []Thread
-	if err := json.NewDecoder(resp.Body).Decode(&threads); err != nil {
+	if err := decodeJSONBody(resp, &threads); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -288,7 +356,7 @@ func (s *ThreadService) CreateReply(threadID, content, authorID, parentID string
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("thread not found")
+		return nil, ErrThreadNotFound
 	}
 
 	if resp.StatusCode != http.StatusCreated {
@@ -297,7 +365,7 @@ func (s *ThreadService) CreateReply(threadID, content, authorID, parentID string
 	}
 
 	var reply Reply
-	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+	if err := decodeJSONBody(resp, &reply); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -325,7 +393,7 @@ func (s *ThreadService) GetReplies(threadID string, page, limit int) ([]Reply, e
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("thread not found")
+		return nil, ErrThreadNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -334,13 +402,53 @@ func (s *ThreadService) GetReplies(threadID string, page, limit int) ([]Reply, e
 	}
 
 	var replies []Reply
-	if err := json.NewDecoder(resp.Body).Decode(&replies); err != nil {
+	if err := decodeJSONBody(resp, &replies); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
 	return replies, nil
 }
 
+// participantsPageSize is the page size used when reading every reply of a thread
+const participantsPageSize = 100
+
+// GetThreadParticipants returns the unique author IDs of a thread and all of
+// its replies, thread author first and the rest in reply order
+func (s *ThreadService) GetThreadParticipants(threadID string) ([]string, error) {
+	thread, err := s.GetThread(threadID)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	var participants []string
+	add := func(authorID string) {
+		if authorID != "" && !seen[authorID] {
+			seen[authorID] = true
+			participants = append(participants, authorID)
+		}
+	}
+
+	add(thread.AuthorID)
+
+	for page := 1; ; page++ {
+		replies, err := s.GetReplies(threadID, page, participantsPageSize)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, reply := range replies {
+			add(reply.AuthorID)
+		}
+
+		if len(replies) < participantsPageSize {
+			break
+		}
+	}
+
+	return participants, nil
+}
+
 // UpdateReply modifies an existing reply
 func (s *ThreadService) UpdateReply(replyID, content string) (*Reply, error) {
 	if replyID == "" {
@@ -374,7 +482,7 @@ func (s *ThreadService) UpdateReply(replyID, content string) (*Reply, error) {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, errors.New("reply not found")
+		return nil, ErrReplyNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -383,7 +491,7 @@ func (s *ThreadService) UpdateReply(replyID, content string) (*Reply, error) {
 	}
 
 	var reply Reply
-	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+	if err := decodeJSONBody(resp, &reply); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -410,7 +518,7 @@ func (s *ThreadService) DeleteReply(replyID string) error {
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return errors.New("reply not found")
+		return ErrReplyNotFound
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
@@ -447,7 +555,7 @@ func (s *ThreadService) SearchThreads(query string, page, limit int) ([]Thread,
 	}
 
 	var threads []Thread
-	if err := json.NewDecoder(resp.Body).Decode(&threads); err != nil {
+	if err := decodeJSONBody(resp, &threads); err != nil {
 		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
@@ -0,0 +1,124 @@
+package integrations
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newTestThreadService(t *testing.T, handler http.HandlerFunc) *ThreadService {
+	t.Helper()
+
+	srv, client := newTestServer(t, handler)
+	s := NewThreadService(srv.URL, "token")
+	s.HTTPClient = client
+	return s
+}
+
+func TestThreadParticipantsAcrossPages(t *testing.T) {
+	s := newTestThreadService(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/threads/t1":
+			fmt.Fprint(w, `{"id":"t1","author_id":"alice"}`)
+		case "/threads/t1/replies":
+			var replies []Reply
+			switch r.URL.Query().Get("page") {
+			case "1":
+				// a full page, so the next one must be read
+				for i := 0; i < participantsPageSize; i++ {
+					author := "bob"
+					if i%2 == 1 {
+						author = "alice"
+					}
+					replies = append(replies, Reply{ID: fmt.Sprint(i), AuthorID: author})
+				}
+			case "2":
+				replies = []Reply{{ID: "a", AuthorID: "carol"}, {ID: "b", AuthorID: "bob"}, {ID: "c"}}
+			default:
+				t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+			}
+			json.NewEncoder(w).Encode(replies)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	participants, err := s.GetThreadParticipants("t1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"alice", "bob", "carol"}; !reflect.DeepEqual(participants, want) {
+		t.Errorf("participants = %v, want %v", participants, want)
+	}
+}
+
+func TestDeleteThreadsMixedResults(t *testing.T) {
+	var mu sync.Mutex
+	deleted := map[string]int{}
+	s := newTestThreadService(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		id := strings.TrimPrefix(r.URL.Path, "/threads/")
+
+		mu.Lock()
+		deleted[id]++
+		mu.Unlock()
+
+		switch id {
+		case "t1":
+			w.WriteHeader(http.StatusNoContent)
+		case "t2":
+			w.WriteHeader(http.StatusOK)
+		case "t3":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	results, err := s.DeleteThreads(context.Background(), []string{"t1", "t2", "t3", "t4", "t1"})
+	if err != nil {
+		t.Fatalf("DeleteThreads error = %v, want nil when every ID was attempted", err)
+	}
+	if len(results) != 4 {
+		t.Fatalf("got %d results, want 4: %v", len(results), results)
+	}
+	if results["t1"] != nil || results["t2"] != nil {
+		t.Errorf("found threads reported %v, %v", results["t1"], results["t2"])
+	}
+	if !errors.Is(results["t3"], ErrThreadNotFound) {
+		t.Errorf("t3 = %v, want ErrThreadNotFound", results["t3"])
+	}
+	if results["t4"] == nil || errors.Is(results["t4"], ErrThreadNotFound) {
+		t.Errorf("t4 = %v, want an API error", results["t4"])
+	}
+	if deleted["t1"] != 1 {
+		t.Errorf("t1 deleted %d times, want once", deleted["t1"])
+	}
+}
+
+func TestDeleteThreadsCanceledBeforeStart(t *testing.T) {
+	s := newTestThreadService(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	results, err := s.DeleteThreads(ctx, []string{"t1", "t2"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want context.Canceled", err)
+	}
+	for id, threadErr := range results {
+		if !errors.Is(threadErr, context.Canceled) {
+			t.Errorf("%s = %v, want context.Canceled", id, threadErr)
+		}
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -24,19 +28,118 @@ type SocialPlatform interface {
 }
 
 type PostData struct {
-	VideoPath    string
+	VideoPath string
+	// VideoReader, when set, is uploaded instead of the file at VideoPath.
+	// VideoSize is its length in bytes, or 0 if unknown, and VideoFilename
+	// is the file name reported to the platform. The reader is consumed by
+	// the first call that uploads or builds a payload from it.
+	VideoReader   io.Reader
+	VideoSize     int64
+	VideoFilename string
+
 	Title        string
 	Description  string
 	Tags         []string
-	Privacy      string // "public", "private", "unlisted"
+	Privacy      Visibility
 	ScheduleTime *time.Time
+	Upload       UploadOptions
+}
+
+// openVideo returns the video to upload along with its file name and size.
+// VideoReader is preferred over VideoPath; the size is 0 when unknown. The
+// returned reader must be closed by the caller.
+func (p PostData) openVideo() (io.ReadCloser, string, int64, error) {
+	if p.VideoReader != nil {
+		filename := p.VideoFilename
+		if filename == "" {
+			filename = "video"
+		}
+		return io.NopCloser(p.VideoReader), filename, p.VideoSize, nil
+	}
+
+	file, err := os.Open(p.VideoPath)
+	if err != nil {
+		return nil, "", 0, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, "", 0, err
+	}
+
+	filename := p.VideoFilename
+	if filename == "" {
+		filename = filepath.Base(p.VideoPath)
+	}
+
+	return file, filename, info.Size(), nil
+}
+
+// postText returns the text of a post for platforms that take a single text
+// field: the title and description separated by a blank line, followed by
+// the tags as hashtags
+func postText(post PostData) string {
+	var parts []string
+	for _, part := range []string{post.Title, post.Description} {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	var hashtags []string
+	for _, tag := range post.Tags {
+		if tag = strings.TrimPrefix(strings.TrimSpace(tag), "#"); tag != "" {
+			hashtags = append(hashtags, "#"+tag)
+		}
+	}
+	if len(hashtags) > 0 {
+		parts = append(parts, strings.Join(hashtags, " "))
+	}
+
+	return strings.Join(parts, "\n\n")
+}
+
+// multipartFileBody builds a multipart body made of the fields written by
+// writeFields followed by a file part that streams from file, so the file is
+// never buffered in memory. The returned length is -1 when size is unknown.
+func multipartFileBody(
+	writeFields func(*multipart.Writer) error,
+	fieldName, filename string,
+	file io.Reader,
+	size int64,
+) (body io.Reader, contentType string, length int64, err error) {
+	buf := &bytes.Buffer{}
+	writer := multipart.NewWriter(buf)
+
+	if err := writeFields(writer); err != nil {
+		return nil, "", 0, err
+	}
+	if _, err := writer.CreateFormFile(fieldName, filename); err != nil {
+		return nil, "", 0, err
+	}
+	head := append([]byte(nil), buf.Bytes()...)
+
+	buf.Reset()
+	if err := writer.Close(); err != nil {
+		return nil, "", 0, err
+	}
+	tail := append([]byte(nil), buf.Bytes()...)
+
+	length = -1
+	if size > 0 {
+		length = int64(len(head)) + size + int64(len(tail))
+	}
+
+	body = io.MultiReader(bytes.NewReader(head), file, bytes.NewReader(tail))
+	return body, writer.FormDataContentType(), length, nil
 }
 
 type UpdateData struct {
 	Title       *string
 	Description *string
 	Tags        *[]string
-	Privacy     *string
+	Privacy     *Visibility
 }
 
 type PostStats struct {
@@ -77,52 +180,20 @@ func NewTikTokClient(accessToken, apiKey string) *TikTokClient {
 
 // CreatePost uploads a video to TikTok
 func (c *TikTokClient) CreatePost(ctx context.Context, post PostData) (string, error) {
-	// Open the video file
-	file, err := os.Open(post.VideoPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to open video file: %w", err)
-	}
-	defer file.Close()
-
-	// Create multipart form data
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
-
-	// Add file
-	part, err := writer.CreateFormFile("video", filepath.Base(post.VideoPath))
+	video, body, contentType, size, err := tiktokUploadBody(post)
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %w", err)
-	}
-
-	if _, err = io.Copy(part, file); err != nil {
-		return "", fmt.Errorf("failed to copy file content: %w", err)
-	}
-
-	// Add metadata
-	_ = writer.WriteField("title", post.Title)
-	_ = writer.WriteField("description", post.Description)
-
-	for _, tag := range post.Tags {
-		_ = writer.WriteField("tags", tag)
-	}
-
-	_ = writer.WriteField("privacy_level", post.Privacy)
-
-	if post.ScheduleTime != nil {
-		_ = writer.WriteField("schedule_time", post.ScheduleTime.Format(time.RFC3339))
-	}
-
-	if err = writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close writer: %w", err)
+		return "", err
 	}
+	defer video.Close()
 
 	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/video/upload/", body)
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/video/upload/", post.Upload.body(body, size))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
+	req.ContentLength = size
 
-	req.Header.Set("Content-Type", writer.FormDataContentType())
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Authorization", "Bearer "+c.accessToken)
 	req.Header.Set("x-api-key", c.apiKey)
 
@@ -144,13 +215,73 @@ func (c *TikTokClient) CreatePost(ctx context.Context, post PostData) (string, e
 		} `json:"data"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return result.Data.VideoID, nil
 }
 
+// BuildPayload returns the multipart body and content type CreatePost would
+// upload for post, without sending it. The video is part of the body, so it
+// is read into memory in full. A VideoReader is read to the end, so a
+// following CreatePost with the same PostData uploads an empty video unless
+// the reader is rewound or replaced first.
+func (c *TikTokClient) BuildPayload(post PostData) ([]byte, string, error) {
+	video, body, contentType, _, err := tiktokUploadBody(post)
+	if err != nil {
+		return nil, "", err
+	}
+	defer video.Close()
+
+	payload, err := io.ReadAll(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read form data: %w", err)
+	}
+	return payload, contentType, nil
+}
+
+// tiktokUploadBody opens the video of post and builds the multipart upload body,
+// streaming the video as the last part. The returned video must be closed
+// once the body has been read.
+func tiktokUploadBody(post PostData) (video io.ReadCloser, body io.Reader, contentType string, size int64, err error) {
+	privacyLevel, err := TikTokPrivacyLevel(post.Privacy)
+	if err != nil {
+		return nil, nil, "", 0, err
+	}
+
+	// Open the video
+	video, filename, videoSize, err := post.openVideo()
+	if err != nil {
+		return nil, nil, "", 0, fmt.Errorf("failed to open video file: %w", err)
+	}
+
+	// Create multipart form data, streaming the video as the last part
+	body, contentType, size, err = multipartFileBody(func(writer *multipart.Writer) error {
+		_ = writer.WriteField("title", post.Title)
+		_ = writer.WriteField("description", post.Description)
+
+		for _, tag := range post.Tags {
+			_ = writer.WriteField("tags", tag)
+		}
+
+		if privacyLevel != "" {
+			_ = writer.WriteField("privacy_level", privacyLevel)
+		}
+
+		if post.ScheduleTime != nil {
+			_ = writer.WriteField("schedule_time", post.ScheduleTime.Format(time.RFC3339))
+		}
+		return nil
+	}, "video", filename, video, videoSize)
+	if err != nil {
+		video.Close()
+		return nil, nil, "", 0, fmt.Errorf("failed to create form data: %w", err)
+	}
+
+	return video, body, contentType, size, nil
+}
+
 // ReplyToComment posts a reply to a comment on TikTok
 func (c *TikTokClient) ReplyToComment(ctx context.Context, postID, commentID, replyText string) (string, error) {
 	data := map[string]string{
@@ -190,7 +321,7 @@ func (c *TikTokClient) ReplyToComment(ctx context.Context, postID, commentID, re
 		} `json:"data"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -235,7 +366,7 @@ func (c *TikTokClient) GetPostStats(ctx context.Context, postID string) (PostSta
 		} `json:"data"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return PostStats{}, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -258,6 +389,12 @@ func (c *TikTokClient) GetPostStats(ctx context.Context, postID string) (PostSta
 	}, nil
 }
 
+// GetStats implements StatsProvider with GetPostStats: views, likes, comments
+// and shares are TikTok's own counters.
+func (c *TikTokClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	return c.GetPostStats(ctx, postID)
+}
+
 // SearchContent searches for content on TikTok
 func (c *TikTokClient) SearchContent(ctx context.Context, query string) ([]ContentItem, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/search/videos/?query=%s", c.baseURL, query), nil)
@@ -299,7 +436,7 @@ func (c *TikTokClient) SearchContent(ctx context.Context, query string) ([]Conte
 		} `json:"data"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -383,7 +520,11 @@ func (c *TikTokClient) UpdateContent(ctx context.Context, contentID string, data
 		updateData["tags"] = *data.Tags
 	}
 	if data.Privacy != nil {
-		updateData["privacy_level"] = *data.Privacy
+		privacyLevel, err := TikTokPrivacyLevel(*data.Privacy)
+		if err != nil {
+			return err
+		}
+		updateData["privacy_level"] = privacyLevel
 	}
 
 	jsonData, err := json.Marshal(updateData)
@@ -418,7 +559,12 @@ func (c *TikTokClient) UpdateContent(ctx context.Context, contentID string, data
 type YouTubeClient struct {
 	accessToken string
 	baseURL     string
+	uploadURL   string
 	httpClient  *http.Client
+
+	// Recorder, when set, receives the subscriber counts fetched by
+	// GetSubscriberCount
+	Recorder MetricsRecorder
 }
 
 // NewYouTubeClient creates a new YouTube API client
@@ -426,6 +572,7 @@ func NewYouTubeClient(accessToken string) *YouTubeClient {
 	return &YouTubeClient{
 		accessToken: accessToken,
 		baseURL:     "https://www.googleapis.com/youtube/v3",
+		uploadURL:   "https://www.googleapis.com/upload/youtube/v3/videos",
 		httpClient:  &http.Client{Timeout: 60 * time.Second},
 	}
 }
@@ -437,24 +584,9 @@ func (c *YouTubeClient) CreatePost(ctx context.Context, post PostData) (string,
 	// 2. Upload video content
 
 	// Step 1: Insert video metadata
-	metaData := map[string]interface{}{
-		"snippet": map[string]interface{}{
-			"title":       post.Title,
-			"description": post.Description,
-			"tags":        post.Tags,
-		},
-		"status": map[string]interface{}{
-			"privacyStatus": post.Privacy,
-		},
-	}
-
-	if post.ScheduleTime != nil {
-		metaData["status"].(map[string]interface{})["publishAt"] = post.ScheduleTime.Format(time.RFC3339)
-	}
-
-	jsonData, err := json.Marshal(metaData)
+	jsonData, err := youtubeMetadata(post)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal metadata: %w", err)
+		return "", err
 	}
 
 	// Step 1: Create metadata request
@@ -471,51 +603,42 @@ func (c *YouTubeClient) CreatePost(ctx context.Context, post PostData) (string,
 	metaReq.Header.Set("Content-Type", "application/json")
 	metaReq.Header.Set("Authorization", "Bearer "+c.accessToken)
 
-	// Step 2: Open the video file
-	file, err := os.Open(post.VideoPath)
+	// Step 2: Open the video
+	video, filename, videoSize, err := post.openVideo()
 	if err != nil {
 		return "", fmt.Errorf("failed to open video file: %w", err)
 	}
-	defer file.Close()
-
-	// Create multipart form data
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+	defer video.Close()
 
-	// Add metadata part
-	metaPart, err := writer.CreateFormField("metadata")
-	if err != nil {
-		return "", fmt.Errorf("failed to create metadata field: %w", err)
-	}
-	if _, err = metaPart.Write(jsonData); err != nil {
-		return "", fmt.Errorf("failed to write metadata: %w", err)
-	}
-
-	// Add file part
-	filePart, err := writer.CreateFormFile("media", filepath.Base(post.VideoPath))
+	// Create multipart form data with the metadata part followed by the
+	// streamed media part
+	body, contentType, size, err := multipartFileBody(func(writer *multipart.Writer) error {
+		metaPart, err := writer.CreateFormField("metadata")
+		if err != nil {
+			return fmt.Errorf("failed to create metadata field: %w", err)
+		}
+		if _, err = metaPart.Write(jsonData); err != nil {
+			return fmt.Errorf("failed to write metadata: %w", err)
+		}
+		return nil
+	}, "media", filename, video, videoSize)
 	if err != nil {
-		return "", fmt.Errorf("failed to create form file: %w", err)
-	}
-	if _, err = io.Copy(filePart, file); err != nil {
-		return "", fmt.Errorf("failed to copy file content: %w", err)
-	}
-
-	if err = writer.Close(); err != nil {
-		return "", fmt.Errorf("failed to close writer: %w", err)
+		return "", fmt.Errorf("failed to create form data: %w", err)
 	}
 
 	// Create upload request
 	uploadReq, err := http.NewRequestWithContext(
 		ctx,
 		"POST",
-		"https://www.googleapis.com/upload/youtube/v3/videos?uploadType=multipart&part=snippet,status",
-		body,
+		c.uploadURL+"?uploadType=multipart&part=snippet,status",
+		post.Upload.body(body, size),
 	)
 	if err != nil {
 		return "", fmt.Errorf("failed to create upload request: %w", err)
 	}
+	uploadReq.ContentLength = size
 
-	uploadReq.Header.Set("Content-Type", writer.FormDataContentType())
+	uploadReq.Header.Set("Content-Type", contentType)
 	uploadReq.Header.Set("Authorization", "Bearer "+c.accessToken)
 
 	// Send request
@@ -527,20 +650,291 @@ func (c *YouTubeClient) CreatePost(ctx context.Context, post PostData) (string,
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(body))
+		return "", googleError("upload", resp.StatusCode, body)
 	}
 
 	var result struct {
 		ID string `json:"id"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
 	return result.ID, nil
 }
 
+// youtubeMetadata builds the snippet and status of a video upload
+func youtubeMetadata(post PostData) ([]byte, error) {
+	metaData := map[string]interface{}{
+		"snippet": map[string]interface{}{
+			"title":       post.Title,
+			"description": post.Description,
+			"tags":        post.Tags,
+		},
+		"status": map[string]interface{}{},
+	}
+
+	privacyStatus, err := YouTubePrivacyStatus(post.Privacy)
+	if err != nil {
+		return nil, err
+	}
+	if privacyStatus != "" {
+		metaData["status"].(map[string]interface{})["privacyStatus"] = privacyStatus
+	}
+
+	if post.ScheduleTime != nil {
+		metaData["status"].(map[string]interface{})["publishAt"] = post.ScheduleTime.Format(time.RFC3339)
+	}
+
+	jsonData, err := json.Marshal(metaData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+	return jsonData, nil
+}
+
+// Resumable uploads are sent in chunks that must be a multiple of
+// youtubeChunkGranularity; DefaultYouTubeChunkSize is used when no chunk size
+// is given
+const (
+	youtubeChunkGranularity = 256 * 1024
+	DefaultYouTubeChunkSize = 32 * youtubeChunkGranularity // 8 MiB
+)
+
+// YouTubeUploadSession identifies a resumable upload. URL is the session URI
+// YouTube returned and Offset the number of bytes it has acknowledged.
+type YouTubeUploadSession struct {
+	URL    string `json:"url"`
+	Offset int64  `json:"offset"`
+}
+
+// YouTubeUploadError is returned when a resumable upload fails after its
+// session was created. Save Session and pass it to ResumeUpload to continue
+// from the last acknowledged byte.
+type YouTubeUploadError struct {
+	Session YouTubeUploadSession
+	Err     error
+}
+
+func (e *YouTubeUploadError) Error() string {
+	return fmt.Sprintf("youtube upload interrupted at byte %d: %v", e.Session.Offset, e.Err)
+}
+
+func (e *YouTubeUploadError) Unwrap() error {
+	return e.Err
+}
+
+// CreatePostResumable uploads a video to YouTube with the resumable upload
+// protocol, sending chunkSize bytes per request. chunkSize must be a multiple
+// of 256 KiB; zero uses DefaultYouTubeChunkSize. The size of the video must
+// be known. If the upload is interrupted the error is a *YouTubeUploadError
+// carrying the session to resume from.
+func (c *YouTubeClient) CreatePostResumable(ctx context.Context, post PostData, chunkSize int64) (string, error) {
+	chunkSize, err := youtubeChunkSize(chunkSize)
+	if err != nil {
+		return "", err
+	}
+
+	video, _, size, err := post.openVideo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open video file: %w", err)
+	}
+	defer video.Close()
+
+	if size <= 0 {
+		return "", errors.New("resumable upload requires the video size")
+	}
+
+	sessionURL, err := c.startResumableUpload(ctx, post, size)
+	if err != nil {
+		return "", err
+	}
+
+	return c.uploadChunks(ctx, post.Upload, YouTubeUploadSession{URL: sessionURL}, video, size, chunkSize)
+}
+
+// ResumeUpload continues an interrupted resumable upload of post from the
+// session of a *YouTubeUploadError. The video is read again from the
+// session offset.
+func (c *YouTubeClient) ResumeUpload(ctx context.Context, post PostData, session YouTubeUploadSession, chunkSize int64) (string, error) {
+	chunkSize, err := youtubeChunkSize(chunkSize)
+	if err != nil {
+		return "", err
+	}
+	if session.URL == "" || session.Offset < 0 {
+		return "", errors.New("invalid upload session")
+	}
+
+	video, _, size, err := post.openVideo()
+	if err != nil {
+		return "", fmt.Errorf("failed to open video file: %w", err)
+	}
+	defer video.Close()
+
+	if size <= 0 {
+		return "", errors.New("resumable upload requires the video size")
+	}
+	if session.Offset > size {
+		return "", fmt.Errorf("upload offset %d is beyond the video size %d", session.Offset, size)
+	}
+
+	// Skip the bytes YouTube already has
+	if seeker, ok := video.(io.Seeker); ok {
+		_, err = seeker.Seek(session.Offset, io.SeekStart)
+	} else {
+		_, err = io.CopyN(io.Discard, video, session.Offset)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to skip to offset %d: %w", session.Offset, err)
+	}
+
+	return c.uploadChunks(ctx, post.Upload, session, video, size, chunkSize)
+}
+
+// youtubeChunkSize validates a resumable upload chunk size
+func youtubeChunkSize(chunkSize int64) (int64, error) {
+	if chunkSize == 0 {
+		return DefaultYouTubeChunkSize, nil
+	}
+	if chunkSize < 0 || chunkSize%youtubeChunkGranularity != 0 {
+		return 0, fmt.Errorf("chunk size %d is not a multiple of %d bytes", chunkSize, youtubeChunkGranularity)
+	}
+	return chunkSize, nil
+}
+
+// startResumableUpload sends the metadata of post and returns the session URI
+// the video bytes are uploaded to
+func (c *YouTubeClient) startResumableUpload(ctx context.Context, post PostData, size int64) (string, error) {
+	jsonData, err := youtubeMetadata(post)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		c.uploadURL+"?uploadType=resumable&part=snippet,status",
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to create upload request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json; charset=UTF-8")
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	req.Header.Set("X-Upload-Content-Length", strconv.FormatInt(size, 10))
+	req.Header.Set("X-Upload-Content-Type", "video/*")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("upload request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", googleError("start upload", resp.StatusCode, body)
+	}
+
+	location := resp.Header.Get("Location")
+	if location == "" {
+		return "", errors.New("upload session response has no Location header")
+	}
+	return location, nil
+}
+
+// uploadChunks sends video, positioned at session.Offset, to the session URI
+// in chunks. When YouTube acknowledges fewer bytes than were sent, the next
+// chunk starts from the acknowledged offset.
+func (c *YouTubeClient) uploadChunks(ctx context.Context, opts UploadOptions, session YouTubeUploadSession, video io.Reader, size, chunkSize int64) (string, error) {
+	// pending holds bytes read from video that YouTube has not acknowledged
+	// yet; it always starts at session.Offset
+	var pending []byte
+	buf := make([]byte, chunkSize)
+
+	for {
+		if missing := chunkSize - int64(len(pending)); missing > 0 && session.Offset+int64(len(pending)) < size {
+			n, err := io.ReadFull(video, buf[:missing])
+			if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+				return "", &YouTubeUploadError{Session: session, Err: err}
+			}
+			pending = append(pending, buf[:n]...)
+		}
+		if len(pending) == 0 {
+			return "", &YouTubeUploadError{Session: session, Err: io.ErrUnexpectedEOF}
+		}
+
+		end := session.Offset + int64(len(pending)) - 1
+		req, err := http.NewRequestWithContext(ctx, "PUT", session.URL, bytes.NewReader(pending))
+		if err != nil {
+			return "", &YouTubeUploadError{Session: session, Err: err}
+		}
+		req.ContentLength = int64(len(pending))
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+		req.Header.Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", session.Offset, end, size))
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return "", &YouTubeUploadError{Session: session, Err: err}
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK, http.StatusCreated:
+			var result struct {
+				ID string `json:"id"`
+			}
+			err = decodeJSONBody(resp, &result)
+			resp.Body.Close()
+			if err != nil {
+				return "", fmt.Errorf("failed to decode response: %w", err)
+			}
+			if opts.Progress != nil {
+				opts.Progress(size, size)
+			}
+			return result.ID, nil
+
+		case http.StatusPermanentRedirect: // 308 Resume Incomplete
+			resp.Body.Close()
+			acked, err := youtubeAckedOffset(resp.Header.Get("Range"))
+			if err != nil || acked < session.Offset || acked > end+1 {
+				return "", &YouTubeUploadError{Session: session, Err: fmt.Errorf("invalid Range in resume response: %q", resp.Header.Get("Range"))}
+			}
+			pending = pending[acked-session.Offset:]
+			session.Offset = acked
+			if opts.Progress != nil {
+				opts.Progress(acked, size)
+			}
+
+		default:
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return "", &YouTubeUploadError{
+				Session: session,
+				Err:     googleError("chunk upload", resp.StatusCode, body),
+			}
+		}
+	}
+}
+
+// youtubeAckedOffset returns the offset following the bytes acknowledged by a
+// Range header such as "bytes=0-262143". No header means nothing was stored.
+func youtubeAckedOffset(rangeHeader string) (int64, error) {
+	if rangeHeader == "" {
+		return 0, nil
+	}
+
+	_, last, ok := strings.Cut(strings.TrimPrefix(rangeHeader, "bytes="), "-")
+	if !ok {
+		return 0, fmt.Errorf("malformed range %q", rangeHeader)
+	}
+	n, err := strconv.ParseInt(last, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("malformed range %q", rangeHeader)
+	}
+	return n + 1, nil
+}
+
 // ReplyToComment posts a reply to a comment on YouTube
 func (c *YouTubeClient) ReplyToComment(ctx context.Context, postID, commentID, replyText string) (string, error) {
 	data := map[string]interface{}{
@@ -571,14 +965,14 @@ func (c *YouTubeClient) ReplyToComment(ctx context.Context, postID, commentID, r
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("reply failed with status %d: %s", resp.StatusCode, string(body))
+		return "", googleError("reply", resp.StatusCode, body)
 	}
 
 	var result struct {
 		ID string `json:"id"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return "", fmt.Errorf("failed to decode response: %w", err)
 	}
 
@@ -608,22 +1002,16 @@ func (c *YouTubeClient) GetPostStats(ctx context.Context, postID string) (PostSt
 
 	if statsResp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(statsResp.Body)
-		return PostStats{}, fmt.Errorf("stats request failed with status %d: %s", statsResp.StatusCode, string(body))
+		return PostStats{}, googleError("stats request", statsResp.StatusCode, body)
 	}
 
 	var statsResult struct {
 		Items []struct {
-			Statistics struct {
-				ViewCount     string `json:"viewCount"`
-				LikeCount     string `json:"likeCount"`
-				DislikeCount  string `json:"dislikeCount"`
-				FavoriteCount string `json:"favoriteCount"`
-				CommentCount  string `json:"commentCount"`
-			} `json:"statistics"`
+			Statistics youtubeStatistics `json:"statistics"`
 		} `json:"items"`
 	}
 
-	if err = json.NewDecoder(statsResp.Body).Decode(&statsResult); err != nil {
+	if err = decodeJSONBody(statsResp, &statsResult); err != nil {
 		return PostStats{}, fmt.Errorf("failed to decode stats response: %w", err)
 	}
 
@@ -631,19 +1019,34 @@ func (c *YouTubeClient) GetPostStats(ctx context.Context, postID string) (PostSt
 		return PostStats{}, fmt.Errorf("no stats found for video ID: %s", postID)
 	}
 
-	stats := statsResult.Items[0].Statistics
+	stats := statsResult.Items[0].Statistics.postStats()
 
 	// Fetch analytics data for demographics (requires YouTube Analytics API)
 	// This is a placeholder as the actual demographics API requires more complex OAuth setup
-	demographics := map[string]interface{}{
+	stats.Demographics = map[string]interface{}{
 		"note": "To get full demographics, use the YouTube Analytics API",
 	}
 
+	return stats, nil
+}
+
+// youtubeStatistics is the statistics part of a video resource. YouTube
+// reports the counts as strings.
+type youtubeStatistics struct {
+	ViewCount     string `json:"viewCount"`
+	LikeCount     string `json:"likeCount"`
+	DislikeCount  string `json:"dislikeCount"`
+	FavoriteCount string `json:"favoriteCount"`
+	CommentCount  string `json:"commentCount"`
+}
+
+// postStats converts the counts into PostStats
+func (s youtubeStatistics) postStats() PostStats {
 	// Parse string counts to int64
-	viewCount, _ := parseInt64(stats.ViewCount)
-	likeCount, _ := parseInt64(stats.LikeCount)
-	commentCount, _ := parseInt64(stats.CommentCount)
-	favoriteCount, _ := parseInt64(stats.FavoriteCount)
+	viewCount, _ := parseInt64(s.ViewCount)
+	likeCount, _ := parseInt64(s.LikeCount)
+	commentCount, _ := parseInt64(s.CommentCount)
+	favoriteCount, _ := parseInt64(s.FavoriteCount)
 
 	// Calculate engagement
 	engagement := float64(0)
@@ -652,16 +1055,295 @@ func (c *YouTubeClient) GetPostStats(ctx context.Context, postID string) (PostSt
 	}
 
 	return PostStats{
-		Views:        viewCount,
-		Likes:        likeCount,
-		Comments:     commentCount,
-		Shares:       favoriteCount, // YouTube uses "favorites" instead of shares
-		Engagement:   engagement,
-		Demographics: demographics,
+		Views:      viewCount,
+		Likes:      likeCount,
+		Comments:   commentCount,
+		Shares:     favoriteCount, // YouTube uses "favorites" instead of shares
+		Engagement: engagement,
+	}
+}
+
+// youtubeStatsBatchSize is the largest number of IDs videos.list accepts
+const youtubeStatsBatchSize = 50
+
+// getVideoStats fetches the statistics of many videos, keyed by video ID, with
+// one videos.list call per youtubeStatsBatchSize IDs. Videos YouTube does not
+// return are left out.
+func (c *YouTubeClient) getVideoStats(ctx context.Context, videoIDs []string) (map[string]PostStats, error) {
+	stats := make(map[string]PostStats, len(videoIDs))
+	for start := 0; start < len(videoIDs); start += youtubeStatsBatchSize {
+		end := min(start+youtubeStatsBatchSize, len(videoIDs))
+
+		params := url.Values{}
+		params.Set("part", "statistics")
+		params.Set("id", strings.Join(videoIDs[start:end], ","))
+
+		req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/videos?"+params.Encode(), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stats request: %w", err)
+		}
+
+		req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("stats request failed: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			body, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, googleError("stats request", resp.StatusCode, body)
+		}
+
+		var result struct {
+			Items []struct {
+				ID         string            `json:"id"`
+				Statistics youtubeStatistics `json:"statistics"`
+			} `json:"items"`
+		}
+		err = decodeJSONBody(resp, &result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode stats response: %w", err)
+		}
+
+		for _, item := range result.Items {
+			stats[item.ID] = item.Statistics.postStats()
+		}
+	}
+	return stats, nil
+}
+
+// GetStats implements StatsProvider with GetPostStats. YouTube has no share
+// counter, so Shares holds the favorites.
+func (c *YouTubeClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	return c.GetPostStats(ctx, postID)
+}
+
+// VideoDetails is the metadata, duration and statistics of a YouTube video
+type VideoDetails struct {
+	ID           string
+	Title        string
+	Description  string
+	ChannelID    string
+	ChannelTitle string
+	PublishedAt  time.Time
+	Tags         []string
+	CategoryID   string
+	Duration     time.Duration
+	Definition   string // "hd" or "sd"
+	Stats        PostStats
+}
+
+// GetVideoDetails retrieves the snippet, content details and statistics of a
+// video. Stats has no Demographics or Engagement; use GetPostStats for those.
+func (c *YouTubeClient) GetVideoDetails(ctx context.Context, videoID string) (*VideoDetails, error) {
+	params := url.Values{}
+	params.Set("part", "snippet,contentDetails,statistics")
+	params.Set("id", videoID)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+"/videos?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, googleError("video request", resp.StatusCode, body)
+	}
+
+	var result struct {
+		Items []struct {
+			ID      string `json:"id"`
+			Snippet struct {
+				Title        string    `json:"title"`
+				Description  string    `json:"description"`
+				ChannelID    string    `json:"channelId"`
+				ChannelTitle string    `json:"channelTitle"`
+				PublishedAt  time.Time `json:"publishedAt"`
+				Tags         []string  `json:"tags"`
+				CategoryID   string    `json:"categoryId"`
+			} `json:"snippet"`
+			ContentDetails struct {
+				Duration   string `json:"duration"`
+				Definition string `json:"definition"`
+			} `json:"contentDetails"`
+			Statistics struct {
+				ViewCount    string `json:"viewCount"`
+				LikeCount    string `json:"likeCount"`
+				CommentCount string `json:"commentCount"`
+			} `json:"statistics"`
+		} `json:"items"`
+	}
+
+	if err = decodeJSONBody(resp, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(result.Items) == 0 {
+		return nil, fmt.Errorf("youtube video %q: %w", videoID, ErrNotFound)
+	}
+	item := result.Items[0]
+
+	// upcoming live streams have no duration yet
+	var duration time.Duration
+	if item.ContentDetails.Duration != "" {
+		if duration, err = parseISO8601Duration(item.ContentDetails.Duration); err != nil {
+			return nil, err
+		}
+	}
+
+	// Counts the owner hides are left out of the response and stay 0
+	views, _ := parseInt64(item.Statistics.ViewCount)
+	likes, _ := parseInt64(item.Statistics.LikeCount)
+	comments, _ := parseInt64(item.Statistics.CommentCount)
+
+	return &VideoDetails{
+		ID:           item.ID,
+		Title:        item.Snippet.Title,
+		Description:  item.Snippet.Description,
+		ChannelID:    item.Snippet.ChannelID,
+		ChannelTitle: item.Snippet.ChannelTitle,
+		PublishedAt:  item.Snippet.PublishedAt,
+		Tags:         item.Snippet.Tags,
+		CategoryID:   item.Snippet.CategoryID,
+		Duration:     duration,
+		Definition:   item.ContentDetails.Definition,
+		Stats: PostStats{
+			Views:    views,
+			Likes:    likes,
+			Comments: comments,
+		},
 	}, nil
 }
 
-// SearchContent searches for videos on YouTube
+// parseISO8601Duration parses the durations YouTube reports, such as
+// "PT1H2M10S" or "P1DT2H". Weeks, days, hours, minutes and (possibly
+// fractional) seconds are supported; years and months are rejected since
+// their length varies.
+func parseISO8601Duration(s string) (time.Duration, error) {
+	invalid := fmt.Errorf("invalid ISO-8601 duration %q", s)
+
+	rest, ok := strings.CutPrefix(s, "P")
+	if !ok || rest == "" {
+		return 0, invalid
+	}
+
+	var total time.Duration
+	inTime := false
+	for rest != "" {
+		if rest[0] == 'T' {
+			if inTime || len(rest) == 1 {
+				return 0, invalid
+			}
+			inTime = true
+			rest = rest[1:]
+			continue
+		}
+
+		end := strings.IndexFunc(rest, func(r rune) bool {
+			return (r < '0' || r > '9') && r != '.'
+		})
+		if end <= 0 {
+			return 0, invalid
+		}
+		value, err := strconv.ParseFloat(rest[:end], 64)
+		if err != nil {
+			return 0, invalid
+		}
+
+		var unit time.Duration
+		switch designator := rest[end]; {
+		case !inTime && designator == 'W':
+			unit = 7 * 24 * time.Hour
+		case !inTime && designator == 'D':
+			unit = 24 * time.Hour
+		case inTime && designator == 'H':
+			unit = time.Hour
+		case inTime && designator == 'M':
+			unit = time.Minute
+		case inTime && designator == 'S':
+			unit = time.Second
+		default:
+			return 0, invalid
+		}
+
+		total += time.Duration(value * float64(unit))
+		rest = rest[end+1:]
+	}
+
+	return total, nil
+}
+
+// GetSubscriberCount retrieves the subscriber count of the authenticated
+// user's channel. YouTube rounds the count down to three significant figures
+// once it exceeds 1000.
+func (c *YouTubeClient) GetSubscriberCount(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"GET",
+		c.baseURL+"/channels?part=statistics&mine=true",
+		nil,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return 0, googleError("channel request", resp.StatusCode, body)
+	}
+
+	var result struct {
+		Items []struct {
+			Statistics struct {
+				SubscriberCount       string `json:"subscriberCount"`
+				HiddenSubscriberCount bool   `json:"hiddenSubscriberCount"`
+			} `json:"statistics"`
+		} `json:"items"`
+	}
+
+	if err = decodeJSONBody(resp, &result); err != nil {
+		return 0, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(result.Items) == 0 {
+		return 0, fmt.Errorf("channel: %w", ErrNotFound)
+	}
+	stats := result.Items[0].Statistics
+	if stats.HiddenSubscriberCount {
+		return 0, errors.New("the channel hides its subscriber count")
+	}
+
+	count, err := strconv.ParseInt(stats.SubscriberCount, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid subscriber count %q: %w", stats.SubscriberCount, err)
+	}
+
+	recordMetric(c.Recorder, PlatformYouTube, MetricSubscribers, float64(count), time.Now())
+
+	return count, nil
+}
+
+// SearchContent searches for videos on YouTube. The stats of the results are
+// filled in with a batched videos.list call.
 func (c *YouTubeClient) SearchContent(ctx context.Context, query string) ([]ContentItem, error) {
 	req, err := http.NewRequestWithContext(
 		ctx,
@@ -683,7 +1365,7 @@ func (c *YouTubeClient) SearchContent(ctx context.Context, query string) ([]Cont
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("search failed with status %d: %s", resp.StatusCode, string(body))
+		return nil, googleError("search", resp.StatusCode, body)
 	}
 
 	var result struct {
@@ -699,41 +1381,30 @@ func (c *YouTubeClient) SearchContent(ctx context.Context, query string) ([]Cont
 		} `json:"items"`
 	}
 
-	if err = json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err = decodeJSONBody(resp, &result); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	items := make([]ContentItem, 0, len(result.Items))
-
-	// Batch fetch video statistics
 	videoIDs := make([]string, 0, len(result.Items))
 	for _, item := range result.Items {
 		videoIDs = append(videoIDs, item.ID.VideoID)
 	}
 
-	// If we have videos, fetch their stats
-	if len(videoIDs) > 0 {
-		for _, item := range result.Items {
-			// We'll only fetch basic info for this example
-			videoURL := fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.ID.VideoID)
-
-			// In a real implementation, we would fetch stats for each video
-			// But for this example, we'll just add placeholder stats
-			items = append(items, ContentItem{
-				ID:          item.ID.VideoID,
-				Title:       item.Snippet.Title,
-				Description: item.Snippet.Description,
-				URL:         videoURL,
-				Author:      item.Snippet.ChannelTitle,
-				Stats: PostStats{
-					Views:      0, // Would need separate API call for stats
-					Likes:      0,
-					Comments:   0,
-					Shares:     0,
-					Engagement: 0,
-				},
-			})
-		}
+	stats, err := c.getVideoStats(ctx, videoIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]ContentItem, 0, len(result.Items))
+	for _, item := range result.Items {
+		items = append(items, ContentItem{
+			ID:          item.ID.VideoID,
+			Title:       item.Snippet.Title,
+			Description: item.Snippet.Description,
+			URL:         fmt.Sprintf("https://www.youtube.com/watch?v=%s", item.ID.VideoID),
+			Author:      item.Snippet.ChannelTitle,
+			Stats:       stats[item.ID.VideoID],
+		})
 	}
 
 	return items, nil
@@ -756,7 +1427,7 @@ func (c *YouTubeClient) DeleteContent(ctx context.Context, contentID string) err
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete failed with status %d: %s", resp.StatusCode, string(body))
+		return googleError("delete", resp.StatusCode, body)
 	}
 
 	return nil
@@ -780,7 +1451,11 @@ func (c *YouTubeClient) UpdateContent(ctx context.Context, contentID string, dat
 		updateData["snippet"].(map[string]interface{})["tags"] = *data.Tags
 	}
 	if data.Privacy != nil {
-		updateData["status"].(map[string]interface{})["privacyStatus"] = *data.Privacy
+		privacyStatus, err := YouTubePrivacyStatus(*data.Privacy)
+		if err != nil {
+			return err
+		}
+		updateData["status"].(map[string]interface{})["privacyStatus"] = privacyStatus
 	}
 
 	jsonData, err := json.Marshal(updateData)
@@ -809,7 +1484,7 @@ func (c *YouTubeClient) UpdateContent(ctx context.Context, contentID string, dat
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("update failed with status %d: %s", resp.StatusCode, string(body))
+		return googleError("update", resp.StatusCode, body)
 	}
 
 	return nil
@@ -0,0 +1,457 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMultipartFileBodyStreamsReader(t *testing.T) {
+	video := bytes.Repeat([]byte("0123456789"), 50_000)
+	file := bytes.NewReader(video)
+
+	body, contentType, length, err := multipartFileBody(func(w *multipart.Writer) error {
+		return w.WriteField("caption", "launch")
+	}, "video", "clip.mp4", file, int64(len(video)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Len() != len(video) {
+		t.Fatalf("%d bytes were read before the body was, want the file to stream", len(video)-file.Len())
+	}
+
+	raw, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(len(raw)) != length {
+		t.Errorf("length = %d, but the body is %d bytes", length, len(raw))
+	}
+
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatal(err)
+	}
+	form, err := multipart.NewReader(bytes.NewReader(raw), params["boundary"]).ReadForm(1 << 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := form.Value["caption"]; len(got) != 1 || got[0] != "launch" {
+		t.Errorf("caption = %v", got)
+	}
+	files := form.File["video"]
+	if len(files) != 1 || files[0].Filename != "clip.mp4" {
+		t.Fatalf("video part = %+v", files)
+	}
+	part, err := files[0].Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer part.Close()
+	got, _ := io.ReadAll(part)
+	if !bytes.Equal(got, video) {
+		t.Errorf("file part is %d bytes, want the %d bytes of the reader", len(got), len(video))
+	}
+}
+
+func TestMultipartFileBodyUnknownSize(t *testing.T) {
+	_, _, length, err := multipartFileBody(func(*multipart.Writer) error { return nil },
+		"source", "clip.mp4", bytes.NewReader([]byte("data")), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if length != -1 {
+		t.Errorf("length = %d, want -1 for an unknown size", length)
+	}
+}
+
+// youtubeResumableServer simulates a YouTube resumable upload session that
+// stores at most storeLimit bytes of each chunk. failPut, when set, makes
+// that PUT (counted from 1) fail with a 503 without storing anything.
+type youtubeResumableServer struct {
+	t          *testing.T
+	size       int64
+	storeLimit int64
+	failPut    int
+
+	puts     int
+	received []byte
+	ranges   []string
+}
+
+func (s *youtubeResumableServer) handle(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/upload/youtube/v3/videos":
+		if r.URL.Query().Get("uploadType") != "resumable" {
+			s.t.Errorf("uploadType = %q, want resumable", r.URL.Query().Get("uploadType"))
+		}
+		if got := r.Header.Get("X-Upload-Content-Length"); got != strconv.FormatInt(s.size, 10) {
+			s.t.Errorf("X-Upload-Content-Length = %q, want %d", got, s.size)
+		}
+		var meta struct {
+			Snippet struct {
+				Title string `json:"title"`
+			} `json:"snippet"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&meta); err != nil || meta.Snippet.Title != "Launch" {
+			s.t.Errorf("metadata title = %q, err %v", meta.Snippet.Title, err)
+		}
+		w.Header().Set("Location", "https://www.googleapis.com/upload/youtube/v3/videos?uploadType=resumable&upload_id=xa298sd_f")
+		w.WriteHeader(http.StatusOK)
+
+	case r.Method == http.MethodPut && r.URL.Query().Get("upload_id") == "xa298sd_f":
+		s.puts++
+		s.ranges = append(s.ranges, r.Header.Get("Content-Range"))
+		chunk, _ := io.ReadAll(r.Body)
+
+		var start, end, total int64
+		if _, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total); err != nil {
+			s.t.Fatalf("Content-Range %q: %v", r.Header.Get("Content-Range"), err)
+		}
+		if start != int64(len(s.received)) || end-start+1 != int64(len(chunk)) || total != s.size {
+			s.t.Errorf("Content-Range %q with %d bytes, want a chunk starting at %d", r.Header.Get("Content-Range"), len(chunk), len(s.received))
+		}
+
+		if s.puts == s.failPut {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"code":503,"message":"Backend Error"}}`)
+			return
+		}
+
+		s.received = append(s.received, chunk[:min(int64(len(chunk)), s.storeLimit)]...)
+		if int64(len(s.received)) == s.size {
+			fmt.Fprint(w, `{"kind":"youtube#video","id":"dQw4w9WgXcQ"}`)
+			return
+		}
+		w.Header().Set("Range", fmt.Sprintf("bytes=0-%d", len(s.received)-1))
+		w.WriteHeader(http.StatusPermanentRedirect)
+
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL)
+	}
+}
+
+func TestYouTubeResumableUploadContinuesFromAcknowledgedOffset(t *testing.T) {
+	const kib256 = youtubeChunkGranularity
+	video := make([]byte, 3*kib256)
+	for i := range video {
+		video[i] = byte(i % 251)
+	}
+	path := writeTempFile(t, "launch.mp4", video)
+
+	// YouTube keeps only 256 KiB of each 512 KiB chunk, so every 308 asks
+	// for the second half of the chunk again
+	srv := &youtubeResumableServer{t: t, size: int64(len(video)), storeLimit: kib256}
+	_, client := newTestServer(t, srv.handle)
+	c := NewYouTubeClient("token")
+	c.httpClient = client
+
+	var rec progressRecorder
+	id, err := c.CreatePostResumable(context.Background(), PostData{
+		VideoPath: path,
+		Title:     "Launch",
+		Upload:    UploadOptions{Progress: rec.record},
+	}, 2*kib256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "dQw4w9WgXcQ" {
+		t.Errorf("id = %q", id)
+	}
+	if !bytes.Equal(srv.received, video) {
+		t.Errorf("YouTube stored %d bytes that differ from the %d byte video", len(srv.received), len(video))
+	}
+	want := []string{"bytes 0-524287/786432", "bytes 262144-786431/786432", "bytes 524288-786431/786432"}
+	if fmt.Sprint(srv.ranges) != fmt.Sprint(want) {
+		t.Errorf("Content-Range headers = %v, want %v", srv.ranges, want)
+	}
+	rec.check(t, int64(len(video)))
+}
+
+func TestYouTubeResumeUploadAfterInterruption(t *testing.T) {
+	const kib256 = youtubeChunkGranularity
+	video := bytes.Repeat([]byte("yt"), 3*kib256/2)
+	path := writeTempFile(t, "launch.mp4", video)
+	post := PostData{VideoPath: path, Title: "Launch"}
+
+	srv := &youtubeResumableServer{t: t, size: int64(len(video)), storeLimit: int64(len(video)), failPut: 2}
+	_, client := newTestServer(t, srv.handle)
+	c := NewYouTubeClient("token")
+	c.httpClient = client
+
+	_, err := c.CreatePostResumable(context.Background(), post, kib256)
+	var uploadErr *YouTubeUploadError
+	if !errors.As(err, &uploadErr) {
+		t.Fatalf("error = %v, want a *YouTubeUploadError", err)
+	}
+	if uploadErr.Session.Offset != kib256 || !strings.Contains(uploadErr.Session.URL, "upload_id=xa298sd_f") {
+		t.Fatalf("session = %+v, want the session URL at offset %d", uploadErr.Session, kib256)
+	}
+
+	id, err := c.ResumeUpload(context.Background(), post, uploadErr.Session, kib256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "dQw4w9WgXcQ" || !bytes.Equal(srv.received, video) {
+		t.Errorf("id = %q with %d bytes stored, want the whole %d byte video", id, len(srv.received), len(video))
+	}
+}
+
+func TestTikTokBuildPayloadMatchesUploadedBody(t *testing.T) {
+	video := bytes.Repeat([]byte("tiktok"), 10_000)
+	post := PostData{
+		VideoPath:   writeTempFile(t, "dance.mp4", video),
+		Title:       "Dance",
+		Description: "Friday moves",
+		Tags:        []string{"dance", "friday"},
+		Privacy:     VisibilityPublic,
+	}
+
+	var sent []byte
+	var sentType string
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v2/video/upload/" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		sentType = r.Header.Get("Content-Type")
+		var err error
+		if sent, err = io.ReadAll(r.Body); err != nil {
+			t.Fatal(err)
+		}
+		fmt.Fprint(w, `{"data":{"video_id":"v1"}}`)
+	})
+	c := NewTikTokClient("token", "key")
+	c.httpClient = client
+
+	payload, contentType, err := c.BuildPayload(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.CreatePost(context.Background(), post); err != nil {
+		t.Fatal(err)
+	}
+
+	// each body gets its own random boundary
+	boundary := func(contentType string) string {
+		_, params, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return params["boundary"]
+	}
+	built, uploaded := boundary(contentType), boundary(sentType)
+	if built == "" || uploaded == "" {
+		t.Fatalf("content types %q and %q lack a boundary", contentType, sentType)
+	}
+	if got := bytes.ReplaceAll(sent, []byte(uploaded), []byte(built)); !bytes.Equal(got, payload) {
+		t.Errorf("uploaded %d bytes that differ from the %d byte BuildPayload body", len(sent), len(payload))
+	}
+	if !bytes.Contains(payload, video) {
+		t.Error("payload does not contain the video")
+	}
+}
+
+func TestTikTokBuildPayloadConsumesVideoReader(t *testing.T) {
+	video := bytes.Repeat([]byte("tiktok"), 1_000)
+	reader := bytes.NewReader(video)
+	post := PostData{VideoReader: reader, VideoSize: int64(len(video)), VideoFilename: "dance.mp4", Title: "Dance"}
+
+	c := NewTikTokClient("token", "key")
+	payload, _, err := c.BuildPayload(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(payload, video) {
+		t.Error("payload does not contain the video")
+	}
+	if reader.Len() != 0 {
+		t.Errorf("%d bytes left in the reader, want it read to the end", reader.Len())
+	}
+
+	// rewinding the reader, as documented, makes the post usable again
+	if _, err := reader.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	again, _, err := c.BuildPayload(post)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(again, video) {
+		t.Error("payload built from the rewound reader does not contain the video")
+	}
+}
+
+func TestParseISO8601Duration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want time.Duration
+	}{
+		{"PT1H2M10S", time.Hour + 2*time.Minute + 10*time.Second},
+		{"PT45S", 45 * time.Second},
+		{"PT3M", 3 * time.Minute},
+		{"P1DT2H", 26 * time.Hour},
+		{"P1W", 7 * 24 * time.Hour},
+		{"PT1.5S", 1500 * time.Millisecond},
+		{"P0D", 0},
+	}
+	for _, tt := range tests {
+		got, err := parseISO8601Duration(tt.in)
+		if err != nil {
+			t.Errorf("parseISO8601Duration(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseISO8601Duration(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "P", "PT", "1H", "PT1H2", "PTH", "P1M", "P1Y", "PT1D", "P1H", "PT1HT2M"} {
+		if got, err := parseISO8601Duration(in); err == nil {
+			t.Errorf("parseISO8601Duration(%q) = %v, want an error", in, got)
+		}
+	}
+}
+
+func TestYouTubeGetVideoDetails(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/youtube/v3/videos" || q.Get("part") != "snippet,contentDetails,statistics" || q.Get("id") != "dQw4w9WgXcQ" {
+			t.Errorf("unexpected request %s", r.URL)
+		}
+		fmt.Fprint(w, `{"items":[{
+			"id": "dQw4w9WgXcQ",
+			"snippet": {
+				"title": "Launch",
+				"channelId": "UC38IQsAvIsxxjztdMZQtwHA",
+				"channelTitle": "Postly",
+				"publishedAt": "2024-03-01T12:00:00Z",
+				"tags": ["launch", "demo"],
+				"categoryId": "28"
+			},
+			"contentDetails": {"duration": "PT1H2M10S", "definition": "hd"},
+			"statistics": {"viewCount": "1000", "likeCount": "80", "commentCount": "12"}
+		}]}`)
+	})
+	c := NewYouTubeClient("token")
+	c.httpClient = client
+
+	details, err := c.GetVideoDetails(context.Background(), "dQw4w9WgXcQ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.Duration != time.Hour+2*time.Minute+10*time.Second || details.Definition != "hd" {
+		t.Errorf("duration = %v, definition = %q", details.Duration, details.Definition)
+	}
+	if details.CategoryID != "28" || fmt.Sprint(details.Tags) != "[launch demo]" || details.ChannelTitle != "Postly" ||
+		!details.PublishedAt.Equal(time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("snippet = %+v", details)
+	}
+	if details.Stats.Views != 1000 || details.Stats.Likes != 80 || details.Stats.Comments != 12 {
+		t.Errorf("stats = %+v", details.Stats)
+	}
+}
+
+func TestYouTubeGetVideoDetailsNotFound(t *testing.T) {
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items":[]}`)
+	})
+	c := NewYouTubeClient("token")
+	c.httpClient = client
+
+	if _, err := c.GetVideoDetails(context.Background(), "missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestYouTubeSearchContentFillsStats(t *testing.T) {
+	var statsRequests int
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		switch r.URL.Path {
+		case "/youtube/v3/search":
+			if q.Get("q") != "golang" || q.Get("part") != "snippet" {
+				t.Errorf("unexpected search %s", r.URL)
+			}
+			fmt.Fprint(w, `{"items":[
+				{"id":{"videoId":"v1"},"snippet":{"title":"Intro to Go","description":"Basics","channelTitle":"Gophers"}},
+				{"id":{"videoId":"v2"},"snippet":{"title":"Generics","description":"Type params","channelTitle":"Gophers"}},
+				{"id":{"videoId":"v3"},"snippet":{"title":"Private","description":"Hidden","channelTitle":"Someone"}}
+			]}`)
+		case "/youtube/v3/videos":
+			statsRequests++
+			if q.Get("part") != "statistics" || q.Get("id") != "v1,v2,v3" {
+				t.Errorf("unexpected stats request %s", r.URL)
+			}
+			// v2 hides its like count; v3 is not returned at all
+			fmt.Fprint(w, `{"items":[
+				{"id":"v1","statistics":{"viewCount":"1000","likeCount":"50","favoriteCount":"10","commentCount":"40"}},
+				{"id":"v2","statistics":{"viewCount":"200","favoriteCount":"0","commentCount":"10"}}
+			]}`)
+		default:
+			t.Errorf("unexpected request %s", r.URL)
+		}
+	})
+	c := NewYouTubeClient("token")
+	c.httpClient = client
+
+	items, err := c.SearchContent(context.Background(), "golang")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statsRequests != 1 {
+		t.Errorf("made %d stats requests, want 1", statsRequests)
+	}
+
+	want := []ContentItem{
+		{ID: "v1", Title: "Intro to Go", Description: "Basics", URL: "https://www.youtube.com/watch?v=v1", Author: "Gophers",
+			Stats: PostStats{Views: 1000, Likes: 50, Comments: 40, Shares: 10, Engagement: 10}},
+		{ID: "v2", Title: "Generics", Description: "Type params", URL: "https://www.youtube.com/watch?v=v2", Author: "Gophers",
+			Stats: PostStats{Views: 200, Comments: 10, Engagement: 5}},
+		{ID: "v3", Title: "Private", Description: "Hidden", URL: "https://www.youtube.com/watch?v=v3", Author: "Someone"},
+	}
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d", len(items), len(want))
+	}
+	for i, item := range items {
+		if fmt.Sprintf("%+v", item) != fmt.Sprintf("%+v", want[i]) {
+			t.Errorf("item %d = %+v, want %+v", i, item, want[i])
+		}
+	}
+}
+
+func TestYouTubeGetVideoStatsBatchesIDs(t *testing.T) {
+	var batches []int
+	_, client := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		ids := strings.Split(r.URL.Query().Get("id"), ",")
+		batches = append(batches, len(ids))
+		var items []string
+		for _, id := range ids {
+			items = append(items, fmt.Sprintf(`{"id":%q,"statistics":{"viewCount":"1"}}`, id))
+		}
+		fmt.Fprintf(w, `{"items":[%s]}`, strings.Join(items, ","))
+	})
+	c := NewYouTubeClient("token")
+	c.httpClient = client
+
+	ids := make([]string, 120)
+	for i := range ids {
+		ids[i] = "v" + strconv.Itoa(i)
+	}
+	stats, err := c.getVideoStats(context.Background(), ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(batches) != "[50 50 20]" || len(stats) != len(ids) {
+		t.Errorf("batches = %v with %d stats, want [50 50 20] and %d", batches, len(stats), len(ids))
+	}
+}
@@ -0,0 +1,117 @@
+package integrations
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OAuthToken is the platform-neutral form of an OAuth token, so tokens from
+// any integration can be stored and restored the same way
+type OAuthToken struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	TokenType    string    `json:"token_type,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+	Scopes       []string  `json:"scopes,omitempty"`
+}
+
+// ToOAuthToken converts an Instagram/LinkedIn token response to an OAuthToken.
+// The expiry is computed relative to the time of the call.
+func (t *TokenResponse) ToOAuthToken() *OAuthToken {
+	token := &OAuthToken{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		TokenType:    t.TokenType,
+		Scopes:       splitScopes(t.Scope),
+	}
+	if token.TokenType == "" {
+		token.TokenType = "Bearer"
+	}
+	if t.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return token
+}
+
+// ToOAuthToken converts a Google token to an OAuthToken
+func (t *GoogleToken) ToOAuthToken() *OAuthToken {
+	token := &OAuthToken{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		TokenType:    t.TokenType,
+		Expiry:       t.Expiry,
+		Scopes:       splitScopes(t.Scope),
+	}
+	if token.Expiry.IsZero() && t.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+	}
+	return token
+}
+
+// splitScopes splits a scope string; Google separates scopes with spaces,
+// LinkedIn with commas
+func splitScopes(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+// flightGroup collapses concurrent calls that share a key into a single
+// execution. It is used to make sure only one token refresh is in flight per
+// client: callers that arrive while a refresh is running wait for it and
+// receive its result instead of issuing a duplicate refresh request.
+//
+// The zero value is ready to use.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is an in-flight or completed flightGroup.Do call
+type flightCall struct {
+	wg   sync.WaitGroup
+	val  interface{}
+	err  error
+	dups int // callers waiting on this call, guarded by flightGroup.mu
+}
+
+// Do executes fn for the given key, making sure that only one execution is in
+// flight at a time. Duplicate callers wait for the original to complete and
+// share its result.
+func (g *flightGroup) Do(key string, fn func() (interface{}, error)) (interface{}, error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		call.dups++
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.val, call.err
+	}
+
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	// a panicking fn must still release the waiters and the key
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		call.wg.Done()
+	}()
+
+	// reported to the waiters if fn panics
+	call.err = errFlightPanicked
+	call.val, call.err = fn()
+
+	return call.val, call.err
+}
+
+// errFlightPanicked is returned to the callers waiting on a flightGroup call
+// whose fn panicked
+var errFlightPanicked = errors.New("token refresh panicked")
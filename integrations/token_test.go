@@ -0,0 +1,119 @@
+package integrations
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFlightGroupReleasesWaitersOnPanic(t *testing.T) {
+	var g flightGroup
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	go func() {
+		defer func() { recover() }()
+		g.Do("key", func() (interface{}, error) {
+			close(started)
+			<-release
+			panic("boom")
+		})
+	}()
+	<-started
+
+	const waiters = 3
+	errs := make(chan error, waiters)
+	for i := 0; i < waiters; i++ {
+		go func() {
+			_, err := g.Do("key", func() (interface{}, error) {
+				t.Error("a waiter ran its own fn instead of sharing the panicked call")
+				return nil, nil
+			})
+			errs <- err
+		}()
+	}
+
+	// release the panicking call only once every waiter is waiting on it
+	for deadline := time.Now().Add(2 * time.Second); ; {
+		g.mu.Lock()
+		dups := g.calls["key"].dups
+		g.mu.Unlock()
+		if dups == waiters {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d of %d waiters joined the call", dups, waiters)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+
+	for i := 0; i < waiters; i++ {
+		if err := <-errs; !errors.Is(err, errFlightPanicked) {
+			t.Errorf("waiter error = %v, want errFlightPanicked", err)
+		}
+	}
+
+	// the key must be free again, so the next call runs its fn
+	var calls int
+	v, err := g.Do("key", func() (interface{}, error) {
+		calls++
+		return "ok", nil
+	})
+	if err != nil || v != "ok" || calls != 1 {
+		t.Fatalf("Do after panic = %v, %v with %d calls, want ok from a new call", v, err, calls)
+	}
+}
+
+func TestTokenResponseToOAuthToken(t *testing.T) {
+	before := time.Now()
+	token := (&TokenResponse{
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresIn:    5184000,
+		Scope:        "r_liteprofile,w_member_social",
+	}).ToOAuthToken()
+
+	if token.AccessToken != "access" || token.RefreshToken != "refresh" {
+		t.Errorf("tokens = %q, %q", token.AccessToken, token.RefreshToken)
+	}
+	if token.TokenType != "Bearer" {
+		t.Errorf("TokenType = %q, want Bearer by default", token.TokenType)
+	}
+	if want := []string{"r_liteprofile", "w_member_social"}; !reflect.DeepEqual(token.Scopes, want) {
+		t.Errorf("Scopes = %v, want %v", token.Scopes, want)
+	}
+	lifetime := 5184000 * time.Second
+	if token.Expiry.Before(before.Add(lifetime)) || token.Expiry.After(time.Now().Add(lifetime)) {
+		t.Errorf("Expiry = %v, want about %v from now", token.Expiry, lifetime)
+	}
+
+	if token := (&TokenResponse{AccessToken: "access"}).ToOAuthToken(); !token.Expiry.IsZero() {
+		t.Errorf("Expiry = %v, want zero without expires_in", token.Expiry)
+	}
+}
+
+func TestGoogleTokenToOAuthToken(t *testing.T) {
+	expiry := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	token := (&GoogleToken{
+		AccessToken: "access",
+		TokenType:   "Bearer",
+		ExpiresIn:   3600,
+		Scope:       ScopeUserinfoEmail + " " + ScopeYouTubeUpload,
+		Expiry:      expiry,
+	}).ToOAuthToken()
+
+	if !token.Expiry.Equal(expiry) {
+		t.Errorf("Expiry = %v, want the token's own %v", token.Expiry, expiry)
+	}
+	if want := []string{ScopeUserinfoEmail, ScopeYouTubeUpload}; !reflect.DeepEqual(token.Scopes, want) {
+		t.Errorf("Scopes = %v, want %v", token.Scopes, want)
+	}
+
+	// Without an Expiry it is derived from expires_in
+	token = (&GoogleToken{AccessToken: "access", ExpiresIn: 3600}).ToOAuthToken()
+	if remaining := time.Until(token.Expiry); remaining < 59*time.Minute || remaining > time.Hour {
+		t.Errorf("Expiry is %v from now, want about an hour", remaining)
+	}
+}
@@ -2,11 +2,17 @@ package integrations
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,8 +25,29 @@ type TwitterClient struct {
 	TokenSecret string
 	HTTPClient  *http.Client
 	BaseURL     string
+	UploadURL   string        // v1.1 media upload endpoint
+	ThreadDelay time.Duration // pause between the tweets of a thread
+
+	limitMu  sync.Mutex
+	limiters map[string]*RateLimiter
+	limits   map[string]RateLimit
 }
 
+// Endpoint keys for SetRateLimiter and RateLimitStatus
+const (
+	TwitterEndpointCreateTweet = "POST /tweets"
+	TwitterEndpointDeleteTweet = "DELETE /tweets/:id"
+	TwitterEndpointGetTweet    = "GET /tweets/:id"
+	TwitterEndpointSearch      = "GET /tweets/search/recent"
+	TwitterEndpointMe          = "GET /users/me"
+	TwitterEndpointMentions    = "GET /users/:id/mentions"
+	TwitterEndpointUserByName  = "GET /users/by/username/:username"
+	TwitterEndpointSendDM      = "POST /dm_conversations/with/:id/messages"
+)
+
+// twitterNoRetry sends a request once; reads are not retried
+var twitterNoRetry = RetryConfig{MaxAttempts: 1}
+
 // NewTwitterClient creates a new Twitter API client
 func NewTwitterClient(apiKey, apiSecret, accessToken, tokenSecret, bearerToken string) *TwitterClient {
 	return &TwitterClient{
@@ -31,7 +58,103 @@ func NewTwitterClient(apiKey, apiSecret, accessToken, tokenSecret, bearerToken s
 		TokenSecret: tokenSecret,
 		HTTPClient:  &http.Client{Timeout: 30 * time.Second},
 		BaseURL:     "https://api.twitter.com/2",
+		UploadURL:   "https://upload.twitter.com/1.1/media/upload.json",
+		ThreadDelay: defaultThreadDelay,
+	}
+}
+
+// oauth1 returns a signer for requests made in the user's context: writes and
+// the v1.1 endpoints, which do not accept the app-only bearer token
+func (c *TwitterClient) oauth1() *oauth1Signer {
+	return newOAuth1Signer(c.APIKey, c.APISecret, c.AccessToken, c.TokenSecret)
+}
+
+// hasUserContext reports whether the client holds OAuth 1.0a user credentials
+func (c *TwitterClient) hasUserContext() bool {
+	return c.APIKey != "" && c.APISecret != "" && c.AccessToken != "" && c.TokenSecret != ""
+}
+
+// authorizeWrite authorizes a request that acts on behalf of the user. An
+// app-only bearer token cannot post or delete, so the request is signed with
+// OAuth 1.0a when user credentials are available; otherwise BearerToken is
+// assumed to be an OAuth 2.0 user token.
+func (c *TwitterClient) authorizeWrite(req *http.Request) {
+	if c.hasUserContext() {
+		c.oauth1().Sign(req, nil)
+		return
 	}
+	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+}
+
+// SetRateLimiter throttles requests to endpoint with limiter, e.g.
+// SetRateLimiter(TwitterEndpointCreateTweet, NewRateLimiter(100, 15*time.Minute)).
+// A nil limiter removes the throttle.
+func (c *TwitterClient) SetRateLimiter(endpoint string, limiter *RateLimiter) {
+	c.limitMu.Lock()
+	defer c.limitMu.Unlock()
+
+	if limiter == nil {
+		delete(c.limiters, endpoint)
+		return
+	}
+	if c.limiters == nil {
+		c.limiters = make(map[string]*RateLimiter)
+	}
+	c.limiters[endpoint] = limiter
+}
+
+// RateLimitStatus returns the rate limit state Twitter last reported for
+// endpoint. ok is false when no response from endpoint has been seen yet.
+func (c *TwitterClient) RateLimitStatus(endpoint string) (limit RateLimit, ok bool) {
+	c.limitMu.Lock()
+	defer c.limitMu.Unlock()
+
+	limit, ok = c.limits[endpoint]
+	return limit, ok
+}
+
+// throttle waits for the limiter of endpoint, if any, and sleeps until the
+// window resets when Twitter reported no requests left
+func (c *TwitterClient) throttle(ctx context.Context, endpoint string) error {
+	c.limitMu.Lock()
+	limiter := c.limiters[endpoint]
+	limit, seen := c.limits[endpoint]
+	c.limitMu.Unlock()
+
+	if seen && limit.Exhausted() {
+		if err := sleepContext(ctx, time.Until(limit.Reset)); err != nil {
+			return err
+		}
+	}
+
+	if limiter != nil {
+		return limiter.Wait(ctx)
+	}
+	return nil
+}
+
+// send throttles req as a request to endpoint, sends it with retry and
+// records the rate limit state of the response
+func (c *TwitterClient) send(req *http.Request, endpoint string, retry RetryConfig) (*http.Response, error) {
+	if err := c.throttle(req.Context(), endpoint); err != nil {
+		return nil, err
+	}
+
+	resp, err := doWithRetry(c.HTTPClient, req, retry)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit, ok := parseRateLimitHeaders(resp.Header); ok {
+		c.limitMu.Lock()
+		if c.limits == nil {
+			c.limits = make(map[string]RateLimit)
+		}
+		c.limits[endpoint] = limit
+		c.limitMu.Unlock()
+	}
+
+	return resp, nil
 }
 
 // Tweet represents a Twitter post
@@ -42,11 +165,98 @@ type Tweet struct {
 	AuthorID         string    `json:"author_id,omitempty"`
 	ConversationID   string    `json:"conversation_id,omitempty"`
 	InReplyToTweetID string    `json:"in_reply_to_tweet_id,omitempty"`
+
+	Attachments *TweetAttachments `json:"attachments,omitempty"`
+	// Media is resolved from the response includes when media is expanded
+	Media []MediaAttachment `json:"media,omitempty"`
+
+	PublicMetrics *TweetMetrics `json:"public_metrics,omitempty"`
+}
+
+// TweetMetrics are the public engagement counters of a tweet
+type TweetMetrics struct {
+	Impressions int64 `json:"impression_count"`
+	Likes       int64 `json:"like_count"`
+	Replies     int64 `json:"reply_count"`
+	Retweets    int64 `json:"retweet_count"`
+	Quotes      int64 `json:"quote_count"`
+	Bookmarks   int64 `json:"bookmark_count"`
+}
+
+// TweetAttachments references the media attached to a tweet
+type TweetAttachments struct {
+	MediaKeys []string `json:"media_keys,omitempty"`
+}
+
+// MediaAttachment is a photo, video or animated GIF attached to a tweet
+type MediaAttachment struct {
+	MediaKey        string `json:"media_key"`
+	Type            string `json:"type"`                        // photo, video or animated_gif
+	URL             string `json:"url,omitempty"`               // photos only
+	PreviewImageURL string `json:"preview_image_url,omitempty"` // videos and GIFs
+	DurationMS      int    `json:"duration_ms,omitempty"`       // videos only
 }
 
 // TweetResponse is the API response for a tweet
 type TweetResponse struct {
-	Data Tweet `json:"data"`
+	Data     Tweet `json:"data"`
+	Includes struct {
+		Media []MediaAttachment `json:"media,omitempty"`
+	} `json:"includes"`
+	Errors []TwitterProblem `json:"errors,omitempty"`
+}
+
+// TwitterProblem is an entry of the errors array of a v2 response, reported
+// for resources that could not be returned
+type TwitterProblem struct {
+	Value        string `json:"value,omitempty"`
+	Title        string `json:"title"`
+	Detail       string `json:"detail"`
+	Type         string `json:"type"` // e.g. https://api.twitter.com/2/problems/resource-not-found
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceID   string `json:"resource_id,omitempty"`
+}
+
+// ErrTweetUnavailable is returned when a tweet cannot be read because it was
+// deleted, withheld, or belongs to a protected or suspended account. The
+// error is a *TweetUnavailableError carrying the reason.
+var ErrTweetUnavailable = errors.New("tweet is unavailable")
+
+// TweetUnavailableError reports why a tweet could not be read. It matches
+// ErrTweetUnavailable with errors.Is.
+type TweetUnavailableError struct {
+	TweetID string
+	Title   string // e.g. "Not Found Error" or "Authorization Error"
+	Reason  string // the detail returned by the API
+	Type    string // problem type URI
+}
+
+func (e *TweetUnavailableError) Error() string {
+	return fmt.Sprintf("tweet %s is unavailable: %s", e.TweetID, e.Reason)
+}
+
+// Unwrap makes the error match ErrTweetUnavailable
+func (e *TweetUnavailableError) Unwrap() error {
+	return ErrTweetUnavailable
+}
+
+// resolveMedia fills in Media from the expanded media includes, in the order
+// the tweet references them
+func (t *Tweet) resolveMedia(included []MediaAttachment) {
+	if t.Attachments == nil || len(t.Attachments.MediaKeys) == 0 {
+		return
+	}
+
+	byKey := make(map[string]MediaAttachment, len(included))
+	for _, media := range included {
+		byKey[media.MediaKey] = media
+	}
+
+	for _, key := range t.Attachments.MediaKeys {
+		if media, ok := byKey[key]; ok {
+			t.Media = append(t.Media, media)
+		}
+	}
 }
 
 // TweetsResponse is the API response for multiple tweets
@@ -58,17 +268,129 @@ type TweetsResponse struct {
 	} `json:"meta"`
 }
 
-// CreateTweet posts a new tweet
-func (c *TwitterClient) CreateTweet(text string) (*Tweet, error) {
-	endpoint := fmt.Sprintf("%s/tweets", c.BaseURL)
+// Cursor returns the cursor of the page after this one
+func (r *TweetsResponse) Cursor() Cursor {
+	return Cursor{Platform: PlatformTwitter, Next: r.Meta.NextToken}
+}
 
+// CreateTweetWithMedia posts a new tweet with media uploaded by UploadMedia
+func (c *TwitterClient) CreateTweetWithMedia(text string, mediaIDs []string) (*Tweet, error) {
+	return c.postTweet(tweetPayload(text, mediaIDs))
+}
+
+// tweetPayload builds the body of a new tweet
+func tweetPayload(text string, mediaIDs []string) map[string]interface{} {
 	payload := map[string]interface{}{
 		"text": text,
 	}
+	if len(mediaIDs) > 0 {
+		payload["media"] = map[string]interface{}{
+			"media_ids": mediaIDs,
+		}
+	}
+	return payload
+}
+
+// BuildPayload returns the body and content type CreateTweet would send for
+// post, without sending it. The tweet text is the title and description
+// followed by the tags as hashtags; media is uploaded separately and not part
+// of the payload.
+func (c *TwitterClient) BuildPayload(post PostData) ([]byte, string, error) {
+	if post.ScheduleTime != nil {
+		return nil, "", ErrSchedulingUnsupported
+	}
+	return encodeTweet(tweetPayload(postText(post), nil))
+}
 
+// encodeTweet marshals a tweet body and returns it with its content type
+func encodeTweet(payload map[string]interface{}) ([]byte, string, error) {
 	jsonPayload, err := json.Marshal(payload)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling tweet: %v", err)
+		return nil, "", fmt.Errorf("error marshaling tweet: %v", err)
+	}
+	return jsonPayload, "application/json", nil
+}
+
+// CreateTweet posts a new tweet
+func (c *TwitterClient) CreateTweet(text string) (*Tweet, error) {
+	return c.postTweet(map[string]interface{}{
+		"text": text,
+	})
+}
+
+// ReplyToTweet posts a reply to an existing tweet
+func (c *TwitterClient) ReplyToTweet(inReplyToTweetID, text string) (*Tweet, error) {
+	return c.postTweet(map[string]interface{}{
+		"text": text,
+		"reply": map[string]string{
+			"in_reply_to_tweet_id": inReplyToTweetID,
+		},
+	})
+}
+
+// QuoteTweet posts a tweet quoting an existing tweet
+func (c *TwitterClient) QuoteTweet(text, quotedTweetID string) (*Tweet, error) {
+	if !isTweetID(quotedTweetID) {
+		return nil, fmt.Errorf("invalid quoted tweet ID: %q", quotedTweetID)
+	}
+
+	return c.postTweet(map[string]interface{}{
+		"text":           text,
+		"quote_tweet_id": quotedTweetID,
+	})
+}
+
+// maxTweetLength is the number of characters Twitter allows in a tweet
+const maxTweetLength = 280
+
+// defaultThreadDelay is the default pause between the tweets of a thread
+const defaultThreadDelay = 2 * time.Second
+
+// CreateThread posts texts as a thread, each tweet replying to the previous
+// one, and returns the created tweets in order. Every segment is validated
+// before anything is posted. If a tweet fails, the tweets posted so far are
+// returned along with the error so the thread can be resumed.
+func (c *TwitterClient) CreateThread(texts []string) ([]*Tweet, error) {
+	if len(texts) == 0 {
+		return nil, fmt.Errorf("thread has no tweets")
+	}
+	for i, text := range texts {
+		if text == "" {
+			return nil, fmt.Errorf("tweet %d of thread is empty", i+1)
+		}
+		if length := tweetLength(text); length > maxTweetLength {
+			return nil, fmt.Errorf("tweet %d of thread is %d characters, maximum is %d", i+1, length, maxTweetLength)
+		}
+	}
+
+	tweets := make([]*Tweet, 0, len(texts))
+	for i, text := range texts {
+		var tweet *Tweet
+		var err error
+		if i == 0 {
+			tweet, err = c.CreateTweet(text)
+		} else {
+			if c.ThreadDelay > 0 {
+				time.Sleep(c.ThreadDelay)
+			}
+			tweet, err = c.ReplyToTweet(tweets[i-1].ID, text)
+		}
+		if err != nil {
+			return tweets, fmt.Errorf("failed to post tweet %d of thread: %w", i+1, err)
+		}
+		tweets = append(tweets, tweet)
+	}
+
+	return tweets, nil
+}
+
+// postTweet sends a v2 create-tweet payload
+func (c *TwitterClient) postTweet(payload map[string]interface{}) (*Tweet, error) {
+	endpoint := fmt.Sprintf("%s/tweets", c.BaseURL)
+
+	jsonPayload, contentType, err := encodeTweet(payload)
+	if err != nil {
+		return nil, err
 	}
 
 	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonPayload))
@@ -76,10 +398,13 @@ func (c *TwitterClient) CreateTweet(text string) (*Tweet, error) {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", contentType)
 
-	resp, err := c.HTTPClient.Do(req)
+	// Every attempt is signed afresh since OAuth nonces must not be reused
+	retry := DefaultRetryConfig
+	retry.Prepare = c.authorizeWrite
+
+	resp, err := c.send(req, TwitterEndpointCreateTweet, retry)
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %v", err)
 	}
@@ -91,68 +416,256 @@ func (c *TwitterClient) CreateTweet(text string) (*Tweet, error) {
 	}
 
 	var tweetResp TweetResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tweetResp); err != nil {
+	if err := decodeJSONBody(resp, &tweetResp); err != nil {
 		return nil, fmt.Errorf("error decoding response: %v", err)
 	}
 
 	return &tweetResp.Data, nil
 }
 
-// ReplyToTweet posts a reply to an existing tweet
-func (c *TwitterClient) ReplyToTweet(inReplyToTweetID, text string) (*Tweet, error) {
-	endpoint := fmt.Sprintf("%s/tweets", c.BaseURL)
+// isTweetID reports whether id looks like a tweet ID (a snowflake of up to 19 digits)
+func isTweetID(id string) bool {
+	if id == "" || len(id) > 19 {
+		return false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
 
-	payload := map[string]interface{}{
-		"text": text,
-		"reply": map[string]string{
-			"in_reply_to_tweet_id": inReplyToTweetID,
-		},
+// twitterMediaChunkSize is the size of each APPEND segment; Twitter accepts
+// segments of up to 5 MB
+const twitterMediaChunkSize = 4 * 1024 * 1024
+
+// twitterMediaProcessingTimeout bounds how long UploadMedia waits for Twitter
+// to process a video or GIF after FINALIZE
+const twitterMediaProcessingTimeout = 5 * time.Minute
+
+// twitterMediaStatusMaxInterval caps the wait between STATUS polls when
+// Twitter gives no check_after_secs
+const twitterMediaStatusMaxInterval = 10 * time.Second
+
+// twitterProcessingInfo is the processing state of uploaded media
+type twitterProcessingInfo struct {
+	State          string `json:"state"` // pending, in_progress, succeeded or failed
+	CheckAfterSecs int    `json:"check_after_secs"`
+	Error          *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// twitterMediaResponse is the response of the media upload commands
+type twitterMediaResponse struct {
+	MediaIDString  string                 `json:"media_id_string"`
+	ProcessingInfo *twitterProcessingInfo `json:"processing_info,omitempty"`
+}
+
+// UploadMedia uploads an image, GIF or video with the chunked v1.1 media upload
+// (INIT, APPEND, FINALIZE) and returns the media ID to attach to a tweet.
+// mediaType is the MIME type of the media, e.g. "image/png" or "video/mp4".
+// When Twitter processes the media asynchronously, UploadMedia polls its STATUS
+// until it succeeds or fails.
+func (c *TwitterClient) UploadMedia(ctx context.Context, reader io.Reader, mediaType string) (string, error) {
+	if !c.hasUserContext() {
+		return "", fmt.Errorf("%w: media upload requires OAuth 1.0a user credentials", ErrNotConfigured)
 	}
 
-	jsonPayload, err := json.Marshal(payload)
+	// INIT needs the total size up front
+	data, err := io.ReadAll(reader)
 	if err != nil {
-		return nil, fmt.Errorf("error marshaling reply: %v", err)
+		return "", fmt.Errorf("error reading media: %v", err)
 	}
 
-	req, err := http.NewRequest("POST", endpoint, bytes.NewBuffer(jsonPayload))
+	initResp, err := c.mediaCommand(ctx, url.Values{
+		"command":        {"INIT"},
+		"total_bytes":    {strconv.Itoa(len(data))},
+		"media_type":     {mediaType},
+		"media_category": {twitterMediaCategory(mediaType)},
+	})
+	if err != nil {
+		return "", err
+	}
+	mediaID := initResp.MediaIDString
+
+	for segment := 0; segment*twitterMediaChunkSize < len(data); segment++ {
+		start := segment * twitterMediaChunkSize
+		end := start + twitterMediaChunkSize
+		if end > len(data) {
+			end = len(data)
+		}
+		if err := c.appendMedia(ctx, mediaID, segment, data[start:end]); err != nil {
+			return "", err
+		}
+	}
+
+	final, err := c.mediaCommand(ctx, url.Values{
+		"command":  {"FINALIZE"},
+		"media_id": {mediaID},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if final.ProcessingInfo != nil {
+		if err := c.waitForMedia(ctx, mediaID, final.ProcessingInfo); err != nil {
+			return "", err
+		}
+	}
+
+	return mediaID, nil
+}
+
+// twitterMediaCategory maps a MIME type to the media_category INIT expects
+func twitterMediaCategory(mediaType string) string {
+	switch {
+	case mediaType == "image/gif":
+		return "tweet_gif"
+	case strings.HasPrefix(mediaType, "video/"):
+		return "tweet_video"
+	}
+	return "tweet_image"
+}
+
+// mediaCommand sends a form-encoded INIT or FINALIZE command
+func (c *TwitterClient) mediaCommand(ctx context.Context, form url.Values) (*twitterMediaResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.UploadURL, strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
-	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c.oauth1().Sign(req, form)
+
+	return c.doMediaRequest(req)
+}
+
+// appendMedia uploads one segment of the media
+func (c *TwitterClient) appendMedia(ctx context.Context, mediaID string, segment int, chunk []byte) error {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+
+	_ = writer.WriteField("command", "APPEND")
+	_ = writer.WriteField("media_id", mediaID)
+	_ = writer.WriteField("segment_index", strconv.Itoa(segment))
+
+	part, err := writer.CreateFormFile("media", "blob")
+	if err != nil {
+		return fmt.Errorf("error creating form file: %v", err)
+	}
+	if _, err := part.Write(chunk); err != nil {
+		return fmt.Errorf("error writing media segment: %v", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error closing form: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.UploadURL, body)
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	c.oauth1().Sign(req, nil)
+
+	_, err = c.doMediaRequest(req)
+	return err
+}
+
+// waitForMedia polls the STATUS command until processing has finished, for
+// at most twitterMediaProcessingTimeout. Twitter's check_after_secs sets the
+// wait between polls; without it the wait backs off from one second.
+func (c *TwitterClient) waitForMedia(ctx context.Context, mediaID string, info *twitterProcessingInfo) error {
+	ctx, cancel := withOperationTimeout(ctx, twitterMediaProcessingTimeout)
+	defer cancel()
+
+	statusURL := c.UploadURL + "?" + url.Values{
+		"command":  {"STATUS"},
+		"media_id": {mediaID},
+	}.Encode()
+
+	// the FINALIZE response holds the first state, so the first check
+	// only looks at info
+	polled := false
+	err := pollUntilAfter(ctx, time.Second, twitterMediaStatusMaxInterval, time.Time{}, func() (bool, time.Duration, error) {
+		if polled {
+			req, err := http.NewRequestWithContext(ctx, "GET", statusURL, nil)
+			if err != nil {
+				return false, 0, fmt.Errorf("error creating request: %v", err)
+			}
+			c.oauth1().Sign(req, nil)
 
+			status, err := c.doMediaRequest(req)
+			if err != nil {
+				return false, 0, err
+			}
+			if status.ProcessingInfo == nil {
+				return true, 0, nil
+			}
+			info = status.ProcessingInfo
+		}
+		polled = true
+
+		switch info.State {
+		case "succeeded":
+			return true, 0, nil
+		case "failed":
+			if info.Error != nil {
+				return false, 0, fmt.Errorf("media processing failed: %s", info.Error.Message)
+			}
+			return false, 0, errors.New("media processing failed")
+		}
+		return false, time.Duration(info.CheckAfterSecs) * time.Second, nil
+	})
+	return operationError(ctx, "media processing", twitterMediaProcessingTimeout, err)
+}
+
+// doMediaRequest sends a media upload request and decodes its response
+func (c *TwitterClient) doMediaRequest(req *http.Request) (*twitterMediaResponse, error) {
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("error sending request: %v", err)
+		return nil, fmt.Errorf("error sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, _ := io.ReadAll(resp.Body)
 		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
 	}
 
-	var tweetResp TweetResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tweetResp); err != nil {
+	var result twitterMediaResponse
+	if err := decodeJSONBody(resp, &result); err != nil {
 		return nil, fmt.Errorf("error decoding response: %v", err)
 	}
 
-	return &tweetResp.Data, nil
+	return &result, nil
 }
 
-// GetTweet retrieves a tweet by ID
+// GetTweet retrieves a tweet by ID, with its attached media and public
+// metrics resolved
 func (c *TwitterClient) GetTweet(tweetID string) (*Tweet, error) {
-	endpoint := fmt.Sprintf("%s/tweets/%s", c.BaseURL, tweetID)
+	return c.GetTweetContext(context.Background(), tweetID)
+}
 
-	req, err := http.NewRequest("GET", endpoint, nil)
+// GetTweetContext is GetTweet with a context
+func (c *TwitterClient) GetTweetContext(ctx context.Context, tweetID string) (*Tweet, error) {
+	params := url.Values{}
+	params.Add("expansions", "attachments.media_keys")
+	params.Add("media.fields", "url,type,duration_ms,preview_image_url")
+	params.Add("tweet.fields", "public_metrics")
+
+	endpoint := fmt.Sprintf("%s/tweets/%s?%s", c.BaseURL, tweetID, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %v", err)
 	}
 
 	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.send(req, TwitterEndpointGetTweet, twitterNoRetry)
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %v", err)
 	}
@@ -164,13 +677,49 @@ func (c *TwitterClient) GetTweet(tweetID string) (*Tweet, error) {
 	}
 
 	var tweetResp TweetResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tweetResp); err != nil {
+	if err := decodeJSONBody(resp, &tweetResp); err != nil {
 		return nil, fmt.Errorf("error decoding response: %v", err)
 	}
 
+	// Deleted and withheld tweets come back in errors instead of data
+	if tweetResp.Data.ID == "" && len(tweetResp.Errors) > 0 {
+		problem := tweetResp.Errors[0]
+		return nil, &TweetUnavailableError{
+			TweetID: tweetID,
+			Title:   problem.Title,
+			Reason:  problem.Detail,
+			Type:    problem.Type,
+		}
+	}
+
+	tweetResp.Data.resolveMedia(tweetResp.Includes.Media)
+
 	return &tweetResp.Data, nil
 }
 
+// GetStats implements StatsProvider with the tweet's public metrics. Views are
+// impressions, Comments are replies and Shares are retweets plus quotes;
+// bookmarks only count towards Engagement.
+func (c *TwitterClient) GetStats(ctx context.Context, postID string) (PostStats, error) {
+	tweet, err := c.GetTweetContext(ctx, postID)
+	if err != nil {
+		return PostStats{}, err
+	}
+	if tweet.PublicMetrics == nil {
+		return PostStats{}, nil
+	}
+
+	m := tweet.PublicMetrics
+	shares := m.Retweets + m.Quotes
+	return PostStats{
+		Views:      m.Impressions,
+		Likes:      m.Likes,
+		Comments:   m.Replies,
+		Shares:     shares,
+		Engagement: engagementPercent(m.Likes+m.Replies+shares+m.Bookmarks, m.Impressions),
+	}, nil
+}
+
 // DeleteTweet deletes a tweet by ID
 func (c *TwitterClient) DeleteTweet(tweetID string) error {
 	endpoint := fmt.Sprintf("%s/tweets/%s", c.BaseURL, tweetID)
@@ -180,9 +729,11 @@ func (c *TwitterClient) DeleteTweet(tweetID string) error {
 		return fmt.Errorf("error creating request: %v", err)
 	}
 
-	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+	// Signed right before sending, after any throttling
+	retry := twitterNoRetry
+	retry.Prepare = c.authorizeWrite
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.send(req, TwitterEndpointDeleteTweet, retry)
 	if err != nil {
 		return fmt.Errorf("error sending request: %v", err)
 	}
@@ -213,7 +764,7 @@ func (c *TwitterClient) SearchRecentTweets(query string, maxResults int) ([]Twee
 
 	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
 
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.send(req, TwitterEndpointSearch, twitterNoRetry)
 	if err != nil {
 		return nil, fmt.Errorf("error sending request: %v", err)
 	}
@@ -225,13 +776,261 @@ func (c *TwitterClient) SearchRecentTweets(query string, maxResults int) ([]Twee
 	}
 
 	var tweetsResp TweetsResponse
-	if err := json.NewDecoder(resp.Body).Decode(&tweetsResp); err != nil {
+	if err := decodeJSONBody(resp, &tweetsResp); err != nil {
 		return nil, fmt.Errorf("error decoding response: %v", err)
 	}
 
 	return tweetsResp.Data, nil
 }
 
+// SendDM sends a direct message to a user, creating the one-to-one
+// conversation if needed, and returns the ID of the message event. DMs are
+// sent on behalf of the user, so OAuth 1.0a user credentials are required.
+func (c *TwitterClient) SendDM(ctx context.Context, recipientID, text string) (string, error) {
+	if !c.hasUserContext() {
+		return "", fmt.Errorf("%w: sending direct messages requires OAuth 1.0a user credentials", ErrNotConfigured)
+	}
+	if recipientID == "" {
+		return "", errors.New("recipient ID is required")
+	}
+
+	endpoint := fmt.Sprintf("%s/dm_conversations/with/%s/messages", c.BaseURL, url.PathEscape(recipientID))
+
+	jsonPayload, err := json.Marshal(map[string]interface{}{
+		"text": text,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshaling message: %v", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonPayload))
+	if err != nil {
+		return "", fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Every attempt is signed afresh since OAuth nonces must not be reused
+	retry := DefaultRetryConfig
+	retry.Prepare = c.authorizeWrite
+
+	resp, err := c.send(req, TwitterEndpointSendDM, retry)
+	if err != nil {
+		return "", fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var dmResp struct {
+		Data struct {
+			ConversationID string `json:"dm_conversation_id"`
+			EventID        string `json:"dm_event_id"`
+		} `json:"data"`
+	}
+	if err := decodeJSONBody(resp, &dmResp); err != nil {
+		return "", fmt.Errorf("error decoding response: %v", err)
+	}
+
+	if dmResp.Data.EventID == "" {
+		return "", errors.New("invalid message response, no event ID found")
+	}
+
+	return dmResp.Data.EventID, nil
+}
+
+// TwitterUser represents a Twitter account. Fields other than ID, Name and
+// Username are only set when requested through UserOptions.
+type TwitterUser struct {
+	ID              string              `json:"id"`
+	Name            string              `json:"name"`
+	Username        string              `json:"username"`
+	Description     string              `json:"description,omitempty"`
+	ProfileImageURL string              `json:"profile_image_url,omitempty"`
+	Protected       bool                `json:"protected,omitempty"`
+	Verified        bool                `json:"verified,omitempty"`
+	CreatedAt       time.Time           `json:"created_at,omitempty"`
+	PublicMetrics   *TwitterUserMetrics `json:"public_metrics,omitempty"`
+}
+
+// TwitterUserMetrics are the public counters of a Twitter account
+type TwitterUserMetrics struct {
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+	TweetCount     int `json:"tweet_count"`
+	ListedCount    int `json:"listed_count"`
+}
+
+// UserOptions selects the user fields returned by user lookups. Fields are
+// names from the user.fields parameter, such as "public_metrics".
+type UserOptions struct {
+	Fields []string
+}
+
+// defaultUserFields are requested when no UserOptions are given
+var defaultUserFields = []string{"description", "profile_image_url", "public_metrics"}
+
+// GetMe retrieves the user the client's access token belongs to. This
+// endpoint needs user context, so the request is authorized like a write.
+func (c *TwitterClient) GetMe() (*TwitterUser, error) {
+	endpoint := fmt.Sprintf("%s/users/me", c.BaseURL)
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	retry := twitterNoRetry
+	retry.Prepare = c.authorizeWrite
+
+	resp, err := c.send(req, TwitterEndpointMe, retry)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var userResp struct {
+		Data TwitterUser `json:"data"`
+	}
+	if err := decodeJSONBody(resp, &userResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %v", err)
+	}
+
+	return &userResp.Data, nil
+}
+
+// GetUserByUsername looks up a user by handle, without the leading "@". A nil
+// opts requests the description, profile image and public metrics.
+// ErrNotFound is returned when no such user exists.
+func (c *TwitterClient) GetUserByUsername(username string, opts *UserOptions) (*TwitterUser, error) {
+	username = strings.TrimPrefix(username, "@")
+	if username == "" {
+		return nil, errors.New("username is required")
+	}
+
+	fields := defaultUserFields
+	if opts != nil {
+		fields = opts.Fields
+	}
+
+	endpoint := fmt.Sprintf("%s/users/by/username/%s", c.BaseURL, url.PathEscape(username))
+	if len(fields) > 0 {
+		params := url.Values{}
+		params.Add("user.fields", strings.Join(fields, ","))
+		endpoint += "?" + params.Encode()
+	}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+
+	resp, err := c.send(req, TwitterEndpointUserByName, twitterNoRetry)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("twitter user %q: %w", username, ErrNotFound)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var userResp struct {
+		Data   *TwitterUser     `json:"data"`
+		Errors []TwitterProblem `json:"errors"`
+	}
+	if err := decodeJSONBody(resp, &userResp); err != nil {
+		return nil, fmt.Errorf("error decoding response: %v", err)
+	}
+
+	// unknown and suspended users come back as a 200 with only errors
+	if userResp.Data == nil {
+		if len(userResp.Errors) > 0 {
+			return nil, fmt.Errorf("twitter user %q: %w: %s", username, ErrNotFound, userResp.Errors[0].Detail)
+		}
+		return nil, fmt.Errorf("twitter user %q: %w", username, ErrNotFound)
+	}
+
+	return userResp.Data, nil
+}
+
+// GetMentions retrieves tweets mentioning a user, newest first. An empty
+// userID means the authenticated user. It returns the token for the next page,
+// which is empty on the last page.
+func (c *TwitterClient) GetMentions(userID string, maxResults int, paginationToken string) ([]Tweet, string, error) {
+	tweets, cursor, err := c.GetMentionsPage(userID, maxResults, Cursor{Next: paginationToken})
+	return tweets, cursor.Next, err
+}
+
+// GetMentionsPage is GetMentions with a Cursor in place of the pagination
+// token. Use the zero Cursor for the first page.
+func (c *TwitterClient) GetMentionsPage(userID string, maxResults int, cursor Cursor) ([]Tweet, Cursor, error) {
+	if userID == "" {
+		me, err := c.GetMe()
+		if err != nil {
+			return nil, Cursor{}, fmt.Errorf("error resolving authenticated user: %v", err)
+		}
+		userID = me.ID
+	}
+
+	endpoint := fmt.Sprintf("%s/users/%s/mentions", c.BaseURL, userID)
+
+	params := url.Values{}
+	params.Add("tweet.fields", "created_at,author_id,conversation_id")
+	if maxResults > 0 {
+		params.Add("max_results", fmt.Sprintf("%d", maxResults))
+	}
+	if cursor.Next != "" {
+		params.Add("pagination_token", cursor.Next)
+	}
+
+	req, err := http.NewRequest("GET", endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, Cursor{}, fmt.Errorf("error creating request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+c.BearerToken)
+
+	resp, err := c.send(req, TwitterEndpointMentions, twitterNoRetry)
+	if err != nil {
+		return nil, Cursor{}, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, Cursor{}, fmt.Errorf("API error: %d - %s", resp.StatusCode, string(body))
+	}
+
+	var tweetsResp TweetsResponse
+	if err := decodeJSONBody(resp, &tweetsResp); err != nil {
+		return nil, Cursor{}, fmt.Errorf("error decoding response: %v", err)
+	}
+
+	return tweetsResp.Data, tweetsResp.Cursor(), nil
+}
+
+// The background services stop their Start loop when closed
+var (
+	_ io.Closer = (*AutomatedTweeter)(nil)
+	_ io.Closer = (*AutoReplier)(nil)
+)
+
 // AutomatedTweeter handles scheduled posting
 type AutomatedTweeter struct {
 	Client       *TwitterClient
@@ -239,6 +1038,8 @@ type AutomatedTweeter struct {
 	Content      []string
 	CurrentIndex int
 	StopChan     chan struct{}
+
+	stopOnce sync.Once
 }
 
 // NewAutomatedTweeter creates a new automated tweeting service
@@ -279,11 +1080,22 @@ func (at *AutomatedTweeter) Start() {
 	}
 }
 
-// Stop halts the automated posting
+// Stop halts the automated posting. It is safe to call more than once.
 func (at *AutomatedTweeter) Stop() {
-	close(at.StopChan)
+	at.stopOnce.Do(func() {
+		close(at.StopChan)
+	})
 }
 
+// Close stops the automated posting, implementing io.Closer
+func (at *AutomatedTweeter) Close() error {
+	at.Stop()
+	return nil
+}
+
+// autoReplyDelay is the pause between consecutive automated replies
+const autoReplyDelay = 2 * time.Second
+
 // AutoReplier handles automated replies to tweets matching criteria
 type AutoReplier struct {
 	Client        *TwitterClient
@@ -292,6 +1104,8 @@ type AutoReplier struct {
 	CheckInterval time.Duration
 	StopChan      chan struct{}
 	LastTweetIDs  map[string]string
+
+	stopOnce sync.Once
 }
 
 // NewAutoReplier creates a new automatic reply service
@@ -346,6 +1160,17 @@ func (ar *AutoReplier) Start() {
 						ar.LastTweetIDs[query] = tweet.ID
 					}
 
+					// Hold off while the reply window is used up, so the
+					// replier stays responsive to Stop meanwhile
+					if limit, ok := ar.Client.RateLimitStatus(TwitterEndpointCreateTweet); ok && limit.Exhausted() {
+						fmt.Printf("Reply rate limit reached, waiting until %s\n", limit.Reset.Format(time.RFC3339))
+						select {
+						case <-time.After(time.Until(limit.Reset)):
+						case <-ar.StopChan:
+							return
+						}
+					}
+
 					// Reply to the tweet
 					_, err := ar.Client.ReplyToTweet(tweet.ID, ar.ReplyContent)
 					if err != nil {
@@ -354,8 +1179,13 @@ func (ar *AutoReplier) Start() {
 						fmt.Printf("Replied to tweet %s matching query '%s'\n", tweet.ID, query)
 					}
 
-					// Add a small delay to avoid rate limiting
-					time.Sleep(2 * time.Second)
+					// Add a small delay to avoid rate limiting, returning
+					// right away if the replier is stopped in the meantime
+					select {
+					case <-time.After(autoReplyDelay):
+					case <-ar.StopChan:
+						return
+					}
 				}
 			}
 		case <-ar.StopChan:
@@ -364,9 +1194,17 @@ func (ar *AutoReplier) Start() {
 	}
 }
 
-// Stop halts the automated reply monitoring
+// Stop halts the automated reply monitoring. It is safe to call more than once.
 func (ar *AutoReplier) Stop() {
-	close(ar.StopChan)
+	ar.stopOnce.Do(func() {
+		close(ar.StopChan)
+	})
+}
+
+// Close stops the automated reply monitoring, implementing io.Closer
+func (ar *AutoReplier) Close() error {
+	ar.Stop()
+	return nil
 }
 
 // Example usage
@@ -0,0 +1,876 @@
+package integrations
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestTwitterClient(t *testing.T, handler http.HandlerFunc) *TwitterClient {
+	t.Helper()
+
+	srv, client := newTestServer(t, handler)
+	c := NewTwitterClient("key", "secret", "token", "token-secret", "bearer")
+	c.HTTPClient = client
+	c.BaseURL = srv.URL + "/2"
+	c.UploadURL = srv.URL + "/1.1/media/upload.json"
+	c.ThreadDelay = 0
+	return c
+}
+
+func TestTwitterBuildPayloadRejectsSchedule(t *testing.T) {
+	c := NewTwitterClient("key", "secret", "token", "token-secret", "bearer")
+
+	at := time.Now().Add(time.Hour)
+	if _, _, err := c.BuildPayload(PostData{Title: "hello", ScheduleTime: &at}); !errors.Is(err, ErrSchedulingUnsupported) {
+		t.Errorf("BuildPayload error = %v, want ErrSchedulingUnsupported", err)
+	}
+	if _, _, err := c.BuildPayload(PostData{Title: "hello"}); err != nil {
+		t.Errorf("BuildPayload without a schedule: %v", err)
+	}
+}
+
+func TestTwitterQuoteTweetPayload(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/2/tweets" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); !strings.HasPrefix(got, "OAuth ") {
+			t.Errorf("Authorization = %q, want OAuth user context", got)
+		}
+
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if len(payload) != 2 || payload["text"] != "worth a read" || payload["quote_tweet_id"] != "1445880548472328192" {
+			t.Errorf("payload = %v", payload)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"data":{"id":"1445880548472328193","text":"worth a read https://t.co/x"}}`)
+	})
+
+	tweet, err := c.QuoteTweet("worth a read", "1445880548472328192")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tweet.ID != "1445880548472328193" {
+		t.Errorf("ID = %q", tweet.ID)
+	}
+}
+
+func TestTwitterQuoteTweetRejectsInvalidID(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, id := range []string{"", "abc", "12345678901234567890", "https://twitter.com/x/status/1"} {
+		if _, err := c.QuoteTweet("text", id); err == nil {
+			t.Errorf("QuoteTweet(%q) succeeded, want an error", id)
+		}
+	}
+}
+
+func TestTwitterGetMentionsPaginates(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/users/me":
+			if !strings.HasPrefix(r.Header.Get("Authorization"), "OAuth ") {
+				t.Errorf("users/me Authorization = %q, want OAuth user context", r.Header.Get("Authorization"))
+			}
+			fmt.Fprint(w, `{"data":{"id":"2244994945","name":"Dev","username":"dev"}}`)
+		case "/2/users/2244994945/mentions":
+			q := r.URL.Query()
+			if q.Get("max_results") != "5" {
+				t.Errorf("max_results = %q", q.Get("max_results"))
+			}
+			switch q.Get("pagination_token") {
+			case "":
+				fmt.Fprint(w, `{"data":[
+					{"id":"1","text":"@dev hi","author_id":"7","conversation_id":"1","created_at":"2026-10-01T12:00:00.000Z"},
+					{"id":"2","text":"@dev hello","author_id":"8","conversation_id":"2","created_at":"2026-10-01T13:00:00.000Z"}
+				],"meta":{"result_count":2,"next_token":"page2"}}`)
+			case "page2":
+				fmt.Fprint(w, `{"data":[{"id":"3","text":"@dev bye","author_id":"7"}],"meta":{"result_count":1}}`)
+			default:
+				t.Errorf("unexpected pagination_token %q", q.Get("pagination_token"))
+			}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	tweets, next, err := c.GetMentions("", 5, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets) != 2 || next != "page2" {
+		t.Fatalf("got %d tweets and next %q, want 2 and page2", len(tweets), next)
+	}
+	first := tweets[0]
+	if first.ID != "1" || first.Text != "@dev hi" || first.AuthorID != "7" || first.ConversationID != "1" {
+		t.Errorf("first mention decoded as %+v", first)
+	}
+	if want := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC); !first.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", first.CreatedAt, want)
+	}
+
+	tweets, next, err = c.GetMentions("2244994945", 5, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets) != 1 || tweets[0].ID != "3" || next != "" {
+		t.Errorf("second page = %+v, next %q", tweets, next)
+	}
+}
+
+func TestBackgroundServicesCloseTwice(t *testing.T) {
+	c := NewTwitterClient("key", "secret", "token", "token-secret", "bearer")
+	closers := map[string]io.Closer{
+		"AutomatedTweeter": NewAutomatedTweeter(c, time.Hour, []string{"hello"}),
+		"AutoReplier":      NewAutoReplier(c, []string{"query"}, "thanks", time.Hour),
+	}
+
+	for name, closer := range closers {
+		if err := closer.Close(); err != nil {
+			t.Errorf("%s: first Close = %v", name, err)
+		}
+		if err := closer.Close(); err != nil {
+			t.Errorf("%s: second Close = %v", name, err)
+		}
+	}
+}
+
+func TestAutoReplierStopsTwiceDuringReplyDelay(t *testing.T) {
+	replied := make(chan struct{}, 1)
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/recent":
+			fmt.Fprint(w, `{"data":[{"id":"100","text":"query match"}]}`)
+		case "/2/tweets":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"data":{"id":"101","text":"thanks"}}`)
+			select {
+			case replied <- struct{}{}:
+			default:
+			}
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	ar := NewAutoReplier(c, []string{"query"}, "thanks", 10*time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		ar.Start()
+		close(done)
+	}()
+
+	select {
+	case <-replied:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reply was sent")
+	}
+
+	// the replier is now waiting out autoReplyDelay
+	ar.Stop()
+	ar.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(autoReplyDelay / 2):
+		t.Fatal("Start did not return after Stop")
+	}
+}
+
+func TestAutomatedTweeterStopsTwice(t *testing.T) {
+	c := NewTwitterClient("key", "secret", "token", "token-secret", "bearer")
+	at := NewAutomatedTweeter(c, time.Hour, []string{"hello"})
+
+	done := make(chan struct{})
+	go func() {
+		at.Start()
+		close(done)
+	}()
+
+	at.Stop()
+	at.Stop()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Stop")
+	}
+}
+
+// checkOAuth1Signature recomputes the HMAC-SHA1 signature of a request signed
+// with the credentials of newTestTwitterClient and compares it with the one
+// in its Authorization header. It parses form bodies, which are signed too.
+func checkOAuth1Signature(t *testing.T, r *http.Request) {
+	t.Helper()
+
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "OAuth ") {
+		t.Fatalf("Authorization = %q, want an OAuth header", header)
+	}
+
+	params := map[string]string{}
+	for _, field := range strings.Split(strings.TrimPrefix(header, "OAuth "), ", ") {
+		key, value, ok := strings.Cut(field, "=")
+		if !ok {
+			t.Fatalf("malformed Authorization field %q", field)
+		}
+		value, err := url.PathUnescape(strings.Trim(value, `"`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		params[key] = value
+	}
+	if params["oauth_consumer_key"] != "key" || params["oauth_token"] != "token" || params["oauth_signature_method"] != "HMAC-SHA1" {
+		t.Errorf("OAuth parameters = %v", params)
+	}
+
+	signature := params["oauth_signature"]
+	delete(params, "oauth_signature")
+	if err := r.ParseForm(); err != nil {
+		t.Fatal(err)
+	}
+	for key, values := range r.Form {
+		params[key] = values[0]
+	}
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = percentEncode(key) + "=" + percentEncode(params[key])
+	}
+
+	baseURL := "http://" + r.Host + r.URL.Path
+	base := r.Method + "&" + percentEncode(baseURL) + "&" + percentEncode(strings.Join(pairs, "&"))
+	mac := hmac.New(sha1.New, []byte("secret&token-secret"))
+	mac.Write([]byte(base))
+	if want := base64.StdEncoding.EncodeToString(mac.Sum(nil)); signature != want {
+		t.Errorf("oauth_signature = %q, want %q for base %q", signature, want, base)
+	}
+}
+
+func TestTwitterSignsWritesWithOAuth1(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/2/tweets":
+			checkOAuth1Signature(t, r)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"data":{"id":"1","text":"hello"}}`)
+		case r.Method == http.MethodDelete && r.URL.Path == "/2/tweets/1":
+			checkOAuth1Signature(t, r)
+			fmt.Fprint(w, `{"data":{"deleted":true}}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/2/tweets/search/recent":
+			if got := r.Header.Get("Authorization"); got != "Bearer bearer" {
+				t.Errorf("search Authorization = %q, want the bearer token", got)
+			}
+			fmt.Fprint(w, `{"data":[],"meta":{"result_count":0}}`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	})
+
+	if _, err := c.CreateTweet("hello"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTweet("1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SearchRecentTweets("golang", 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTwitterUploadMediaChunkedFlow(t *testing.T) {
+	video := bytes.Repeat([]byte("v"), twitterMediaChunkSize+10)
+
+	var commands []string
+	var received []byte
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/1.1/media/upload.json" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		checkOAuth1Signature(t, r)
+
+		if r.Method == http.MethodGet {
+			q := r.URL.Query()
+			commands = append(commands, q.Get("command"))
+			if q.Get("media_id") != "m1" {
+				t.Errorf("STATUS media_id = %q", q.Get("media_id"))
+			}
+			fmt.Fprint(w, `{"media_id_string":"m1","processing_info":{"state":"succeeded","progress_percent":100}}`)
+			return
+		}
+
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			if err := r.ParseMultipartForm(8 << 20); err != nil {
+				t.Fatal(err)
+			}
+			commands = append(commands, r.FormValue("command")+":"+r.FormValue("segment_index"))
+			if r.FormValue("media_id") != "m1" {
+				t.Errorf("APPEND media_id = %q", r.FormValue("media_id"))
+			}
+			file, _, err := r.FormFile("media")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer file.Close()
+			chunk, _ := io.ReadAll(file)
+			received = append(received, chunk...)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		commands = append(commands, r.PostForm.Get("command"))
+		switch r.PostForm.Get("command") {
+		case "INIT":
+			if r.PostForm.Get("total_bytes") != strconv.Itoa(len(video)) ||
+				r.PostForm.Get("media_type") != "video/mp4" ||
+				r.PostForm.Get("media_category") != "tweet_video" {
+				t.Errorf("INIT form = %v", r.PostForm)
+			}
+			fmt.Fprint(w, `{"media_id":1,"media_id_string":"m1","expires_after_secs":86400}`)
+		case "FINALIZE":
+			fmt.Fprint(w, `{"media_id_string":"m1","processing_info":{"state":"pending","check_after_secs":1}}`)
+		}
+	})
+
+	mediaID, err := c.UploadMedia(context.Background(), bytes.NewReader(video), "video/mp4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mediaID != "m1" {
+		t.Errorf("media ID = %q, want m1", mediaID)
+	}
+	if fmt.Sprint(commands) != "[INIT APPEND:0 APPEND:1 FINALIZE STATUS]" {
+		t.Errorf("commands = %v", commands)
+	}
+	if !bytes.Equal(received, video) {
+		t.Errorf("received %d bytes, want the %d bytes of the video", len(received), len(video))
+	}
+}
+
+func TestTwitterUploadMediaProcessingFailure(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			t.Error("STATUS polled after processing failed")
+		}
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		r.ParseForm()
+		if r.PostForm.Get("command") == "INIT" {
+			fmt.Fprint(w, `{"media_id_string":"m1"}`)
+			return
+		}
+		fmt.Fprint(w, `{"media_id_string":"m1","processing_info":{"state":"failed","error":{"code":1,"name":"InvalidMedia","message":"Unsupported video codec"}}}`)
+	})
+
+	_, err := c.UploadMedia(context.Background(), strings.NewReader("not a video"), "video/mp4")
+	if err == nil || !strings.Contains(err.Error(), "Unsupported video codec") {
+		t.Fatalf("error = %v, want the processing error", err)
+	}
+}
+
+func TestTwitterUploadMediaRequiresUserContext(t *testing.T) {
+	c := NewTwitterClient("", "", "", "", "bearer")
+	if _, err := c.UploadMedia(context.Background(), strings.NewReader("x"), "image/png"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("error = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestTwitterGetTweetResolvesMedia(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/tweets/1460323737035677698" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		q := r.URL.Query()
+		if q.Get("expansions") != "attachments.media_keys" || !strings.Contains(q.Get("media.fields"), "preview_image_url") {
+			t.Errorf("query = %v", q)
+		}
+		fmt.Fprint(w, `{
+			"data": {
+				"id": "1460323737035677698",
+				"text": "Introducing a new era for the Twitter Developer Platform!",
+				"attachments": {"media_keys": ["3_1460323727544434691", "7_1460323727544434692"]},
+				"public_metrics": {"retweet_count": 9, "reply_count": 3, "like_count": 40, "quote_count": 1, "impression_count": 1200}
+			},
+			"includes": {
+				"media": [
+					{"media_key": "7_1460323727544434692", "type": "video", "duration_ms": 46947, "preview_image_url": "https://pbs.twimg.com/preview.jpg"},
+					{"media_key": "3_1460323727544434691", "type": "photo", "url": "https://pbs.twimg.com/media/photo.jpg"}
+				]
+			}
+		}`)
+	})
+
+	tweet, err := c.GetTweet("1460323737035677698")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweet.Media) != 2 {
+		t.Fatalf("got %d media, want 2", len(tweet.Media))
+	}
+
+	photo, video := tweet.Media[0], tweet.Media[1]
+	if photo.Type != "photo" || photo.URL != "https://pbs.twimg.com/media/photo.jpg" {
+		t.Errorf("photo = %+v", photo)
+	}
+	if video.Type != "video" || video.DurationMS != 46947 || video.PreviewImageURL != "https://pbs.twimg.com/preview.jpg" {
+		t.Errorf("video = %+v", video)
+	}
+	if tweet.PublicMetrics == nil || tweet.PublicMetrics.Impressions != 1200 || tweet.PublicMetrics.Likes != 40 {
+		t.Errorf("public metrics = %+v", tweet.PublicMetrics)
+	}
+}
+
+func TestTwitterCreateThreadChainsReplies(t *testing.T) {
+	var replyTo []string
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text  string `json:"text"`
+			Reply *struct {
+				InReplyToTweetID string `json:"in_reply_to_tweet_id"`
+			} `json:"reply"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		parent := ""
+		if payload.Reply != nil {
+			parent = payload.Reply.InReplyToTweetID
+		}
+		replyTo = append(replyTo, parent)
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"data":{"id":"%d","text":%q}}`, 100+len(replyTo), payload.Text)
+	})
+
+	tweets, err := c.CreateThread([]string{"one", "two", "three"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets) != 3 || tweets[0].ID != "101" || tweets[2].ID != "103" || tweets[1].Text != "two" {
+		t.Fatalf("tweets = %+v", tweets)
+	}
+	if fmt.Sprint(replyTo) != "[ 101 102]" {
+		t.Errorf("in_reply_to_tweet_id per tweet = %q, want none, 101, 102", replyTo)
+	}
+}
+
+func TestTwitterCreateThreadReturnsPostedTweetsOnFailure(t *testing.T) {
+	var requests int
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 3 {
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"detail":"You are not allowed to create a Tweet with duplicate content.","status":403}`)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"data":{"id":"%d","text":"t"}}`, requests)
+	})
+
+	tweets, err := c.CreateThread([]string{"one", "two", "three", "four"})
+	if err == nil || !strings.Contains(err.Error(), "tweet 3 of thread") {
+		t.Fatalf("error = %v, want a failure of tweet 3", err)
+	}
+	if len(tweets) != 2 || tweets[0].ID != "1" || tweets[1].ID != "2" {
+		t.Errorf("tweets = %+v, want the two posted before the failure", tweets)
+	}
+	if requests != 3 {
+		t.Errorf("requests = %d, want the thread to stop at the failure", requests)
+	}
+}
+
+func TestTwitterCreateThreadValidatesLength(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	})
+
+	for _, texts := range [][]string{
+		nil,
+		{"fine", strings.Repeat("a", 281)},
+		{"fine", ""},
+	} {
+		if _, err := c.CreateThread(texts); err == nil {
+			t.Errorf("CreateThread(%d tweets) succeeded, want a validation error", len(texts))
+		}
+	}
+
+	c = newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"data":{"id":"1"}}`)
+	})
+	if _, err := c.CreateThread([]string{strings.Repeat("a", 280)}); err != nil {
+		t.Errorf("a 280 character tweet was rejected: %v", err)
+	}
+}
+
+// setExhaustedRateLimit reports a used-up rate limit window on w that resets
+// in an hour
+func setExhaustedRateLimit(w http.ResponseWriter) {
+	w.Header().Set("x-rate-limit-limit", "900")
+	w.Header().Set("x-rate-limit-remaining", "0")
+	w.Header().Set("x-rate-limit-reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+}
+
+func TestTwitterRateLimitStatusPerEndpoint(t *testing.T) {
+	var requests int
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/2/tweets/123" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		setExhaustedRateLimit(w)
+		fmt.Fprint(w, `{"data":{"id":"123","text":"hello"}}`)
+	})
+
+	if _, ok := c.RateLimitStatus(TwitterEndpointGetTweet); ok {
+		t.Error("RateLimitStatus reported a limit before any request")
+	}
+	if _, err := c.GetTweet("123"); err != nil {
+		t.Fatal(err)
+	}
+
+	limit, ok := c.RateLimitStatus(TwitterEndpointGetTweet)
+	if !ok {
+		t.Fatal("RateLimitStatus did not record the response headers")
+	}
+	if limit.Limit != 900 || limit.Remaining != 0 || !limit.Exhausted() {
+		t.Errorf("limit = %+v, want 0 of 900 left", limit)
+	}
+	if until := time.Until(limit.Reset); until < 59*time.Minute || until > time.Hour {
+		t.Errorf("reset in %v, want about an hour", until)
+	}
+	if _, ok := c.RateLimitStatus(TwitterEndpointCreateTweet); ok {
+		t.Error("the limit of GET /tweets/:id leaked into POST /tweets")
+	}
+
+	// With the window used up the client sleeps until the reset instead of
+	// sending the request
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := c.GetTweetContext(ctx, "123"); err == nil || !strings.Contains(err.Error(), context.DeadlineExceeded.Error()) {
+		t.Errorf("error = %v, want the context deadline while waiting for the reset", err)
+	}
+	if requests != 1 {
+		t.Errorf("requests = %d, want 1", requests)
+	}
+}
+
+func TestTwitterSetRateLimiterSpacesRequests(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"id":"123","text":"hello"}}`)
+	})
+
+	const interval = 30 * time.Millisecond
+	c.SetRateLimiter(TwitterEndpointGetTweet, NewRateLimiter(1, interval))
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetTweet("123"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 2*interval {
+		t.Errorf("3 requests took %v, want at least %v with 1 request per %v", elapsed, 2*interval, interval)
+	}
+
+	c.SetRateLimiter(TwitterEndpointGetTweet, nil)
+	start = time.Now()
+	for i := 0; i < 3; i++ {
+		if _, err := c.GetTweet("123"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed >= 2*interval {
+		t.Errorf("3 requests took %v after removing the limiter", elapsed)
+	}
+}
+
+func TestAutoReplierWaitsForReplyRateLimit(t *testing.T) {
+	var posts atomic.Int32
+	searched := make(chan struct{}, 1)
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/2/tweets/search/recent":
+			fmt.Fprint(w, `{"data":[{"id":"100","text":"query match"}]}`)
+			select {
+			case searched <- struct{}{}:
+			default:
+			}
+		case "/2/tweets":
+			posts.Add(1)
+			setExhaustedRateLimit(w)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"data":{"id":"101","text":"thanks"}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	// Use up the reply window before the replier starts
+	if _, err := c.CreateTweet("hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	ar := NewAutoReplier(c, []string{"query"}, "thanks", 10*time.Millisecond)
+	done := make(chan struct{})
+	go func() {
+		ar.Start()
+		close(done)
+	}()
+
+	select {
+	case <-searched:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the replier never searched")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := posts.Load(); n != 1 {
+		t.Errorf("posts = %d, want no reply while the window is used up", n)
+	}
+
+	ar.Stop()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Start did not return after Stop while waiting for the reset")
+	}
+}
+
+func TestTwitterGetTweetReportsUnavailableTweet(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		title  string
+		reason string
+		typ    string
+	}{
+		{
+			"deleted",
+			`{"errors":[{"value":"1460323737035677698","detail":"Could not find tweet with id: [1460323737035677698].","title":"Not Found Error","resource_type":"tweet","parameter":"id","resource_id":"1460323737035677698","type":"https://api.twitter.com/2/problems/resource-not-found"}]}`,
+			"Not Found Error",
+			"Could not find tweet with id: [1460323737035677698].",
+			"https://api.twitter.com/2/problems/resource-not-found",
+		},
+		{
+			"withheld",
+			`{"errors":[{"resource_id":"1460323737035677698","parameter":"id","resource_type":"tweet","section":"data","title":"Authorization Error","value":"1460323737035677698","detail":"Sorry, you are not authorized to see the Tweet with id: [1460323737035677698].","type":"https://api.twitter.com/2/problems/not-authorized-for-resource"}]}`,
+			"Authorization Error",
+			"Sorry, you are not authorized to see the Tweet with id: [1460323737035677698].",
+			"https://api.twitter.com/2/problems/not-authorized-for-resource",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+				// The v2 API reports unavailable tweets with a 200
+				fmt.Fprint(w, tt.body)
+			})
+
+			tweet, err := c.GetTweet("1460323737035677698")
+			if tweet != nil {
+				t.Errorf("tweet = %+v, want nil", tweet)
+			}
+			if !errors.Is(err, ErrTweetUnavailable) {
+				t.Fatalf("error = %v, want ErrTweetUnavailable", err)
+			}
+			var unavailable *TweetUnavailableError
+			if !errors.As(err, &unavailable) {
+				t.Fatalf("error = %v, want a *TweetUnavailableError", err)
+			}
+			want := TweetUnavailableError{TweetID: "1460323737035677698", Title: tt.title, Reason: tt.reason, Type: tt.typ}
+			if *unavailable != want {
+				t.Errorf("error = %+v, want %+v", *unavailable, want)
+			}
+			if !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("Error() = %q does not carry the reason", err.Error())
+			}
+		})
+	}
+}
+
+func TestTwitterGetUserByUsernameDecodes(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/2/users/by/username/TwitterDev" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer bearer" {
+			t.Errorf("Authorization = %q, want the app bearer token", got)
+		}
+		if got := r.URL.Query().Get("user.fields"); got != "description,profile_image_url,public_metrics" {
+			t.Errorf("user.fields = %q, want the defaults", got)
+		}
+		fmt.Fprint(w, `{"data":{
+			"id": "2244994945",
+			"name": "Developers",
+			"username": "TwitterDev",
+			"description": "The voice of the X Dev team",
+			"profile_image_url": "https://pbs.twimg.com/profile_images/1445764922474827784/W2zEPN7U_normal.jpg",
+			"verified": true,
+			"created_at": "2013-12-14T04:35:55.000Z",
+			"public_metrics": {"followers_count": 513958, "following_count": 2039, "tweet_count": 3635, "listed_count": 1672}
+		}}`)
+	})
+
+	user, err := c.GetUserByUsername("@TwitterDev", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != "2244994945" || user.Name != "Developers" || user.Username != "TwitterDev" || !user.Verified || user.Protected {
+		t.Errorf("user = %+v", user)
+	}
+	if user.Description == "" || !strings.HasSuffix(user.ProfileImageURL, "_normal.jpg") {
+		t.Errorf("description = %q, profile image = %q", user.Description, user.ProfileImageURL)
+	}
+	if want := time.Date(2013, 12, 14, 4, 35, 55, 0, time.UTC); !user.CreatedAt.Equal(want) {
+		t.Errorf("CreatedAt = %v, want %v", user.CreatedAt, want)
+	}
+	want := TwitterUserMetrics{FollowersCount: 513958, FollowingCount: 2039, TweetCount: 3635, ListedCount: 1672}
+	if user.PublicMetrics == nil || *user.PublicMetrics != want {
+		t.Errorf("public metrics = %+v, want %+v", user.PublicMetrics, want)
+	}
+}
+
+func TestTwitterGetUserByUsernameFieldsAndErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		opts   *UserOptions
+		fields string
+		status int
+		body   string
+	}{
+		{"unknown user", nil, "description,profile_image_url,public_metrics", http.StatusOK,
+			`{"errors":[{"value":"nobody","detail":"Could not find user with username: [nobody].","title":"Not Found Error","type":"https://api.twitter.com/2/problems/resource-not-found"}]}`},
+		{"not found status", &UserOptions{Fields: []string{"created_at"}}, "created_at", http.StatusNotFound, `{}`},
+		{"no fields", &UserOptions{}, "", http.StatusOK, `{}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+				q := r.URL.Query()
+				if _, ok := q["user.fields"]; ok != (tt.fields != "") || q.Get("user.fields") != tt.fields {
+					t.Errorf("user.fields = %v, want %q", q["user.fields"], tt.fields)
+				}
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			})
+
+			user, err := c.GetUserByUsername("nobody", tt.opts)
+			if !errors.Is(err, ErrNotFound) || user != nil {
+				t.Errorf("GetUserByUsername() = %+v, %v, want ErrNotFound", user, err)
+			}
+		})
+	}
+
+	if _, err := NewTwitterClient("", "", "", "", "bearer").GetUserByUsername("@", nil); err == nil {
+		t.Error("expected an error for an empty username")
+	}
+}
+
+func TestTwitterSendDMPayload(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/2/dm_conversations/with/9876543210/messages" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		checkOAuth1Signature(t, r)
+
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Fatal(err)
+		}
+		if len(payload) != 1 || payload["text"] != "Thanks for reaching out!" {
+			t.Errorf("payload = %v, want only the text", payload)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"data":{"dm_conversation_id":"1234567890-9876543210","dm_event_id":"128341038123"}}`)
+	})
+
+	id, err := c.SendDM(context.Background(), "9876543210", "Thanks for reaching out!")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id != "128341038123" {
+		t.Errorf("SendDM() = %q, want the DM event ID", id)
+	}
+
+	appOnly := NewTwitterClient("", "", "", "", "bearer")
+	if _, err := appOnly.SendDM(context.Background(), "9876543210", "hi"); !errors.Is(err, ErrNotConfigured) {
+		t.Errorf("SendDM() without user context = %v, want ErrNotConfigured", err)
+	}
+}
+
+func TestTwitterGetMentionsResolvesUserWithOAuth2Token(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer user-token" {
+			t.Errorf("%s: Authorization = %q, want the OAuth 2.0 user token", r.URL.Path, got)
+		}
+		switch r.URL.Path {
+		case "/2/users/me":
+			fmt.Fprint(w, `{"data":{"id":"2244994945","name":"Postly","username":"postly"}}`)
+		case "/2/users/2244994945/mentions":
+			fmt.Fprint(w, `{"data":[{"id":"1","text":"@postly hi"}],"meta":{"result_count":1}}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	c.APIKey, c.APISecret, c.AccessToken, c.TokenSecret = "", "", "", ""
+	c.BearerToken = "user-token"
+
+	tweets, next, err := c.GetMentions("", 10, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tweets) != 1 || tweets[0].ID != "1" || next != "" {
+		t.Errorf("GetMentions() = %+v, %q", tweets, next)
+	}
+}
+
+func TestTwitterWaitForMediaStopsWithContext(t *testing.T) {
+	c := newTestTwitterClient(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request %s", r.URL)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := c.waitForMedia(ctx, "m1", &twitterProcessingInfo{State: "pending", CheckAfterSecs: 60})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("waited %v for check_after_secs past the deadline", elapsed)
+	}
+}
@@ -41,3 +41,20 @@ type LinkedInPostMetrics struct {
 	CTR            float64 `json:"ctr"`
 	EngagementRate float64 `json:"engagementRate"`
 }
+
+// LinkedInPaging is the paging block of a LinkedIn collection response
+type LinkedInPaging struct {
+	Start int `json:"start"`
+	Count int `json:"count"`
+	Total int `json:"total,omitempty"`
+	Links []struct {
+		Rel  string `json:"rel"`
+		Href string `json:"href"`
+	} `json:"links,omitempty"`
+}
+
+// LinkedInCompanyPagesPage is one page of the company pages a user administers
+type LinkedInCompanyPagesPage struct {
+	Pages  []LinkedInCompanyPage `json:"pages"`
+	Paging LinkedInPaging        `json:"paging"`
+}
@@ -0,0 +1,63 @@
+package integrations
+
+import "fmt"
+
+// Visibility is the audience a post is published to. Each platform supports a
+// different subset; use the mapping functions below to translate it.
+type Visibility string
+
+const (
+	VisibilityPublic      Visibility = "public"
+	VisibilityPrivate     Visibility = "private"
+	VisibilityUnlisted    Visibility = "unlisted"
+	VisibilityConnections Visibility = "connections"
+)
+
+// unsupportedVisibility returns the error for a visibility a platform cannot express
+func unsupportedVisibility(platform string, v Visibility) error {
+	return fmt.Errorf("visibility %q is not supported by %s", v, platform)
+}
+
+// YouTubePrivacyStatus maps v to a YouTube status.privacyStatus value.
+// An empty visibility maps to an empty status, leaving YouTube's default.
+func YouTubePrivacyStatus(v Visibility) (string, error) {
+	switch v {
+	case "":
+		return "", nil
+	case VisibilityPublic:
+		return "public", nil
+	case VisibilityPrivate:
+		return "private", nil
+	case VisibilityUnlisted:
+		return "unlisted", nil
+	}
+	return "", unsupportedVisibility("YouTube", v)
+}
+
+// TikTokPrivacyLevel maps v to a TikTok privacy_level value.
+// An empty visibility maps to an empty level, leaving TikTok's default.
+func TikTokPrivacyLevel(v Visibility) (string, error) {
+	switch v {
+	case "":
+		return "", nil
+	case VisibilityPublic:
+		return "PUBLIC_TO_EVERYONE", nil
+	case VisibilityPrivate:
+		return "SELF_ONLY", nil
+	case VisibilityConnections:
+		return "MUTUAL_FOLLOW_FRIENDS", nil
+	}
+	return "", unsupportedVisibility("TikTok", v)
+}
+
+// LinkedInVisibility maps v to a LinkedIn MemberNetworkVisibility value.
+// An empty visibility maps to PUBLIC.
+func LinkedInVisibility(v Visibility) (string, error) {
+	switch v {
+	case "", VisibilityPublic:
+		return "PUBLIC", nil
+	case VisibilityConnections:
+		return "CONNECTIONS", nil
+	}
+	return "", unsupportedVisibility("LinkedIn", v)
+}
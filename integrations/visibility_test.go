@@ -0,0 +1,50 @@
+package integrations
+
+import "testing"
+
+func TestVisibilityMapping(t *testing.T) {
+	mappers := map[string]func(Visibility) (string, error){
+		"YouTube":  YouTubePrivacyStatus,
+		"TikTok":   TikTokPrivacyLevel,
+		"LinkedIn": LinkedInVisibility,
+	}
+
+	tests := []struct {
+		platform   string
+		visibility Visibility
+		want       string
+		wantErr    bool
+	}{
+		{"YouTube", "", "", false},
+		{"YouTube", VisibilityPublic, "public", false},
+		{"YouTube", VisibilityPrivate, "private", false},
+		{"YouTube", VisibilityUnlisted, "unlisted", false},
+		{"YouTube", VisibilityConnections, "", true},
+
+		{"TikTok", "", "", false},
+		{"TikTok", VisibilityPublic, "PUBLIC_TO_EVERYONE", false},
+		{"TikTok", VisibilityPrivate, "SELF_ONLY", false},
+		{"TikTok", VisibilityConnections, "MUTUAL_FOLLOW_FRIENDS", false},
+		{"TikTok", VisibilityUnlisted, "", true},
+
+		{"LinkedIn", "", "PUBLIC", false},
+		{"LinkedIn", VisibilityPublic, "PUBLIC", false},
+		{"LinkedIn", VisibilityConnections, "CONNECTIONS", false},
+		{"LinkedIn", VisibilityPrivate, "", true},
+		{"LinkedIn", VisibilityUnlisted, "", true},
+
+		{"YouTube", "friends", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.platform+"/"+string(tt.visibility), func(t *testing.T) {
+			got, err := mappers[tt.platform](tt.visibility)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}